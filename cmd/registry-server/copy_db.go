@@ -0,0 +1,79 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/server/registry"
+	"github.com/spf13/pflag"
+)
+
+// copyDatabase implements the copy-db mode, which copies all stored data
+// from one database to another while the server is not running.
+func copyDatabase(args []string) error {
+	flags := pflag.NewFlagSet("copy-db", pflag.ContinueOnError)
+	var from, to string
+	var batchSize int
+	flags.StringVar(&from, "from", "", "The source database, as driver:dsn.")
+	flags.StringVar(&to, "to", "", "The destination database, as driver:dsn.")
+	flags.IntVar(&batchSize, "batch-size", 1000, "The number of rows to copy at a time.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	src, err := parseDatabase("from", from)
+	if err != nil {
+		return err
+	}
+	dst, err := parseDatabase("to", to)
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return fmt.Errorf("invalid --batch-size %d: must be positive", batchSize)
+	}
+
+	logger := log.NewLogger()
+	logger.Infof("Copying %s database to %s database", src.Driver, dst.Driver)
+	err = registry.CopyDatabase(context.Background(), src, dst, registry.CopyDatabaseOptions{
+		BatchSize: batchSize,
+		Progress: func(table string, rows int64) {
+			logger.Infof("Copied %d rows of %s", rows, table)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info("Copy complete and verified")
+	return nil
+}
+
+// parseDatabase parses a database flag of the form driver:dsn.
+func parseDatabase(flag, value string) (registry.DatabaseConfig, error) {
+	driver, dsn, ok := strings.Cut(value, ":")
+	if !ok || dsn == "" {
+		return registry.DatabaseConfig{}, fmt.Errorf("invalid --%s %q: must be of the form driver:dsn", flag, value)
+	}
+	switch driver {
+	case "sqlite3", "postgres", "cloudsqlpostgres":
+	default:
+		return registry.DatabaseConfig{}, fmt.Errorf("invalid --%s driver %q: must be one of [sqlite3, postgres, cloudsqlpostgres]", flag, driver)
+	}
+	return registry.DatabaseConfig{Driver: driver, DSN: dsn}, nil
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/apigee/registry/log"
 	"github.com/apigee/registry/log/interceptor"
@@ -32,10 +33,21 @@ import (
 // ServerConfig is the top-level configuration structure.
 type ServerConfig struct {
 	// Server port. If unset or zero, an open port will be assigned.
-	Port     int            `yaml:"port"`
-	Database DatabaseConfig `yaml:"database"`
-	Logging  LoggingConfig  `yaml:"logging"`
-	Pubsub   PubsubConfig   `yaml:"pubsub"`
+	Port       int              `yaml:"port"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Logging    LoggingConfig    `yaml:"logging"`
+	Pubsub     PubsubConfig     `yaml:"pubsub"`
+	References ReferencesConfig `yaml:"references"`
+	Webhooks   []WebhookConfig  `yaml:"webhooks"`
+	Specs      SpecsConfig      `yaml:"specs"`
+	Audit      AuditConfig      `yaml:"audit"`
+	Quotas     QuotasConfig     `yaml:"quotas"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+	Uploads    UploadsConfig    `yaml:"uploads"`
+	// Metadata keys set by an authenticating proxy that identify callers
+	// in audit events and rate limits. Only set these if all requests pass
+	// through a proxy that removes them from client requests.
+	PrincipalHeaders []string `yaml:"principal_headers"`
 }
 
 // DatabaseConfig holds database configuration.
@@ -47,6 +59,12 @@ type DatabaseConfig struct {
 	// PostgreSQL Reference: See "Connection Strings" at https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING
 	// SQLite Reference: See "URI filename examples" at https://www.sqlite.org/c3ref/open.html
 	Config string `yaml:"config"`
+	// ReplicaConfig optionally configures a connection to a read-only replica
+	// of the database, which uses the same driver. The format is a DSN.
+	ReplicaConfig string `yaml:"replica_config"`
+	// InstanceDir is the directory of the SQLite databases of provisioned instances.
+	// It defaults to the "instances" subdirectory of the directory of the database.
+	InstanceDir string `yaml:"instance_dir"`
 }
 
 // LoggingConfig holds logging configuration.
@@ -69,6 +87,86 @@ type PubsubConfig struct {
 	Project string `yaml:"project"`
 }
 
+// ReferencesConfig holds configuration for references between resources.
+type ReferencesConfig struct {
+	// Validate references in recommended_version, recommended_deployment,
+	// and api_spec_revision fields when resources are created or updated.
+	// Values: [ true, false ]
+	Validate bool `yaml:"validate"`
+	// Deletion controls the deletion of resources that are referenced by others.
+	// Values: [ ignore, reject, clear ]
+	Deletion string `yaml:"deletion"`
+}
+
+// SpecsConfig holds configuration for the handling of spec contents.
+type SpecsConfig struct {
+	// Summarize spec contents into a ".summary" artifact attached to each spec.
+	// Values: [ true, false ]
+	Summarize bool `yaml:"summarize"`
+}
+
+// AuditConfig holds configuration for the audit log.
+type AuditConfig struct {
+	// Enable recording of an audit event for every call that changes a resource.
+	// Values: [ true, false ]
+	Enable bool `yaml:"enable"`
+}
+
+// QuotaLimitsConfig holds limits on the resources of a project.
+// Zero values are unlimited.
+type QuotaLimitsConfig struct {
+	// Maximum number of APIs in a project.
+	MaxApis int64 `yaml:"max_apis"`
+	// Maximum number of revisions of each spec.
+	MaxRevisionsPerSpec int64 `yaml:"max_revisions_per_spec"`
+	// Maximum total size in bytes of the contents of specs and artifacts in a project.
+	MaxBlobBytes int64 `yaml:"max_blob_bytes"`
+}
+
+// QuotasConfig holds quota configuration.
+type QuotasConfig struct {
+	// Limits that apply to all projects that aren't listed in Projects.
+	QuotaLimitsConfig `yaml:",inline"`
+	// Limits of specific projects, keyed by project ID. These replace the default limits.
+	Projects map[string]QuotaLimitsConfig `yaml:"projects"`
+}
+
+// RateLimitConfig holds rate limiting configuration.
+// Calls are limited separately for each caller in each project.
+type RateLimitConfig struct {
+	// Sustained rate of calls that are allowed. If zero, calls are not limited.
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	// Number of calls that are allowed at once. If zero, the rate (rounded up) is used.
+	Burst int `yaml:"burst"`
+}
+
+// UploadsConfig holds configuration for uploads of spec and artifact contents.
+type UploadsConfig struct {
+	// Unfinished uploads that haven't received any data for this long are deleted, e.g. "24h".
+	// If unset, a default of 24h is used.
+	Expiration time.Duration `yaml:"expiration"`
+}
+
+// WebhookConfig holds configuration for an admission webhook.
+type WebhookConfig struct {
+	// Name of the webhook, used in logs and error messages.
+	Name string `yaml:"name"`
+	// URL where admission reviews are posted.
+	URL string `yaml:"url"`
+	// Methods that are sent to the webhook, e.g. "CreateApiSpec". If unset, all mutating methods are sent.
+	Methods []string `yaml:"methods"`
+	// Patterns of resource names that are sent to the webhook. If unset, all resources are sent.
+	Resources []string `yaml:"resources"`
+	// Timeout for webhook calls, e.g. "5s". If unset, a default of 10s is used.
+	Timeout time.Duration `yaml:"timeout"`
+	// Failure determines the handling of mutations when the webhook can't be reached.
+	// Values: [ open, closed ]
+	Failure string `yaml:"failure"`
+	// Mutating allows the webhook to modify the proposed resource.
+	// Values: [ true, false ]
+	Mutating bool `yaml:"mutating"`
+}
+
 // default configuration
 var config = ServerConfig{
 	Port: 8080,
@@ -84,9 +182,20 @@ var config = ServerConfig{
 		Enable:  false,
 		Project: "",
 	},
+	References: ReferencesConfig{
+		Validate: false,
+		Deletion: "ignore",
+	},
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "copy-db" {
+		if err := copyDatabase(os.Args[2:]); err != nil {
+			log.NewLogger().WithError(err).Fatal("Failed to copy database")
+		}
+		return
+	}
+
 	var configPath string
 	pflag.StringVarP(&configPath, "configuration", "c", "", "The server configuration file to load.")
 	pflag.Parse()
@@ -125,6 +234,21 @@ func main() {
 		LogFormat: config.Logging.Format,
 		Notify:    config.Pubsub.Enable,
 		ProjectID: config.Pubsub.Project,
+
+		ValidateReferences: config.References.Validate,
+		ReferenceDeletion:  config.References.Deletion,
+		Webhooks:           webhooks(config.Webhooks),
+		SummarizeSpecs:     config.Specs.Summarize,
+		Audit:              config.Audit.Enable,
+		PrincipalHeaders:   config.PrincipalHeaders,
+		Quotas:             quotas(config.Quotas),
+		RateLimit: registry.RateLimitConfig{
+			RequestsPerSecond: config.RateLimit.RequestsPerSecond,
+			Burst:             config.RateLimit.Burst,
+		},
+		UploadExpiration: config.Uploads.Expiration,
+		DBReplicaConfig:  config.Database.ReplicaConfig,
+		InstanceDir:      config.Database.InstanceDir,
 	})
 	if err != nil {
 		logger.WithError(err).Fatalf("Failed to create registry server")
@@ -171,9 +295,77 @@ func validateConfig() error {
 		return fmt.Errorf("invalid pubsub.project %q: pubsub cannot be enabled without GCP project ID", project)
 	}
 
+	switch deletion := config.References.Deletion; deletion {
+	case "", "ignore", "reject", "clear":
+	default:
+		return fmt.Errorf("invalid references.deletion %q: must be one of [ignore, reject, clear]", deletion)
+	}
+
+	for i, hook := range config.Webhooks {
+		if hook.URL == "" {
+			return fmt.Errorf("invalid webhooks[%d].url: must be set", i)
+		}
+		if hook.Timeout < 0 {
+			return fmt.Errorf("invalid webhooks[%d].timeout %q: must be non-negative", i, hook.Timeout)
+		}
+		switch failure := hook.Failure; failure {
+		case "", "open", "closed":
+		default:
+			return fmt.Errorf("invalid webhooks[%d].failure %q: must be one of [open, closed]", i, failure)
+		}
+	}
+
+	if rps := config.RateLimit.RequestsPerSecond; rps < 0 {
+		return fmt.Errorf("invalid rate_limit.requests_per_second %v: must be non-negative", rps)
+	}
+	if burst := config.RateLimit.Burst; burst < 0 {
+		return fmt.Errorf("invalid rate_limit.burst %d: must be non-negative", burst)
+	}
+
+	if expiration := config.Uploads.Expiration; expiration < 0 {
+		return fmt.Errorf("invalid uploads.expiration %q: must be non-negative", expiration)
+	}
+
 	return nil
 }
 
+func webhooks(conf []WebhookConfig) []registry.WebhookConfig {
+	hooks := make([]registry.WebhookConfig, len(conf))
+	for i, c := range conf {
+		hooks[i] = registry.WebhookConfig{
+			Name:      c.Name,
+			URL:       c.URL,
+			Methods:   c.Methods,
+			Resources: c.Resources,
+			Timeout:   c.Timeout,
+			FailOpen:  c.Failure == "open",
+			Mutating:  c.Mutating,
+		}
+		if hooks[i].Name == "" {
+			hooks[i].Name = c.URL
+		}
+	}
+	return hooks
+}
+
+func quotas(conf QuotasConfig) registry.QuotaConfig {
+	limits := func(c QuotaLimitsConfig) registry.QuotaLimits {
+		return registry.QuotaLimits{
+			MaxApis:             c.MaxApis,
+			MaxRevisionsPerSpec: c.MaxRevisionsPerSpec,
+			MaxBlobBytes:        c.MaxBlobBytes,
+		}
+	}
+	quotas := registry.QuotaConfig{
+		Default:  limits(conf.QuotaLimitsConfig),
+		Projects: make(map[string]registry.QuotaLimits, len(conf.Projects)),
+	}
+	for id, c := range conf.Projects {
+		quotas.Projects[id] = limits(c)
+	}
+	return quotas
+}
+
 func loggerOptions(conf LoggingConfig) []log.Option {
 	opts := make([]log.Option, 0, 2)
 	switch conf.Level {
@@ -15,8 +15,12 @@
 package apply
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
 	"io/fs"
+	"os"
+	"strings"
 
 	"github.com/apigee/registry/cmd/registry/core"
 	"github.com/apigee/registry/cmd/registry/patch"
@@ -25,35 +29,178 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// options holds the flag values shared by apply and its plan subcommand.
+type options struct {
+	fileName  string
+	parent    string
+	recursive bool
+	jobs      int
+	prune     bool
+	selector  string
+	manager   string
+	overlay   string
+	values    string
+	env       bool
+}
+
+// addRenderFlags adds the flags that select and render patches.
+func (o *options) addRenderFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.fileName, "file", "f", "", "File or directory containing the patch(es) to apply")
+	cmd.Flags().BoolVarP(&o.recursive, "recursive", "R", false,
+		"Process the directory used in -f, --file recursively. Useful when you want to manage related manifests organized within the same directory")
+	cmd.Flags().StringVar(&o.overlay, "overlay", "", "Directory of partial patches to merge into the patches before applying them")
+	cmd.Flags().StringVar(&o.values, "values", "", "YAML file of values to substitute for ${NAME} references in patches")
+	cmd.Flags().BoolVar(&o.env, "env", false, "Substitute environment variables for ${NAME} references that aren't defined by --values")
+}
+
+// addFlags adds the flags shared by apply and its plan subcommand.
+func (o *options) addFlags(cmd *cobra.Command) {
+	o.addRenderFlags(cmd)
+	cmd.Flags().StringVar(&o.parent, "parent", "", "Parent resource for the patch")
+	cmd.Flags().IntVarP(&o.jobs, "jobs", "j", 10, "Number of apply operations to perform simultaneously")
+	cmd.Flags().BoolVar(&o.prune, "prune", false,
+		"Treat the patches as the complete contents of the parent and delete resources that they don't describe; requires --selector or --managed-by")
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", "",
+		"Only prune resources with these labels (e.g. key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&o.manager, "managed-by", "",
+		"Only prune resources whose \""+patch.ManagedByLabel+"\" label is unset or has this value")
+}
+
+// render renders the selected patches with overlays and values.
+func (o *options) render() ([]*patch.RenderedPatch, error) {
+	opts := patch.RenderOptions{Overlay: o.overlay}
+	if o.env {
+		opts.Lookup = os.LookupEnv
+	}
+	if o.values != "" {
+		values, err := patch.ReadValues(o.values)
+		if err != nil {
+			return nil, err
+		}
+		opts.Values = values
+	}
+	return patch.Render(o.fileName, o.recursive, opts)
+}
+
+// source returns the location of the patches to apply. If overlays or values
+// are specified, patches are rendered into a temporary directory, which is
+// removed by the returned cleanup function. Patches are applied unchanged otherwise,
+// so ${NAME} references and $$ escapes are only substituted when rendering.
+func (o *options) source() (path string, recursive bool, cleanup func(), err error) {
+	cleanup = func() {}
+	if o.overlay == "" && o.values == "" && !o.env {
+		return o.fileName, o.recursive, cleanup, nil
+	}
+	patches, err := o.render()
+	if err != nil {
+		return "", false, cleanup, err
+	}
+	dir, err := os.MkdirTemp("", "registry-apply-")
+	if err != nil {
+		return "", false, cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	if err := patch.WriteRendered(dir, patches); err != nil {
+		cleanup()
+		return "", false, func() {}, err
+	}
+	return dir, true, cleanup, nil
+}
+
+// pruneOptions returns the options for pruning.
+func (o *options) pruneOptions() (patch.PruneOptions, error) {
+	selector, err := patch.ParseSelector(o.selector)
+	if err != nil {
+		return patch.PruneOptions{}, err
+	}
+	opts := patch.PruneOptions{Selector: selector, Manager: o.manager}
+	if o.prune {
+		if err := opts.Validate(); err != nil {
+			return patch.PruneOptions{}, err
+		}
+	}
+	return opts, nil
+}
+
 func Command() *cobra.Command {
-	var fileName string
-	var parent string
-	var recursive bool
-	var jobs int
+	opts := &options{}
+	var dryRun bool
+	var yes bool
 	cmd := &cobra.Command{
 		Use:   "apply",
 		Short: "Apply patches that add content to the API Registry",
 		Args:  cobra.ExactArgs(0),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dryRun {
+				return runPlan(cmd, opts)
+			}
 			ctx := cmd.Context()
+			pruneOpts, err := opts.pruneOptions()
+			if err != nil {
+				return err
+			}
+			path, recursive, cleanup, err := opts.source()
+			if errors.Is(err, fs.ErrNotExist) {
+				log.FromContext(ctx).WithError(err).Fatalf("File %q doesn't exist", opts.fileName)
+			} else if err != nil {
+				return fmt.Errorf("failed to render patches: %s", err)
+			}
+			defer cleanup()
 			client, err := connection.NewRegistryClient(ctx)
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Fatal("Failed to get client")
 			}
-			taskQueue, wait := core.WorkerPool(ctx, jobs)
-			defer wait()
-			err = patch.Apply(ctx, client, fileName, parent, recursive, taskQueue)
+			taskQueue, wait := core.WorkerPool(ctx, opts.jobs)
+			err = patch.Apply(ctx, client, path, opts.parent, recursive, taskQueue)
+			wait()
 			if errors.Is(err, fs.ErrNotExist) {
-				log.FromContext(ctx).WithError(err).Fatalf("File %q doesn't exist", fileName)
+				log.FromContext(ctx).WithError(err).Fatalf("File %q doesn't exist", opts.fileName)
 			} else if err != nil {
 				log.FromContext(ctx).WithError(err).Fatal("Unknown error")
 			}
+			if !opts.prune {
+				return nil
+			}
+			plan := &patch.Plan{}
+			if err := patch.PlanPrune(ctx, client, path, opts.parent, recursive, pruneOpts, plan); errors.Is(err, patch.ErrNothingToKeep) {
+				return err
+			} else if err != nil {
+				log.FromContext(ctx).WithError(err).Fatal("Failed to list resources to prune")
+			}
+			if !plan.Pending() {
+				return nil
+			}
+			if err := plan.Write(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			if !yes && !confirm(cmd, "Delete these resources?") {
+				cmd.SilenceUsage = true
+				return errors.New("prune canceled, nothing was deleted")
+			}
+			if err := patch.Prune(ctx, client, plan); err != nil {
+				log.FromContext(ctx).WithError(err).Fatal("Failed to prune")
+			}
+			return nil
 		},
 	}
-	cmd.Flags().StringVarP(&fileName, "file", "f", "", "File or directory containing the patch(es) to apply")
-	cmd.Flags().StringVar(&parent, "parent", "", "Parent resource for the patch")
-	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false,
-		"Process the directory used in -f, --file recursively. Useful when you want to manage related manifests organized within the same directory")
-	cmd.Flags().IntVarP(&jobs, "jobs", "j", 10, "Number of apply operations to perform simultaneously")
+	opts.addFlags(cmd)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the changes that would be made without applying them; exits with an error if changes are pending")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Prune without asking for confirmation")
+	cmd.AddCommand(planCommand())
+	cmd.AddCommand(renderCommand())
+	cmd.AddCommand(schemaCommand())
+	cmd.AddCommand(validateCommand())
 	return cmd
 }
+
+// confirm asks a yes-or-no question and returns true if the answer is yes.
+func confirm(cmd *cobra.Command, question string) bool {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/cmd/registry/patch"
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/spf13/cobra"
+)
+
+// ErrPendingChanges is returned by plans that would change the registry
+// so that callers such as CI jobs can detect drift from the exit status.
+var ErrPendingChanges = errors.New("changes are pending")
+
+func planCommand() *cobra.Command {
+	opts := &options{}
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Print the changes that applying patches would make to the API Registry",
+		Long: "Print the changes that applying patches would make to the API Registry. " +
+			"Exits with an error if any resources would be created, updated, or deleted.",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlan(cmd, opts)
+		},
+	}
+	opts.addFlags(cmd)
+	return cmd
+}
+
+func runPlan(cmd *cobra.Command, opts *options) error {
+	ctx := cmd.Context()
+	pruneOpts, err := opts.pruneOptions()
+	if err != nil {
+		return err
+	}
+	path, recursive, cleanup, err := opts.source()
+	if errors.Is(err, fs.ErrNotExist) {
+		log.FromContext(ctx).WithError(err).Fatalf("File %q doesn't exist", opts.fileName)
+	} else if err != nil {
+		return fmt.Errorf("failed to render patches: %s", err)
+	}
+	defer cleanup()
+	client, err := connection.NewRegistryClient(ctx)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Fatal("Failed to get client")
+	}
+	plan := &patch.Plan{}
+	taskQueue, wait := core.WorkerPool(ctx, opts.jobs)
+	err = patch.PlanApply(ctx, client, path, opts.parent, recursive, taskQueue, plan)
+	wait()
+	if errors.Is(err, fs.ErrNotExist) {
+		log.FromContext(ctx).WithError(err).Fatalf("File %q doesn't exist", opts.fileName)
+	} else if err != nil {
+		log.FromContext(ctx).WithError(err).Fatal("Unknown error")
+	}
+	if opts.prune {
+		if err := patch.PlanPrune(ctx, client, path, opts.parent, recursive, pruneOpts, plan); errors.Is(err, patch.ErrNothingToKeep) {
+			return err
+		} else if err != nil {
+			log.FromContext(ctx).WithError(err).Fatal("Failed to list resources to prune")
+		}
+	}
+	if err := plan.Write(cmd.OutOrStdout()); err != nil {
+		return err
+	}
+	if plan.Pending() {
+		// The plan has already been printed, so usage text would only be noise.
+		cmd.SilenceUsage = true
+		counts := plan.Counts()
+		return fmt.Errorf("%w: %d to create, %d to update, %d to delete", ErrPendingChanges,
+			counts[patch.Create], counts[patch.Update], counts[patch.Delete])
+	}
+	return nil
+}
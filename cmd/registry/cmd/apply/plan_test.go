@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const planDir = "testdata/plan"
+
+// runPlanCommand runs a plan with the given arguments and returns its output.
+func runPlanCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := Command()
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPlan(t *testing.T) {
+	project := names.Project{ProjectID: "plan-test"}
+	parent := project.String() + "/locations/global"
+
+	ctx := context.Background()
+	registryClient, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, project.ProjectID)
+	t.Cleanup(func() { cmdtest.DeleteProjects(t, adminClient, project.ProjectID) })
+
+	api := project.Api("petstore")
+
+	t.Run("create", func(t *testing.T) {
+		out, err := runPlanCommand(t, "plan", "-f", planDir, "--parent", parent)
+		if !errors.Is(err, ErrPendingChanges) {
+			t.Fatalf("plan returned %v, want %v", err, ErrPendingChanges)
+		}
+		for _, want := range []string{
+			"+ create " + api.String() + "\n",
+			"    display_name: \"Petstore\"\n",
+			"    labels.owner: \"pets\"\n",
+			"+ create " + api.Version("v1").Spec("openapi.yaml").String() + "\n",
+			"+ create " + project.Artifact("lifecycle").String() + "\n",
+			"Plan: 5 to create, 0 to update, 0 to delete, 0 unchanged.\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("plan output is missing %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		if _, err := runPlanCommand(t, "-f", planDir, "--parent", parent); err != nil {
+			t.Fatalf("apply returned error: %s", err)
+		}
+		out, err := runPlanCommand(t, "plan", "-f", planDir, "--parent", parent)
+		if err != nil {
+			t.Fatalf("plan after apply returned error: %s\n%s", err, out)
+		}
+		if want := "Plan: 0 to create, 0 to update, 0 to delete, 5 unchanged.\n"; !strings.HasSuffix(out, want) {
+			t.Errorf("plan after apply returned %q, want suffix %q", out, want)
+		}
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		if _, err := registryClient.UpdateApi(ctx, &rpc.UpdateApiRequest{
+			Api: &rpc.Api{
+				Name:        api.String(),
+				Description: "changed",
+				Labels:      map[string]string{"owner": "pets", "extra": "label"},
+			},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"description", "labels"}},
+		}); err != nil {
+			t.Fatalf("Setup: failed to update api: %s", err)
+		}
+		contents, err := core.GZippedBytes([]byte("openapi: 3.0.0"))
+		if err != nil {
+			t.Fatalf("Setup: failed to compress spec: %s", err)
+		}
+		if _, err := registryClient.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+			ApiSpec: &rpc.ApiSpec{
+				Name:     api.Version("v1").Spec("openapi.yaml").String(),
+				Contents: contents,
+			},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"contents"}},
+		}); err != nil {
+			t.Fatalf("Setup: failed to update spec: %s", err)
+		}
+
+		out, err := runPlanCommand(t, "--dry-run", "-f", planDir, "--parent", parent)
+		if !errors.Is(err, ErrPendingChanges) {
+			t.Fatalf("apply --dry-run returned %v, want %v", err, ErrPendingChanges)
+		}
+		for _, want := range []string{
+			"~ update " + api.String() + "\n",
+			"    description: \"changed\" -> \"A sample API for tracking pets.\"\n",
+			"    labels.extra: \"label\" -> <unset>\n",
+			"~ update " + api.Version("v1").Spec("openapi.yaml").String() + "\n",
+			"    contents: sha256:",
+			"  unchanged " + api.Deployment("prod").String() + "\n",
+			"Plan: 0 to create, 2 to update, 0 to delete, 3 unchanged.\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("dry run output is missing %q:\n%s", want, out)
+			}
+		}
+
+		// A dry run must not change anything.
+		got, err := registryClient.GetApi(ctx, &rpc.GetApiRequest{Name: api.String()})
+		if err != nil {
+			t.Fatalf("GetApi returned error: %s", err)
+		}
+		if got.Description != "changed" {
+			t.Errorf("apply --dry-run changed description to %q", got.Description)
+		}
+	})
+}
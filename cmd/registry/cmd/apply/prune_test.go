@@ -0,0 +1,197 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/cmd/registry/patch"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// runApplyCommand runs apply with the given input and arguments and returns its output.
+func runApplyCommand(t *testing.T, input string, args ...string) (string, error) {
+	t.Helper()
+	cmd := Command()
+	out := new(bytes.Buffer)
+	cmd.SetIn(strings.NewReader(input))
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPrune(t *testing.T) {
+	project := names.Project{ProjectID: "prune-test"}
+	parent := project.String() + "/locations/global"
+
+	ctx := context.Background()
+	registryClient, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, project.ProjectID)
+	t.Cleanup(func() { cmdtest.DeleteProjects(t, adminClient, project.ProjectID) })
+
+	if _, err := runApplyCommand(t, "", "-f", planDir, "--parent", parent); err != nil {
+		t.Fatalf("Setup: apply returned error: %s", err)
+	}
+
+	// Add resources that are not described by the patches.
+	extra := project.Api("extra")
+	if _, err := registryClient.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: parent,
+		ApiId:  extra.ApiID,
+		Api:    &rpc.Api{Labels: map[string]string{"team": "a"}},
+	}); err != nil {
+		t.Fatalf("Setup: failed to create api: %s", err)
+	}
+	claimed := project.Api("claimed")
+	if _, err := registryClient.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: parent,
+		ApiId:  claimed.ApiID,
+		Api:    &rpc.Api{Labels: map[string]string{"team": "a", patch.ManagedByLabel: "someone-else"}},
+	}); err != nil {
+		t.Fatalf("Setup: failed to create api: %s", err)
+	}
+	extraVersion := project.Api("petstore").Version("v2")
+	if _, err := registryClient.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent:       extraVersion.Api().String(),
+		ApiVersionId: extraVersion.VersionID,
+		ApiVersion:   &rpc.ApiVersion{},
+	}); err != nil {
+		t.Fatalf("Setup: failed to create version: %s", err)
+	}
+	extraArtifact := project.Artifact("old-lifecycle")
+	notes := project.Artifact("notes")
+	for _, a := range []struct {
+		name     names.Artifact
+		mimeType string
+	}{
+		{extraArtifact, patch.MimeTypeForKind("Lifecycle")},
+		{notes, "text/plain"},
+	} {
+		if _, err := registryClient.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+			Parent:     parent,
+			ArtifactId: a.name.ArtifactID(),
+			Artifact:   &rpc.Artifact{MimeType: a.mimeType},
+		}); err != nil {
+			t.Fatalf("Setup: failed to create artifact: %s", err)
+		}
+	}
+
+	exists := func(name string) bool {
+		t.Helper()
+		var err error
+		switch name {
+		case extra.String(), claimed.String():
+			_, err = registryClient.GetApi(ctx, &rpc.GetApiRequest{Name: name})
+		case extraVersion.String():
+			_, err = registryClient.GetApiVersion(ctx, &rpc.GetApiVersionRequest{Name: name})
+		default:
+			_, err = registryClient.GetArtifact(ctx, &rpc.GetArtifactRequest{Name: name})
+		}
+		if status.Code(err) == codes.NotFound {
+			return false
+		} else if err != nil {
+			t.Fatalf("Get(%q) returned error: %s", name, err)
+		}
+		return true
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		out, err := runApplyCommand(t, "", "--dry-run", "--prune", "--managed-by", "test", "-f", planDir, "--parent", parent)
+		if !errors.Is(err, ErrPendingChanges) {
+			t.Fatalf("apply --dry-run --prune returned %v, want %v", err, ErrPendingChanges)
+		}
+		for _, want := range []string{
+			"- delete " + extra.String() + "\n",
+			"- delete " + extraVersion.String() + "\n",
+			"- delete " + extraArtifact.String() + "\n",
+			"Plan: 0 to create, 0 to update, 3 to delete, 5 unchanged.\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("dry run output is missing %q:\n%s", want, out)
+			}
+		}
+		for _, unwanted := range []string{claimed.String(), notes.String()} {
+			if strings.Contains(out, unwanted) {
+				t.Errorf("dry run output includes %q:\n%s", unwanted, out)
+			}
+		}
+	})
+
+	t.Run("selector", func(t *testing.T) {
+		out, err := runApplyCommand(t, "", "plan", "--prune", "-l", "team=a", "-f", planDir, "--parent", parent)
+		if !errors.Is(err, ErrPendingChanges) {
+			t.Fatalf("apply plan --prune returned %v, want %v", err, ErrPendingChanges)
+		}
+		if want := "Plan: 0 to create, 0 to update, 1 to delete, 5 unchanged.\n"; !strings.HasSuffix(out, want) {
+			t.Errorf("plan with selector returned %q, want suffix %q", out, want)
+		}
+	})
+
+	t.Run("unrestricted", func(t *testing.T) {
+		if _, err := runApplyCommand(t, "y\n", "--prune", "-f", planDir, "--parent", parent); !errors.Is(err, patch.ErrUnrestrictedPrune) {
+			t.Errorf("apply --prune without a selector or manager returned %v, want %v", err, patch.ErrUnrestrictedPrune)
+		}
+		if !exists(extra.String()) {
+			t.Errorf("apply --prune without a selector or manager deleted %s", extra)
+		}
+	})
+
+	t.Run("no apis", func(t *testing.T) {
+		if _, err := runApplyCommand(t, "y\n", "--prune", "--managed-by", "test", "-f", t.TempDir(), "--parent", parent); !errors.Is(err, patch.ErrNothingToKeep) {
+			t.Errorf("apply --prune with no apis returned %v, want %v", err, patch.ErrNothingToKeep)
+		}
+		if !exists(extra.String()) {
+			t.Errorf("apply --prune with no apis deleted %s", extra)
+		}
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		if _, err := runApplyCommand(t, "n\n", "--prune", "--managed-by", "test", "-f", planDir, "--parent", parent); err == nil {
+			t.Errorf("apply --prune succeeded without confirmation")
+		}
+		if !exists(extra.String()) {
+			t.Errorf("apply --prune deleted %s without confirmation", extra)
+		}
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		if _, err := runApplyCommand(t, "y\n", "--prune", "--managed-by", "test", "-f", planDir, "--parent", parent); err != nil {
+			t.Fatalf("apply --prune returned error: %s", err)
+		}
+		for _, name := range []string{extra.String(), extraVersion.String(), extraArtifact.String()} {
+			if exists(name) {
+				t.Errorf("apply --prune didn't delete %s", name)
+			}
+		}
+		for _, name := range []string{claimed.String(), notes.String()} {
+			if !exists(name) {
+				t.Errorf("apply --prune deleted %s", name)
+			}
+		}
+		if out, err := runApplyCommand(t, "", "plan", "--prune", "--managed-by", "test", "-f", planDir, "--parent", parent); err != nil {
+			t.Errorf("plan after prune returned error: %s\n%s", err, out)
+		}
+	})
+}
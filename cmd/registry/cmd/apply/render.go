@@ -0,0 +1,39 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"github.com/apigee/registry/cmd/registry/patch"
+	"github.com/spf13/cobra"
+)
+
+func renderCommand() *cobra.Command {
+	opts := &options{}
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print patches after merging overlays and substituting values",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			patches, err := opts.render()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			return patch.PrintRendered(cmd.OutOrStdout(), patches)
+		},
+	}
+	opts.addRenderFlags(cmd)
+	return cmd
+}
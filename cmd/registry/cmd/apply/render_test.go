@@ -0,0 +1,181 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/cmd/registry/patch"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/google/go-cmp/cmp"
+)
+
+const renderDir = "testdata/render"
+
+func TestRender(t *testing.T) {
+	t.Run("overlay and values", func(t *testing.T) {
+		expected, err := os.ReadFile(renderDir + "/prod-rendered.yaml")
+		if err != nil {
+			t.Fatalf("Failed to read expected output: %s", err)
+		}
+		out, err := runApplyCommand(t, "", "render",
+			"-f", renderDir+"/base",
+			"--overlay", renderDir+"/prod",
+			"--values", renderDir+"/prod-values.yaml")
+		if err != nil {
+			t.Fatalf("render returned error: %s", err)
+		}
+		if diff := cmp.Diff(string(expected), out); diff != "" {
+			t.Errorf("render returned unexpected diff: (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "dev")
+		t.Setenv("HOST", "dev.example.com")
+		out, err := runApplyCommand(t, "", "render", "-f", renderDir+"/base", "--env")
+		if err != nil {
+			t.Fatalf("render returned error: %s", err)
+		}
+		for _, want := range []string{
+			"    environment: dev\n",
+			"        endpointURI: https://dev.example.com/v1\n",
+			"        accessGuidance: Contact the petstore team for access.\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("render output is missing %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("missing value", func(t *testing.T) {
+		_, err := runApplyCommand(t, "", "render", "-f", renderDir+"/base")
+		if err == nil || !strings.Contains(err.Error(), "no value for ENVIRONMENT") {
+			t.Errorf("render returned %v, want missing value error", err)
+		}
+	})
+}
+
+func TestApplyOverlay(t *testing.T) {
+	project := names.Project{ProjectID: "overlay-test"}
+	parent := project.String() + "/locations/global"
+
+	ctx := context.Background()
+	registryClient, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, project.ProjectID)
+	t.Cleanup(func() { cmdtest.DeleteProjects(t, adminClient, project.ProjectID) })
+
+	args := []string{
+		"-f", renderDir + "/base",
+		"--overlay", renderDir + "/prod",
+		"--values", renderDir + "/prod-values.yaml",
+		"--parent", parent,
+	}
+	if _, err := runApplyCommand(t, "", args...); err != nil {
+		t.Fatalf("apply returned error: %s", err)
+	}
+
+	api, err := registryClient.GetApi(ctx, &rpc.GetApiRequest{Name: project.Api("petstore").String()})
+	if err != nil {
+		t.Fatalf("GetApi returned error: %s", err)
+	}
+	if want := map[string]string{"environment": "prod", "tier": "critical"}; !cmp.Equal(want, api.Labels) {
+		t.Errorf("GetApi returned labels %v, want %v", api.Labels, want)
+	}
+	canary, err := registryClient.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{
+		Name: project.Api("petstore").Deployment("canary").String(),
+	})
+	if err != nil {
+		t.Fatalf("GetApiDeployment returned error: %s", err)
+	}
+	if want := "https://canary.petstore.example.com/v1"; canary.EndpointUri != want {
+		t.Errorf("GetApiDeployment returned endpoint %q, want %q", canary.EndpointUri, want)
+	}
+
+	out, err := runApplyCommand(t, "", append([]string{"plan"}, args...)...)
+	if err != nil {
+		t.Errorf("plan after apply returned error: %s\n%s", err, out)
+	}
+
+	t.Run("missing value", func(t *testing.T) {
+		for _, cmd := range [][]string{nil, {"plan"}} {
+			_, err := runApplyCommand(t, "", append(cmd, "-f", renderDir+"/base", "--parent", parent, "--env")...)
+			if want := renderDir + "/base/petstore.yaml: no value for ENVIRONMENT"; err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%v returned %v, want error containing %q", cmd, err, want)
+			}
+		}
+	})
+
+	t.Run("sources", func(t *testing.T) {
+		opts := &options{fileName: renderDir + "/base", overlay: renderDir + "/prod", values: renderDir + "/prod-values.yaml"}
+		path, recursive, cleanup, err := opts.source()
+		if err != nil {
+			t.Fatalf("source() returned error: %s", err)
+		}
+		defer cleanup()
+		tasks := make(chan core.Task, 10)
+		if err := patch.PlanApply(ctx, registryClient, path, parent, recursive, tasks, &patch.Plan{}); err != nil {
+			t.Fatalf("PlanApply() returned error: %s", err)
+		}
+		close(tasks)
+		var got []string
+		for task := range tasks {
+			got = append(got, task.String())
+		}
+		if want := []string{"plan file " + renderDir + "/base/petstore.yaml"}; !cmp.Equal(want, got) {
+			t.Errorf("PlanApply() queued %v, want %v", got, want)
+		}
+	})
+
+	t.Run("escapes", func(t *testing.T) {
+		dir := t.TempDir()
+		b := []byte(`apiVersion: apigeeregistry/v1
+kind: API
+metadata:
+  name: escapes
+data:
+  description: Costs $$5 for ${ITEM}.
+`)
+		if err := os.WriteFile(dir+"/escapes.yaml", b, 0644); err != nil {
+			t.Fatalf("Setup: failed to write patch: %s", err)
+		}
+		t.Setenv("ITEM", "pets")
+		tests := []struct {
+			args []string
+			want string
+		}{
+			{nil, "Costs $$5 for ${ITEM}."},
+			{[]string{"--env"}, "Costs $5 for pets."},
+		}
+		for _, test := range tests {
+			if _, err := runApplyCommand(t, "", append([]string{"-f", dir, "--parent", parent}, test.args...)...); err != nil {
+				t.Fatalf("apply %v returned error: %s", test.args, err)
+			}
+			api, err := registryClient.GetApi(ctx, &rpc.GetApiRequest{Name: project.Api("escapes").String()})
+			if err != nil {
+				t.Fatalf("GetApi returned error: %s", err)
+			}
+			if api.Description != test.want {
+				t.Errorf("apply %v set description %q, want %q", test.args, api.Description, test.want)
+			}
+		}
+	})
+}
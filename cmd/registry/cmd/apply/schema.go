@@ -0,0 +1,57 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apigee/registry/cmd/registry/patch"
+	"github.com/spf13/cobra"
+)
+
+func schemaCommand() *cobra.Command {
+	var outputDir string
+	cmd := &cobra.Command{
+		Use:   "schema [KIND]",
+		Short: "Print the JSON Schema of patches of a kind",
+		Long: "Print the JSON Schema of patches of a kind, or with --output-dir, write the schemas of all kinds. " +
+			"Kinds are " + strings.Join(patch.SchemaKinds(), ", ") + ".",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputDir != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("a kind can't be specified with --output-dir")
+				}
+				return patch.WriteSchemas(outputDir)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("a kind or --output-dir must be specified")
+			}
+			s, err := patch.SchemaForKind(args[0])
+			if err != nil {
+				return err
+			}
+			b, err := patch.MarshalSchema(s)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(b)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Directory to write the schemas of all kinds to")
+	return cmd
+}
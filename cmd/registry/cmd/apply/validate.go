@@ -0,0 +1,72 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apigee/registry/cmd/registry/patch"
+	"github.com/spf13/cobra"
+)
+
+// ErrInvalidPatches is returned when validation finds problems in patches.
+var ErrInvalidPatches = errors.New("invalid patches")
+
+func validateCommand() *cobra.Command {
+	opts := &options{}
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check patches against their schemas without contacting the API Registry",
+		Long: "Check patches against their schemas without contacting the API Registry. " +
+			"Reports unknown fields, values of the wrong type, invalid resource IDs, and unknown kinds. " +
+			"If overlays or values are specified, the rendered patches are checked.",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			problems, err := opts.validate()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			for _, p := range problems {
+				fmt.Fprintln(cmd.OutOrStdout(), p)
+			}
+			if len(problems) > 0 {
+				// The problems have already been printed, so usage text would only be noise.
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%w: %d problem(s) found", ErrInvalidPatches, len(problems))
+			}
+			return nil
+		},
+	}
+	opts.addRenderFlags(cmd)
+	return cmd
+}
+
+// validate checks the selected patches, rendering them first if necessary.
+func (o *options) validate() ([]*patch.ValidationError, error) {
+	if o.overlay == "" && o.values == "" && !o.env {
+		return patch.Validate(o.fileName, o.recursive)
+	}
+	patches, err := o.render()
+	if err != nil {
+		return nil, err
+	}
+	var problems []*patch.ValidationError
+	for _, p := range patches {
+		problems = append(problems, patch.ValidateBytes(p.Path, p.Bytes)...)
+	}
+	return problems, nil
+}
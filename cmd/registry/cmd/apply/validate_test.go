@@ -0,0 +1,110 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/patch"
+	"github.com/google/go-cmp/cmp"
+)
+
+const schemaDir = "../../../../pkg/models/schemas"
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		for _, dir := range []string{"testdata/sample", "testdata/plan"} {
+			out, err := runApplyCommand(t, "", "validate", "-f", dir, "-R")
+			if err != nil {
+				t.Errorf("validate %s returned error: %s\n%s", dir, err, out)
+			}
+		}
+	})
+
+	t.Run("rendered", func(t *testing.T) {
+		out, err := runApplyCommand(t, "", "validate",
+			"-f", renderDir+"/base",
+			"--overlay", renderDir+"/prod",
+			"--values", renderDir+"/prod-values.yaml")
+		if err != nil {
+			t.Errorf("validate returned error: %s\n%s", err, out)
+		}
+	})
+
+	t.Run("scalars", func(t *testing.T) {
+		// Unquoted numbers and booleans are applied as strings.
+		dir := t.TempDir()
+		patch := `apiVersion: apigeeregistry/v1
+kind: API
+metadata:
+  name: scalars
+data:
+  displayName: 2022
+  description: true
+  versions:
+    - metadata:
+        name: v1
+      data:
+        state: 1.5
+`
+		if err := os.WriteFile(filepath.Join(dir, "scalars.yaml"), []byte(patch), 0644); err != nil {
+			t.Fatalf("Setup: failed to write patch: %s", err)
+		}
+		if out, err := runApplyCommand(t, "", "validate", "-f", dir); err != nil {
+			t.Errorf("validate returned error: %s\n%s", err, out)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		out, err := runApplyCommand(t, "", "validate", "-f", "testdata/validate")
+		if !errors.Is(err, ErrInvalidPatches) {
+			t.Errorf("validate returned %v, want %v", err, ErrInvalidPatches)
+		}
+		want := `testdata/validate/petstore.yaml:4:9: metadata.name: invalid identifier "Petstore_API": must match "^[a-z0-9]([a-z0-9-.]{0,78}[a-z0-9])?$"
+testdata/validate/petstore.yaml:6:16: data.displayName: expected string, got array
+testdata/validate/petstore.yaml:7:3: data: unknown field "recomendedVersion"
+testdata/validate/petstore.yaml:12:16: data.versions[0].data.state: expected string, got object
+testdata/validate/petstore.yaml:20:13: data.artifacts[0].data.stages[0]: unknown field "bogus"
+testdata/validate/petstore.yaml:21:13: data.artifacts[1].kind: "Nope" must be one of ApiSpecExtensionList, ConformanceReport, DisplaySettings, Lifecycle, Lint, Manifest, ReferenceList, Score, ScoreCard, ScoreCardDefinition, ScoreDefinition, SpecSummary, StyleGuide, TaxonomyList
+testdata/validate/widget.yaml:2:7: kind: unknown kind "Widget", expected one of API, Project, ApiSpecExtensionList, ConformanceReport, DisplaySettings, Lifecycle, Lint, Manifest, ReferenceList, Score, ScoreCard, ScoreCardDefinition, ScoreDefinition, SpecSummary, StyleGuide, TaxonomyList
+`
+		if diff := cmp.Diff(want, out); diff != "" {
+			t.Errorf("validate returned unexpected diff: (-want +got):\n%s", diff)
+		}
+	})
+}
+
+// TestPublishedSchemas checks that the published schemas match the patch types.
+// If it fails, update them with `registry apply schema --output-dir pkg/models/schemas`.
+func TestPublishedSchemas(t *testing.T) {
+	for _, kind := range patch.SchemaKinds() {
+		t.Run(kind, func(t *testing.T) {
+			want, err := runApplyCommand(t, "", "schema", kind)
+			if err != nil {
+				t.Fatalf("schema returned error: %s", err)
+			}
+			got, err := os.ReadFile(filepath.Join(schemaDir, patch.SchemaFileName(kind)))
+			if err != nil {
+				t.Fatalf("Failed to read published schema: %s", err)
+			}
+			if diff := cmp.Diff(want, string(got)); diff != "" {
+				t.Errorf("published schema is out of date: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
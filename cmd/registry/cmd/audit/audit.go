@@ -0,0 +1,152 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"github.com/spf13/cobra"
+	"google.golang.org/api/iterator"
+)
+
+func Command() *cobra.Command {
+	var filter string
+	var changes bool
+	cmd := &cobra.Command{
+		Use:   "audit [RESOURCE]",
+		Short: "List the audit events of resources in the API Registry",
+		Long: "List the audit events recorded for changes to a project or to a resource and its children. " +
+			"If no resource is specified, the events of the configured project are listed. " +
+			"Use \"projects/-\" to list the events of all projects.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := cmd.Context()
+			c, err := connection.ActiveConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get config: %s", err)
+			}
+			var name string
+			if len(args) > 0 {
+				name = c.FQName(args[0])
+			} else if c.Project != "" {
+				name = "projects/" + c.Project
+			} else {
+				return fmt.Errorf("no resource specified and no project configured")
+			}
+
+			parent, resource := auditScope(name)
+			if resource != "" {
+				filter = resourceFilter(filter, resource)
+			}
+
+			client, err := connection.NewAdminClientWithSettings(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to get client: %s", err)
+			}
+			it := client.ListAuditEvents(ctx, &rpc.ListAuditEventsRequest{
+				Parent: parent,
+				Filter: filter,
+			})
+
+			var events []*rpc.AuditEvent
+			for {
+				event, err := it.Next()
+				if err == iterator.Done {
+					break
+				} else if err != nil {
+					return fmt.Errorf("failed to list audit events: %s", err)
+				}
+				events = append(events, event)
+			}
+			if changes {
+				printChanges(cmd.OutOrStdout(), events)
+			} else {
+				printTable(cmd.OutOrStdout(), events)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filter, "filter", "", "Filter selected events")
+	cmd.Flags().BoolVar(&changes, "changes", false, "Print the old and new values of changed fields")
+	return cmd
+}
+
+// auditScope returns the project whose events should be listed
+// and, if the name is below a project, the resource to select.
+func auditScope(name string) (parent, resource string) {
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 {
+		return name, ""
+	}
+	parent = parts[0] + "/" + parts[1]
+	if len(parts) == 3 {
+		resource = name
+	}
+	return parent, resource
+}
+
+// resourceFilter restricts a filter to events of a resource and its children.
+func resourceFilter(filter, resource string) string {
+	f := fmt.Sprintf("(resource == %q || resource.startsWith(%q))", resource, resource+"/")
+	if filter == "" {
+		return f
+	}
+	return fmt.Sprintf("(%s) && %s", filter, f)
+}
+
+func printTable(out io.Writer, events []*rpc.AuditEvent) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "TIME\tMETHOD\tRESOURCE\tPRINCIPAL\tREQUEST_ID\tCHANGES")
+	for _, e := range events {
+		fields := make([]string, len(e.GetChanges()))
+		for i, c := range e.GetChanges() {
+			fields[i] = c.GetField()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.GetCreateTime().AsTime().Format(time.RFC3339),
+			e.GetMethod(),
+			e.GetResource(),
+			orDash(e.GetPrincipal()),
+			orDash(e.GetRequestId()),
+			orDash(strings.Join(fields, ",")))
+	}
+}
+
+func printChanges(out io.Writer, events []*rpc.AuditEvent) {
+	for _, e := range events {
+		fmt.Fprintf(out, "%s %s %s\n", e.GetCreateTime().AsTime().Format(time.RFC3339), e.GetMethod(), e.GetResource())
+		fmt.Fprintf(out, "  principal: %s\n", orDash(e.GetPrincipal()))
+		fmt.Fprintf(out, "  request_id: %s\n", orDash(e.GetRequestId()))
+		for _, c := range e.GetChanges() {
+			fmt.Fprintf(out, "  %s: %q -> %q\n", c.GetField(), c.GetOldValue(), c.GetNewValue())
+		}
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
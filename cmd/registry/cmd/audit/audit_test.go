@@ -0,0 +1,118 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/pkg/connection/grpctest"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// TestMain will set up a local RegistryServer and grpc.Server for all
+// tests in this package if APG_REGISTRY_ADDRESS env var is not set
+// for the client.
+func TestMain(m *testing.M) {
+	grpctest.TestMain(m, registry.Config{Audit: true})
+}
+
+func TestAudit(t *testing.T) {
+	const (
+		projectID   = "audit-test"
+		projectName = "projects/" + projectID
+		apiName     = projectName + "/locations/global/apis/a"
+	)
+	ctx := context.Background()
+	registryClient, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, projectID)
+	for _, id := range []string{"a", "ab"} {
+		if _, err := registryClient.CreateApi(ctx, &rpc.CreateApiRequest{
+			Parent: projectName + "/locations/global",
+			ApiId:  id,
+			Api:    &rpc.Api{},
+		}); err != nil {
+			t.Fatalf("Error creating api %s", err)
+		}
+	}
+	if _, err := registryClient.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api:        &rpc.Api{Name: apiName, DisplayName: "Pets"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name"}},
+	}); err != nil {
+		t.Fatalf("Error updating api %s", err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := Command()
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) returned error: %s", args, err)
+		}
+		return out.String()
+	}
+
+	out := run(projectName)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("audit %s printed %d lines, want a header and 4 events:\n%s", projectName, len(lines), out)
+	}
+	for i, method := range []string{"CreateProject", "CreateApi", "CreateApi", "UpdateApi"} {
+		if !strings.Contains(lines[i+1], method) {
+			t.Errorf("audit %s printed %q, want %s", projectName, lines[i+1], method)
+		}
+	}
+
+	// Resources select events of themselves and their children only.
+	out = run(apiName, "--changes")
+	for _, want := range []string{"CreateApi " + apiName, "UpdateApi " + apiName, `display_name: "" -> "Pets"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("audit %s --changes printed %q, want %q", apiName, out, want)
+		}
+	}
+	if strings.Contains(out, apiName+"b") {
+		t.Errorf("audit %s --changes printed %q, want no events of other APIs", apiName, out)
+	}
+
+	out = run(projectName, "--filter", `method == "UpdateApi"`)
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 {
+		t.Errorf("audit %s --filter printed %q, want a header and 1 event", projectName, out)
+	}
+}
+
+func TestAuditScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   string
+		resource string
+	}{
+		{"projects/p", "projects/p", ""},
+		{"projects/-", "projects/-", ""},
+		{"projects/p/locations/global/apis/a", "projects/p", "projects/p/locations/global/apis/a"},
+	}
+	for _, test := range tests {
+		parent, resource := auditScope(test.name)
+		if parent != test.parent || resource != test.resource {
+			t.Errorf("auditScope(%q) returned (%q, %q), want (%q, %q)", test.name, parent, resource, test.parent, test.resource)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Command() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cp SOURCE DESTINATION",
+		Short: "Copy an API, version, spec, or deployment to a new name",
+		Long: "Copy an API, version, spec, or deployment and everything that it contains to a new name, " +
+			"which may be in another parent or project. Copies keep all spec and deployment revisions, " +
+			"with their IDs and revision tags, and the artifacts and labels of every copied resource. " +
+			"References between copied resources are changed to refer to the copies. " +
+			"Only the copied resources are read, and they are written with the Admin service's ImportProject method. " +
+			"Servers that don't support ImportProject receive the copies through the Registry service, " +
+			"which gives copied revisions new revision IDs.",
+		Example: "registry cp projects/p/locations/global/apis/petstore projects/p/locations/global/apis/pets",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := cmd.Context()
+			c, err := connection.ActiveConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get config: %s", err)
+			}
+			client, err := connection.NewRegistryClientWithSettings(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to get client: %s", err)
+			}
+			adminClient, err := connection.NewAdminClientWithSettings(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to get client: %s", err)
+			}
+			from, to := c.FQName(args[0]), c.FQName(args[1])
+			if _, err := Copy(ctx, client, adminClient, from, to); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Copied %s to %s\n", from, to)
+			return nil
+		},
+	}
+}
+
+// resource is the name of a resource that can be copied.
+type resource struct {
+	kind    string
+	name    string
+	project names.Project
+	parent  string
+}
+
+// parseResource parses the name of an API, version, spec, or deployment.
+// Revisions and patterns can't be copied.
+func parseResource(name string) (resource, error) {
+	r := resource{name: name}
+	if strings.Contains(name, "@") || strings.HasSuffix(name, "/-") || strings.Contains(name, "/-/") {
+		return r, fmt.Errorf("invalid name %q: revisions and patterns can't be copied", name)
+	}
+	if n, err := names.ParseApi(name); err == nil {
+		r.kind, r.project, r.parent = "api", n.Project(), n.Project().String()
+	} else if n, err := names.ParseVersion(name); err == nil {
+		r.kind, r.project, r.parent = "version", n.Project(), n.Api().String()
+	} else if n, err := names.ParseSpec(name); err == nil {
+		r.kind, r.project, r.parent = "spec", n.Project(), n.Version().String()
+	} else if n, err := names.ParseDeployment(name); err == nil {
+		r.kind, r.project, r.parent = "deployment", n.Project(), n.Api().String()
+	} else {
+		return r, fmt.Errorf("invalid name %q: must be an API, version, spec, or deployment", name)
+	}
+	return r, nil
+}
+
+// exists returns nil if the named resource exists.
+func exists(ctx context.Context, client connection.RegistryClient, adminClient connection.AdminClient, name string) error {
+	var err error
+	if _, e := names.ParseProject(name); e == nil {
+		_, err = adminClient.GetProject(ctx, &rpc.GetProjectRequest{Name: name})
+	} else if _, e := names.ParseApi(name); e == nil {
+		_, err = client.GetApi(ctx, &rpc.GetApiRequest{Name: name})
+	} else if _, e := names.ParseVersion(name); e == nil {
+		_, err = client.GetApiVersion(ctx, &rpc.GetApiVersionRequest{Name: name})
+	} else if _, e := names.ParseSpec(name); e == nil {
+		_, err = client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: name})
+	} else if _, e := names.ParseDeployment(name); e == nil {
+		_, err = client.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: name})
+	} else {
+		err = fmt.Errorf("unsupported resource name %q", name)
+	}
+	return err
+}
+
+// Copy copies a resource and everything that it contains to a new name.
+// It returns an archive of the copies that were written.
+func Copy(ctx context.Context,
+	client connection.RegistryClient,
+	adminClient connection.AdminClient,
+	from, to string) (*rpc.ProjectArchive, error) {
+	source, err := parseResource(from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := parseResource(to)
+	if err != nil {
+		return nil, err
+	}
+	if source.kind != target.kind {
+		return nil, fmt.Errorf("%s is a %s and %s is a %s, they must be the same kind of resource", from, source.kind, to, target.kind)
+	}
+	if from == to {
+		return nil, fmt.Errorf("%s can't be copied to itself", from)
+	}
+	if err := exists(ctx, client, adminClient, from); err != nil {
+		return nil, err
+	}
+	if err := exists(ctx, client, adminClient, target.parent); err != nil {
+		return nil, err
+	}
+	if err := exists(ctx, client, adminClient, to); err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "%s already exists", to)
+	} else if status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+
+	archive, err := list(ctx, client, source, true)
+	if err != nil {
+		return nil, err
+	}
+	copied := Subtree(archive, from, to)
+	// Existing resources (including the project) are left unchanged.
+	_, err = core.ImportProjectArchive(ctx, adminClient, copied, "", rpc.ImportProjectRequest_SKIP)
+	if status.Code(err) == codes.Unimplemented {
+		return write(ctx, client, copied)
+	} else if err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// list returns an archive of a resource and everything that it contains.
+// Revisions are in the order of their creation. If contents is true, the archive
+// includes the stored contents of spec revisions and artifacts.
+func list(ctx context.Context, client connection.RegistryClient, r resource, contents bool) (*rpc.ProjectArchive, error) {
+	archive := &rpc.ProjectArchive{
+		FormatVersion: core.ArchiveFormatVersion,
+		CreateTime:    timestamppb.Now(),
+		Project:       &rpc.Project{Name: r.project.String()},
+	}
+	var (
+		specs       []names.Spec
+		deployments []names.Deployment
+		artifacts   []names.Artifact
+	)
+	addApi := func(v *rpc.Api) error {
+		archive.Apis = append(archive.Apis, v)
+		return nil
+	}
+	addVersion := func(v *rpc.ApiVersion) error {
+		archive.Versions = append(archive.Versions, v)
+		return nil
+	}
+	switch r.kind {
+	case "api":
+		n, err := names.ParseApi(r.name)
+		if err != nil {
+			return nil, err
+		}
+		if err := core.ListAPIs(ctx, client, n, "", addApi); err != nil {
+			return nil, err
+		}
+		if err := core.ListVersions(ctx, client, n.Version("-"), "", addVersion); err != nil {
+			return nil, err
+		}
+		specs = []names.Spec{n.Version("-").Spec("-")}
+		deployments = []names.Deployment{n.Deployment("-")}
+		artifacts = []names.Artifact{
+			n.Artifact("-"),
+			n.Version("-").Artifact("-"),
+			n.Version("-").Spec("-").Artifact("-"),
+			n.Deployment("-").Artifact("-"),
+		}
+	case "version":
+		n, err := names.ParseVersion(r.name)
+		if err != nil {
+			return nil, err
+		}
+		if err := core.ListVersions(ctx, client, n, "", addVersion); err != nil {
+			return nil, err
+		}
+		specs = []names.Spec{n.Spec("-")}
+		artifacts = []names.Artifact{n.Artifact("-"), n.Spec("-").Artifact("-")}
+	case "spec":
+		n, err := names.ParseSpec(r.name)
+		if err != nil {
+			return nil, err
+		}
+		specs = []names.Spec{n}
+		artifacts = []names.Artifact{n.Artifact("-")}
+	case "deployment":
+		n, err := names.ParseDeployment(r.name)
+		if err != nil {
+			return nil, err
+		}
+		deployments = []names.Deployment{n}
+		artifacts = []names.Artifact{n.Artifact("-")}
+	}
+
+	tag := func(tags *[]*rpc.ProjectArchive_Tag) core.RevisionTagHandler {
+		return func(t *rpc.RevisionTag) error {
+			*tags = append(*tags, &rpc.ProjectArchive_Tag{
+				Revision:   t.GetRevision(),
+				Tag:        t.GetTag(),
+				CreateTime: archive.GetCreateTime(),
+				UpdateTime: archive.GetCreateTime(),
+			})
+			return nil
+		}
+	}
+	for _, n := range specs {
+		if err := core.ListSpecRevisionsWithTags(ctx, client, n, func(v *rpc.ApiSpec) error {
+			archive.SpecRevisions = append(archive.SpecRevisions, v)
+			return nil
+		}, tag(&archive.SpecRevisionTags)); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range deployments {
+		if err := core.ListDeploymentRevisionsWithTags(ctx, client, n, func(v *rpc.ApiDeployment) error {
+			archive.DeploymentRevisions = append(archive.DeploymentRevisions, v)
+			return nil
+		}, tag(&archive.DeploymentRevisionTags)); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range artifacts {
+		if err := core.ListArtifacts(ctx, client, n, "", false, func(v *rpc.Artifact) error {
+			archive.Artifacts = append(archive.Artifacts, v)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(archive.SpecRevisions, func(i, j int) bool {
+		return archive.SpecRevisions[i].GetRevisionCreateTime().AsTime().Before(archive.SpecRevisions[j].GetRevisionCreateTime().AsTime())
+	})
+	sort.SliceStable(archive.DeploymentRevisions, func(i, j int) bool {
+		return archive.DeploymentRevisions[i].GetRevisionCreateTime().AsTime().Before(archive.DeploymentRevisions[j].GetRevisionCreateTime().AsTime())
+	})
+
+	if !contents {
+		return archive, nil
+	}
+	for _, v := range archive.SpecRevisions {
+		var err error
+		if v.Contents, err = core.ArchivedSpecContents(ctx, client, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range archive.Artifacts {
+		var err error
+		if v.Contents, err = core.ArchivedArtifactContents(ctx, client, v); err != nil {
+			return nil, err
+		}
+	}
+	return archive, nil
+}
+
+// Verify returns an error if the copies in an archive returned by Copy
+// aren't all in the registry with the same contents.
+func Verify(ctx context.Context, client connection.RegistryClient, copied *rpc.ProjectArchive, to string) error {
+	target, err := parseResource(to)
+	if err != nil {
+		return err
+	}
+	archive, err := list(ctx, client, target, false)
+	if err != nil {
+		return err
+	}
+	want, got := inventory(copied), inventory(archive)
+	for name, hash := range want {
+		if h, ok := got[name]; !ok {
+			return fmt.Errorf("copy of %s is missing", name)
+		} else if h != hash {
+			return fmt.Errorf("copy of %s has hash %q, want %q", name, h, hash)
+		}
+	}
+	if len(got) != len(want) {
+		var extra []string
+		for name := range got {
+			if _, ok := want[name]; !ok {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+		return fmt.Errorf("unexpected resources in %s: %s", to, strings.Join(extra, ", "))
+	}
+	return nil
+}
+
+// inventory returns the names of the resources and tags in an archive with the hashes of their contents.
+func inventory(archive *rpc.ProjectArchive) map[string]string {
+	m := map[string]string{}
+	for _, v := range archive.GetApis() {
+		m[v.GetName()] = ""
+	}
+	for _, v := range archive.GetVersions() {
+		m[v.GetName()] = ""
+	}
+	for _, v := range archive.GetSpecRevisions() {
+		m[v.GetName()] = v.GetHash()
+	}
+	for _, v := range archive.GetDeploymentRevisions() {
+		m[v.GetName()] = v.GetApiSpecRevision()
+	}
+	for _, v := range archive.GetArtifacts() {
+		m[v.GetName()] = v.GetHash()
+	}
+	for _, t := range append(archive.GetSpecRevisionTags(), archive.GetDeploymentRevisionTags()...) {
+		m[t.GetRevision()+"#"+t.GetTag()] = ""
+	}
+	return m
+}
+
+// Subtree returns an archive of the resources in an archive that are named
+// from or are contained in it, renamed to to. References to these resources
+// are changed to refer to their new names.
+func Subtree(archive *rpc.ProjectArchive, from, to string) *rpc.ProjectArchive {
+	rename := func(name string) (string, bool) {
+		switch {
+		case name == from:
+			return to, true
+		case strings.HasPrefix(name, from+"/"), strings.HasPrefix(name, from+"@"):
+			return to + strings.TrimPrefix(name, from), true
+		}
+		return name, false
+	}
+	ref := func(name string) string {
+		name, _ = rename(name)
+		return name
+	}
+	target, _, _ := strings.Cut(strings.TrimPrefix(to, "projects/"), "/")
+	s := &rpc.ProjectArchive{
+		FormatVersion: archive.GetFormatVersion(),
+		CreateTime:    archive.GetCreateTime(),
+		Project:       &rpc.Project{Name: "projects/" + target},
+	}
+	for _, v := range archive.GetApis() {
+		if name, ok := rename(v.GetName()); ok {
+			v = proto.Clone(v).(*rpc.Api)
+			v.Name = name
+			v.RecommendedVersion, v.RecommendedDeployment = ref(v.GetRecommendedVersion()), ref(v.GetRecommendedDeployment())
+			s.Apis = append(s.Apis, v)
+		}
+	}
+	for _, v := range archive.GetVersions() {
+		if name, ok := rename(v.GetName()); ok {
+			v = proto.Clone(v).(*rpc.ApiVersion)
+			v.Name = name
+			s.Versions = append(s.Versions, v)
+		}
+	}
+	for _, v := range archive.GetSpecRevisions() {
+		if name, ok := rename(v.GetName()); ok {
+			v = proto.Clone(v).(*rpc.ApiSpec)
+			v.Name = name
+			s.SpecRevisions = append(s.SpecRevisions, v)
+		}
+	}
+	for _, v := range archive.GetDeploymentRevisions() {
+		if name, ok := rename(v.GetName()); ok {
+			v = proto.Clone(v).(*rpc.ApiDeployment)
+			v.Name = name
+			v.ApiSpecRevision = ref(v.GetApiSpecRevision())
+			s.DeploymentRevisions = append(s.DeploymentRevisions, v)
+		}
+	}
+	for _, v := range archive.GetArtifacts() {
+		if name, ok := rename(v.GetName()); ok {
+			v = proto.Clone(v).(*rpc.Artifact)
+			v.Name = name
+			s.Artifacts = append(s.Artifacts, v)
+		}
+	}
+	for _, t := range archive.GetSpecRevisionTags() {
+		if name, ok := rename(t.GetRevision()); ok {
+			t = proto.Clone(t).(*rpc.ProjectArchive_Tag)
+			t.Revision = name
+			s.SpecRevisionTags = append(s.SpecRevisionTags, t)
+		}
+	}
+	for _, t := range archive.GetDeploymentRevisionTags() {
+		if name, ok := rename(t.GetRevision()); ok {
+			t = proto.Clone(t).(*rpc.ProjectArchive_Tag)
+			t.Revision = name
+			s.DeploymentRevisionTags = append(s.DeploymentRevisionTags, t)
+		}
+	}
+	return s
+}
@@ -0,0 +1,225 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/pkg/connection/grpctest"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// TestMain will set up a local RegistryServer and grpc.Server for all
+// tests in this package if APG_REGISTRY_ADDRESS env var is not set
+// for the client.
+func TestMain(m *testing.M) {
+	grpctest.TestMain(m, registry.Config{})
+}
+
+const parent = "projects/cp-test/locations/global"
+
+// setup creates projects for the tests and an API with a version, a spec with
+// two revisions, a tag, a deployment, and artifacts. It returns the revisions of the spec.
+func setup(t *testing.T) (connection.RegistryClient, connection.AdminClient, []*rpc.ApiSpec) {
+	t.Helper()
+	ctx := context.Background()
+	client, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, "cp-test", "cp-other")
+
+	api := parent + "/apis/a"
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: parent,
+		ApiId:  "a",
+		Api:    &rpc.Api{Labels: map[string]string{"owner": "me"}},
+	}); err != nil {
+		t.Fatalf("Error creating api %s", err)
+	}
+	revisions := cmdtest.SeedSpec(t, client, api)
+	if _, err := client.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     api + "/versions/v1/specs/s",
+		ArtifactId: "x",
+		Artifact:   &rpc.Artifact{MimeType: "text/plain", Contents: []byte("x")},
+	}); err != nil {
+		t.Fatalf("Error creating artifact %s", err)
+	}
+	if _, err := client.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api:        &rpc.Api{Name: api, RecommendedVersion: api + "/versions/v1"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"recommended_version"}},
+	}); err != nil {
+		t.Fatalf("Error updating api %s", err)
+	}
+	return client, adminClient, revisions
+}
+
+func TestCopyApi(t *testing.T) {
+	client, adminClient, revisions := setup(t)
+	ctx := context.Background()
+	from, to := parent+"/apis/a", parent+"/apis/b"
+	copied, err := Copy(ctx, client, adminClient, from, to)
+	if err != nil {
+		t.Fatalf("Copy returned error: %s", err)
+	}
+	if err := Verify(ctx, client, copied, to); err != nil {
+		t.Errorf("Verify returned error: %s", err)
+	}
+
+	api, err := client.GetApi(ctx, &rpc.GetApiRequest{Name: to})
+	if err != nil {
+		t.Fatalf("GetApi returned error: %s", err)
+	}
+	if api.GetRecommendedVersion() != to+"/versions/v1" || api.GetLabels()["owner"] != "me" {
+		t.Errorf("Copied API is %+v, want its reference and labels", api)
+	}
+	copies := cmdtest.ListSpecRevisions(t, client, to+"/versions/v1/specs/s")
+	opts := cmp.Options{
+		protocmp.Transform(),
+		protocmp.IgnoreFields(new(rpc.ApiSpec), "name"),
+	}
+	if diff := cmp.Diff(revisions, copies, opts); diff != "" {
+		t.Errorf("Copied revisions differ (-want +got):\n%s", diff)
+	}
+	tagged, err := client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: to + "/versions/v1/specs/s@prod"})
+	if err != nil {
+		t.Fatalf("GetApiSpec returned error: %s", err)
+	}
+	if tagged.GetRevisionId() != revisions[1].GetRevisionId() {
+		t.Errorf("Copied tag is on revision %s, want %s", tagged.GetRevisionId(), revisions[1].GetRevisionId())
+	}
+	deployment, err := client.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: to + "/deployments/d"})
+	if err != nil {
+		t.Fatalf("GetApiDeployment returned error: %s", err)
+	}
+	if want := to + "/versions/v1/specs/s@" + revisions[0].GetRevisionId(); deployment.GetApiSpecRevision() != want {
+		t.Errorf("Copied deployment refers to %s, want %s", deployment.GetApiSpecRevision(), want)
+	}
+	if _, err := client.GetArtifact(ctx, &rpc.GetArtifactRequest{Name: to + "/versions/v1/specs/s/artifacts/x"}); err != nil {
+		t.Errorf("GetArtifact returned error: %s", err)
+	}
+	// The original is unchanged.
+	if _, err := client.GetApi(ctx, &rpc.GetApiRequest{Name: from}); err != nil {
+		t.Errorf("GetApi returned error: %s", err)
+	}
+
+	if _, err := Copy(ctx, client, adminClient, from, to); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Copy to an existing API returned %v, want AlreadyExists", err)
+	}
+}
+
+func TestCopyWithoutImport(t *testing.T) {
+	client, _, revisions := setup(t)
+	ctx := context.Background()
+	from, to := parent+"/apis/a", parent+"/apis/b"
+	source, err := parseResource(from)
+	if err != nil {
+		t.Fatalf("parseResource returned error: %s", err)
+	}
+	archive, err := list(ctx, client, source, true)
+	if err != nil {
+		t.Fatalf("list returned error: %s", err)
+	}
+	copied, err := write(ctx, client, Subtree(archive, from, to))
+	if err != nil {
+		t.Fatalf("write returned error: %s", err)
+	}
+	if err := Verify(ctx, client, copied, to); err != nil {
+		t.Errorf("Verify returned error: %s", err)
+	}
+
+	// Copies get new revision IDs, and references and tags follow them.
+	copies := cmdtest.ListSpecRevisions(t, client, to+"/versions/v1/specs/s")
+	if len(copies) != len(revisions) {
+		t.Fatalf("Copied revisions are %v, want %d revisions", copies, len(revisions))
+	}
+	for i := range copies {
+		if copies[i].GetHash() != revisions[i].GetHash() {
+			t.Errorf("Copied revision %s has hash %s, want %s", copies[i].GetName(), copies[i].GetHash(), revisions[i].GetHash())
+		}
+	}
+	tagged, err := client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: to + "/versions/v1/specs/s@prod"})
+	if err != nil {
+		t.Fatalf("GetApiSpec returned error: %s", err)
+	}
+	if tagged.GetRevisionId() != copies[1].GetRevisionId() {
+		t.Errorf("Copied tag is on revision %s, want %s", tagged.GetRevisionId(), copies[1].GetRevisionId())
+	}
+	deployment, err := client.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: to + "/deployments/d"})
+	if err != nil {
+		t.Fatalf("GetApiDeployment returned error: %s", err)
+	}
+	if want := copies[0].GetName(); deployment.GetApiSpecRevision() != want {
+		t.Errorf("Copied deployment refers to %s, want %s", deployment.GetApiSpecRevision(), want)
+	}
+	api, err := client.GetApi(ctx, &rpc.GetApiRequest{Name: to})
+	if err != nil {
+		t.Fatalf("GetApi returned error: %s", err)
+	}
+	if api.GetRecommendedVersion() != to+"/versions/v1" || api.GetLabels()["owner"] != "me" {
+		t.Errorf("Copied API is %+v, want its reference and labels", api)
+	}
+}
+
+func TestCopyVersionToOtherProject(t *testing.T) {
+	client, adminClient, revisions := setup(t)
+	ctx := context.Background()
+	from := parent + "/apis/a/versions/v1"
+	to := "projects/cp-other/locations/global/apis/a/versions/v2"
+	if _, err := Copy(ctx, client, adminClient, from, to); status.Code(err) != codes.NotFound {
+		t.Errorf("Copy to a missing API returned %v, want NotFound", err)
+	}
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: "projects/cp-other/locations/global",
+		ApiId:  "a",
+		Api:    &rpc.Api{},
+	}); err != nil {
+		t.Fatalf("Error creating api %s", err)
+	}
+	copied, err := Copy(ctx, client, adminClient, from, to)
+	if err != nil {
+		t.Fatalf("Copy returned error: %s", err)
+	}
+	if err := Verify(ctx, client, copied, to); err != nil {
+		t.Errorf("Verify returned error: %s", err)
+	}
+	copies := cmdtest.ListSpecRevisions(t, client, to+"/specs/s")
+	if len(copies) != len(revisions) || copies[0].GetRevisionId() != revisions[0].GetRevisionId() {
+		t.Errorf("Copied revisions are %v, want %v", copies, revisions)
+	}
+}
+
+func TestCopyErrors(t *testing.T) {
+	client, adminClient, _ := setup(t)
+	ctx := context.Background()
+	for _, test := range []struct{ from, to string }{
+		{parent + "/apis/a", parent + "/apis/a/versions/v2"},
+		{parent + "/apis/a", parent + "/apis/a"},
+		{parent + "/apis/-", parent + "/apis/b"},
+		{parent + "/apis/a/versions/v1/specs/s@prod", parent + "/apis/a/versions/v1/specs/t"},
+		{parent + "/apis/a/artifacts/x", parent + "/apis/a/artifacts/y"},
+		{parent + "/apis/missing", parent + "/apis/b"},
+	} {
+		if _, err := Copy(ctx, client, adminClient, test.from, test.to); err == nil {
+			t.Errorf("Copy(%s, %s) succeeded, want error", test.from, test.to)
+		}
+	}
+}
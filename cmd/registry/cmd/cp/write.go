@@ -0,0 +1,203 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cp
+
+import (
+	"context"
+
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// write writes the resources in an archive with the Registry service, for
+// servers that don't implement ImportProject. Revisions are created in order and
+// get new revision IDs, so the returned archive lists the resources as they were written.
+func write(ctx context.Context, client connection.RegistryClient, archive *rpc.ProjectArchive) (*rpc.ProjectArchive, error) {
+	written := &rpc.ProjectArchive{
+		FormatVersion: archive.GetFormatVersion(),
+		CreateTime:    archive.GetCreateTime(),
+		Project:       archive.GetProject(),
+	}
+	// revisions maps the archived names of revisions to the names of their copies.
+	revisions := map[string]string{}
+	ref := func(name string) string {
+		if revision, ok := revisions[name]; ok {
+			return revision
+		}
+		return name
+	}
+	all := &fieldmaskpb.FieldMask{Paths: []string{"*"}}
+
+	for _, v := range archive.GetApis() {
+		n, err := names.ParseApi(v.GetName())
+		if err != nil {
+			return nil, err
+		}
+		api := proto.Clone(v).(*rpc.Api)
+		api.Name = ""
+		// References are set after the resources that they refer to are written.
+		api.RecommendedVersion, api.RecommendedDeployment = "", ""
+		if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+			Parent: n.Parent(),
+			ApiId:  n.ApiID,
+			Api:    api,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range archive.GetVersions() {
+		n, err := names.ParseVersion(v.GetName())
+		if err != nil {
+			return nil, err
+		}
+		version := proto.Clone(v).(*rpc.ApiVersion)
+		version.Name = ""
+		result, err := client.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+			Parent:       n.Parent(),
+			ApiVersionId: n.VersionID,
+			ApiVersion:   version,
+		})
+		if err != nil {
+			return nil, err
+		}
+		written.Versions = append(written.Versions, result)
+	}
+
+	// The first revision of each spec creates it and later revisions update it.
+	specs := map[string]bool{}
+	for _, v := range archive.GetSpecRevisions() {
+		n, err := names.ParseSpecRevision(v.GetName())
+		if err != nil {
+			return nil, err
+		}
+		spec := proto.Clone(v).(*rpc.ApiSpec)
+		spec.Name, spec.RevisionId = n.Spec().String(), ""
+		var result *rpc.ApiSpec
+		if specs[spec.Name] {
+			result, err = client.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{ApiSpec: spec, UpdateMask: all})
+		} else {
+			spec.Name = ""
+			result, err = client.CreateApiSpec(ctx, &rpc.CreateApiSpecRequest{
+				Parent:    n.Spec().Parent(),
+				ApiSpecId: n.SpecID,
+				ApiSpec:   spec,
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		specs[n.Spec().String()] = true
+		result.Name = result.GetName() + "@" + result.GetRevisionId()
+		revisions[v.GetName()] = result.GetName()
+		written.SpecRevisions = append(written.SpecRevisions, result)
+	}
+	for _, t := range archive.GetSpecRevisionTags() {
+		if _, err := client.TagApiSpecRevision(ctx, &rpc.TagApiSpecRevisionRequest{
+			Name: ref(t.GetRevision()),
+			Tag:  t.GetTag(),
+		}); err != nil {
+			return nil, err
+		}
+		t = proto.Clone(t).(*rpc.ProjectArchive_Tag)
+		t.Revision = ref(t.GetRevision())
+		written.SpecRevisionTags = append(written.SpecRevisionTags, t)
+	}
+
+	deployments := map[string]bool{}
+	for _, v := range archive.GetDeploymentRevisions() {
+		n, err := names.ParseDeploymentRevision(v.GetName())
+		if err != nil {
+			return nil, err
+		}
+		deployment := proto.Clone(v).(*rpc.ApiDeployment)
+		deployment.Name, deployment.RevisionId = n.Deployment().String(), ""
+		deployment.ApiSpecRevision = ref(v.GetApiSpecRevision())
+		var result *rpc.ApiDeployment
+		if deployments[deployment.Name] {
+			result, err = client.UpdateApiDeployment(ctx, &rpc.UpdateApiDeploymentRequest{ApiDeployment: deployment, UpdateMask: all})
+		} else {
+			deployment.Name = ""
+			result, err = client.CreateApiDeployment(ctx, &rpc.CreateApiDeploymentRequest{
+				Parent:          n.Deployment().Parent(),
+				ApiDeploymentId: n.DeploymentID,
+				ApiDeployment:   deployment,
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		deployments[n.Deployment().String()] = true
+		result.Name = result.GetName() + "@" + result.GetRevisionId()
+		revisions[v.GetName()] = result.GetName()
+		written.DeploymentRevisions = append(written.DeploymentRevisions, result)
+	}
+	for _, t := range archive.GetDeploymentRevisionTags() {
+		if _, err := client.TagApiDeploymentRevision(ctx, &rpc.TagApiDeploymentRevisionRequest{
+			Name: ref(t.GetRevision()),
+			Tag:  t.GetTag(),
+		}); err != nil {
+			return nil, err
+		}
+		t = proto.Clone(t).(*rpc.ProjectArchive_Tag)
+		t.Revision = ref(t.GetRevision())
+		written.DeploymentRevisionTags = append(written.DeploymentRevisionTags, t)
+	}
+
+	for _, v := range archive.GetArtifacts() {
+		n, err := names.ParseArtifact(v.GetName())
+		if err != nil {
+			return nil, err
+		}
+		// Artifacts that are maintained by the server can't be created by clients.
+		if n.Reserved() {
+			continue
+		}
+		artifact := proto.Clone(v).(*rpc.Artifact)
+		artifact.Name = ""
+		result, err := client.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+			Parent:     n.Parent(),
+			ArtifactId: n.ArtifactID(),
+			Artifact:   artifact,
+		})
+		if err != nil {
+			return nil, err
+		}
+		written.Artifacts = append(written.Artifacts, result)
+	}
+
+	for _, v := range archive.GetApis() {
+		if v.GetRecommendedVersion() == "" && v.GetRecommendedDeployment() == "" {
+			written.Apis = append(written.Apis, v)
+			continue
+		}
+		api := &rpc.Api{
+			Name:                  v.GetName(),
+			RecommendedVersion:    v.GetRecommendedVersion(),
+			RecommendedDeployment: ref(v.GetRecommendedDeployment()),
+		}
+		result, err := client.UpdateApi(ctx, &rpc.UpdateApiRequest{
+			Api:        api,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"recommended_version", "recommended_deployment"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		written.Apis = append(written.Apis, result)
+	}
+	return written, nil
+}
@@ -17,6 +17,7 @@ package delete
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/apigee/registry/cmd/registry/core"
 	"github.com/apigee/registry/gapic"
@@ -29,10 +30,13 @@ import (
 
 func Command() *cobra.Command {
 	var filter string
+	var dryRun bool
 	cmd := &cobra.Command{
 		Use:   "delete",
 		Short: "Delete resources from the API Registry",
-		Args:  cobra.ExactArgs(1),
+		Long: "Delete resources from the API Registry. Projects are deleted with everything that they " +
+			"contain by a server-side operation, which requires the Admin service.",
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := cmd.Context()
 			c, err := connection.ActiveConfig()
@@ -41,6 +45,19 @@ func Command() *cobra.Command {
 			}
 			args[0] = c.FQName(args[0])
 
+			if project, err := names.ParseProject(args[0]); err == nil {
+				adminClient, err := connection.NewAdminClientWithSettings(ctx, c)
+				if err != nil {
+					log.FromContext(ctx).WithError(err).Fatal("Failed to get client")
+				}
+				counts, err := bulkDelete(ctx, adminClient, project.String(), dryRun)
+				if err != nil {
+					log.FromContext(ctx).WithError(err).Fatal("Failed to delete project")
+				}
+				printCounts(cmd.OutOrStdout(), counts, dryRun)
+				return
+			}
+
 			client, err := connection.NewRegistryClientWithSettings(ctx, c)
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Fatal("Failed to get client")
@@ -50,7 +67,7 @@ func Command() *cobra.Command {
 			taskQueue, wait := core.WorkerPool(ctx, 64)
 			defer wait()
 
-			err = matchAndHandleDeleteCmd(ctx, client, taskQueue, args[0], filter)
+			err = matchAndHandleDeleteCmd(ctx, client, taskQueue, args[0], filter, dryRun)
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Fatal("Failed to match or handle command")
 			}
@@ -58,13 +75,50 @@ func Command() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&filter, "filter", "", "Filter selected resources")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what would be deleted without deleting anything")
 	return cmd
 }
 
+// bulkDelete deletes a resource and everything that it contains with a server-side operation
+// and returns the number of resources of each type that were deleted.
+func bulkDelete(ctx context.Context, client connection.AdminClient, name string, dryRun bool) (*rpc.ResourceCounts, error) {
+	op, err := client.BulkDelete(ctx, &rpc.BulkDeleteRequest{Name: name, ValidateOnly: dryRun})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf(ctx, "Waiting for operation %s", op.Name())
+	response, err := op.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return response.GetDeleted(), nil
+}
+
+func printCounts(w io.Writer, counts *rpc.ResourceCounts, dryRun bool) {
+	verb := "Deleted"
+	if dryRun {
+		verb = "Would delete"
+	}
+	for _, c := range []struct {
+		kind  string
+		count int32
+	}{
+		{"projects", counts.GetProjects()},
+		{"apis", counts.GetApis()},
+		{"versions", counts.GetVersions()},
+		{"spec revisions", counts.GetSpecRevisions()},
+		{"deployment revisions", counts.GetDeploymentRevisions()},
+		{"artifacts", counts.GetArtifacts()},
+	} {
+		fmt.Fprintf(w, "%s %d %s\n", verb, c.count, c.kind)
+	}
+}
+
 type deleteTask struct {
 	client       connection.RegistryClient
 	resourceName string
 	resourceKind string
+	dryRun       bool
 }
 
 func (task *deleteTask) String() string {
@@ -72,6 +126,10 @@ func (task *deleteTask) String() string {
 }
 
 func (task *deleteTask) Run(ctx context.Context) error {
+	if task.dryRun {
+		fmt.Printf("Would delete %s\n", task.resourceName)
+		return nil
+	}
 	log.Debugf(ctx, "Deleting %s %s", task.resourceKind, task.resourceName)
 	switch task.resourceKind {
 	case "api":
@@ -93,15 +151,16 @@ func matchAndHandleDeleteCmd(
 	taskQueue chan<- core.Task,
 	name string,
 	filter string,
+	dryRun bool,
 ) error {
 	if api, err := names.ParseApi(name); err == nil {
-		return deleteAPIs(ctx, client, api, filter, taskQueue)
+		return deleteAPIs(ctx, client, api, filter, dryRun, taskQueue)
 	} else if version, err := names.ParseVersion(name); err == nil {
-		return deleteVersions(ctx, client, version, filter, taskQueue)
+		return deleteVersions(ctx, client, version, filter, dryRun, taskQueue)
 	} else if spec, err := names.ParseSpec(name); err == nil {
-		return deleteSpecs(ctx, client, spec, filter, taskQueue)
+		return deleteSpecs(ctx, client, spec, filter, dryRun, taskQueue)
 	} else if artifact, err := names.ParseArtifact(name); err == nil {
-		return deleteArtifacts(ctx, client, artifact, filter, taskQueue)
+		return deleteArtifacts(ctx, client, artifact, filter, dryRun, taskQueue)
 	} else {
 		return fmt.Errorf("unsupported resource name: see the 'registry rpc delete-' subcommands for alternatives")
 	}
@@ -112,12 +171,14 @@ func deleteAPIs(
 	client *gapic.RegistryClient,
 	api names.Api,
 	filterFlag string,
+	dryRun bool,
 	taskQueue chan<- core.Task) error {
 	return core.ListAPIs(ctx, client, api, filterFlag, func(api *rpc.Api) error {
 		taskQueue <- &deleteTask{
 			client:       client,
 			resourceName: api.Name,
 			resourceKind: "api",
+			dryRun:       dryRun,
 		}
 		return nil
 	})
@@ -128,12 +189,14 @@ func deleteVersions(
 	client *gapic.RegistryClient,
 	version names.Version,
 	filterFlag string,
+	dryRun bool,
 	taskQueue chan<- core.Task) error {
 	return core.ListVersions(ctx, client, version, filterFlag, func(version *rpc.ApiVersion) error {
 		taskQueue <- &deleteTask{
 			client:       client,
 			resourceName: version.Name,
 			resourceKind: "version",
+			dryRun:       dryRun,
 		}
 		return nil
 	})
@@ -144,12 +207,14 @@ func deleteSpecs(
 	client *gapic.RegistryClient,
 	spec names.Spec,
 	filterFlag string,
+	dryRun bool,
 	taskQueue chan<- core.Task) error {
 	return core.ListSpecs(ctx, client, spec, filterFlag, func(spec *rpc.ApiSpec) error {
 		taskQueue <- &deleteTask{
 			client:       client,
 			resourceName: spec.Name,
 			resourceKind: "spec",
+			dryRun:       dryRun,
 		}
 		return nil
 	})
@@ -160,12 +225,14 @@ func deleteArtifacts(
 	client *gapic.RegistryClient,
 	artifact names.Artifact,
 	filterFlag string,
+	dryRun bool,
 	taskQueue chan<- core.Task) error {
 	return core.ListArtifacts(ctx, client, artifact, filterFlag, false, func(artifact *rpc.Artifact) error {
 		taskQueue <- &deleteTask{
 			client:       client,
 			resourceName: artifact.Name,
 			resourceKind: "artifact",
+			dryRun:       dryRun,
 		}
 		return nil
 	})
@@ -0,0 +1,85 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package delete
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/pkg/connection/grpctest"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestMain will set up a local RegistryServer and grpc.Server for all
+// tests in this package if APG_REGISTRY_ADDRESS env var is not set
+// for the client.
+func TestMain(m *testing.M) {
+	grpctest.TestMain(m, registry.Config{})
+}
+
+func TestDeleteProject(t *testing.T) {
+	const (
+		projectID   = "delete-test"
+		projectName = "projects/" + projectID
+	)
+	ctx := context.Background()
+	registryClient, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, projectID)
+	for _, id := range []string{"a", "b"} {
+		if _, err := registryClient.CreateApi(ctx, &rpc.CreateApiRequest{
+			Parent: projectName + "/locations/global",
+			ApiId:  id,
+			Api:    &rpc.Api{},
+		}); err != nil {
+			t.Fatalf("Error creating api %s", err)
+		}
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := Command()
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v) returned error: %s", args, err)
+		}
+		return out.String()
+	}
+
+	out := run(projectName, "--dry-run")
+	for _, want := range []string{"Would delete 1 projects", "Would delete 2 apis"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("delete %s --dry-run printed %q, want %q", projectName, out, want)
+		}
+	}
+	if _, err := adminClient.GetProject(ctx, &rpc.GetProjectRequest{Name: projectName}); err != nil {
+		t.Fatalf("delete %s --dry-run deleted the project: %s", projectName, err)
+	}
+
+	out = run(projectName)
+	if want := "Deleted 2 apis"; !strings.Contains(out, want) {
+		t.Errorf("delete %s printed %q, want %q", projectName, out, want)
+	}
+	if _, err := adminClient.GetProject(ctx, &rpc.GetProjectRequest{Name: projectName}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProject() after delete returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+}
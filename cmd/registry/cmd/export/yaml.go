@@ -28,6 +28,7 @@ import (
 
 func yamlCommand() *cobra.Command {
 	var jobs int
+	var contents bool
 	cmd := &cobra.Command{
 		Use:   "yaml RESOURCE",
 		Short: "Export a subtree of the registry as YAML",
@@ -49,7 +50,12 @@ func yamlCommand() *cobra.Command {
 			defer wait()
 
 			if project, err := names.ParseProject(args[0]); err == nil {
-				err = patch.ExportProject(ctx, client, project, taskQueue)
+				adminClient, err := connection.NewAdminClientWithSettings(ctx, c)
+				if err != nil {
+					log.FromContext(ctx).WithError(err).Fatal("Failed to get client")
+				}
+				opts := patch.ExportOptions{AdminClient: adminClient, Contents: contents}
+				err = patch.ExportProject(ctx, client, project, opts, taskQueue)
 				if err != nil {
 					log.FromContext(ctx).WithError(err).Fatal("Failed to export project YAML")
 				}
@@ -84,5 +90,6 @@ func yamlCommand() *cobra.Command {
 		},
 	}
 	cmd.Flags().IntVarP(&jobs, "jobs", "j", 10, "Number of file exports to perform simultaneously")
+	cmd.Flags().BoolVar(&contents, "contents", false, "Write spec contents next to the YAML files of exported projects")
 	return cmd
 }
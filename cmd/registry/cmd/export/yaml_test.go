@@ -0,0 +1,204 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/cmd/registry/patch"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+)
+
+// exportYAML exports a project with spec contents into a new directory and
+// returns the directory and the contents of the exported files.
+func exportYAML(t *testing.T, project names.Project) (string, map[string]string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %s", err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %s", err)
+	}
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatalf("Failed to restore working directory: %s", err)
+		}
+	}()
+
+	cmd := Command()
+	cmd.SetArgs([]string{"yaml", project.String(), "--contents"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() with args %v returned error: %s", cmd.Args, err)
+	}
+
+	files := make(map[string]string)
+	if err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		files[rel] = string(b)
+		return err
+	}); err != nil {
+		t.Fatalf("Failed to read exported files: %s", err)
+	}
+	return dir, files
+}
+
+func TestExportYAMLRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	client, adminClient := cmdtest.Clients(t)
+	project := names.Project{ProjectID: "export-yaml-test"}
+	cmdtest.DeleteProjects(t, adminClient, project.ProjectID)
+	if _, err := adminClient.CreateProject(ctx, &rpc.CreateProjectRequest{
+		ProjectId: project.ProjectID,
+		Project: &rpc.Project{
+			DisplayName: "Export Test",
+			Description: "A project that is exported and reapplied",
+		},
+	}); err != nil {
+		t.Fatalf("Setup: Failed to create test project: %s", err)
+	}
+	t.Cleanup(func() { cmdtest.DeleteProjects(t, adminClient, project.ProjectID) })
+
+	api := project.Api("petstore")
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: api.Parent(),
+		ApiId:  api.ApiID,
+		Api:    &rpc.Api{DisplayName: "Petstore", Labels: map[string]string{"tier": "critical"}},
+	}); err != nil {
+		t.Fatalf("Setup: Failed to create api: %s", err)
+	}
+	version := api.Version("v1")
+	if _, err := client.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent:       version.Parent(),
+		ApiVersionId: version.VersionID,
+		ApiVersion:   &rpc.ApiVersion{State: "Production"},
+	}); err != nil {
+		t.Fatalf("Setup: Failed to create version: %s", err)
+	}
+	openapi, err := core.GZippedBytes([]byte("openapi: 3.0.0\n"))
+	if err != nil {
+		t.Fatalf("Setup: Failed to compress spec: %s", err)
+	}
+	for _, spec := range []*rpc.ApiSpec{
+		{
+			Name:      version.Spec("openapi.yaml").String(),
+			MimeType:  "application/x.openapi+gzip;version=3",
+			SourceUri: "https://example.com/openapi.yaml",
+			Contents:  openapi,
+		},
+		{
+			Name:     version.Spec("protos.zip").String(),
+			MimeType: "application/x.protobuf+zip",
+			Contents: []byte("not really a zip archive"),
+		},
+	} {
+		specName, _ := names.ParseSpec(spec.Name)
+		if _, err := client.CreateApiSpec(ctx, &rpc.CreateApiSpecRequest{
+			Parent:    specName.Parent(),
+			ApiSpecId: specName.SpecID,
+			ApiSpec:   spec,
+		}); err != nil {
+			t.Fatalf("Setup: Failed to create spec: %s", err)
+		}
+	}
+	lifecycle, err := proto.Marshal(&rpc.Lifecycle{
+		Id:          "lifecycle",
+		Kind:        "Lifecycle",
+		DisplayName: "Lifecycle",
+		Stages:      []*rpc.Lifecycle_Stage{{Id: "design", DisplayName: "Design"}},
+	})
+	if err != nil {
+		t.Fatalf("Setup: Failed to marshal artifact: %s", err)
+	}
+	if _, err := client.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     project.String() + "/locations/global",
+		ArtifactId: "lifecycle",
+		Artifact:   &rpc.Artifact{MimeType: patch.MimeTypeForKind("Lifecycle"), Contents: lifecycle},
+	}); err != nil {
+		t.Fatalf("Setup: Failed to create artifact: %s", err)
+	}
+
+	dir, before := exportYAML(t, project)
+	for _, name := range []string{
+		"export-yaml-test/project.yaml",
+		"export-yaml-test/apis/petstore.yaml",
+		"export-yaml-test/apis/petstore/v1/openapi.yaml",
+		"export-yaml-test/apis/petstore/v1/protos.zip",
+		"export-yaml-test/artifacts/lifecycle.yaml",
+	} {
+		if _, ok := before[name]; !ok {
+			t.Errorf("Export is missing %s", name)
+		}
+	}
+	if want := "openapi: 3.0.0\n"; before["export-yaml-test/apis/petstore/v1/openapi.yaml"] != want {
+		t.Errorf("Exported spec contents are %q, want %q", before["export-yaml-test/apis/petstore/v1/openapi.yaml"], want)
+	}
+	if want := "contentsURI: file:petstore/v1/openapi.yaml"; !strings.Contains(before["export-yaml-test/apis/petstore.yaml"], want) {
+		t.Errorf("Exported API doesn't contain %q:\n%s", want, before["export-yaml-test/apis/petstore.yaml"])
+	}
+
+	// Specs above the streaming threshold are streamed with the same contents.
+	threshold := core.StreamingThreshold
+	core.StreamingThreshold = 8
+	_, streamed := exportYAML(t, project)
+	core.StreamingThreshold = threshold
+	if diff := cmp.Diff(before, streamed); diff != "" {
+		t.Errorf("Streamed export returned unexpected diff (-want +got):\n%s", diff)
+	}
+
+	// Recreate the project from the export.
+	if err := adminClient.DeleteProject(ctx, &rpc.DeleteProjectRequest{
+		Name:  project.String(),
+		Force: true,
+	}); err != nil {
+		t.Fatalf("Failed to delete test project: %s", err)
+	}
+	taskQueue, wait := core.WorkerPool(ctx, 4)
+	err = patch.Apply(ctx, client, filepath.Join(dir, project.ProjectID), project.String()+"/locations/global", true, taskQueue)
+	wait()
+	if err != nil {
+		t.Fatalf("Apply returned error: %s", err)
+	}
+
+	_, after := exportYAML(t, project)
+	if diff := cmp.Diff(before, after); diff != "" {
+		t.Errorf("Export after apply returned unexpected diff: (-want +got):\n%s", diff)
+	}
+	spec, err := client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: version.Spec("openapi.yaml").String()})
+	if err != nil {
+		t.Fatalf("GetApiSpec returned error: %s", err)
+	}
+	if want := "https://example.com/openapi.yaml"; spec.SourceUri != want {
+		t.Errorf("GetApiSpec returned source URI %q, want %q", spec.SourceUri, want)
+	}
+}
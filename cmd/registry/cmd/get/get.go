@@ -16,16 +16,20 @@ package get
 
 import (
 	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/cmd/registry/output"
+	"github.com/apigee/registry/cmd/registry/patch"
 	"github.com/apigee/registry/log"
 	"github.com/apigee/registry/pkg/connection"
 	"github.com/apigee/registry/server/registry/names"
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
 )
 
 func Command() *cobra.Command {
 	var getContents bool
 	var getRawContents bool
 	var getPrintedContents bool
+	var outputOpts output.Options
 
 	cmd := &cobra.Command{
 		Use:   "get",
@@ -45,6 +49,9 @@ func Command() *cobra.Command {
 				getPrintedContents = true
 				log.FromContext(ctx).Warn("--contents is deprecated, please use --print or --raw instead.")
 			}
+			if outputOpts.Specified() && (getPrintedContents || getRawContents) {
+				log.FromContext(ctx).Fatal("Output formats can't be used with --print, --raw, or --contents.")
+			}
 
 			client, err := connection.NewRegistryClientWithSettings(ctx, c)
 			if err != nil {
@@ -54,31 +61,41 @@ func Command() *cobra.Command {
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Fatal("Failed to get client")
 			}
+			var printer *output.Printer
+			if outputOpts.Specified() {
+				printer, err = output.New(cmd.OutOrStdout(), outputOpts, false)
+				if err != nil {
+					log.FromContext(ctx).WithError(err).Fatal("Invalid output options")
+				}
+				printer.Model = func(m proto.Message) (interface{}, error) {
+					return patch.NewModel(ctx, client, m)
+				}
+			}
 
 			var err2 error
 			if project, err := names.ParseProject(args[0]); err == nil {
-				err2 = core.GetProject(ctx, adminClient, project, core.PrintProjectDetail)
+				err2 = core.GetProject(ctx, adminClient, project, output.Handler(printer, core.PrintProjectDetail))
 			} else if api, err := names.ParseApi(args[0]); err == nil {
-				err2 = core.GetAPI(ctx, client, api, core.PrintAPIDetail)
+				err2 = core.GetAPI(ctx, client, api, output.Handler(printer, core.PrintAPIDetail))
 			} else if deployment, err := names.ParseDeployment(args[0]); err == nil {
-				err2 = core.GetDeployment(ctx, client, deployment, core.PrintDeploymentDetail)
+				err2 = core.GetDeployment(ctx, client, deployment, output.Handler(printer, core.PrintDeploymentDetail))
 			} else if deployment, err := names.ParseDeploymentRevision(args[0]); err == nil {
-				err2 = core.GetDeploymentRevision(ctx, client, deployment, core.PrintDeploymentDetail)
+				err2 = core.GetDeploymentRevision(ctx, client, deployment, output.Handler(printer, core.PrintDeploymentDetail))
 			} else if version, err := names.ParseVersion(args[0]); err == nil {
-				err2 = core.GetVersion(ctx, client, version, core.PrintVersionDetail)
+				err2 = core.GetVersion(ctx, client, version, output.Handler(printer, core.PrintVersionDetail))
 			} else if spec, err := names.ParseSpec(args[0]); err == nil {
 				// for specs, these options are synonymous
 				if getPrintedContents || getRawContents {
 					err2 = core.GetSpec(ctx, client, spec, true, core.WriteSpecContents)
 				} else {
-					err2 = core.GetSpec(ctx, client, spec, false, core.PrintSpecDetail)
+					err2 = core.GetSpec(ctx, client, spec, false, output.Handler(printer, core.PrintSpecDetail))
 				}
 			} else if spec, err := names.ParseSpecRevision(args[0]); err == nil {
 				// for specs, these options are synonymous
 				if getPrintedContents || getRawContents {
 					err2 = core.GetSpecRevision(ctx, client, spec, true, core.WriteSpecContents)
 				} else {
-					err2 = core.GetSpecRevision(ctx, client, spec, false, core.PrintSpecDetail)
+					err2 = core.GetSpecRevision(ctx, client, spec, false, output.Handler(printer, core.PrintSpecDetail))
 				}
 			} else if artifact, err := names.ParseArtifact(args[0]); err == nil {
 				if getPrintedContents {
@@ -86,11 +103,14 @@ func Command() *cobra.Command {
 				} else if getRawContents {
 					err2 = core.GetArtifact(ctx, client, artifact, true, core.WriteArtifactContents)
 				} else {
-					err2 = core.GetArtifact(ctx, client, artifact, false, core.PrintArtifactDetail)
+					err2 = core.GetArtifact(ctx, client, artifact, false, output.Handler(printer, core.PrintArtifactDetail))
 				}
 			} else {
 				log.Errorf(ctx, "Unsupported entity %+v", args)
 			}
+			if err2 == nil && printer != nil {
+				err2 = printer.Flush()
+			}
 			if err2 != nil {
 				log.FromContext(ctx).WithError(err2).Errorf("Failed to get resource")
 			}
@@ -100,5 +120,6 @@ func Command() *cobra.Command {
 	cmd.Flags().BoolVar(&getContents, "contents", false, "Get resource contents if available (deprecated)")
 	cmd.Flags().BoolVar(&getRawContents, "raw", false, "Get raw resource contents if available")
 	cmd.Flags().BoolVar(&getPrintedContents, "print", false, "Print resource contents if available")
+	outputOpts.AddFlags(cmd.Flags())
 	return cmd
 }
@@ -0,0 +1,271 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	breakingchangedetector "github.com/apigee/registry/cmd/registry/breaking-change-detector"
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/cmd/registry/diff"
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "history RESOURCE",
+		Short: "Show the revision history of a spec or deployment",
+		Long: "Show every revision of a spec or deployment, oldest first, with its creation time and tags. " +
+			"Spec revisions include their hash and size and, for OpenAPI v3 specs, the number of breaking, " +
+			"non-breaking, and unknown changes from the previous revision. Deployment revisions include " +
+			"the spec revision that they refer to.",
+		Example: "registry history projects/p/locations/global/apis/a/versions/v1/specs/openapi -o markdown",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := cmd.Context()
+			printer, ok := printers[format]
+			if !ok {
+				return fmt.Errorf("unsupported output format %q", format)
+			}
+			c, err := connection.ActiveConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get config: %s", err)
+			}
+			client, err := connection.NewRegistryClientWithSettings(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to get client: %s", err)
+			}
+			h, err := GetHistory(ctx, client, c.FQName(args[0]))
+			if err != nil {
+				return err
+			}
+			return printer(cmd.OutOrStdout(), h)
+		},
+	}
+	cmd.Flags().StringVarP(&format, "output", "o", "text", "Output format: one of text, json, or markdown")
+	return cmd
+}
+
+// History is the revision history of a spec or deployment.
+type History struct {
+	Name      string      `json:"name"`
+	Revisions []*Revision `json:"revisions"`
+}
+
+// Revision describes one revision of a spec or deployment.
+// Hash, SizeBytes, and Changes are only set for specs,
+// and ApiSpecRevision is only set for deployments.
+type Revision struct {
+	RevisionID      string    `json:"revision_id"`
+	CreateTime      time.Time `json:"create_time"`
+	Tags            []string  `json:"tags,omitempty"`
+	Hash            string    `json:"hash,omitempty"`
+	SizeBytes       int32     `json:"size_bytes,omitempty"`
+	ApiSpecRevision string    `json:"api_spec_revision,omitempty"`
+	Changes         *Changes  `json:"changes,omitempty"`
+}
+
+// Changes counts the classified changes between a spec revision and the previous revision.
+type Changes struct {
+	Breaking    int `json:"breaking"`
+	NonBreaking int `json:"non_breaking"`
+	Unknown     int `json:"unknown"`
+}
+
+// GetHistory returns the revisions of the named spec or deployment, oldest first.
+func GetHistory(ctx context.Context,
+	client connection.RegistryClient,
+	name string) (*History, error) {
+	h := &History{Name: name}
+	var specs []*rpc.ApiSpec
+	// Tags are indexed by revision ID.
+	tags := map[string][]string{}
+	addTag := func(t *rpc.RevisionTag) error {
+		_, id, _ := strings.Cut(t.GetRevision(), "@")
+		tags[id] = append(tags[id], t.GetTag())
+		return nil
+	}
+	if spec, err := names.ParseSpec(name); err == nil {
+		err = core.ListSpecRevisionsWithTags(ctx, client, spec, func(r *rpc.ApiSpec) error {
+			specs = append(specs, r)
+			h.Revisions = append(h.Revisions, &Revision{
+				RevisionID: r.GetRevisionId(),
+				CreateTime: r.GetRevisionCreateTime().AsTime(),
+				Hash:       r.GetHash(),
+				SizeBytes:  r.GetSizeBytes(),
+			})
+			return nil
+		}, addTag)
+		if err != nil {
+			return nil, err
+		}
+	} else if deployment, err := names.ParseDeployment(name); err == nil {
+		err = core.ListDeploymentRevisionsWithTags(ctx, client, deployment, func(r *rpc.ApiDeployment) error {
+			h.Revisions = append(h.Revisions, &Revision{
+				RevisionID:      r.GetRevisionId(),
+				CreateTime:      r.GetRevisionCreateTime().AsTime(),
+				ApiSpecRevision: r.GetApiSpecRevision(),
+			})
+			return nil
+		}, addTag)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("invalid name %q: must be a spec or deployment", name)
+	}
+
+	// Revisions are listed newest first.
+	for i, j := 0, len(h.Revisions)-1; i < j; i, j = i+1, j-1 {
+		h.Revisions[i], h.Revisions[j] = h.Revisions[j], h.Revisions[i]
+	}
+	for i, j := 0, len(specs)-1; i < j; i, j = i+1, j-1 {
+		specs[i], specs[j] = specs[j], specs[i]
+	}
+
+	for _, r := range h.Revisions {
+		r.Tags = tags[r.RevisionID]
+		sort.Strings(r.Tags)
+	}
+
+	for i := 1; i < len(specs); i++ {
+		changes, err := compare(ctx, client, specs[i-1], specs[i])
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Warnf("Failed to compare %s with the previous revision", specs[i].GetName())
+			continue
+		}
+		h.Revisions[i].Changes = changes
+	}
+	return h, nil
+}
+
+// compare classifies the changes between two revisions of an OpenAPI v3 spec.
+// It returns nil if the revisions can't be compared.
+func compare(ctx context.Context, client connection.RegistryClient, base, revision *rpc.ApiSpec) (*Changes, error) {
+	if !core.IsOpenAPIv3(revision.GetMimeType()) || core.IsZipArchive(revision.GetMimeType()) ||
+		base.GetMimeType() != revision.GetMimeType() {
+		return nil, nil
+	}
+	// Contents are returned uncompressed unless the request accepts gzip.
+	b, err := client.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{Name: base.GetName()})
+	if err != nil {
+		return nil, err
+	}
+	r, err := client.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{Name: revision.GetName()})
+	if err != nil {
+		return nil, err
+	}
+	d, err := diff.GetDiff(b.GetData(), r.GetData())
+	if err != nil {
+		return nil, err
+	}
+	details := breakingchangedetector.GetChangeDetails(d)
+	return &Changes{
+		Breaking:    count(details.GetBreakingChanges()),
+		NonBreaking: count(details.GetNonBreakingChanges()),
+		Unknown:     count(details.GetUnknownChanges()),
+	}, nil
+}
+
+func count(d *rpc.Diff) int {
+	return len(d.GetAdditions()) + len(d.GetDeletions()) + len(d.GetModifications())
+}
+
+var printers = map[string]func(io.Writer, *History) error{
+	"text":     printText,
+	"json":     printJSON,
+	"markdown": printMarkdown,
+}
+
+// columns returns the header and the rows of a history table.
+func columns(h *History) ([]string, [][]string) {
+	deployment := false
+	if _, err := names.ParseDeployment(h.Name); err == nil {
+		deployment = true
+	}
+	header := []string{"REVISION", "CREATED", "TAGS", "HASH", "SIZE", "CHANGES"}
+	if deployment {
+		header = []string{"REVISION", "CREATED", "TAGS", "SPEC REVISION"}
+	}
+	var rows [][]string
+	for _, r := range h.Revisions {
+		row := []string{r.RevisionID, r.CreateTime.Format(time.RFC3339), strings.Join(r.Tags, ",")}
+		if deployment {
+			row = append(row, r.ApiSpecRevision)
+		} else {
+			changes := ""
+			if c := r.Changes; c != nil {
+				changes = fmt.Sprintf("%d breaking, %d non-breaking, %d unknown", c.Breaking, c.NonBreaking, c.Unknown)
+			}
+			row = append(row, r.Hash, fmt.Sprintf("%d", r.SizeBytes), changes)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+func printText(w io.Writer, h *History) error {
+	header, rows := columns(h)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, h *History) error {
+	if h.Revisions == nil {
+		h.Revisions = []*Revision{}
+	}
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func printMarkdown(w io.Writer, h *History) error {
+	header, rows := columns(h)
+	fmt.Fprintf(w, "# %s\n\n", h.Name)
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(header)))
+	for _, row := range rows {
+		for i, v := range row {
+			if v == "" {
+				v = "-"
+			}
+			row[i] = strings.ReplaceAll(v, "|", `\|`)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/pkg/connection/grpctest"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// TestMain will set up a local RegistryServer and grpc.Server for all
+// tests in this package if APG_REGISTRY_ADDRESS env var is not set
+// for the client.
+func TestMain(m *testing.M) {
+	grpctest.TestMain(m, registry.Config{})
+}
+
+const api = "projects/history-test/locations/global/apis/a"
+
+const specV1 = `openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+  /owners:
+    get:
+      responses:
+        "200":
+          description: OK
+`
+
+const specV2 = `openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.1.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+`
+
+func setup(t *testing.T) connection.RegistryClient {
+	t.Helper()
+	ctx := context.Background()
+	client, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, "history-test")
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: "projects/history-test/locations/global",
+		ApiId:  "a",
+		Api:    &rpc.Api{},
+	}); err != nil {
+		t.Fatalf("Error creating api %s", err)
+	}
+	if _, err := client.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent:       api,
+		ApiVersionId: "v1",
+		ApiVersion:   &rpc.ApiVersion{},
+	}); err != nil {
+		t.Fatalf("Error creating version %s", err)
+	}
+	return client
+}
+
+func TestSpecHistory(t *testing.T) {
+	client := setup(t)
+	ctx := context.Background()
+	spec := api + "/versions/v1/specs/openapi"
+	first, err := client.CreateApiSpec(ctx, &rpc.CreateApiSpecRequest{
+		Parent:    api + "/versions/v1",
+		ApiSpecId: "openapi",
+		ApiSpec:   &rpc.ApiSpec{MimeType: "application/x.openapi;version=3", Contents: []byte(specV1)},
+	})
+	if err != nil {
+		t.Fatalf("Error creating spec %s", err)
+	}
+	second, err := client.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+		ApiSpec:    &rpc.ApiSpec{Name: spec, Contents: []byte(specV2)},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"contents"}},
+	})
+	if err != nil {
+		t.Fatalf("Error updating spec %s", err)
+	}
+	for _, tag := range []string{"prod", "latest"} {
+		if _, err := client.TagApiSpecRevision(ctx, &rpc.TagApiSpecRevisionRequest{Name: spec + "@" + second.GetRevisionId(), Tag: tag}); err != nil {
+			t.Fatalf("Error tagging spec %s", err)
+		}
+	}
+
+	got, err := GetHistory(ctx, client, spec)
+	if err != nil {
+		t.Fatalf("GetHistory returned error: %s", err)
+	}
+	want := &History{
+		Name: spec,
+		Revisions: []*Revision{
+			{
+				RevisionID: first.GetRevisionId(),
+				CreateTime: first.GetRevisionCreateTime().AsTime(),
+				Hash:       first.GetHash(),
+				SizeBytes:  first.GetSizeBytes(),
+			},
+			{
+				RevisionID: second.GetRevisionId(),
+				CreateTime: second.GetRevisionCreateTime().AsTime(),
+				Tags:       []string{"latest", "prod"},
+				Hash:       second.GetHash(),
+				SizeBytes:  second.GetSizeBytes(),
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Revision{}, "Changes")); diff != "" {
+		t.Errorf("GetHistory returned unexpected diff (-want +got):\n%s", diff)
+	}
+	if got.Revisions[0].Changes != nil {
+		t.Errorf("First revision has changes %+v, want none", got.Revisions[0].Changes)
+	}
+	// Removing a path is breaking and changing the info version isn't.
+	if c := got.Revisions[1].Changes; c == nil || c.Breaking == 0 || c.NonBreaking == 0 {
+		t.Errorf("Second revision has changes %+v, want breaking and non-breaking changes", c)
+	}
+
+	var text bytes.Buffer
+	if err := printText(&text, got); err != nil {
+		t.Fatalf("printText returned error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "REVISION") ||
+		!strings.HasPrefix(lines[2], second.GetRevisionId()) || !strings.Contains(lines[2], "latest,prod") {
+		t.Errorf("printText printed:\n%s", text.String())
+	}
+
+	var markdown bytes.Buffer
+	if err := printMarkdown(&markdown, got); err != nil {
+		t.Fatalf("printMarkdown returned error: %s", err)
+	}
+	if !strings.Contains(markdown.String(), "| "+first.GetRevisionId()+" | ") {
+		t.Errorf("printMarkdown printed:\n%s", markdown.String())
+	}
+
+	var out bytes.Buffer
+	if err := printJSON(&out, got); err != nil {
+		t.Fatalf("printJSON returned error: %s", err)
+	}
+	parsed := &History{}
+	if err := json.Unmarshal(out.Bytes(), parsed); err != nil {
+		t.Fatalf("printJSON printed invalid JSON: %s", err)
+	}
+	if diff := cmp.Diff(got, parsed); diff != "" {
+		t.Errorf("printJSON returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDeploymentHistory(t *testing.T) {
+	client := setup(t)
+	ctx := context.Background()
+	deployment := api + "/deployments/d"
+	first, err := client.CreateApiDeployment(ctx, &rpc.CreateApiDeploymentRequest{
+		Parent:          api,
+		ApiDeploymentId: "d",
+		ApiDeployment:   &rpc.ApiDeployment{ApiSpecRevision: api + "/versions/v1/specs/s@1"},
+	})
+	if err != nil {
+		t.Fatalf("Error creating deployment %s", err)
+	}
+	second, err := client.UpdateApiDeployment(ctx, &rpc.UpdateApiDeploymentRequest{
+		ApiDeployment: &rpc.ApiDeployment{Name: deployment, ApiSpecRevision: api + "/versions/v1/specs/s@2"},
+		UpdateMask:    &fieldmaskpb.FieldMask{Paths: []string{"api_spec_revision"}},
+	})
+	if err != nil {
+		t.Fatalf("Error updating deployment %s", err)
+	}
+	if _, err := client.TagApiDeploymentRevision(ctx, &rpc.TagApiDeploymentRevisionRequest{Name: deployment + "@" + first.GetRevisionId(), Tag: "stable"}); err != nil {
+		t.Fatalf("Error tagging deployment %s", err)
+	}
+
+	got, err := GetHistory(ctx, client, deployment)
+	if err != nil {
+		t.Fatalf("GetHistory returned error: %s", err)
+	}
+	want := &History{
+		Name: deployment,
+		Revisions: []*Revision{
+			{
+				RevisionID:      first.GetRevisionId(),
+				CreateTime:      first.GetRevisionCreateTime().AsTime(),
+				Tags:            []string{"stable"},
+				ApiSpecRevision: first.GetApiSpecRevision(),
+			},
+			{
+				RevisionID:      second.GetRevisionId(),
+				CreateTime:      second.GetRevisionCreateTime().AsTime(),
+				ApiSpecRevision: second.GetApiSpecRevision(),
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetHistory returned unexpected diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := GetHistory(ctx, client, api); err == nil {
+		t.Errorf("GetHistory(%s) succeeded, want error", api)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmdtest provides fixtures for tests of registry commands.
+package cmdtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Clients returns registry and admin clients that are closed when the test ends.
+func Clients(t *testing.T) (connection.RegistryClient, connection.AdminClient) {
+	t.Helper()
+	ctx := context.Background()
+	client, err := connection.NewRegistryClient(ctx)
+	if err != nil {
+		t.Fatalf("Error creating client: %+v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	adminClient, err := connection.NewAdminClient(ctx)
+	if err != nil {
+		t.Fatalf("Error creating client: %+v", err)
+	}
+	t.Cleanup(func() { adminClient.Close() })
+	return client, adminClient
+}
+
+// DeleteProjects deletes the named projects and everything in them, if they exist.
+func DeleteProjects(t *testing.T, adminClient connection.AdminClient, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		err := adminClient.DeleteProject(context.Background(), &rpc.DeleteProjectRequest{Name: "projects/" + id, Force: true})
+		if err != nil && status.Code(err) != codes.NotFound {
+			t.Fatalf("Error deleting test project: %+v", err)
+		}
+	}
+}
+
+// CreateProjects creates empty projects, replacing any that exist.
+func CreateProjects(t *testing.T, adminClient connection.AdminClient, ids ...string) {
+	t.Helper()
+	DeleteProjects(t, adminClient, ids...)
+	for _, id := range ids {
+		if _, err := adminClient.CreateProject(context.Background(), &rpc.CreateProjectRequest{
+			ProjectId: id,
+			Project:   &rpc.Project{},
+		}); err != nil {
+			t.Fatalf("Error creating project %s", err)
+		}
+	}
+}
+
+// SeedSpec creates version "v1" of an existing API with a spec "s" that has two
+// revisions, tags the older revision "prod", and creates a deployment "d" of the
+// newer revision. It returns the revisions of the spec, newest first.
+func SeedSpec(t *testing.T, client connection.RegistryClient, api string) []*rpc.ApiSpec {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := client.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent:       api,
+		ApiVersionId: "v1",
+		ApiVersion:   &rpc.ApiVersion{},
+	}); err != nil {
+		t.Fatalf("Error creating version %s", err)
+	}
+	spec := api + "/versions/v1/specs/s"
+	if _, err := client.CreateApiSpec(ctx, &rpc.CreateApiSpecRequest{
+		Parent:    api + "/versions/v1",
+		ApiSpecId: "s",
+		ApiSpec:   &rpc.ApiSpec{MimeType: "text/plain", Contents: []byte("one")},
+	}); err != nil {
+		t.Fatalf("Error creating spec %s", err)
+	}
+	if _, err := client.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+		ApiSpec:    &rpc.ApiSpec{Name: spec, Contents: []byte("two")},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"contents"}},
+	}); err != nil {
+		t.Fatalf("Error updating spec %s", err)
+	}
+	revisions := ListSpecRevisions(t, client, spec)
+	if len(revisions) != 2 {
+		t.Fatalf("Spec has %d revisions, want 2", len(revisions))
+	}
+	if _, err := client.TagApiSpecRevision(ctx, &rpc.TagApiSpecRevisionRequest{
+		Name: revisions[1].GetName(),
+		Tag:  "prod",
+	}); err != nil {
+		t.Fatalf("Error tagging spec %s", err)
+	}
+	if _, err := client.CreateApiDeployment(ctx, &rpc.CreateApiDeploymentRequest{
+		Parent:          api,
+		ApiDeploymentId: "d",
+		ApiDeployment:   &rpc.ApiDeployment{ApiSpecRevision: revisions[0].GetName()},
+	}); err != nil {
+		t.Fatalf("Error creating deployment %s", err)
+	}
+	return revisions
+}
+
+// ListSpecRevisions returns the revisions of a spec, newest first.
+func ListSpecRevisions(t *testing.T, client connection.RegistryClient, spec string) []*rpc.ApiSpec {
+	t.Helper()
+	var revisions []*rpc.ApiSpec
+	it := client.ListApiSpecRevisions(context.Background(), &rpc.ListApiSpecRevisionsRequest{Name: spec})
+	for r, err := it.Next(); err == nil; r, err = it.Next() {
+		revisions = append(revisions, r)
+	}
+	return revisions
+}
+
+// Contains reports whether values includes value.
+func Contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -20,14 +20,18 @@ import (
 	"strings"
 
 	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/cmd/registry/output"
+	"github.com/apigee/registry/cmd/registry/patch"
 	"github.com/apigee/registry/log"
 	"github.com/apigee/registry/pkg/connection"
 	"github.com/apigee/registry/server/registry/names"
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
 )
 
 func Command() *cobra.Command {
 	var filter string
+	var outputOpts output.Options
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List resources in the API Registry",
@@ -48,14 +52,30 @@ func Command() *cobra.Command {
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Fatal("Failed to get client")
 			}
-			err = matchAndHandleListCmd(ctx, client, adminClient, args[0], filter)
+			var printer *output.Printer
+			if outputOpts.Specified() {
+				printer, err = output.New(cmd.OutOrStdout(), outputOpts, true)
+				if err != nil {
+					log.FromContext(ctx).WithError(err).Fatal("Invalid output options")
+				}
+				printer.Model = func(m proto.Message) (interface{}, error) {
+					return patch.NewModel(ctx, client, m)
+				}
+			}
+			err = matchAndHandleListCmd(ctx, client, adminClient, args[0], filter, printer)
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Fatal("Failed to match or handle command")
 			}
+			if printer != nil {
+				if err := printer.Flush(); err != nil {
+					log.FromContext(ctx).WithError(err).Fatal("Failed to print resources")
+				}
+			}
 		},
 	}
 
 	cmd.Flags().StringVar(&filter, "filter", "", "Filter selected resources")
+	outputOpts.AddFlags(cmd.Flags())
 	return cmd
 }
 
@@ -65,35 +85,36 @@ func matchAndHandleListCmd(
 	adminClient connection.AdminClient,
 	name string,
 	filter string,
+	printer *output.Printer,
 ) error {
 	// First try to match collection names.
 	if project, err := names.ParseProjectCollection(name); err == nil {
-		return core.ListProjects(ctx, adminClient, project, filter, core.PrintProject)
+		return core.ListProjects(ctx, adminClient, project, filter, output.Handler(printer, core.PrintProject))
 	} else if api, err := names.ParseApiCollection(name); err == nil {
-		return core.ListAPIs(ctx, client, api, filter, core.PrintAPI)
+		return core.ListAPIs(ctx, client, api, filter, output.Handler(printer, core.PrintAPI))
 	} else if deployment, err := names.ParseDeploymentCollection(name); err == nil {
-		return core.ListDeployments(ctx, client, deployment, filter, core.PrintDeployment)
+		return core.ListDeployments(ctx, client, deployment, filter, output.Handler(printer, core.PrintDeployment))
 	} else if version, err := names.ParseVersionCollection(name); err == nil {
-		return core.ListVersions(ctx, client, version, filter, core.PrintVersion)
+		return core.ListVersions(ctx, client, version, filter, output.Handler(printer, core.PrintVersion))
 	} else if spec, err := names.ParseSpecCollection(name); err == nil {
-		return core.ListSpecs(ctx, client, spec, filter, core.PrintSpec)
+		return core.ListSpecs(ctx, client, spec, filter, output.Handler(printer, core.PrintSpec))
 	} else if artifact, err := names.ParseArtifactCollection(name); err == nil {
-		return core.ListArtifacts(ctx, client, artifact, filter, false, core.PrintArtifact)
+		return core.ListArtifacts(ctx, client, artifact, filter, false, output.Handler(printer, core.PrintArtifact))
 	}
 
 	// Then try to match resource names.
 	if project, err := names.ParseProject(name); err == nil {
-		return core.ListProjects(ctx, adminClient, project, filter, core.PrintProject)
+		return core.ListProjects(ctx, adminClient, project, filter, output.Handler(printer, core.PrintProject))
 	} else if api, err := names.ParseApi(name); err == nil {
-		return core.ListAPIs(ctx, client, api, filter, core.PrintAPI)
+		return core.ListAPIs(ctx, client, api, filter, output.Handler(printer, core.PrintAPI))
 	} else if deployment, err := names.ParseDeployment(name); err == nil {
-		return core.ListDeployments(ctx, client, deployment, filter, core.PrintDeployment)
+		return core.ListDeployments(ctx, client, deployment, filter, output.Handler(printer, core.PrintDeployment))
 	} else if version, err := names.ParseVersion(name); err == nil {
-		return core.ListVersions(ctx, client, version, filter, core.PrintVersion)
+		return core.ListVersions(ctx, client, version, filter, output.Handler(printer, core.PrintVersion))
 	} else if spec, err := names.ParseSpec(name); err == nil {
-		return core.ListSpecs(ctx, client, spec, filter, core.PrintSpec)
+		return core.ListSpecs(ctx, client, spec, filter, output.Handler(printer, core.PrintSpec))
 	} else if artifact, err := names.ParseArtifact(name); err == nil {
-		return core.ListArtifacts(ctx, client, artifact, filter, false, core.PrintArtifact)
+		return core.ListArtifacts(ctx, client, artifact, filter, false, output.Handler(printer, core.PrintArtifact))
 	}
 
 	// Then try to match resources with revisions.
@@ -101,9 +122,9 @@ func matchAndHandleListCmd(
 	if strings.HasSuffix(name, "@-") {
 		name := strings.TrimSuffix(name, "@-")
 		if deployment, err := names.ParseDeployment(name); err == nil {
-			return core.ListDeploymentRevisions(ctx, client, deployment, core.PrintDeployment)
+			return core.ListDeploymentRevisions(ctx, client, deployment, output.Handler(printer, core.PrintDeployment))
 		} else if spec, err := names.ParseSpec(name); err == nil {
-			return core.ListSpecRevisions(ctx, client, spec, core.PrintSpec)
+			return core.ListSpecRevisions(ctx, client, spec, output.Handler(printer, core.PrintSpec))
 		}
 	}
 
@@ -0,0 +1,138 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apigee/registry/cmd/registry/cmd/cp"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func Command() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mv SOURCE DESTINATION",
+		Short: "Move an API, version, spec, or deployment to a new name",
+		Long: "Move an API, version, spec, or deployment and everything that it contains to a new name, " +
+			"which may be in another parent or project. The resources are copied as with " +
+			"\"registry cp\", the copies are compared with the originals, references to the " +
+			"originals are changed to refer to the copies, and then the originals are deleted.",
+		Example: "registry mv projects/p/locations/global/apis/a/versions/v1 projects/p/locations/global/apis/b/versions/v1",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := cmd.Context()
+			c, err := connection.ActiveConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get config: %s", err)
+			}
+			client, err := connection.NewRegistryClientWithSettings(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to get client: %s", err)
+			}
+			adminClient, err := connection.NewAdminClientWithSettings(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to get client: %s", err)
+			}
+			from, to := c.FQName(args[0]), c.FQName(args[1])
+			if err := Move(ctx, client, adminClient, from, to); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved %s to %s\n", from, to)
+			return nil
+		},
+	}
+}
+
+// Move copies a resource to a new name and deletes the original after
+// verifying the copy and updating the references to the original.
+func Move(ctx context.Context,
+	client connection.RegistryClient,
+	adminClient connection.AdminClient,
+	from, to string) error {
+	copied, err := cp.Copy(ctx, client, adminClient, from, to)
+	if err != nil {
+		return err
+	}
+	if err := cp.Verify(ctx, client, copied, to); err != nil {
+		return fmt.Errorf("failed to verify copy, %s was not deleted: %s", from, err)
+	}
+	refs, err := adminClient.ListReferences(ctx, &rpc.ListReferencesRequest{Name: from})
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs.GetReferences() {
+		// References held by the original resources were changed in their copies.
+		if within(ref.GetName(), from) {
+			continue
+		}
+		value := to + strings.TrimPrefix(ref.GetValue(), from)
+		if err := updateReference(ctx, client, ref.GetName(), ref.GetField(), value); err != nil {
+			return fmt.Errorf("failed to update %s.%s: %s", ref.GetName(), ref.GetField(), err)
+		}
+	}
+	return deleteResource(ctx, client, from)
+}
+
+// within returns true if a resource is named name or is contained in it.
+func within(resource, name string) bool {
+	return resource == name || strings.HasPrefix(resource, name+"/") || strings.HasPrefix(resource, name+"@")
+}
+
+// updateReference sets a reference field of an API or deployment.
+func updateReference(ctx context.Context, client connection.RegistryClient, name, field, value string) error {
+	mask := &fieldmaskpb.FieldMask{Paths: []string{field}}
+	if _, err := names.ParseApi(name); err == nil {
+		api := &rpc.Api{Name: name}
+		switch field {
+		case "recommended_version":
+			api.RecommendedVersion = value
+		case "recommended_deployment":
+			api.RecommendedDeployment = value
+		default:
+			return fmt.Errorf("unsupported field %q", field)
+		}
+		_, err := client.UpdateApi(ctx, &rpc.UpdateApiRequest{Api: api, UpdateMask: mask})
+		return err
+	}
+	if _, err := names.ParseDeployment(name); err == nil && field == "api_spec_revision" {
+		_, err := client.UpdateApiDeployment(ctx, &rpc.UpdateApiDeploymentRequest{
+			ApiDeployment: &rpc.ApiDeployment{Name: name, ApiSpecRevision: value},
+			UpdateMask:    mask,
+		})
+		return err
+	}
+	return fmt.Errorf("unsupported reference %s.%s", name, field)
+}
+
+// deleteResource deletes a resource and everything that it contains.
+func deleteResource(ctx context.Context, client connection.RegistryClient, name string) error {
+	if _, err := names.ParseApi(name); err == nil {
+		return client.DeleteApi(ctx, &rpc.DeleteApiRequest{Name: name, Force: true})
+	} else if _, err := names.ParseVersion(name); err == nil {
+		return client.DeleteApiVersion(ctx, &rpc.DeleteApiVersionRequest{Name: name, Force: true})
+	} else if _, err := names.ParseSpec(name); err == nil {
+		return client.DeleteApiSpec(ctx, &rpc.DeleteApiSpecRequest{Name: name, Force: true})
+	} else if _, err := names.ParseDeployment(name); err == nil {
+		return client.DeleteApiDeployment(ctx, &rpc.DeleteApiDeploymentRequest{Name: name, Force: true})
+	}
+	return fmt.Errorf("unsupported resource name %q", name)
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/pkg/connection/grpctest"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// TestMain will set up a local RegistryServer and grpc.Server for all
+// tests in this package if APG_REGISTRY_ADDRESS env var is not set
+// for the client.
+func TestMain(m *testing.M) {
+	grpctest.TestMain(m, registry.Config{})
+}
+
+func TestMoveVersion(t *testing.T) {
+	const api = "projects/mv-test/locations/global/apis/a"
+	ctx := context.Background()
+	client, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, "mv-test")
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: "projects/mv-test/locations/global",
+		ApiId:  "a",
+		Api:    &rpc.Api{},
+	}); err != nil {
+		t.Fatalf("Error creating api %s", err)
+	}
+	if _, err := client.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent:       api,
+		ApiVersionId: "v1",
+		ApiVersion:   &rpc.ApiVersion{},
+	}); err != nil {
+		t.Fatalf("Error creating version %s", err)
+	}
+	spec, err := client.CreateApiSpec(ctx, &rpc.CreateApiSpecRequest{
+		Parent:    api + "/versions/v1",
+		ApiSpecId: "s",
+		ApiSpec:   &rpc.ApiSpec{MimeType: "text/plain", Contents: []byte("one")},
+	})
+	if err != nil {
+		t.Fatalf("Error creating spec %s", err)
+	}
+	if _, err := client.CreateApiDeployment(ctx, &rpc.CreateApiDeploymentRequest{
+		Parent:          api,
+		ApiDeploymentId: "d",
+		ApiDeployment:   &rpc.ApiDeployment{ApiSpecRevision: spec.GetName() + "@" + spec.GetRevisionId()},
+	}); err != nil {
+		t.Fatalf("Error creating deployment %s", err)
+	}
+	if _, err := client.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api:        &rpc.Api{Name: api, RecommendedVersion: api + "/versions/v1"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"recommended_version"}},
+	}); err != nil {
+		t.Fatalf("Error updating api %s", err)
+	}
+
+	if err := Move(ctx, client, adminClient, api+"/versions/v1", api+"/versions/v2"); err != nil {
+		t.Fatalf("Move returned error: %s", err)
+	}
+	if _, err := client.GetApiVersion(ctx, &rpc.GetApiVersionRequest{Name: api + "/versions/v1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetApiVersion(v1) returned %v, want NotFound", err)
+	}
+	moved, err := client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: api + "/versions/v2/specs/s"})
+	if err != nil {
+		t.Fatalf("GetApiSpec returned error: %s", err)
+	}
+	if moved.GetRevisionId() != spec.GetRevisionId() || moved.GetHash() != spec.GetHash() {
+		t.Errorf("Moved spec is %+v, want the revision and contents of %+v", moved, spec)
+	}
+
+	// References to the moved resources refer to the new names.
+	a, err := client.GetApi(ctx, &rpc.GetApiRequest{Name: api})
+	if err != nil {
+		t.Fatalf("GetApi returned error: %s", err)
+	}
+	if want := api + "/versions/v2"; a.GetRecommendedVersion() != want {
+		t.Errorf("recommended_version is %q, want %q", a.GetRecommendedVersion(), want)
+	}
+	d, err := client.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: api + "/deployments/d"})
+	if err != nil {
+		t.Fatalf("GetApiDeployment returned error: %s", err)
+	}
+	if want := moved.GetName() + "@" + moved.GetRevisionId(); d.GetApiSpecRevision() != want {
+		t.Errorf("api_spec_revision is %q, want %q", d.GetApiSpecRevision(), want)
+	}
+
+	// The original is kept if the copy fails.
+	if err := Move(ctx, client, adminClient, api+"/versions/v2", api+"/versions/v2"); err == nil {
+		t.Errorf("Move to itself succeeded, want error")
+	}
+	if _, err := client.GetApiVersion(ctx, &rpc.GetApiVersionRequest{Name: api + "/versions/v2"}); err != nil {
+		t.Errorf("GetApiVersion(v2) returned error: %s", err)
+	}
+}
@@ -17,21 +17,27 @@ package cmd
 import (
 	"github.com/apigee/registry/cmd/registry/cmd/annotate"
 	"github.com/apigee/registry/cmd/registry/cmd/apply"
+	"github.com/apigee/registry/cmd/registry/cmd/audit"
 	"github.com/apigee/registry/cmd/registry/cmd/auth"
 	"github.com/apigee/registry/cmd/registry/cmd/compute"
 	"github.com/apigee/registry/cmd/registry/cmd/config"
 	"github.com/apigee/registry/cmd/registry/cmd/count"
+	"github.com/apigee/registry/cmd/registry/cmd/cp"
 	"github.com/apigee/registry/cmd/registry/cmd/delete"
 	"github.com/apigee/registry/cmd/registry/cmd/diff"
 	"github.com/apigee/registry/cmd/registry/cmd/export"
 	"github.com/apigee/registry/cmd/registry/cmd/get"
+	"github.com/apigee/registry/cmd/registry/cmd/history"
 	"github.com/apigee/registry/cmd/registry/cmd/index"
 	"github.com/apigee/registry/cmd/registry/cmd/label"
 	"github.com/apigee/registry/cmd/registry/cmd/list"
+	"github.com/apigee/registry/cmd/registry/cmd/mv"
 	"github.com/apigee/registry/cmd/registry/cmd/resolve"
 	"github.com/apigee/registry/cmd/registry/cmd/rpc"
+	"github.com/apigee/registry/cmd/registry/cmd/sync"
 	"github.com/apigee/registry/cmd/registry/cmd/upload"
 	"github.com/apigee/registry/cmd/registry/cmd/vocabulary"
+	"github.com/apigee/registry/cmd/registry/cmd/watch"
 	pkgconf "github.com/apigee/registry/pkg/config"
 	"github.com/spf13/cobra"
 )
@@ -50,20 +56,26 @@ func Command() *cobra.Command {
 
 	cmd.AddCommand(annotate.Command())
 	cmd.AddCommand(apply.Command())
+	cmd.AddCommand(audit.Command())
 	cmd.AddCommand(auth.Command())
 	cmd.AddCommand(compute.Command())
 	cmd.AddCommand(config.Command())
 	cmd.AddCommand(count.Command())
+	cmd.AddCommand(cp.Command())
 	cmd.AddCommand(resolve.Command())
 	cmd.AddCommand(delete.Command())
 	cmd.AddCommand(diff.Command())
 	cmd.AddCommand(export.Command())
 	cmd.AddCommand(get.Command())
+	cmd.AddCommand(history.Command())
 	cmd.AddCommand(index.Command())
 	cmd.AddCommand(label.Command())
 	cmd.AddCommand(list.Command())
+	cmd.AddCommand(mv.Command())
 	cmd.AddCommand(upload.Command())
 	cmd.AddCommand(vocabulary.Command())
 	cmd.AddCommand(rpc.Command())
+	cmd.AddCommand(sync.Command())
+	cmd.AddCommand(watch.Command())
 	return cmd
 }
@@ -0,0 +1,75 @@
+// Code generated. DO NOT EDIT.
+
+package generated
+
+import (
+	"github.com/spf13/cobra"
+
+	"fmt"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	"os"
+
+	rpcpb "github.com/apigee/registry/rpc"
+)
+
+var ListReferencesInput rpcpb.ListReferencesRequest
+
+var ListReferencesFromFile string
+
+func init() {
+	AdminServiceCmd.AddCommand(ListReferencesCmd)
+
+	ListReferencesCmd.Flags().StringVar(&ListReferencesInput.Name, "name", "", "Required. The name of the referenced resource.  Format:...")
+
+	ListReferencesCmd.Flags().StringVar(&ListReferencesFromFile, "from_file", "", "Absolute path to JSON file containing request payload")
+
+}
+
+var ListReferencesCmd = &cobra.Command{
+	Use:   "list-references",
+	Short: "ListReferences returns the resources that refer to...",
+	Long:  "ListReferences returns the resources that refer to a specified resource  or to any of its children.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+
+		if ListReferencesFromFile == "" {
+
+			cmd.MarkFlagRequired("name")
+
+		}
+
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+
+		in := os.Stdin
+		if ListReferencesFromFile != "" {
+			in, err = os.Open(ListReferencesFromFile)
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+
+			err = jsonpb.Unmarshal(in, &ListReferencesInput)
+			if err != nil {
+				return err
+			}
+
+		}
+
+		if Verbose {
+			printVerboseInput("Admin", "ListReferences", &ListReferencesInput)
+		}
+		resp, err := AdminClient.ListReferences(ctx, &ListReferencesInput)
+		if err != nil {
+			return err
+		}
+
+		if Verbose {
+			fmt.Print("Output: ")
+		}
+		printMessage(resp)
+
+		return err
+	},
+}
@@ -0,0 +1,586 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/pkg/config"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Command() *cobra.Command {
+	var filter string
+	var deletions bool
+	var continuous bool
+	var interval time.Duration
+	cmd := &cobra.Command{
+		Use:   "sync SOURCE_CONFIG/PATTERN DEST_CONFIG/PARENT",
+		Short: "Copy APIs from one registry to another",
+		Long: "Copy the APIs matching PATTERN in the registry of the SOURCE_CONFIG configuration " +
+			"to the PARENT project in the registry of the DEST_CONFIG configuration. " +
+			"APIs are copied with their versions, all spec and deployment revisions, revision tags, " +
+			"and artifacts, and keep their revision IDs and timestamps. Only resources that are " +
+			"missing or differ in the destination are written. PATTERN may name a project or a location, " +
+			"which also copies project artifacts, or APIs (e.g. projects/p/locations/global/apis/-). " +
+			"PARENT may name a project, which keeps the locations of the copied resources, or a location, " +
+			"which receives the resources of a single source location. Resources are compared using " +
+			"their listings, only the contents of changed spec revisions and artifacts are read, and " +
+			"the destination is written with the Admin service's ImportProject method.",
+		Example: "registry sync central/projects/apis/locations/global/apis/- regional/projects/apis-eu",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := cmd.Context()
+			source, err := newEndpoint(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			dest, err := newEndpoint(ctx, args[1])
+			if err != nil {
+				return err
+			}
+			s := &syncer{
+				source:    source,
+				dest:      dest,
+				filter:    filter,
+				deletions: deletions,
+				out:       cmd.OutOrStdout(),
+			}
+			if s.scope, err = parseScope(source.name); err != nil {
+				return err
+			}
+			if s.target, err = parseParent(dest.name); err != nil {
+				return err
+			}
+			if s.from, s.to, err = prefixes(s.scope, s.target); err != nil {
+				return err
+			}
+			if s.from == s.to && source.config.Address == dest.config.Address {
+				return errors.New("the source and destination are the same")
+			}
+			for {
+				if err := s.run(ctx); err != nil {
+					if ctx.Err() != nil {
+						return nil // the sync was interrupted
+					}
+					return err
+				}
+				if !continuous {
+					return nil
+				}
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(interval):
+				}
+			}
+		},
+	}
+	cmd.Flags().StringVar(&filter, "filter", "", "Only copy APIs that match this filter")
+	cmd.Flags().BoolVar(&deletions, "delete", false,
+		"Delete resources in the destination that don't exist in the source")
+	cmd.Flags().BoolVar(&continuous, "continuous", false, "Repeat the sync until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "Time between repeated syncs")
+	return cmd
+}
+
+// endpoint is a registry named by a configuration and a resource name in it.
+type endpoint struct {
+	config connection.Config
+	name   string
+	client connection.RegistryClient
+	admin  connection.AdminClient
+}
+
+// newEndpoint returns the endpoint of an argument of the form CONFIG/NAME.
+func newEndpoint(ctx context.Context, arg string) (*endpoint, error) {
+	configName, name, _ := strings.Cut(arg, "/")
+	if configName == "" {
+		return nil, fmt.Errorf("%q must begin with the name of a configuration", arg)
+	}
+	c, err := readConfig(configName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration %q: %s", configName, err)
+	}
+	e := &endpoint{config: c, name: c.FQName(name)}
+	if e.client, err = connection.NewRegistryClientWithSettings(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to get client: %s", err)
+	}
+	if e.admin, err = connection.NewAdminClientWithSettings(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to get client: %s", err)
+	}
+	return e, nil
+}
+
+// readConfig reads a named configuration. Unlike connection.ReadConfig, it
+// ignores flags and environment variables, which would apply to both registries.
+func readConfig(name string) (connection.Config, error) {
+	c, err := config.Read(name)
+	if err != nil {
+		return connection.Config{}, err
+	}
+	if err := c.Resolve(); err != nil {
+		return connection.Config{}, err
+	}
+	if err := c.Validate(); err != nil {
+		return connection.Config{}, err
+	}
+	return connection.Config{
+		Address:  c.Registry.Address,
+		Insecure: c.Registry.Insecure,
+		Location: c.Registry.Location,
+		Project:  c.Registry.Project,
+		Token:    c.Registry.Token,
+	}, nil
+}
+
+// scope describes the source resources that are copied.
+type scope struct {
+	project   names.Project
+	apis      names.Api // the location and API IDs may be "-" to select all locations and APIs
+	artifacts bool      // true if project artifacts are copied
+}
+
+// parseScope returns the scope selected by a source pattern.
+func parseScope(pattern string) (scope, error) {
+	if l, err := parseParent(pattern); err == nil {
+		return scope{project: l.Project(), apis: l.Api("-"), artifacts: true}, nil
+	}
+	if a, err := names.ParseApiCollection(pattern); err == nil {
+		a.ApiID = "-"
+		return scope{project: a.Project(), apis: a}, nil
+	}
+	if a, err := names.ParseApi(pattern); err == nil {
+		return scope{project: a.Project(), apis: a}, nil
+	}
+	return scope{}, fmt.Errorf("invalid pattern %q: must name a project, a location, or APIs", pattern)
+}
+
+// parseParent returns the location named by a project or location name.
+// The location ID of a project is "-", which selects all of its locations.
+func parseParent(name string) (names.ProjectLocation, error) {
+	if p, err := names.ParseProject(name); err == nil && p.ProjectID != "-" {
+		return p.Location("-"), nil
+	}
+	if l, err := names.ParseLocation(name); err == nil && l.ProjectID != "-" {
+		return l, nil
+	}
+	return names.ProjectLocation{}, fmt.Errorf("invalid parent %q: must name a project or a location", name)
+}
+
+// prefixes returns the name prefixes that are replaced to move source resources
+// to the target. A target project keeps the locations of the source resources,
+// and a target location receives the resources of a single source location.
+func prefixes(source scope, target names.ProjectLocation) (string, string, error) {
+	if target.LocationID == "-" {
+		return source.project.String(), target.Project().String(), nil
+	}
+	if source.apis.LocationID == "-" {
+		return "", "", fmt.Errorf("the destination %s is a location, but the source includes all locations", target)
+	}
+	return source.apis.Location().String(), target.String(), nil
+}
+
+// syncer copies resources from a source project to a target project.
+type syncer struct {
+	source    *endpoint
+	dest      *endpoint
+	scope     scope
+	target    names.ProjectLocation
+	from, to  string // source names begin with from, which is replaced with to in the target
+	filter    string
+	deletions bool
+	out       io.Writer
+}
+
+// stamp holds the fields of resources that are compared to find the ones that changed.
+// Because copies keep their timestamps, resources with equal stamps are the same.
+type stamp struct {
+	time time.Time
+	hash string
+}
+
+func stampOf(t *timestamppb.Timestamp, hash string) stamp {
+	// Times are compared at the precision used in storage.
+	return stamp{time: t.AsTime().Round(time.Microsecond), hash: hash}
+}
+
+func (s stamp) equal(other stamp) bool {
+	return s.time.Equal(other.time) && s.hash == other.hash
+}
+
+// resources holds the resources of a registry that are listed for a sync.
+// Contents are not included.
+type resources struct {
+	apis           []*rpc.Api
+	versions       []*rpc.ApiVersion
+	specs          []*rpc.ApiSpec       // all revisions
+	deployments    []*rpc.ApiDeployment // all revisions
+	artifacts      []*rpc.Artifact
+	specTags       []*rpc.RevisionTag
+	deploymentTags []*rpc.RevisionTag
+}
+
+// list lists the resources of the selected APIs and, if artifacts is true,
+// the project artifacts of their location.
+func list(ctx context.Context, client connection.RegistryClient, apis names.Api, artifacts bool) (*resources, error) {
+	r := &resources{}
+	if err := core.ListAPIs(ctx, client, apis, "", func(v *rpc.Api) error {
+		r.apis = append(r.apis, v)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := core.ListVersions(ctx, client, apis.Version("-"), "", func(v *rpc.ApiVersion) error {
+		r.versions = append(r.versions, v)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := core.ListSpecRevisionsWithTags(ctx, client, apis.Version("-").Spec("-"), func(v *rpc.ApiSpec) error {
+		r.specs = append(r.specs, v)
+		return nil
+	}, func(t *rpc.RevisionTag) error {
+		r.specTags = append(r.specTags, t)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := core.ListDeploymentRevisionsWithTags(ctx, client, apis.Deployment("-"), func(v *rpc.ApiDeployment) error {
+		r.deployments = append(r.deployments, v)
+		return nil
+	}, func(t *rpc.RevisionTag) error {
+		r.deploymentTags = append(r.deploymentTags, t)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	collections := []names.Artifact{
+		apis.Artifact("-"),
+		apis.Version("-").Artifact("-"),
+		apis.Version("-").Spec("-").Artifact("-"),
+		apis.Deployment("-").Artifact("-"),
+	}
+	if artifacts {
+		collections = append(collections, apis.Location().Artifact("-"))
+	}
+	for _, c := range collections {
+		if err := core.ListArtifacts(ctx, client, c, "", false, func(v *rpc.Artifact) error {
+			r.artifacts = append(r.artifacts, v)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	// Revisions are sorted so that the revisions of each resource are copied in order.
+	sort.SliceStable(r.specs, func(i, j int) bool {
+		return r.specs[i].GetRevisionCreateTime().AsTime().Before(r.specs[j].GetRevisionCreateTime().AsTime())
+	})
+	sort.SliceStable(r.deployments, func(i, j int) bool {
+		return r.deployments[i].GetRevisionCreateTime().AsTime().Before(r.deployments[j].GetRevisionCreateTime().AsTime())
+	})
+	return r, nil
+}
+
+// stamps returns the stamps of the listed resources.
+// Specs and deployments are included without stamps along with their revisions.
+func (r *resources) stamps() map[string]stamp {
+	stamps := map[string]stamp{}
+	for _, v := range r.apis {
+		stamps[v.GetName()] = stampOf(v.GetUpdateTime(), "")
+	}
+	for _, v := range r.versions {
+		stamps[v.GetName()] = stampOf(v.GetUpdateTime(), "")
+	}
+	for _, v := range r.specs {
+		stamps[revisionParent(v.GetName())] = stamp{}
+		stamps[v.GetName()] = stampOf(v.GetRevisionUpdateTime(), v.GetHash())
+	}
+	for _, v := range r.deployments {
+		stamps[revisionParent(v.GetName())] = stamp{}
+		stamps[v.GetName()] = stampOf(v.GetRevisionUpdateTime(), "")
+	}
+	for _, v := range r.artifacts {
+		stamps[v.GetName()] = stampOf(v.GetUpdateTime(), v.GetHash())
+	}
+	return stamps
+}
+
+// tags returns the revisions of the listed tags, indexed by the tag names of the resources.
+func (r *resources) tags() map[string]string {
+	tags := map[string]string{}
+	for _, t := range append(r.specTags, r.deploymentTags...) {
+		tags[revisionParent(t.GetRevision())+"@"+t.GetTag()] = t.GetRevision()
+	}
+	return tags
+}
+
+// run performs one sync and prints the resources that it changes.
+// Resources are compared using their listings, and only the contents of
+// the spec revisions and artifacts that are copied are read.
+func (s *syncer) run(ctx context.Context) error {
+	listing, err := list(ctx, s.source.client, s.scope.apis, s.scope.artifacts)
+	if err != nil {
+		return err
+	}
+	selected := map[string]bool{}
+	if err := core.ListAPIs(ctx, s.source.client, s.scope.apis, s.filter, func(api *rpc.Api) error {
+		selected[api.GetName()] = true
+		return nil
+	}); err != nil {
+		return err
+	}
+	project, err := s.dest.admin.GetProject(ctx, &rpc.GetProjectRequest{Name: s.target.Project().String()})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	existing := map[string]stamp{}
+	tagged := map[string]string{}
+	if project != nil {
+		current, err := list(ctx, s.dest.client, s.targetApis(), s.scope.artifacts)
+		if err != nil {
+			return err
+		}
+		existing, tagged = current.stamps(), current.tags()
+	} else if project, err = s.source.admin.GetProject(ctx, &rpc.GetProjectRequest{Name: s.scope.project.String()}); err != nil {
+		return err
+	}
+
+	// The archive that is imported contains the selected resources that are missing or differ.
+	// Its resources are named as they are in the target.
+	now := timestamppb.Now()
+	copied := &rpc.ProjectArchive{
+		FormatVersion: core.ArchiveFormatVersion,
+		CreateTime:    now,
+		Project:       proto.Clone(project).(*rpc.Project),
+	}
+	copied.Project.Name = s.target.Project().String()
+	var changes []string
+	present := map[string]bool{}
+	include := func(name string, st stamp) bool {
+		target := s.rename(name)
+		present[target] = true
+		if !s.selected(name, selected) {
+			return false
+		}
+		old, ok := existing[target]
+		switch {
+		case !ok:
+			changes = append(changes, "created "+target)
+		case !old.equal(st):
+			changes = append(changes, "updated "+target)
+		default:
+			return false
+		}
+		return true
+	}
+	for _, v := range listing.apis {
+		if include(v.GetName(), stampOf(v.GetUpdateTime(), "")) {
+			v.Name = s.rename(v.GetName())
+			v.RecommendedVersion, v.RecommendedDeployment = s.rename(v.GetRecommendedVersion()), s.rename(v.GetRecommendedDeployment())
+			copied.Apis = append(copied.Apis, v)
+		}
+	}
+	for _, v := range listing.versions {
+		if include(v.GetName(), stampOf(v.GetUpdateTime(), "")) {
+			v.Name = s.rename(v.GetName())
+			copied.Versions = append(copied.Versions, v)
+		}
+	}
+	for _, v := range listing.specs {
+		present[s.rename(revisionParent(v.GetName()))] = true
+		if include(v.GetName(), stampOf(v.GetRevisionUpdateTime(), v.GetHash())) {
+			if v.Contents, err = core.ArchivedSpecContents(ctx, s.source.client, v); err != nil {
+				return err
+			}
+			v.Name = s.rename(v.GetName())
+			copied.SpecRevisions = append(copied.SpecRevisions, v)
+		}
+	}
+	for _, v := range listing.deployments {
+		present[s.rename(revisionParent(v.GetName()))] = true
+		if include(v.GetName(), stampOf(v.GetRevisionUpdateTime(), "")) {
+			v.Name, v.ApiSpecRevision = s.rename(v.GetName()), s.rename(v.GetApiSpecRevision())
+			copied.DeploymentRevisions = append(copied.DeploymentRevisions, v)
+		}
+	}
+	for _, v := range listing.artifacts {
+		if include(v.GetName(), stampOf(v.GetUpdateTime(), v.GetHash())) {
+			if v.Contents, err = core.ArchivedArtifactContents(ctx, s.source.client, v); err != nil {
+				return err
+			}
+			v.Name = s.rename(v.GetName())
+			copied.Artifacts = append(copied.Artifacts, v)
+		}
+	}
+	// Tags are copied if the target doesn't have them or has them on other revisions.
+	copyTags := func(tags []*rpc.RevisionTag) []*rpc.ProjectArchive_Tag {
+		var copies []*rpc.ProjectArchive_Tag
+		for _, t := range tags {
+			revision := s.rename(t.GetRevision())
+			name := revisionParent(revision) + "@" + t.GetTag()
+			if !s.selected(t.GetRevision(), selected) || tagged[name] == revision {
+				continue
+			}
+			changes = append(changes, "tagged "+name)
+			copies = append(copies, &rpc.ProjectArchive_Tag{
+				Revision:   revision,
+				Tag:        t.GetTag(),
+				CreateTime: now,
+				UpdateTime: now,
+			})
+		}
+		return copies
+	}
+	copied.SpecRevisionTags = copyTags(listing.specTags)
+	copied.DeploymentRevisionTags = copyTags(listing.deploymentTags)
+
+	if len(changes) > 0 {
+		if _, err := core.ImportProjectArchive(ctx, s.dest.admin, copied, s.target.ProjectID,
+			rpc.ImportProjectRequest_OVERWRITE); err != nil {
+			return err
+		}
+	}
+	for _, c := range changes {
+		fmt.Fprintln(s.out, c)
+	}
+	if !s.deletions {
+		return nil
+	}
+	return s.delete(ctx, existing, present, selected)
+}
+
+// targetApis returns the APIs of the target that are within the scope of the sync.
+func (s *syncer) targetApis() names.Api {
+	if s.target.LocationID == "-" {
+		return s.target.Project().Location(s.scope.apis.LocationID).Api("-")
+	}
+	return s.target.Api("-")
+}
+
+// delete deletes the resources in the target project that are within the scope
+// of the sync and aren't present in the source.
+func (s *syncer) delete(ctx context.Context, existing map[string]stamp, present, selected map[string]bool) error {
+	var deleted []string
+	for name := range existing {
+		if present[name] || !s.deletable(name, selected) {
+			continue
+		}
+		deleted = append(deleted, name)
+	}
+	// Parents sort before their children, which are deleted with them.
+	sort.Strings(deleted)
+	var parents []string
+	for _, name := range deleted {
+		if hasParent(name, parents) {
+			continue
+		}
+		if err := s.deleteResource(ctx, name); err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		fmt.Fprintln(s.out, "deleted "+name)
+		parents = append(parents, name)
+	}
+	return nil
+}
+
+// hasParent returns true if a resource belongs to one of the named parents.
+func hasParent(name string, parents []string) bool {
+	for _, p := range parents {
+		if strings.HasPrefix(name, p+"/") || strings.HasPrefix(name, p+"@") {
+			return true
+		}
+	}
+	return false
+}
+
+// deletable returns true if a target resource is within the scope of deletions.
+// Target APIs that don't exist in the source are deleted if they match the pattern.
+func (s *syncer) deletable(name string, selected map[string]bool) bool {
+	if api, err := names.ParseApi(name); err == nil {
+		return s.scope.apis.ApiID == "-" || s.scope.apis.ApiID == api.ApiID
+	}
+	return s.selected(rename(name, s.to, s.from), selected)
+}
+
+func (s *syncer) deleteResource(ctx context.Context, name string) error {
+	client := s.dest.client
+	if strings.Contains(name, "@") {
+		if _, err := names.ParseSpecRevision(name); err == nil {
+			_, err := client.DeleteApiSpecRevision(ctx, &rpc.DeleteApiSpecRevisionRequest{Name: name})
+			return err
+		}
+		_, err := client.DeleteApiDeploymentRevision(ctx, &rpc.DeleteApiDeploymentRevisionRequest{Name: name})
+		return err
+	}
+	if _, err := names.ParseArtifact(name); err == nil {
+		return client.DeleteArtifact(ctx, &rpc.DeleteArtifactRequest{Name: name})
+	} else if _, err := names.ParseApi(name); err == nil {
+		return client.DeleteApi(ctx, &rpc.DeleteApiRequest{Name: name, Force: true})
+	} else if _, err := names.ParseVersion(name); err == nil {
+		return client.DeleteApiVersion(ctx, &rpc.DeleteApiVersionRequest{Name: name, Force: true})
+	} else if _, err := names.ParseSpec(name); err == nil {
+		return client.DeleteApiSpec(ctx, &rpc.DeleteApiSpecRequest{Name: name, Force: true})
+	} else if _, err := names.ParseDeployment(name); err == nil {
+		return client.DeleteApiDeployment(ctx, &rpc.DeleteApiDeploymentRequest{Name: name, Force: true})
+	}
+	return fmt.Errorf("unsupported resource name %q", name)
+}
+
+// selected returns true if a source resource is within the scope of the sync.
+func (s *syncer) selected(name string, selected map[string]bool) bool {
+	if _, err := names.ParseArtifact(name); err == nil && strings.Count(name, "/") == 5 {
+		return s.scope.artifacts // a project artifact
+	}
+	api, _, _ := strings.Cut(name, "@")
+	if parts := strings.SplitN(api, "/", 7); len(parts) >= 6 {
+		api = strings.Join(parts[:6], "/")
+	}
+	return selected[api]
+}
+
+// rename returns the name of the copy of a source resource in the target.
+func (s *syncer) rename(name string) string {
+	return rename(name, s.from, s.to)
+}
+
+// rename replaces the from prefix of a resource name with to.
+func rename(name string, from, to string) string {
+	if strings.HasPrefix(name, from+"/") {
+		return to + strings.TrimPrefix(name, from)
+	}
+	return name
+}
+
+// revisionParent returns the name of the resource of a revision.
+func revisionParent(name string) string {
+	parent, _, _ := strings.Cut(name, "@")
+	return parent
+}
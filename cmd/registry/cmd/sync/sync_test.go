@@ -0,0 +1,310 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/pkg/config"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/pkg/connection/grpctest"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// TestMain will set up a local RegistryServer and grpc.Server for all
+// tests in this package if APG_REGISTRY_ADDRESS env var is not set
+// for the client.
+func TestMain(m *testing.M) {
+	grpctest.TestMain(m, registry.Config{})
+}
+
+const (
+	sourceParent = "projects/sync-source/locations/global"
+	destParent   = "projects/sync-dest/locations/global"
+)
+
+// setup writes configurations named "source" and "dest" that both use the
+// test registry and creates an empty source project.
+func setup(t *testing.T) (connection.RegistryClient, connection.AdminClient) {
+	t.Helper()
+	for _, name := range []string{"source", "dest"} {
+		c := config.Configuration{Registry: config.Registry{
+			Address:  os.Getenv("APG_REGISTRY_ADDRESS"),
+			Insecure: os.Getenv("APG_REGISTRY_INSECURE") != "",
+			Token:    os.Getenv("APG_REGISTRY_TOKEN"),
+		}}
+		if err := c.Write(name); err != nil {
+			t.Fatalf("Error writing configuration: %s", err)
+		}
+	}
+	registryClient, adminClient := cmdtest.Clients(t)
+	cmdtest.DeleteProjects(t, adminClient, "sync-source", "sync-dest")
+	cmdtest.CreateProjects(t, adminClient, "sync-source")
+	return registryClient, adminClient
+}
+
+// seed creates resources in the source project and returns the revisions of its spec.
+func seed(t *testing.T, client connection.RegistryClient) []*rpc.ApiSpec {
+	t.Helper()
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+			Parent: sourceParent,
+			ApiId:  id,
+			Api:    &rpc.Api{Labels: map[string]string{"synced": "true"}},
+		}); err != nil {
+			t.Fatalf("Error creating api %s", err)
+		}
+	}
+	revisions := cmdtest.SeedSpec(t, client, sourceParent+"/apis/a")
+	for _, parent := range []string{sourceParent, sourceParent + "/apis/a"} {
+		if _, err := client.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+			Parent:     parent,
+			ArtifactId: "x",
+			Artifact:   &rpc.Artifact{MimeType: "text/plain", Contents: []byte("x")},
+		}); err != nil {
+			t.Fatalf("Error creating artifact %s", err)
+		}
+	}
+	return revisions
+}
+
+// sync runs the sync command and returns the lines that it prints.
+func sync(t *testing.T, args ...string) []string {
+	t.Helper()
+	out := new(bytes.Buffer)
+	cmd := Command()
+	cmd.SetArgs(args)
+	cmd.SetOut(out)
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("Execute(%v) returned error: %s", args, err)
+	}
+	if out.Len() == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+}
+
+func TestSync(t *testing.T) {
+	client, _ := setup(t)
+	ctx := context.Background()
+	revisions := seed(t, client)
+	source, dest := "source/"+sourceParent+"/apis/-", "dest/projects/sync-dest"
+
+	got := sync(t, source, dest)
+	for _, want := range []string{
+		"created " + destParent + "/apis/a",
+		"created " + destParent + "/apis/a/versions/v1",
+		"created " + destParent + "/apis/a/versions/v1/specs/s@" + revisions[0].GetRevisionId(),
+		"created " + destParent + "/apis/a/artifacts/x",
+		"created " + destParent + "/apis/b",
+		"tagged " + destParent + "/apis/a/versions/v1/specs/s@prod",
+	} {
+		if !cmdtest.Contains(got, want) {
+			t.Errorf("sync printed %v, want %q", got, want)
+		}
+	}
+
+	// Revisions keep their IDs, order, contents, and tags.
+	copies := cmdtest.ListSpecRevisions(t, client, destParent+"/apis/a/versions/v1/specs/s")
+	if len(copies) != len(revisions) {
+		t.Fatalf("Copied spec has %d revisions, want %d", len(copies), len(revisions))
+	}
+	for i, r := range revisions {
+		if copies[i].GetRevisionId() != r.GetRevisionId() || copies[i].GetHash() != r.GetHash() {
+			t.Errorf("Copied revision %d is %s with hash %s, want %s with hash %s", i,
+				copies[i].GetRevisionId(), copies[i].GetHash(), r.GetRevisionId(), r.GetHash())
+		}
+	}
+	tagged, err := client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: destParent + "/apis/a/versions/v1/specs/s@prod"})
+	if err != nil {
+		t.Fatalf("GetApiSpec returned error: %s", err)
+	}
+	if tagged.GetRevisionId() != revisions[1].GetRevisionId() {
+		t.Errorf("Copied tag is on revision %s, want %s", tagged.GetRevisionId(), revisions[1].GetRevisionId())
+	}
+	deployment, err := client.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: destParent + "/apis/a/deployments/d"})
+	if err != nil {
+		t.Fatalf("GetApiDeployment returned error: %s", err)
+	}
+	if want := destParent + "/apis/a/versions/v1/specs/s@" + revisions[0].GetRevisionId(); deployment.GetApiSpecRevision() != want {
+		t.Errorf("Copied deployment references %s, want %s", deployment.GetApiSpecRevision(), want)
+	}
+	if want := "created " + deployment.GetName() + "@" + deployment.GetRevisionId(); !cmdtest.Contains(got, want) {
+		t.Errorf("sync printed %v, want %q", got, want)
+	}
+	// Project artifacts are only copied when the pattern is a project.
+	if _, err := client.GetArtifact(ctx, &rpc.GetArtifactRequest{Name: destParent + "/artifacts/x"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetArtifact returned %v, want NotFound", err)
+	}
+
+	if got := sync(t, source, dest); len(got) > 0 {
+		t.Errorf("Repeated sync printed %v, want no changes", got)
+	}
+
+	if _, err := client.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api:        &rpc.Api{Name: sourceParent + "/apis/a", DisplayName: "A"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name"}},
+	}); err != nil {
+		t.Fatalf("Error updating api %s", err)
+	}
+	if err := client.DeleteApi(ctx, &rpc.DeleteApiRequest{Name: sourceParent + "/apis/b", Force: true}); err != nil {
+		t.Fatalf("Error deleting api %s", err)
+	}
+	if err := client.DeleteArtifact(ctx, &rpc.DeleteArtifactRequest{Name: sourceParent + "/apis/a/artifacts/x"}); err != nil {
+		t.Fatalf("Error deleting artifact %s", err)
+	}
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{Parent: destParent, ApiId: "c", Api: &rpc.Api{}}); err != nil {
+		t.Fatalf("Error creating api %s", err)
+	}
+
+	// Without --delete, only the update is copied.
+	want := []string{"updated " + destParent + "/apis/a"}
+	if diff := cmp.Diff(want, sync(t, source, dest)); diff != "" {
+		t.Errorf("sync printed unexpected diff (-want +got):\n%s", diff)
+	}
+	want = []string{
+		"deleted " + destParent + "/apis/a/artifacts/x",
+		"deleted " + destParent + "/apis/b",
+		"deleted " + destParent + "/apis/c",
+	}
+	if diff := cmp.Diff(want, sync(t, source, dest, "--delete")); diff != "" {
+		t.Errorf("sync --delete printed unexpected diff (-want +got):\n%s", diff)
+	}
+
+	// A project pattern also copies project artifacts.
+	want = []string{"created " + destParent + "/artifacts/x"}
+	if diff := cmp.Diff(want, sync(t, "source/projects/sync-source", dest)); diff != "" {
+		t.Errorf("sync printed unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    scope
+	}{
+		{"projects/p", scope{project: names.Project{ProjectID: "p"}, apis: names.Project{ProjectID: "p"}.Location("-").Api("-"), artifacts: true}},
+		{"projects/p/locations/global", scope{project: names.Project{ProjectID: "p"}, apis: names.Project{ProjectID: "p"}.Api("-"), artifacts: true}},
+		{"projects/p/locations/eu", scope{project: names.Project{ProjectID: "p"}, apis: names.Project{ProjectID: "p"}.Location("eu").Api("-"), artifacts: true}},
+		{"projects/p/locations/global/apis", scope{project: names.Project{ProjectID: "p"}, apis: names.Project{ProjectID: "p"}.Api("-")}},
+		{"projects/p/locations/global/apis/a", scope{project: names.Project{ProjectID: "p"}, apis: names.Project{ProjectID: "p"}.Api("a")}},
+		{"projects/p/locations/eu/apis/a", scope{project: names.Project{ProjectID: "p"}, apis: names.Project{ProjectID: "p"}.Location("eu").Api("a")}},
+	}
+	for _, test := range tests {
+		got, err := parseScope(test.pattern)
+		if err != nil {
+			t.Errorf("parseScope(%q) returned error: %s", test.pattern, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseScope(%q) returned %+v, want %+v", test.pattern, got, test.want)
+		}
+	}
+	for _, pattern := range []string{"projects/-", "projects/p/locations/global/apis/a/versions/v", ""} {
+		if _, err := parseScope(pattern); err == nil {
+			t.Errorf("parseScope(%q) succeeded, want error", pattern)
+		}
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	p := names.Project{ProjectID: "p"}
+	q := names.Project{ProjectID: "q"}
+	tests := []struct {
+		source   string
+		target   names.ProjectLocation
+		from, to string
+	}{
+		{"projects/p", q.Location("-"), "projects/p", "projects/q"},
+		{"projects/p/locations/eu/apis/a", q.Location("-"), "projects/p", "projects/q"},
+		{"projects/p/locations/eu", q.Location("us"), "projects/p/locations/eu", "projects/q/locations/us"},
+		{"projects/p/locations/eu/apis/a", p.Location("us"), "projects/p/locations/eu", "projects/p/locations/us"},
+	}
+	for _, test := range tests {
+		source, err := parseScope(test.source)
+		if err != nil {
+			t.Fatalf("parseScope(%q) returned error: %s", test.source, err)
+		}
+		from, to, err := prefixes(source, test.target)
+		if err != nil {
+			t.Errorf("prefixes(%q, %s) returned error: %s", test.source, test.target, err)
+			continue
+		}
+		if from != test.from || to != test.to {
+			t.Errorf("prefixes(%q, %s) returned %q, %q, want %q, %q", test.source, test.target, from, to, test.from, test.to)
+		}
+	}
+	source, err := parseScope("projects/p")
+	if err != nil {
+		t.Fatalf("parseScope returned error: %s", err)
+	}
+	if _, _, err := prefixes(source, q.Location("us")); err == nil {
+		t.Errorf("prefixes(%+v, %s) succeeded, want error", source, q.Location("us"))
+	}
+}
+
+func TestSyncLocations(t *testing.T) {
+	client, _ := setup(t)
+	ctx := context.Background()
+	for _, parent := range []string{sourceParent, "projects/sync-source/locations/eu"} {
+		if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{Parent: parent, ApiId: "a", Api: &rpc.Api{}}); err != nil {
+			t.Fatalf("Error creating api %s", err)
+		}
+	}
+	if _, err := client.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent:       "projects/sync-source/locations/eu/apis/a",
+		ApiVersionId: "v1",
+		ApiVersion:   &rpc.ApiVersion{},
+	}); err != nil {
+		t.Fatalf("Error creating version %s", err)
+	}
+
+	// A project pattern copies every location.
+	want := []string{
+		"created projects/sync-dest/locations/eu/apis/a",
+		"created projects/sync-dest/locations/global/apis/a",
+		"created projects/sync-dest/locations/eu/apis/a/versions/v1",
+	}
+	if diff := cmp.Diff(want, sync(t, "source/projects/sync-source", "dest/projects/sync-dest")); diff != "" {
+		t.Errorf("sync printed unexpected diff (-want +got):\n%s", diff)
+	}
+	if got := sync(t, "source/projects/sync-source", "dest/projects/sync-dest", "--delete"); len(got) > 0 {
+		t.Errorf("Repeated sync printed %v, want no changes", got)
+	}
+
+	// A location can be copied to another location.
+	want = []string{
+		"created projects/sync-dest/locations/us/apis/a",
+		"created projects/sync-dest/locations/us/apis/a/versions/v1",
+	}
+	if diff := cmp.Diff(want, sync(t, "source/projects/sync-source/locations/eu", "dest/projects/sync-dest/locations/us", "--delete")); diff != "" {
+		t.Errorf("sync printed unexpected diff (-want +got):\n%s", diff)
+	}
+	if _, err := client.GetApi(ctx, &rpc.GetApiRequest{Name: "projects/sync-dest/locations/eu/apis/a"}); err != nil {
+		t.Errorf("Sync to another location deleted an API in the original location: %s", err)
+	}
+}
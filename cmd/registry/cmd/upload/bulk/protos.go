@@ -267,7 +267,7 @@ func (task *uploadProtoTask) createOrUpdateSpec(ctx context.Context) error {
 		request.ApiSpec.SourceUri = fmt.Sprintf("%s/%s", task.baseURI, task.apiPath())
 	}
 
-	response, err := task.client.UpdateApiSpec(ctx, request)
+	response, err := core.UpdateSpec(ctx, task.client, request)
 	if err != nil {
 		log.FromContext(ctx).WithError(err).Errorf("Error %s [contents-length: %d]", task.specName(), len(task.contents))
 	} else {
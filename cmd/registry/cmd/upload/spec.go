@@ -103,7 +103,7 @@ func uploadSpecDirectory(ctx context.Context, dirname string, client *gapic.Regi
 			Contents: buf.Bytes(),
 		},
 	}
-	response, err := client.CreateApiSpec(ctx, request)
+	response, err := core.CreateSpec(ctx, client, request)
 	if err == nil {
 		log.Debugf(ctx, "Created %s", response.Name)
 	} else if status.Code(err) == codes.AlreadyExists {
@@ -151,7 +151,7 @@ func uploadSpecFile(ctx context.Context, filename string, client *gapic.Registry
 				log.FromContext(ctx).WithError(err).Debug("Failed to compress spec contents")
 			}
 
-			response, err := client.CreateApiSpec(ctx, request)
+			response, err := core.CreateSpec(ctx, client, request)
 			if err != nil {
 				log.FromContext(ctx).WithError(err).Debug("Failed to create spec")
 			}
@@ -0,0 +1,81 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/test/seeder"
+)
+
+func TestSpecUploadWithStreaming(t *testing.T) {
+	const (
+		project = "upload-spec-streaming"
+		version = "projects/" + project + "/locations/global/apis/a/versions/v1"
+	)
+	ctx := context.Background()
+	client, adminClient := cmdtest.Clients(t)
+	cmdtest.DeleteProjects(t, adminClient, project)
+	if err := seeder.SeedVersions(ctx, seeder.Client{
+		RegistryClient: client,
+		AdminClient:    adminClient,
+	}, &rpc.ApiVersion{Name: version}); err != nil {
+		t.Fatalf("Setup: Failed to seed registry: %s", err)
+	}
+
+	// Stream everything in small chunks.
+	threshold, chunkSize := core.StreamingThreshold, core.UploadChunkSize
+	core.StreamingThreshold, core.UploadChunkSize = 100, 64
+	t.Cleanup(func() { core.StreamingThreshold, core.UploadChunkSize = threshold, chunkSize })
+
+	contents := "openapi: 3.0.0\ninfo:\n  title: Sample\n  version: 1.0.0\n" +
+		"  description: " + strings.Repeat("x", 500) + "\npaths: {}\n"
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Setup: Failed to write spec: %s", err)
+	}
+
+	cmd := Command()
+	args := []string{"spec", path, "--version", version, "--style", "openapi"}
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() with args %v returned error: %s", args, err)
+	}
+
+	spec, err := client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: version + "/specs/openapi.yaml"})
+	if err != nil {
+		t.Fatalf("GetApiSpec() returned error: %s", err)
+	}
+	if got, want := spec.GetSizeBytes(), int32(len(contents)); got != want {
+		t.Errorf("GetApiSpec() returned size %d, want %d", got, want)
+	}
+	body, err := core.GetSpecContents(ctx, client, spec)
+	if err != nil {
+		t.Fatalf("GetSpecContents() returned error: %s", err)
+	}
+	if string(body.GetData()) != contents {
+		t.Errorf("GetSpecContents() returned %q, want %q", body.GetData(), contents)
+	}
+	if got, want := body.GetContentType(), "application/x.openapi;version=3"; got != want {
+		t.Errorf("GetSpecContents() returned content type %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/spf13/cobra"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Event sources.
+const (
+	sourceAuto   = "auto"
+	sourcePubSub = "pubsub"
+	sourceAudit  = "audit"
+	sourcePoll   = "poll"
+)
+
+func Command() *cobra.Command {
+	var filter string
+	var source string
+	var subscription string
+	var interval time.Duration
+	var format string
+	cmd := &cobra.Command{
+		Use:   "watch PATTERN",
+		Short: "Print changes to resources in the API Registry as they happen",
+		Long: "Print an event for each resource matching PATTERN that is created, updated, or deleted. " +
+			"Events are read from a Pub/Sub subscription to the registry's notifications (--subscription), " +
+			"from the registry's audit log (--source audit), or by polling and comparing update times. " +
+			"By default, the subscription is used if one is specified; otherwise, resources are polled.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := cmd.Context()
+			c, err := connection.ActiveConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get config: %s", err)
+			}
+			if format != "text" && format != "json" {
+				return fmt.Errorf("unsupported output format %q", format)
+			}
+			if source == sourceAuto {
+				source = sourcePoll
+				if subscription != "" {
+					source = sourcePubSub
+				}
+			}
+			client, err := connection.NewRegistryClientWithSettings(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to get client: %s", err)
+			}
+			adminClient, err := connection.NewAdminClientWithSettings(ctx, c)
+			if err != nil {
+				return fmt.Errorf("failed to get client: %s", err)
+			}
+			w := &watcher{
+				client:      client,
+				adminClient: adminClient,
+				pattern:     namePattern(c.FQName(args[0])),
+				filter:      filter,
+				out:         cmd.OutOrStdout(),
+				json:        format == "json",
+			}
+			switch source {
+			case sourcePubSub:
+				if subscription == "" {
+					return errors.New("--subscription is required to watch Pub/Sub notifications")
+				}
+				err = w.receive(ctx, subscription)
+			case sourceAudit:
+				err = w.repeat(ctx, interval, w.auditEvents)
+			case sourcePoll:
+				err = w.repeat(ctx, interval, w.poll)
+			default:
+				return fmt.Errorf("unsupported event source %q", source)
+			}
+			if ctx.Err() != nil {
+				return nil // the watch was interrupted
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&filter, "filter", "", "Only print events of resources that match this filter")
+	cmd.Flags().StringVar(&source, "source", sourceAuto, "Source of events: one of auto, pubsub, audit, or poll")
+	cmd.Flags().StringVar(&subscription, "subscription", "",
+		"Pub/Sub subscription to the registry's notifications (projects/PROJECT/subscriptions/SUBSCRIPTION)")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Time between reads of the audit log or resources")
+	cmd.Flags().StringVarP(&format, "output", "o", "text", "Output format: one of text or json")
+	return cmd
+}
+
+// namePattern returns the resource name pattern that matches the resources of a name or collection.
+// Collection names like "projects/p/locations/global/apis" select all of their resources.
+func namePattern(name string) string {
+	if strings.Count(name, "/")%2 == 0 {
+		return name + "/-"
+	}
+	return name
+}
+
+// watcher finds changes to the resources matching a pattern and prints them.
+type watcher struct {
+	client      connection.RegistryClient
+	adminClient connection.AdminClient
+	pattern     string
+	filter      string
+	out         io.Writer
+	json        bool
+
+	mu sync.Mutex // serializes printing of events
+
+	// The resources found by the previous poll, or nil before the first poll.
+	known map[string]resourceState
+
+	// The time of the latest audit event and the IDs of the events seen at that time.
+	since   time.Time
+	seen    map[string]bool
+	started bool
+}
+
+// resourceState holds the fields of resources that are compared to detect changes.
+type resourceState struct {
+	updateTime *timestamppb.Timestamp
+	revision   string
+}
+
+// repeat calls next at the specified interval and prints the events that it returns.
+func (w *watcher) repeat(ctx context.Context, interval time.Duration, next func(context.Context) ([]*rpc.Notification, error)) error {
+	for {
+		events, err := next(ctx)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := w.print(e); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (w *watcher) print(event *rpc.Notification) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.json {
+		b, err := protojson.Marshal(event)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w.out, string(b))
+		return err
+	}
+	_, err := fmt.Fprintf(w.out, "%s %s %s\n",
+		event.GetChangeTime().AsTime().Format(time.RFC3339), event.GetChange(), event.GetResource())
+	return err
+}
+
+// poll lists the matching resources and returns events for the differences from the previous poll.
+// The first poll returns no events. Resources that stop matching the filter are reported as deleted.
+func (w *watcher) poll(ctx context.Context) ([]*rpc.Notification, error) {
+	current, err := w.list(ctx, w.pattern, w.filter)
+	if err != nil {
+		return nil, err
+	}
+	previous := w.known
+	w.known = current
+	if previous == nil {
+		return nil, nil
+	}
+	var events []*rpc.Notification
+	for name, state := range current {
+		old, ok := previous[name]
+		if !ok {
+			events = append(events, &rpc.Notification{Change: rpc.Notification_CREATED, Resource: name, ChangeTime: state.updateTime})
+		} else if !proto.Equal(old.updateTime, state.updateTime) || old.revision != state.revision {
+			events = append(events, &rpc.Notification{Change: rpc.Notification_UPDATED, Resource: name, ChangeTime: state.updateTime})
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			events = append(events, &rpc.Notification{Change: rpc.Notification_DELETED, Resource: name, ChangeTime: timestamppb.Now()})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if a, b := events[i].GetChangeTime().AsTime(), events[j].GetChangeTime().AsTime(); !a.Equal(b) {
+			return a.Before(b)
+		}
+		return events[i].GetResource() < events[j].GetResource()
+	})
+	return events, nil
+}
+
+// list returns the state of the resources that match a name and filter.
+func (w *watcher) list(ctx context.Context, name, filter string) (map[string]resourceState, error) {
+	found := make(map[string]resourceState)
+	add := func(name string, updateTime *timestamppb.Timestamp, revision string) error {
+		found[name] = resourceState{updateTime: updateTime, revision: revision}
+		return nil
+	}
+	var err error
+	if project, err2 := names.ParseProject(name); err2 == nil {
+		err = core.ListProjects(ctx, w.adminClient, project, filter, func(m *rpc.Project) error {
+			return add(m.GetName(), m.GetUpdateTime(), "")
+		})
+	} else if api, err2 := names.ParseApi(name); err2 == nil {
+		err = core.ListAPIs(ctx, w.client, api, filter, func(m *rpc.Api) error {
+			return add(m.GetName(), m.GetUpdateTime(), "")
+		})
+	} else if version, err2 := names.ParseVersion(name); err2 == nil {
+		err = core.ListVersions(ctx, w.client, version, filter, func(m *rpc.ApiVersion) error {
+			return add(m.GetName(), m.GetUpdateTime(), "")
+		})
+	} else if spec, err2 := names.ParseSpec(name); err2 == nil {
+		err = core.ListSpecs(ctx, w.client, spec, filter, func(m *rpc.ApiSpec) error {
+			return add(m.GetName(), m.GetRevisionUpdateTime(), m.GetRevisionId())
+		})
+	} else if deployment, err2 := names.ParseDeployment(name); err2 == nil {
+		err = core.ListDeployments(ctx, w.client, deployment, filter, func(m *rpc.ApiDeployment) error {
+			return add(m.GetName(), m.GetRevisionUpdateTime(), m.GetRevisionId())
+		})
+	} else if artifact, err2 := names.ParseArtifact(name); err2 == nil {
+		err = core.ListArtifacts(ctx, w.client, artifact, filter, false, func(m *rpc.Artifact) error {
+			return add(m.GetName(), m.GetUpdateTime(), "")
+		})
+	} else {
+		return nil, fmt.Errorf("unsupported resource name %q", name)
+	}
+	return found, err
+}
+
+// matches returns true if a changed resource matches the pattern and filter.
+// Deleted resources can't be checked against the filter and match if their names match.
+func (w *watcher) matches(ctx context.Context, event *rpc.Notification) (bool, error) {
+	name := event.GetResource()
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i] // revisions match the patterns of their resources
+	}
+	if !matchPattern(w.pattern, name) {
+		return false, nil
+	}
+	if w.filter == "" || event.GetChange() == rpc.Notification_DELETED {
+		return true, nil
+	}
+	found, err := w.list(ctx, name, w.filter)
+	if err != nil {
+		return false, err
+	}
+	return len(found) > 0, nil
+}
+
+// matchPattern returns true if a name matches a pattern in which "-" matches any ID.
+func matchPattern(pattern, name string) bool {
+	p, n := strings.Split(pattern, "/"), strings.Split(name, "/")
+	if len(p) != len(n) {
+		return false
+	}
+	for i := range p {
+		if p[i] != n[i] && !(i%2 == 1 && p[i] == "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// auditEvents returns events for the matching entries that were added to the audit log
+// since the previous call. The first call returns no events.
+func (w *watcher) auditEvents(ctx context.Context) ([]*rpc.Notification, error) {
+	parent := strings.Join(strings.SplitN(w.pattern, "/", 3)[:2], "/")
+	if !w.started {
+		// Start after the latest event.
+		it := w.adminClient.ListAuditEvents(ctx, &rpc.ListAuditEventsRequest{
+			Parent:   parent,
+			OrderBy:  "create_time desc",
+			PageSize: 1,
+		})
+		latest, err := it.Next()
+		if err == nil {
+			w.since = latest.GetCreateTime().AsTime()
+			w.seen = map[string]bool{latest.GetId(): true}
+		} else if err != iterator.Done {
+			return nil, err
+		}
+		w.started = true
+		return nil, nil
+	}
+	filter := ""
+	if !w.since.IsZero() {
+		filter = fmt.Sprintf("create_time >= timestamp(%q)", w.since.Format(time.RFC3339Nano))
+	}
+	it := w.adminClient.ListAuditEvents(ctx, &rpc.ListAuditEventsRequest{
+		Parent: parent,
+		Filter: filter,
+	})
+	var events []*rpc.Notification
+	for {
+		e, err := it.Next()
+		if err == iterator.Done {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if w.seen[e.GetId()] {
+			continue
+		}
+		if t := e.GetCreateTime().AsTime(); t.After(w.since) {
+			w.since = t
+			w.seen = make(map[string]bool)
+		}
+		w.seen[e.GetId()] = true
+		event := &rpc.Notification{
+			Change:     changeForMethod(e.GetMethod()),
+			Resource:   e.GetResource(),
+			ChangeTime: e.GetCreateTime(),
+		}
+		if ok, err := w.matches(ctx, event); err != nil {
+			return nil, err
+		} else if ok {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// changeForMethod returns the kind of change made by a method recorded in the audit log.
+func changeForMethod(method string) rpc.Notification_Change {
+	switch {
+	case strings.HasPrefix(method, "Create"):
+		return rpc.Notification_CREATED
+	case strings.HasPrefix(method, "Delete"):
+		return rpc.Notification_DELETED
+	default:
+		return rpc.Notification_UPDATED
+	}
+}
+
+// receive prints the matching notifications received from a Pub/Sub subscription until ctx is done.
+func (w *watcher) receive(ctx context.Context, subscription string) error {
+	parts := strings.Split(subscription, "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[2] != "subscriptions" {
+		return fmt.Errorf("invalid subscription %q: must be projects/PROJECT/subscriptions/SUBSCRIPTION", subscription)
+	}
+	client, err := pubsub.NewClient(ctx, parts[1])
+	if err != nil {
+		return fmt.Errorf("failed to create Pub/Sub client: %s", err)
+	}
+	defer client.Close()
+	sub := client.Subscription(parts[3])
+	// Handle one message at a time so that events are printed in the order they are received.
+	sub.ReceiveSettings.NumGoroutines = 1
+	sub.ReceiveSettings.MaxOutstandingMessages = 1
+	// Messages are acknowledged after they are handled, so that messages that
+	// can't be checked or printed are delivered again.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var printErr error
+	err = sub.Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+		event := &rpc.Notification{}
+		if err := protojson.Unmarshal(m.Data, event); err != nil {
+			log.FromContext(ctx).WithError(err).Warnf("Skipping message %s", m.ID)
+			m.Ack()
+			return
+		}
+		ok, err := w.matches(ctx, event)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Warnf("Failed to check %s", event.GetResource())
+			m.Nack()
+			return
+		}
+		if ok {
+			if err := w.print(event); err != nil {
+				// Output is broken, so stop receiving and leave the message for another watcher.
+				printErr = err
+				m.Nack()
+				cancel()
+				return
+			}
+		}
+		m.Ack()
+	})
+	if err != nil {
+		return err
+	}
+	return printErr
+}
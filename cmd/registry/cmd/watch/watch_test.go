@@ -0,0 +1,295 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/apigee/registry/cmd/registry/cmd/internal/cmdtest"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/pkg/connection/grpctest"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// TestMain will set up a local RegistryServer and grpc.Server for all
+// tests in this package if APG_REGISTRY_ADDRESS env var is not set
+// for the client.
+func TestMain(m *testing.M) {
+	grpctest.TestMain(m, registry.Config{Audit: true})
+}
+
+// setup creates an empty project and returns clients to use with it.
+func setup(t *testing.T, projectID string) (connection.RegistryClient, connection.AdminClient) {
+	t.Helper()
+	registryClient, adminClient := cmdtest.Clients(t)
+	cmdtest.CreateProjects(t, adminClient, projectID)
+	return registryClient, adminClient
+}
+
+// changeApis creates, updates, and deletes APIs in a project.
+func changeApis(t *testing.T, client connection.RegistryClient, parent string) {
+	t.Helper()
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+			Parent: parent,
+			ApiId:  id,
+			Api:    &rpc.Api{Labels: map[string]string{"watched": id}},
+		}); err != nil {
+			t.Fatalf("Error creating api %s", err)
+		}
+	}
+	if _, err := client.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api:        &rpc.Api{Name: parent + "/apis/a", DisplayName: "A"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name"}},
+	}); err != nil {
+		t.Fatalf("Error updating api %s", err)
+	}
+	if err := client.DeleteApi(ctx, &rpc.DeleteApiRequest{Name: parent + "/apis/b"}); err != nil {
+		t.Fatalf("Error deleting api %s", err)
+	}
+}
+
+// summary returns the changes and resources of events.
+func summary(events []*rpc.Notification) []string {
+	s := make([]string, len(events))
+	for i, e := range events {
+		s[i] = e.GetChange().String() + " " + e.GetResource()
+	}
+	return s
+}
+
+func TestPoll(t *testing.T) {
+	const parent = "projects/watch-poll/locations/global"
+	client, adminClient := setup(t, "watch-poll")
+	ctx := context.Background()
+	w := &watcher{client: client, adminClient: adminClient, pattern: namePattern(parent + "/apis")}
+	if events, err := w.poll(ctx); err != nil {
+		t.Fatalf("poll returned error: %s", err)
+	} else if len(events) > 0 {
+		t.Errorf("first poll returned %v, want no events", summary(events))
+	}
+
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{Parent: parent, ApiId: "a", Api: &rpc.Api{}}); err != nil {
+		t.Fatalf("Error creating api %s", err)
+	}
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{Parent: parent, ApiId: "b", Api: &rpc.Api{}}); err != nil {
+		t.Fatalf("Error creating api %s", err)
+	}
+	events, err := w.poll(ctx)
+	if err != nil {
+		t.Fatalf("poll returned error: %s", err)
+	}
+	want := []string{"CREATED " + parent + "/apis/a", "CREATED " + parent + "/apis/b"}
+	if diff := cmp.Diff(want, summary(events)); diff != "" {
+		t.Errorf("poll returned unexpected events (-want +got):\n%s", diff)
+	}
+
+	if _, err := client.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api:        &rpc.Api{Name: parent + "/apis/a", DisplayName: "A"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name"}},
+	}); err != nil {
+		t.Fatalf("Error updating api %s", err)
+	}
+	if err := client.DeleteApi(ctx, &rpc.DeleteApiRequest{Name: parent + "/apis/b"}); err != nil {
+		t.Fatalf("Error deleting api %s", err)
+	}
+	events, err = w.poll(ctx)
+	if err != nil {
+		t.Fatalf("poll returned error: %s", err)
+	}
+	got := summary(events)
+	for _, want := range []string{"UPDATED " + parent + "/apis/a", "DELETED " + parent + "/apis/b"} {
+		if !cmdtest.Contains(got, want) {
+			t.Errorf("poll returned %v, want %q", got, want)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("poll returned %v, want 2 events", got)
+	}
+}
+
+func TestAuditEvents(t *testing.T) {
+	const parent = "projects/watch-audit/locations/global"
+	client, adminClient := setup(t, "watch-audit")
+	ctx := context.Background()
+	out := new(bytes.Buffer)
+	w := &watcher{
+		client:      client,
+		adminClient: adminClient,
+		pattern:     namePattern(parent + "/apis"),
+		filter:      "labels.watched == 'a'",
+		out:         out,
+		json:        true,
+	}
+	if events, err := w.auditEvents(ctx); err != nil {
+		t.Fatalf("auditEvents returned error: %s", err)
+	} else if len(events) > 0 {
+		t.Errorf("first call returned %v, want no events", summary(events))
+	}
+
+	changeApis(t, client, parent)
+	events, err := w.auditEvents(ctx)
+	if err != nil {
+		t.Fatalf("auditEvents returned error: %s", err)
+	}
+	// Deletions match if their names match because their fields aren't available.
+	want := []string{
+		"CREATED " + parent + "/apis/a",
+		"UPDATED " + parent + "/apis/a",
+		"DELETED " + parent + "/apis/b",
+	}
+	if diff := cmp.Diff(want, summary(events)); diff != "" {
+		t.Errorf("auditEvents returned unexpected events (-want +got):\n%s", diff)
+	}
+
+	if events, err := w.auditEvents(ctx); err != nil {
+		t.Fatalf("auditEvents returned error: %s", err)
+	} else if len(events) > 0 {
+		t.Errorf("repeated call returned %v, want no events", summary(events))
+	}
+
+	if err := w.print(events[0]); err != nil {
+		t.Fatalf("print returned error: %s", err)
+	}
+	line := strings.ReplaceAll(out.String(), " ", "")
+	if !strings.HasPrefix(line, `{"change":"CREATED","resource":"`+parent+`/apis/a"`) {
+		t.Errorf("print wrote %q, want a JSON notification", out.String())
+	}
+}
+
+// cancelingWriter cancels a context after each write, and fails if err is set.
+type cancelingWriter struct {
+	bytes.Buffer
+	cancel context.CancelFunc
+	err    error
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	defer w.cancel()
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestReceive(t *testing.T) {
+	ctx := context.Background()
+	server := pstest.NewServer()
+	defer server.Close()
+	t.Setenv("PUBSUB_EMULATOR_HOST", server.Addr)
+	client, err := pubsub.NewClient(ctx, "watch-receive")
+	if err != nil {
+		t.Fatalf("Error creating Pub/Sub client: %s", err)
+	}
+	defer client.Close()
+	topic, err := client.CreateTopic(ctx, "changes")
+	if err != nil {
+		t.Fatalf("Error creating topic: %s", err)
+	}
+	if _, err := client.CreateSubscription(ctx, "watch", pubsub.SubscriptionConfig{Topic: topic}); err != nil {
+		t.Fatalf("Error creating subscription: %s", err)
+	}
+	resource := "projects/watch-receive/locations/global/apis/a"
+	data, err := protojson.Marshal(&rpc.Notification{Change: rpc.Notification_CREATED, Resource: resource})
+	if err != nil {
+		t.Fatalf("Error marshaling notification: %s", err)
+	}
+	id, err := topic.Publish(ctx, &pubsub.Message{Data: data}).Get(ctx)
+	if err != nil {
+		t.Fatalf("Error publishing notification: %s", err)
+	}
+	acks := func() int {
+		t.Helper()
+		m := server.Message(id)
+		if m == nil {
+			t.Fatalf("Message %s not found", id)
+		}
+		return m.Acks
+	}
+	subscription := "projects/watch-receive/subscriptions/watch"
+
+	// Messages that can't be printed aren't acknowledged, so they are delivered again.
+	failing := errors.New("closed")
+	rctx, cancel := context.WithCancel(ctx)
+	w := &watcher{pattern: "projects/watch-receive/locations/global/apis/-", out: &cancelingWriter{cancel: cancel, err: failing}}
+	if err := w.receive(rctx, subscription); !errors.Is(err, failing) {
+		t.Errorf("receive() returned %v, want %v", err, failing)
+	}
+	if n := acks(); n != 0 {
+		t.Errorf("Message was acknowledged %d times after printing failed, want 0", n)
+	}
+
+	rctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out := &cancelingWriter{cancel: cancel}
+	w.out = out
+	if err := w.receive(rctx, subscription); err != nil {
+		t.Errorf("receive() returned error: %s", err)
+	}
+	if !strings.Contains(out.String(), "CREATED "+resource) {
+		t.Errorf("receive() printed %q, want the redelivered notification", out.String())
+	}
+	if n := acks(); n != 1 {
+		t.Errorf("Message was acknowledged %d times after it was printed, want 1", n)
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"projects/p/locations/global/apis/-", "projects/p/locations/global/apis/a", true},
+		{"projects/p/locations/global/apis/a", "projects/p/locations/global/apis/a", true},
+		{"projects/p/locations/global/apis/a", "projects/p/locations/global/apis/b", false},
+		{"projects/p/locations/global/apis/-", "projects/p/locations/global/apis/a/versions/v", false},
+		{"projects/-/locations/global/apis/-/versions/-/specs/-", "projects/p/locations/global/apis/a/versions/v/specs/s", true},
+		{"projects/p/locations/-/apis/-", "projects/p/locations/global/apis/a", true},
+		{"projects/p/locations/global/apis/-", "projects/p/locations/global/-/a", false},
+	}
+	for _, test := range tests {
+		if got := matchPattern(test.pattern, test.name); got != test.want {
+			t.Errorf("matchPattern(%q, %q) returned %t, want %t", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
+func TestNamePattern(t *testing.T) {
+	tests := map[string]string{
+		"projects":                           "projects/-",
+		"projects/p":                         "projects/p",
+		"projects/p/locations/global/apis":   "projects/p/locations/global/apis/-",
+		"projects/p/locations/global/apis/-": "projects/p/locations/global/apis/-",
+		"projects/p/locations/global/apis/a": "projects/p/locations/global/apis/a",
+	}
+	for name, want := range tests {
+		if got := namePattern(name); got != want {
+			t.Errorf("namePattern(%q) returned %q, want %q", name, got, want)
+		}
+	}
+}
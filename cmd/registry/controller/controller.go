@@ -269,8 +269,8 @@ func generateCreateActions(
 
 	parentName := parsedResourcePattern.ParentName()
 	switch parentName.(type) {
-	case patterns.ProjectName:
-		// If parent is a project, we can't list projects since this is registry client command.
+	case patterns.ProjectName, patterns.LocationName:
+		// If parent is a project or a location, we can't list projects since this is registry client command.
 		// Since the manifest definition is scoped  only for a particular project,
 		// there will be only one target resource in this case.
 		// There are two cases where this might happen:
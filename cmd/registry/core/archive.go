@@ -0,0 +1,146 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"strings"
+
+	"github.com/apigee/registry/gapic"
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ArchiveFormatVersion is the version of the archives that are imported.
+const ArchiveFormatVersion = 1
+
+// ArchivedSpecContents returns the contents of a spec revision as they are
+// stored, which is how they are kept in archives.
+func ArchivedSpecContents(ctx context.Context, client *gapic.RegistryClient, spec *rpc.ApiSpec) ([]byte, error) {
+	// Gzipped contents are returned as they are stored if the request accepts gzip.
+	ctx = metadata.AppendToOutgoingContext(ctx, "accept-encoding", "gzip")
+	body, err := GetSpecContents(ctx, client, spec)
+	if status.Code(err) == codes.NotFound && spec.GetSizeBytes() == 0 {
+		return nil, nil // the revision has no contents
+	} else if err != nil {
+		return nil, err
+	}
+	return body.GetData(), nil
+}
+
+// ArchivedArtifactContents returns the contents of an artifact as they are
+// stored, which is how they are kept in archives.
+func ArchivedArtifactContents(ctx context.Context, client *gapic.RegistryClient, artifact *rpc.Artifact) ([]byte, error) {
+	body, err := client.GetArtifactContents(ctx, &rpc.GetArtifactContentsRequest{Name: artifact.GetName()})
+	if status.Code(err) == codes.NotFound && artifact.GetSizeBytes() == 0 {
+		return nil, nil // the artifact has no contents
+	} else if err != nil {
+		return nil, err
+	}
+	// Artifact contents are always returned uncompressed.
+	if strings.Contains(artifact.GetMimeType(), "+gzip") {
+		return GZippedBytes(body.GetData())
+	}
+	return body.GetData(), nil
+}
+
+// ImportProjectArchive writes the resources in an archive to the project with
+// the specified ID, or to the archived project if the ID is empty. The archive
+// is uploaded in chunks and interrupted uploads are resumed from their committed size.
+func ImportProjectArchive(ctx context.Context,
+	client *gapic.AdminClient,
+	archive *rpc.ProjectArchive,
+	projectID string,
+	policy rpc.ImportProjectRequest_ConflictPolicy) (*rpc.ImportProjectResponse, error) {
+	b, err := proto.Marshal(archive)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = GZippedBytes(b); err != nil {
+		return nil, err
+	}
+
+	name := names.Archive{ArchiveID: uuid.New().String()}.String()
+	var offset int64
+	for attempt := 0; ; attempt++ {
+		if err = uploadArchiveChunks(ctx, client, name, b, offset); err == nil {
+			break
+		}
+		if status.Code(err) != codes.Unavailable || attempt+1 == streamingAttempts {
+			return nil, err
+		}
+		log.FromContext(ctx).WithError(err).Debugf("Resuming upload of %s", name)
+		if offset, err = uploadedArchiveSize(ctx, client, name); err != nil {
+			return nil, err
+		}
+	}
+
+	op, err := client.ImportProject(ctx, &rpc.ImportProjectRequest{
+		Archive:        name,
+		ProjectId:      projectID,
+		ConflictPolicy: policy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf(ctx, "Waiting for operation %s", op.Name())
+	return op.Wait(ctx)
+}
+
+// uploadArchiveChunks sends the contents that follow an offset and finishes the upload.
+func uploadArchiveChunks(ctx context.Context, client *gapic.AdminClient, name string, contents []byte, offset int64) error {
+	stream, err := client.UploadArchive(ctx)
+	if err != nil {
+		return err
+	}
+	req := &rpc.UploadArchiveRequest{Name: name}
+	for {
+		end := offset + int64(UploadChunkSize)
+		if end > int64(len(contents)) {
+			end = int64(len(contents))
+		}
+		req.WriteOffset = offset
+		req.Data = contents[offset:end]
+		req.FinishWrite = end == int64(len(contents))
+		// Send errors are returned by CloseAndRecv.
+		if err := stream.Send(req); err != nil || req.FinishWrite {
+			break
+		}
+		offset = end
+		req = &rpc.UploadArchiveRequest{}
+	}
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+// uploadedArchiveSize gets the committed size of a pending archive upload.
+func uploadedArchiveSize(ctx context.Context, client *gapic.AdminClient, name string) (int64, error) {
+	stream, err := client.UploadArchive(ctx)
+	if err != nil {
+		return 0, err
+	}
+	_ = stream.Send(&rpc.UploadArchiveRequest{Name: name})
+	response, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, err
+	}
+	return response.GetCommittedSize(), nil
+}
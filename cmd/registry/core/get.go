@@ -107,9 +107,7 @@ func GetSpec(ctx context.Context,
 	}
 	if getContents {
 		ctx := metadata.AppendToOutgoingContext(ctx, "accept-encoding", "gzip")
-		contents, err := client.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{
-			Name: spec.GetName(),
-		})
+		contents, err := GetSpecContents(ctx, client, spec)
 		if err != nil {
 			return err
 		}
@@ -133,11 +131,8 @@ func GetSpecRevision(ctx context.Context,
 		return err
 	}
 	if getContents {
-		request := &rpc.GetApiSpecContentsRequest{
-			Name: spec.GetName(),
-		}
 		ctx := metadata.AppendToOutgoingContext(ctx, "accept-encoding", "gzip")
-		contents, err := client.GetApiSpecContents(ctx, request)
+		contents, err := GetSpecContents(ctx, client, spec)
 		if err != nil {
 			return err
 		}
@@ -24,3 +24,4 @@ type DeploymentHandler func(*rpc.ApiDeployment) error
 type VersionHandler func(*rpc.ApiVersion) error
 type SpecHandler func(*rpc.ApiSpec) error
 type ArtifactHandler func(*rpc.Artifact) error
+type RevisionTagHandler func(*rpc.RevisionTag) error
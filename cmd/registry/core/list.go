@@ -111,13 +111,32 @@ func ListDeploymentRevisions(ctx context.Context,
 	client *gapic.RegistryClient,
 	name names.Deployment,
 	handler DeploymentHandler) error {
+	return ListDeploymentRevisionsWithTags(ctx, client, name, handler, nil)
+}
+
+// ListDeploymentRevisionsWithTags also calls tagHandler with the tags of the listed revisions.
+// The tags of each page of revisions are handled before the revisions.
+func ListDeploymentRevisionsWithTags(ctx context.Context,
+	client *gapic.RegistryClient,
+	name names.Deployment,
+	handler DeploymentHandler,
+	tagHandler RevisionTagHandler) error {
 	it := client.ListApiDeploymentRevisions(ctx, &rpc.ListApiDeploymentRevisionsRequest{
 		Name: name.String(),
 	})
+	var page *rpc.ListApiDeploymentRevisionsResponse
 	for r, err := it.Next(); err != iterator.Done; r, err = it.Next() {
 		if err != nil {
 			return err
 		}
+		if response, ok := it.Response.(*rpc.ListApiDeploymentRevisionsResponse); ok && response != page && tagHandler != nil {
+			page = response
+			for _, t := range response.GetRevisionTags() {
+				if err := tagHandler(t); err != nil {
+					return err
+				}
+			}
+		}
 		if err := handler(r); err != nil {
 			return err
 		}
@@ -185,13 +204,32 @@ func ListSpecRevisions(ctx context.Context,
 	client *gapic.RegistryClient,
 	name names.Spec,
 	handler SpecHandler) error {
+	return ListSpecRevisionsWithTags(ctx, client, name, handler, nil)
+}
+
+// ListSpecRevisionsWithTags also calls tagHandler with the tags of the listed revisions.
+// The tags of each page of revisions are handled before the revisions.
+func ListSpecRevisionsWithTags(ctx context.Context,
+	client *gapic.RegistryClient,
+	name names.Spec,
+	handler SpecHandler,
+	tagHandler RevisionTagHandler) error {
 	it := client.ListApiSpecRevisions(ctx, &rpc.ListApiSpecRevisionsRequest{
 		Name: name.String(),
 	})
+	var page *rpc.ListApiSpecRevisionsResponse
 	for r, err := it.Next(); err != iterator.Done; r, err = it.Next() {
 		if err != nil {
 			return err
 		}
+		if response, ok := it.Response.(*rpc.ListApiSpecRevisionsResponse); ok && response != page && tagHandler != nil {
+			page = response
+			for _, t := range response.GetRevisionTags() {
+				if err := tagHandler(t); err != nil {
+					return err
+				}
+			}
+		}
 
 		if err := handler(r); err != nil {
 			return err
@@ -132,6 +132,8 @@ func PrintArtifactContents(artifact *rpc.Artifact) error {
 		return unmarshalAndPrint(artifact.GetContents(), &rpc.Lifecycle{})
 	case "google.cloud.apigeeregistry.v1.apihub.ReferenceList":
 		return unmarshalAndPrint(artifact.GetContents(), &rpc.ReferenceList{})
+	case "google.cloud.apigeeregistry.v1.apihub.SpecSummary":
+		return unmarshalAndPrint(artifact.GetContents(), &rpc.SpecSummary{})
 	case "google.cloud.apigeeregistry.v1.apihub.TaxonomyList":
 		return unmarshalAndPrint(artifact.GetContents(), &rpc.TaxonomyList{})
 	case "google.cloud.apigeeregistry.v1.controller.Manifest":
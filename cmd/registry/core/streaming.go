@@ -0,0 +1,193 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/rpc"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// StreamingThreshold is the size of contents above which streaming RPCs
+	// are used to transfer them.
+	StreamingThreshold = 2 << 20
+	// UploadChunkSize is the size of the chunks of streamed uploads.
+	UploadChunkSize = 1 << 20
+	// streamingAttempts limits the number of times an interrupted transfer is resumed.
+	streamingAttempts = 3
+)
+
+// CreateSpec creates a spec, uploading contents above the streaming threshold
+// in chunks.
+func CreateSpec(ctx context.Context, client connection.RegistryClient, req *rpc.CreateApiSpecRequest) (*rpc.ApiSpec, error) {
+	if len(req.GetApiSpec().GetContents()) <= StreamingThreshold {
+		return client.CreateApiSpec(ctx, req)
+	}
+	name := req.GetParent() + "/specs/" + req.GetApiSpecId()
+	if _, err := client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: name}); err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "API spec %q already exists", name)
+	} else if status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+	spec := proto.Clone(req.GetApiSpec()).(*rpc.ApiSpec)
+	spec.Name = name
+	return UploadSpec(ctx, client, spec)
+}
+
+// UpdateSpec updates a spec, uploading contents above the streaming threshold
+// in chunks. Streaming is only used for requests that allow missing specs and
+// don't have update masks because uploads create or update all set fields.
+func UpdateSpec(ctx context.Context, client connection.RegistryClient, req *rpc.UpdateApiSpecRequest) (*rpc.ApiSpec, error) {
+	if len(req.GetApiSpec().GetContents()) <= StreamingThreshold || !req.GetAllowMissing() || req.GetUpdateMask() != nil {
+		return client.UpdateApiSpec(ctx, req)
+	}
+	return UploadSpec(ctx, client, req.GetApiSpec())
+}
+
+// UploadSpec creates or updates a spec with UploadApiSpecContents.
+// The upload's ID is requested before any contents are sent, so that
+// interrupted uploads can be resumed from their committed size.
+func UploadSpec(ctx context.Context, client connection.RegistryClient, spec *rpc.ApiSpec) (*rpc.ApiSpec, error) {
+	contents := spec.GetContents()
+	header := proto.Clone(spec).(*rpc.ApiSpec)
+	header.Contents = nil
+
+	pending, err := uploadedSpec(ctx, client, header, "")
+	if err != nil {
+		return nil, err
+	}
+	id, offset := pending.GetUploadId(), pending.GetCommittedSize()
+	for attempt := 0; attempt < streamingAttempts; attempt++ {
+		var response *rpc.UploadApiSpecContentsResponse
+		if response, err = uploadSpecChunks(ctx, client, header, id, contents, offset); err == nil {
+			return response.GetApiSpec(), nil
+		}
+		if status.Code(err) != codes.Unavailable {
+			return nil, err
+		}
+		log.FromContext(ctx).WithError(err).Debugf("Resuming upload of %s", spec.GetName())
+		if pending, err = uploadedSpec(ctx, client, header, id); err != nil {
+			return nil, err
+		}
+		offset = pending.GetCommittedSize()
+	}
+	return nil, err
+}
+
+// uploadSpecChunks sends the contents that follow an offset and finishes the upload.
+func uploadSpecChunks(ctx context.Context, client connection.RegistryClient, spec *rpc.ApiSpec, id string, contents []byte, offset int64) (*rpc.UploadApiSpecContentsResponse, error) {
+	stream, err := client.UploadApiSpecContents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := &rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id}
+	for {
+		end := offset + int64(UploadChunkSize)
+		if end > int64(len(contents)) {
+			end = int64(len(contents))
+		}
+		req.WriteOffset = offset
+		req.Data = contents[offset:end]
+		req.FinishWrite = end == int64(len(contents))
+		// Send errors are returned by CloseAndRecv.
+		if err := stream.Send(req); err != nil || req.FinishWrite {
+			break
+		}
+		offset = end
+		req = &rpc.UploadApiSpecContentsRequest{}
+	}
+	return stream.CloseAndRecv()
+}
+
+// uploadedSpec gets the committed size of a pending upload, or starts a new
+// upload and gets its ID if id is empty.
+func uploadedSpec(ctx context.Context, client connection.RegistryClient, spec *rpc.ApiSpec, id string) (*rpc.UploadApiSpecContentsResponse, error) {
+	stream, err := client.UploadApiSpecContents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = stream.Send(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id})
+	return stream.CloseAndRecv()
+}
+
+// GetSpecContents gets the contents of a spec, streaming contents above the
+// streaming threshold. Interrupted streams are resumed from the last byte received.
+// Streams read the revision that is current when they start, so that a resumed
+// stream doesn't read part of a revision that was created after the interruption.
+func GetSpecContents(ctx context.Context, client connection.RegistryClient, spec *rpc.ApiSpec) (*httpbody.HttpBody, error) {
+	if int(spec.GetSizeBytes()) <= StreamingThreshold {
+		return client.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{Name: spec.GetName()})
+	}
+
+	name := spec.GetName()
+	if !strings.Contains(name, "@") {
+		revisionID := spec.GetRevisionId()
+		if revisionID == "" {
+			current, err := client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: name})
+			if err != nil {
+				return nil, err
+			}
+			revisionID = current.GetRevisionId()
+		}
+		name += "@" + revisionID
+	}
+
+	body := &httpbody.HttpBody{}
+	var buf bytes.Buffer
+	var err error
+	for attempt := 0; attempt < streamingAttempts; attempt++ {
+		if err = streamSpecContents(ctx, client, name, body, &buf); err == nil {
+			body.Data = buf.Bytes()
+			return body, nil
+		}
+		if status.Code(err) != codes.Unavailable {
+			return nil, err
+		}
+		log.FromContext(ctx).WithError(err).Debugf("Resuming download of %s at %d", name, buf.Len())
+	}
+	return nil, err
+}
+
+func streamSpecContents(ctx context.Context, client connection.RegistryClient, name string, body *httpbody.HttpBody, buf *bytes.Buffer) error {
+	stream, err := client.StreamApiSpecContents(ctx, &rpc.StreamApiSpecContentsRequest{
+		Name:       name,
+		ReadOffset: int64(buf.Len()),
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if response.GetContentType() != "" {
+			body.ContentType = response.GetContentType()
+		}
+		buf.Write(response.GetData())
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a template in the subset of the kubectl JSONPath syntax that is supported:
+// text outside braces is printed as-is, and braces enclose field paths such as
+// {.items[*].name}, quoted strings such as {"\n"}, and {range PATH}...{end} blocks.
+type jsonPath struct {
+	nodes []jsonPathNode
+}
+
+// jsonPathNode is one of the parts of a template.
+type jsonPathNode struct {
+	text    string         // printed if path is nil
+	path    *path          // printed, or iterated over in range blocks
+	isRange bool           // true for range blocks
+	body    []jsonPathNode // the contents of a range block
+}
+
+func parseJSONPath(s string) (*jsonPath, error) {
+	nodes, _, err := parseJSONPathNodes(s, false)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonPath{nodes: nodes}, nil
+}
+
+// parseJSONPathNodes parses nodes until the end of the string or, in a range block,
+// until {end}. It returns the parsed nodes and the unparsed remainder of the string.
+func parseJSONPathNodes(s string, inRange bool) ([]jsonPathNode, string, error) {
+	var nodes []jsonPathNode
+	for s != "" {
+		open := strings.Index(s, "{")
+		if open < 0 {
+			nodes = append(nodes, jsonPathNode{text: s})
+			s = ""
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jsonPathNode{text: s[:open]})
+		}
+		end := strings.Index(s[open:], "}")
+		if end < 0 {
+			return nil, "", errors.New("unclosed {")
+		}
+		action := strings.TrimSpace(s[open+1 : open+end])
+		s = s[open+end+1:]
+		switch {
+		case action == "end":
+			if !inRange {
+				return nil, "", errors.New("{end} without {range}")
+			}
+			return nodes, s, nil
+		case strings.HasPrefix(action, "range "):
+			p, err := parsePath(strings.TrimPrefix(action, "range "))
+			if err != nil {
+				return nil, "", err
+			}
+			body, rest, err := parseJSONPathNodes(s, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: p, isRange: true, body: body})
+			s = rest
+		case strings.HasPrefix(action, `"`):
+			text, err := strconv.Unquote(action)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid string %s", action)
+			}
+			nodes = append(nodes, jsonPathNode{text: text})
+		default:
+			p, err := parsePath(action)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: p})
+		}
+	}
+	if inRange {
+		return nil, "", errors.New("{range} without {end}")
+	}
+	return nodes, s, nil
+}
+
+func (j *jsonPath) execute(w io.Writer, data interface{}) error {
+	return executeJSONPathNodes(w, j.nodes, data)
+}
+
+func executeJSONPathNodes(w io.Writer, nodes []jsonPathNode, data interface{}) error {
+	for _, n := range nodes {
+		switch {
+		case n.path == nil:
+			if _, err := io.WriteString(w, n.text); err != nil {
+				return err
+			}
+		case n.isRange:
+			for _, v := range n.path.eval(data) {
+				if err := executeJSONPathNodes(w, n.body, v); err != nil {
+					return err
+				}
+			}
+		default:
+			results := n.path.eval(data)
+			s := make([]string, len(results))
+			for i, r := range results {
+				s[i] = text(r)
+			}
+			if _, err := io.WriteString(w, strings.Join(s, " ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// path selects values in objects. Paths are sequences of field names
+// and list indexes, such as .items[0].labels.owner or items[*].name.
+type path struct {
+	steps []step
+}
+
+// step selects a field if name is set, otherwise list elements by index.
+type step struct {
+	name  string
+	index int
+	all   bool
+}
+
+// parsePath parses a path. The leading "." or "$." and enclosing braces are optional.
+func parsePath(s string) (*path, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	s = strings.TrimPrefix(s, "$")
+	if s != "" && s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+	p := &path{}
+	for s != "" {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if name := s[:end]; name != "" {
+				p.steps = append(p.steps, step{name: name})
+			}
+			s = s[end:]
+		case '[':
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return nil, errors.New("unclosed [")
+			}
+			index := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			if index == "*" {
+				p.steps = append(p.steps, step{all: true})
+				continue
+			}
+			i, err := strconv.Atoi(index)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", index)
+			}
+			p.steps = append(p.steps, step{index: i})
+		default:
+			return nil, fmt.Errorf("unexpected %q", s)
+		}
+	}
+	return p, nil
+}
+
+// eval returns the values selected by a path. Missing fields select nothing.
+func (p *path) eval(data interface{}) []interface{} {
+	values := []interface{}{data}
+	for _, s := range p.steps {
+		var next []interface{}
+		for _, v := range values {
+			if s.name != "" {
+				next = append(next, field(v, s.name)...)
+				continue
+			}
+			list, ok := v.([]interface{})
+			if !ok {
+				continue
+			}
+			if s.all {
+				next = append(next, list...)
+				continue
+			}
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				next = append(next, list[i])
+			}
+		}
+		values = next
+	}
+	return values
+}
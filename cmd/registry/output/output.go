@@ -0,0 +1,359 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package output prints registry resources in formats that are convenient for scripts.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Options describe how resources are printed.
+type Options struct {
+	// Format is one of name, json, yaml, table, jsonpath=EXPRESSION, or template=TEMPLATE.
+	Format string
+	// Columns are the fields printed in tables.
+	Columns []string
+	// SortBy is the field that listed resources are sorted by.
+	SortBy string
+	// NoHeaders omits the header row of tables.
+	NoHeaders bool
+}
+
+// AddFlags adds flags that set the options.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Format, "output", "o", "",
+		"Output format: one of name, json, yaml, table, jsonpath=EXPRESSION, or template=TEMPLATE")
+	flags.StringSliceVar(&o.Columns, "columns", nil,
+		"Fields to print as table columns (e.g. name,labels.owner,update_time); implies -o table")
+	flags.StringVar(&o.SortBy, "sort-by", "", "Field to sort resources by (e.g. update_time)")
+	flags.BoolVar(&o.NoHeaders, "no-headers", false, "Don't print a header row in tables")
+}
+
+// Specified returns true if any of the options are set.
+// Commands should keep their default output when they aren't.
+func (o *Options) Specified() bool {
+	return o.Format != "" || len(o.Columns) > 0 || o.SortBy != "" || o.NoHeaders
+}
+
+// Printer prints resources in the format selected by its options.
+// Resources are collected with Add and printed by Flush so that they can be sorted.
+type Printer struct {
+	w        io.Writer
+	opts     Options
+	format   string
+	list     bool
+	jsonpath *jsonPath
+	template *template.Template
+	sortBy   *path
+	columns  []*path
+	items    []proto.Message
+
+	// Model returns the pkg/models representation of a resource for YAML output.
+	Model func(proto.Message) (interface{}, error)
+}
+
+// New returns a printer that writes to w. If list is true, the printed
+// resources are the results of a list and structured formats enclose them
+// in an object with an "items" field; otherwise a single resource is printed.
+func New(w io.Writer, opts Options, list bool) (*Printer, error) {
+	p := &Printer{w: w, opts: opts, list: list}
+	format, arg, _ := strings.Cut(opts.Format, "=")
+	switch format {
+	case "":
+		p.format = "name"
+		if len(opts.Columns) > 0 {
+			p.format = "table"
+		}
+	case "name", "json", "yaml", "table":
+		p.format = format
+	case "jsonpath":
+		expr, err := parseJSONPath(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath %q: %s", arg, err)
+		}
+		p.format, p.jsonpath = format, expr
+	case "template", "go-template":
+		t, err := template.New("output").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %s", err)
+		}
+		p.format, p.template = "template", t
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", opts.Format)
+	}
+	if len(opts.Columns) > 0 && p.format != "table" {
+		return nil, errors.New("--columns can only be used with table output")
+	}
+	for _, c := range opts.Columns {
+		path, err := parsePath(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column %q: %s", c, err)
+		}
+		p.columns = append(p.columns, path)
+	}
+	if opts.SortBy != "" {
+		path, err := parsePath(opts.SortBy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --sort-by %q: %s", opts.SortBy, err)
+		}
+		p.sortBy = path
+	}
+	return p, nil
+}
+
+// Add adds a resource to be printed.
+func (p *Printer) Add(message proto.Message) error {
+	p.items = append(p.items, message)
+	return nil
+}
+
+// Handler returns a function that adds resources to p, or h if p is nil.
+// It is used to select the handlers of the core Get and List functions.
+func Handler[T proto.Message](p *Printer, h func(T) error) func(T) error {
+	if p == nil {
+		return h
+	}
+	return func(message T) error {
+		return p.Add(message)
+	}
+}
+
+// Flush prints the resources that have been added.
+func (p *Printer) Flush() error {
+	objects := make([]interface{}, len(p.items))
+	for i, m := range p.items {
+		o, err := object(m)
+		if err != nil {
+			return err
+		}
+		objects[i] = o
+	}
+	if p.sortBy != nil {
+		order := make([]int, len(objects))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return less(first(p.sortBy.eval(objects[order[i]])), first(p.sortBy.eval(objects[order[j]])))
+		})
+		items := make([]proto.Message, len(order))
+		sorted := make([]interface{}, len(order))
+		for i, k := range order {
+			items[i], sorted[i] = p.items[k], objects[k]
+		}
+		p.items, objects = items, sorted
+	}
+	defer func() { p.items = nil }()
+	switch p.format {
+	case "name":
+		for _, o := range objects {
+			fmt.Fprintln(p.w, text(first(field(o, "name"))))
+		}
+		return nil
+	case "json":
+		b, err := json.MarshalIndent(p.root(objects), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(b))
+		return err
+	case "yaml":
+		return p.printYAML()
+	case "table":
+		return p.printTable(objects)
+	case "jsonpath":
+		return p.jsonpath.execute(p.w, p.root(objects))
+	case "template":
+		return p.template.Execute(p.w, p.root(objects))
+	}
+	return nil
+}
+
+// root returns the value that structured formats print.
+func (p *Printer) root(objects []interface{}) interface{} {
+	if p.list {
+		return map[string]interface{}{"items": objects}
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+	return objects[0]
+}
+
+func (p *Printer) printYAML() error {
+	if p.Model == nil {
+		return errors.New("yaml output is not supported for these resources")
+	}
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	for _, m := range p.items {
+		model, err := p.Model(m)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(model); err != nil {
+			return err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err := p.w.Write(b.Bytes())
+	return err
+}
+
+func (p *Printer) printTable(objects []interface{}) error {
+	if len(p.items) == 0 {
+		return nil
+	}
+	columns, paths := p.opts.Columns, p.columns
+	if len(columns) == 0 {
+		columns = defaultColumns(p.items[0])
+		for _, c := range columns {
+			path, _ := parsePath(c)
+			paths = append(paths, path)
+		}
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 8, 3, ' ', 0)
+	if !p.opts.NoHeaders {
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = strings.ToUpper(strings.Trim(c, "{}.$"))
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, o := range objects {
+		values := make([]string, len(paths))
+		for i, path := range paths {
+			results := path.eval(o)
+			if len(results) == 0 {
+				values[i] = "<none>"
+				continue
+			}
+			s := make([]string, len(results))
+			for j, r := range results {
+				s[j] = text(r)
+			}
+			values[i] = strings.Join(s, ",")
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+// defaultColumns returns the table columns of resources whose columns weren't specified.
+func defaultColumns(message proto.Message) []string {
+	switch message.ProtoReflect().Descriptor().Name() {
+	case "Project":
+		return []string{"name", "display_name", "update_time"}
+	case "Api":
+		return []string{"name", "display_name", "availability", "update_time"}
+	case "ApiVersion":
+		return []string{"name", "display_name", "state", "update_time"}
+	case "ApiSpec":
+		return []string{"name", "filename", "mime_type", "revision_id", "update_time"}
+	case "ApiDeployment":
+		return []string{"name", "display_name", "endpoint_uri", "revision_id", "update_time"}
+	case "Artifact":
+		return []string{"name", "mime_type", "size_bytes", "update_time"}
+	default:
+		return []string{"name"}
+	}
+}
+
+// object returns the JSON representation of a message as a tree of maps and slices.
+// Field names are the proto names (e.g. update_time) and numbers are json.Numbers.
+func object(message proto.Message) (interface{}, error) {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var o interface{}
+	if err := dec.Decode(&o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// field returns the named field of an object, if it has one.
+func field(o interface{}, name string) []interface{} {
+	if m, ok := o.(map[string]interface{}); ok {
+		if v, ok := m[name]; ok {
+			return []interface{}{v}
+		}
+	}
+	return nil
+}
+
+// first returns the first of a list of values, or nil if the list is empty.
+func first(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// text returns the printed form of a value. Structured values are printed as JSON.
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+// less orders values for sorting. Missing values come first and numbers are compared numerically.
+func less(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	if x, ok := a.(json.Number); ok {
+		if y, ok := b.(json.Number); ok {
+			xf, errx := x.Float64()
+			yf, erry := y.Float64()
+			if errx == nil && erry == nil {
+				return xf < yf
+			}
+		}
+	}
+	return text(a) < text(b)
+}
@@ -0,0 +1,198 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+)
+
+var testApis = []*rpc.Api{
+	{
+		Name:        "projects/p/locations/global/apis/petstore",
+		DisplayName: "Petstore",
+		Labels:      map[string]string{"owner": "pets"},
+	},
+	{
+		Name:        "projects/p/locations/global/apis/bookstore",
+		DisplayName: "Bookstore",
+	},
+}
+
+func TestPrinter(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts Options
+		list bool
+		want string
+	}{
+		{
+			desc: "name",
+			opts: Options{Format: "name"},
+			list: true,
+			want: "projects/p/locations/global/apis/petstore\nprojects/p/locations/global/apis/bookstore\n",
+		},
+		{
+			desc: "sorted names",
+			opts: Options{SortBy: "display_name"},
+			list: true,
+			want: "projects/p/locations/global/apis/bookstore\nprojects/p/locations/global/apis/petstore\n",
+		},
+		{
+			desc: "json",
+			opts: Options{Format: "json"},
+			want: `{
+  "display_name": "Petstore",
+  "labels": {
+    "owner": "pets"
+  },
+  "name": "projects/p/locations/global/apis/petstore"
+}
+`,
+		},
+		{
+			desc: "json list",
+			opts: Options{Format: "json", SortBy: ".name"},
+			list: true,
+			want: `{
+  "items": [
+    {
+      "display_name": "Bookstore",
+      "name": "projects/p/locations/global/apis/bookstore"
+    },
+    {
+      "display_name": "Petstore",
+      "labels": {
+        "owner": "pets"
+      },
+      "name": "projects/p/locations/global/apis/petstore"
+    }
+  ]
+}
+`,
+		},
+		{
+			desc: "table columns",
+			opts: Options{Format: "table", Columns: []string{"display_name", "labels.owner"}},
+			list: true,
+			want: "DISPLAY_NAME   LABELS.OWNER\nPetstore       pets\nBookstore      <none>\n",
+		},
+		{
+			desc: "table without headers",
+			opts: Options{Columns: []string{"name"}, NoHeaders: true, SortBy: "name"},
+			list: true,
+			want: "projects/p/locations/global/apis/bookstore\nprojects/p/locations/global/apis/petstore\n",
+		},
+		{
+			desc: "jsonpath",
+			opts: Options{Format: "jsonpath={.items[*].display_name}"},
+			list: true,
+			want: "Petstore Bookstore",
+		},
+		{
+			desc: "jsonpath range",
+			opts: Options{Format: `jsonpath={range .items[*]}{.display_name}{"\t"}{.labels.owner}{"\n"}{end}`},
+			list: true,
+			want: "Petstore\tpets\nBookstore\t\n",
+		},
+		{
+			desc: "template",
+			opts: Options{Format: "template={{.display_name}} is owned by {{.labels.owner}}\n"},
+			want: "Petstore is owned by pets\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			p, err := New(&out, test.opts, test.list)
+			if err != nil {
+				t.Fatalf("New(%+v) returned error: %s", test.opts, err)
+			}
+			items := testApis
+			if !test.list {
+				items = items[:1]
+			}
+			for _, api := range items {
+				if err := Handler(p, func(*rpc.Api) error { return nil })(api); err != nil {
+					t.Fatalf("Handler returned error: %s", err)
+				}
+			}
+			if err := p.Flush(); err != nil {
+				t.Fatalf("Flush returned error: %s", err)
+			}
+			if diff := cmp.Diff(test.want, out.String()); diff != "" {
+				t.Errorf("Flush printed unexpected diff: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrinterYAML(t *testing.T) {
+	var out bytes.Buffer
+	p, err := New(&out, Options{Format: "yaml"}, true)
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+	p.Model = func(m proto.Message) (interface{}, error) {
+		return map[string]string{"name": m.(*rpc.Api).DisplayName}, nil
+	}
+	for _, api := range testApis {
+		if err := p.Add(api); err != nil {
+			t.Fatalf("Add returned error: %s", err)
+		}
+	}
+	if err := p.Flush(); err != nil {
+		t.Fatalf("Flush returned error: %s", err)
+	}
+	want := "name: Petstore\n---\nname: Bookstore\n"
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Errorf("Flush printed unexpected diff: (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrinterErrors(t *testing.T) {
+	for _, opts := range []Options{
+		{Format: "xml"},
+		{Format: "json", Columns: []string{"name"}},
+		{Format: "jsonpath={.items"},
+		{Format: "jsonpath={range .items[*]}{.name}"},
+		{Format: "jsonpath={.name}{end}"},
+		{Format: "jsonpath={.items[x]}"},
+		{Format: "template={{.name"},
+		{SortBy: "items["},
+	} {
+		if _, err := New(&bytes.Buffer{}, opts, true); err == nil {
+			t.Errorf("New(%+v) succeeded, want error", opts)
+		}
+	}
+}
+
+func TestHandlerWithoutPrinter(t *testing.T) {
+	called := false
+	h := Handler(nil, func(*rpc.Api) error {
+		called = true
+		return nil
+	})
+	if err := h(testApis[0]); err != nil {
+		t.Fatalf("handler returned error: %s", err)
+	}
+	if !called {
+		t.Errorf("Handler(nil, h) didn't return h")
+	}
+}
@@ -73,6 +73,10 @@ func newApi(ctx context.Context, client *gapic.RegistryClient, message *rpc.Api)
 	}
 	artifacts := make([]*models.Artifact, 0)
 	if err = core.ListArtifacts(ctx, client, apiName.Artifact("-"), "", true, func(message *rpc.Artifact) error {
+		// Artifacts that are maintained by the server aren't exported.
+		if name, err := names.ParseArtifact(message.Name); err == nil && name.Reserved() {
+			return nil
+		}
 		var artifact *models.Artifact
 		artifact, err = newArtifact(message)
 		if err != nil {
@@ -185,27 +189,25 @@ func optionalDeploymentName(apiName names.Api, deploymentID string) string {
 	return apiName.Deployment(deploymentID).String()
 }
 
-func applyApiPatch(ctx context.Context, client connection.RegistryClient, bytes []byte, parent string) error {
+func applyApiPatch(ctx context.Context, client connection.RegistryClient, bytes []byte, parent, dir string) error {
 	var api models.Api
 	err := yaml.Unmarshal(bytes, &api)
 	if err != nil {
 		return err
 	}
-	projectName, err := names.ParseProjectWithLocation(parent)
+	location, err := names.ParseLocation(parent)
 	if err != nil {
 		return err
 	}
-	apiName := projectName.Api(api.Metadata.Name)
+	apiName := location.Api(api.Metadata.Name)
 	req := &rpc.UpdateApiRequest{
 		Api: &rpc.Api{
-			Name:                  apiName.String(),
-			DisplayName:           api.Data.DisplayName,
-			Description:           api.Data.Description,
-			Availability:          api.Data.Availability,
-			RecommendedVersion:    optionalVersionName(apiName, api.Data.RecommendedVersion),
-			RecommendedDeployment: optionalDeploymentName(apiName, api.Data.RecommendedDeployment),
-			Labels:                api.Metadata.Labels,
-			Annotations:           api.Metadata.Annotations,
+			Name:         apiName.String(),
+			DisplayName:  api.Data.DisplayName,
+			Description:  api.Data.Description,
+			Availability: api.Data.Availability,
+			Labels:       api.Metadata.Labels,
+			Annotations:  api.Metadata.Annotations,
 		},
 		AllowMissing: true,
 	}
@@ -214,7 +216,7 @@ func applyApiPatch(ctx context.Context, client connection.RegistryClient, bytes
 		return err
 	}
 	for _, versionPatch := range api.Data.ApiVersions {
-		err := applyApiVersionPatch(ctx, client, versionPatch, apiName.String())
+		err := applyApiVersionPatch(ctx, client, versionPatch, apiName.String(), dir)
 		if err != nil {
 			return err
 		}
@@ -225,6 +227,20 @@ func applyApiPatch(ctx context.Context, client connection.RegistryClient, bytes
 			return err
 		}
 	}
+	// Recommendations are set after the versions and deployments they refer to
+	// so that they can be applied to servers that validate references.
+	if api.Data.RecommendedVersion != "" || api.Data.RecommendedDeployment != "" {
+		_, err = client.UpdateApi(ctx, &rpc.UpdateApiRequest{
+			Api: &rpc.Api{
+				Name:                  apiName.String(),
+				RecommendedVersion:    optionalVersionName(apiName, api.Data.RecommendedVersion),
+				RecommendedDeployment: optionalDeploymentName(apiName, api.Data.RecommendedDeployment),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
 	for _, artifactPatch := range api.Data.Artifacts {
 		err = applyArtifactPatch(ctx, client, artifactPatch, apiName.String())
 		if err != nil {
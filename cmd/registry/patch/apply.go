@@ -16,6 +16,7 @@ package patch
 
 import (
 	"context"
+	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -27,7 +28,49 @@ import (
 )
 
 func Apply(ctx context.Context, client connection.RegistryClient, path, parent string, recursive bool, taskQueue chan<- core.Task) error {
-	return filepath.WalkDir(path,
+	files, err := patchFiles(path, recursive)
+	if err != nil {
+		return err
+	}
+	// Projects are applied first because they contain everything else.
+	queued := make([]*applyFileTask, 0, len(files))
+	for _, f := range files {
+		bytes, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		source := patchSource(f, bytes)
+		header, err := readHeader(bytes)
+		if errors.Is(err, errNotPatch) {
+			log.FromContext(ctx).Debugf("Skipping %s", source)
+			continue
+		} else if err != nil {
+			return err
+		}
+		if header.Kind == "Project" {
+			log.FromContext(ctx).Infof("Applying %s", source)
+			if err := applyProjectPatch(ctx, bytes, parent); err != nil {
+				return err
+			}
+			continue
+		}
+		queued = append(queued, &applyFileTask{
+			client: client,
+			path:   f,
+			source: source,
+			parent: parent,
+		})
+	}
+	for _, task := range queued {
+		taskQueue <- task
+	}
+	return nil
+}
+
+// patchFiles returns the names of the YAML files at path.
+func patchFiles(path string, recursive bool) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.WalkDir(path,
 		func(p string, entry fs.DirEntry, err error) error {
 			if err != nil {
 				return err
@@ -35,36 +78,27 @@ func Apply(ctx context.Context, client connection.RegistryClient, path, parent s
 				return filepath.SkipDir // Skip the directory and contents.
 			} else if entry.IsDir() {
 				return nil // Do nothing for the directory, but still walk its contents.
+			} else if strings.HasSuffix(p, ".yaml") {
+				files = append(files, p)
 			}
-			return applyFile(ctx, client, p, parent, taskQueue)
+			return nil
 		})
-}
-
-func applyFile(ctx context.Context, client connection.RegistryClient, fileName, parent string, taskQueue chan<- core.Task) error {
-	if !strings.HasSuffix(fileName, ".yaml") {
-		return nil
-	}
-	// Create an upload job for each API.
-	taskQueue <- &applyFileTask{
-		client: client,
-		path:   fileName,
-		parent: parent,
-	}
-	return nil
+	return files, err
 }
 
 type applyFileTask struct {
 	client connection.RegistryClient
 	path   string
+	source string // the file the patch was rendered from, or path
 	parent string
 }
 
 func (task *applyFileTask) String() string {
-	return "apply file " + task.path
+	return "apply file " + task.source
 }
 
 func (task *applyFileTask) Run(ctx context.Context) error {
-	log.FromContext(ctx).Infof("Applying %s", task.path)
+	log.FromContext(ctx).Infof("Applying %s", task.source)
 	bytes, err := os.ReadFile(task.path)
 	if err != nil {
 		return err
@@ -75,7 +109,7 @@ func (task *applyFileTask) Run(ctx context.Context) error {
 	}
 	switch header.Kind {
 	case "API":
-		return applyApiPatch(ctx, task.client, bytes, task.parent)
+		return applyApiPatch(ctx, task.client, bytes, task.parent, filepath.Dir(task.path))
 	default: // for everything else, try an artifact type
 		return applyArtifactPatchBytes(ctx, task.client, bytes, task.parent)
 	}
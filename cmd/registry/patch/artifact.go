@@ -162,30 +162,7 @@ func applyArtifactPatchBytes(ctx context.Context, client connection.RegistryClie
 }
 
 func applyArtifactPatch(ctx context.Context, client connection.RegistryClient, content *models.Artifact, parent string) error {
-	// Restyle the YAML representation so that yaml.Marshal will marshal it as JSON.
-	styleForJSON(&content.Data)
-	// Marshal the YAML representation into the JSON serialization.
-	j, err := yaml.Marshal(content.Data)
-	if err != nil {
-		return err
-	}
-	// Populate Id and Kind fields in the contents of the artifact
-	j, err = populateIdAndKind(j, content.Kind, content.Metadata.Name)
-	if err != nil {
-		return err
-	}
-	// Unmarshal the JSON serialization into the message struct.
-	var m proto.Message
-	m, err = protoMessageForKind(content.Kind)
-	if err != nil {
-		return err
-	}
-	err = protojson.Unmarshal(j, m)
-	if err != nil {
-		return err
-	}
-	// Marshal the message struct to bytes.
-	bytes, err := proto.Marshal(m)
+	bytes, err := artifactContents(content)
 	if err != nil {
 		return err
 	}
@@ -209,6 +186,35 @@ func applyArtifactPatch(ctx context.Context, client connection.RegistryClient, c
 	return err
 }
 
+// artifactContents returns the serialized protobuf message described by an artifact patch.
+func artifactContents(content *models.Artifact) ([]byte, error) {
+	// Restyle the YAML representation so that yaml.Marshal will marshal it as JSON.
+	styleForJSON(&content.Data)
+	// Marshal the YAML representation into the JSON serialization.
+	j, err := yaml.Marshal(content.Data)
+	if err != nil {
+		return nil, err
+	}
+	// Populate Id and Kind fields in the contents of the artifact
+	j, err = populateIdAndKind(j, content.Kind, content.Metadata.Name)
+	if err != nil {
+		return nil, err
+	}
+	// Unmarshal the JSON serialization into the message struct.
+	var m proto.Message
+	m, err = protoMessageForKind(content.Kind)
+	if err != nil {
+		return nil, err
+	}
+	err = protojson.Unmarshal(j, m)
+	if err != nil {
+		return nil, err
+	}
+	// Marshal the message struct to bytes. Deterministic output lets
+	// planned contents be compared with stored contents by hash.
+	return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+}
+
 // populateIdAndKind inserts the "id" and "kind" fields in the supplied json bytes.
 func populateIdAndKind(bytes []byte, kind, id string) ([]byte, error) {
 	var jsonData map[string]interface{}
@@ -273,6 +279,7 @@ var artifactMessageTypes map[string]messageFactory = map[string]messageFactory{
 	"google.cloud.apigeeregistry.v1.apihub.DisplaySettings":      func() proto.Message { return new(rpc.DisplaySettings) },
 	"google.cloud.apigeeregistry.v1.apihub.Lifecycle":            func() proto.Message { return new(rpc.Lifecycle) },
 	"google.cloud.apigeeregistry.v1.apihub.ReferenceList":        func() proto.Message { return new(rpc.ReferenceList) },
+	"google.cloud.apigeeregistry.v1.apihub.SpecSummary":          func() proto.Message { return new(rpc.SpecSummary) },
 	"google.cloud.apigeeregistry.v1.apihub.TaxonomyList":         func() proto.Message { return new(rpc.TaxonomyList) },
 	"google.cloud.apigeeregistry.v1.controller.Manifest":         func() proto.Message { return new(rpc.Manifest) },
 	"google.cloud.apigeeregistry.v1.scoring.Score":               func() proto.Message { return new(rpc.Score) },
@@ -15,6 +15,7 @@
 package patch
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/apigee/registry/pkg/models"
@@ -23,12 +24,19 @@ import (
 
 const RegistryV1 = "apigeeregistry/v1"
 
+// errNotPatch is returned for YAML files that have neither an apiVersion nor a kind.
+// These are skipped so that files such as API specs can be stored next to patches.
+var errNotPatch = errors.New("not a patch")
+
 func readHeader(bytes []byte) (models.Header, error) {
 	var header models.Header
 	err := yaml.Unmarshal(bytes, &header)
 	if err != nil {
 		return header, err
 	}
+	if header.ApiVersion == "" && header.Kind == "" {
+		return header, errNotPatch
+	}
 	if header.ApiVersion != RegistryV1 {
 		return header, fmt.Errorf("unsupported API version: %s", header.ApiVersion)
 	}
@@ -0,0 +1,56 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package patch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apigee/registry/gapic"
+	"github.com/apigee/registry/rpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewModel returns the pkg/models representation of a registry resource,
+// which is the form that it takes in patches. APIs and versions include
+// their children, which are read with the client, as do artifact contents
+// that aren't included in the message.
+func NewModel(ctx context.Context, client *gapic.RegistryClient, message proto.Message) (interface{}, error) {
+	switch m := message.(type) {
+	case *rpc.Project:
+		return newProject(m)
+	case *rpc.Api:
+		return newApi(ctx, client, m)
+	case *rpc.ApiVersion:
+		return newApiVersion(ctx, client, m)
+	case *rpc.ApiSpec:
+		return newApiSpec(m)
+	case *rpc.ApiDeployment:
+		return newApiDeployment(m)
+	case *rpc.Artifact:
+		if m.Contents == nil {
+			contents, err := client.GetArtifactContents(ctx, &rpc.GetArtifactContentsRequest{
+				Name: m.Name,
+			})
+			if err != nil {
+				return nil, err
+			}
+			m.Contents = contents.GetData()
+		}
+		return newArtifact(m)
+	default:
+		return nil, fmt.Errorf("unsupported resource type %T", message)
+	}
+}
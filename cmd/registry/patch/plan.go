@@ -0,0 +1,520 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package patch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/pkg/models"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+// Action describes what applying a patch would do to a resource.
+type Action int
+
+const (
+	// NoChange indicates that the resource already matches the patch.
+	NoChange Action = iota
+	// Create indicates that the resource would be created.
+	Create
+	// Update indicates that one or more fields of the resource would change.
+	Update
+	// Delete indicates that the resource would be deleted with everything that it contains.
+	Delete
+)
+
+// FieldChange describes the difference in a single field of a resource.
+type FieldChange struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+// Change describes the effect that applying a patch would have on a resource.
+type Change struct {
+	Action Action
+	Name   string
+	Fields []FieldChange
+}
+
+// compare records a field change if a desired value is set and differs from the current one.
+// Unset values are skipped because apply only writes the fields that a patch populates.
+func (c *Change) compare(field, current, desired string) {
+	if desired == "" || current == desired {
+		return
+	}
+	c.Fields = append(c.Fields, FieldChange{Field: field, Current: quoted(current), Desired: quoted(desired)})
+}
+
+// compareMap records a field change for each key of a map that would change.
+// Maps are replaced as a whole, so keys that are absent from the patch are removed.
+func (c *Change) compareMap(field string, current, desired map[string]string) {
+	if len(desired) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(current)+len(desired))
+	for k := range current {
+		keys = append(keys, k)
+	}
+	for k := range desired {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cv, cok := current[k]
+		dv, dok := desired[k]
+		if cok && dok && cv == dv {
+			continue
+		}
+		change := FieldChange{Field: field + "." + k, Current: "<unset>", Desired: "<unset>"}
+		if cok {
+			change.Current = quoted(cv)
+		}
+		if dok {
+			change.Desired = quoted(dv)
+		}
+		c.Fields = append(c.Fields, change)
+	}
+}
+
+// compareHash records a change of contents, identified by their hashes.
+func (c *Change) compareHash(field, current, desired string) {
+	if current == desired {
+		return
+	}
+	change := FieldChange{Field: field, Current: "<empty>", Desired: "<empty>"}
+	if current != "" {
+		change.Current = "sha256:" + shortHash(current)
+	}
+	if desired != "" {
+		change.Desired = "sha256:" + shortHash(desired)
+	}
+	c.Fields = append(c.Fields, change)
+}
+
+// finish sets the action of a change to an existing resource.
+func (c *Change) finish() *Change {
+	if c.Action == NoChange && len(c.Fields) > 0 {
+		c.Action = Update
+	}
+	return c
+}
+
+func quoted(s string) string {
+	if s == "" {
+		return "<unset>"
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+func shortHash(h string) string {
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
+
+// Plan collects the changes that applying a set of patches would make.
+// It is safe for concurrent use by planning tasks.
+type Plan struct {
+	mu      sync.Mutex
+	changes []*Change
+}
+
+func (p *Plan) add(c *Change) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.changes = append(p.changes, c.finish())
+}
+
+// Changes returns the planned changes sorted by resource name.
+func (p *Plan) Changes() []*Change {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changes := make([]*Change, len(p.changes))
+	copy(changes, p.changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// Counts returns the number of resources planned for each action.
+func (p *Plan) Counts() map[Action]int {
+	counts := make(map[Action]int)
+	for _, c := range p.Changes() {
+		counts[c.Action]++
+	}
+	return counts
+}
+
+// Pending returns true if applying the patches would change the registry.
+func (p *Plan) Pending() bool {
+	counts := p.Counts()
+	return counts[Create]+counts[Update]+counts[Delete] > 0
+}
+
+// Write prints a readable summary of the plan.
+func (p *Plan) Write(w io.Writer) error {
+	for _, c := range p.Changes() {
+		var err error
+		switch c.Action {
+		case Create:
+			_, err = fmt.Fprintf(w, "+ create %s\n", c.Name)
+		case Update:
+			_, err = fmt.Fprintf(w, "~ update %s\n", c.Name)
+		case Delete:
+			_, err = fmt.Fprintf(w, "- delete %s\n", c.Name)
+		default:
+			_, err = fmt.Fprintf(w, "  unchanged %s\n", c.Name)
+		}
+		if err != nil {
+			return err
+		}
+		for _, f := range c.Fields {
+			if c.Action == Create {
+				_, err = fmt.Fprintf(w, "    %s: %s\n", f.Field, f.Desired)
+			} else {
+				_, err = fmt.Fprintf(w, "    %s: %s -> %s\n", f.Field, f.Current, f.Desired)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	counts := p.Counts()
+	_, err := fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete, %d unchanged.\n",
+		counts[Create], counts[Update], counts[Delete], counts[NoChange])
+	return err
+}
+
+// PlanApply reads patches like Apply but, instead of writing them, compares them
+// with the current contents of the registry and records the changes in plan.
+func PlanApply(ctx context.Context, client connection.RegistryClient, path, parent string, recursive bool, taskQueue chan<- core.Task, plan *Plan) error {
+	files, err := patchFiles(path, recursive)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		bytes, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		taskQueue <- &planFileTask{
+			client: client,
+			path:   f,
+			source: patchSource(f, bytes),
+			parent: parent,
+			plan:   plan,
+		}
+	}
+	return nil
+}
+
+type planFileTask struct {
+	client connection.RegistryClient
+	path   string
+	source string // the file the patch was rendered from, or path
+	parent string
+	plan   *Plan
+}
+
+func (task *planFileTask) String() string {
+	return "plan file " + task.source
+}
+
+func (task *planFileTask) Run(ctx context.Context) error {
+	log.FromContext(ctx).Debugf("Planning %s", task.source)
+	bytes, err := os.ReadFile(task.path)
+	if err != nil {
+		return err
+	}
+	header, err := readHeader(bytes)
+	if errors.Is(err, errNotPatch) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	switch header.Kind {
+	case "Project":
+		var project models.Project
+		if err := yaml.Unmarshal(bytes, &project); err != nil {
+			return err
+		}
+		return planProjectPatch(ctx, &project, task.parent, task.plan)
+	case "API":
+		var api models.Api
+		if err := yaml.Unmarshal(bytes, &api); err != nil {
+			return err
+		}
+		return planApiPatch(ctx, task.client, &api, task.parent, filepath.Dir(task.path), task.plan)
+	default: // for everything else, try an artifact type
+		var artifact models.Artifact
+		if err := yaml.Unmarshal(bytes, &artifact); err != nil {
+			return err
+		}
+		return planArtifactPatch(ctx, task.client, &artifact, task.parent, true, task.plan)
+	}
+}
+
+// notFound returns true if err is a NotFound error and false for nil errors.
+func notFound(err error) bool {
+	return err != nil && status.Code(err) == codes.NotFound
+}
+
+func planProjectPatch(ctx context.Context, project *models.Project, parent string, plan *Plan) error {
+	location, err := names.ParseLocation(parent)
+	if err != nil {
+		return err
+	}
+	client, err := connection.NewAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	projectName := location.Project()
+	current, err := client.GetProject(ctx, &rpc.GetProjectRequest{Name: projectName.String()})
+	if err != nil && !notFound(err) {
+		return err
+	}
+	change := &Change{Name: projectName.String()}
+	if notFound(err) {
+		change.Action = Create
+	}
+	change.compare("display_name", current.GetDisplayName(), project.Data.DisplayName)
+	change.compare("description", current.GetDescription(), project.Data.Description)
+	plan.add(change)
+	return nil
+}
+
+func planApiPatch(ctx context.Context, client connection.RegistryClient, api *models.Api, parent, dir string, plan *Plan) error {
+	location, err := names.ParseLocation(parent)
+	if err != nil {
+		return err
+	}
+	apiName := location.Api(api.Metadata.Name)
+	current, err := client.GetApi(ctx, &rpc.GetApiRequest{Name: apiName.String()})
+	if notFound(err) {
+		current = &rpc.Api{}
+	} else if err != nil {
+		return err
+	}
+	exists := err == nil
+	change := &Change{Name: apiName.String()}
+	if !exists {
+		change.Action = Create
+	}
+	change.compare("display_name", current.DisplayName, api.Data.DisplayName)
+	change.compare("description", current.Description, api.Data.Description)
+	change.compare("availability", current.Availability, api.Data.Availability)
+	recommendedVersion, err := relativeVersionName(apiName, current.RecommendedVersion)
+	if err != nil {
+		return err
+	}
+	change.compare("recommended_version", recommendedVersion, api.Data.RecommendedVersion)
+	recommendedDeployment, err := relativeDeploymentName(apiName, current.RecommendedDeployment)
+	if err != nil {
+		return err
+	}
+	change.compare("recommended_deployment", recommendedDeployment, api.Data.RecommendedDeployment)
+	change.compareMap("labels", current.Labels, api.Metadata.Labels)
+	change.compareMap("annotations", current.Annotations, api.Metadata.Annotations)
+	plan.add(change)
+
+	for _, version := range api.Data.ApiVersions {
+		if err := planApiVersionPatch(ctx, client, version, apiName, dir, exists, plan); err != nil {
+			return err
+		}
+	}
+	for _, deployment := range api.Data.ApiDeployments {
+		if err := planApiDeploymentPatch(ctx, client, deployment, apiName, exists, plan); err != nil {
+			return err
+		}
+	}
+	for _, artifact := range api.Data.Artifacts {
+		if err := planArtifactPatch(ctx, client, artifact, apiName.String(), exists, plan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func planApiVersionPatch(ctx context.Context, client connection.RegistryClient, version *models.ApiVersion, apiName names.Api, dir string, parentExists bool, plan *Plan) error {
+	versionName := apiName.Version(version.Metadata.Name)
+	current, exists, err := getIfParentExists(parentExists, func() (*rpc.ApiVersion, error) {
+		return client.GetApiVersion(ctx, &rpc.GetApiVersionRequest{Name: versionName.String()})
+	})
+	if err != nil {
+		return err
+	}
+	change := &Change{Name: versionName.String()}
+	if !exists {
+		change.Action = Create
+	}
+	change.compare("display_name", current.GetDisplayName(), version.Data.DisplayName)
+	change.compare("description", current.GetDescription(), version.Data.Description)
+	change.compare("state", current.GetState(), version.Data.State)
+	change.compareMap("labels", current.GetLabels(), version.Metadata.Labels)
+	change.compareMap("annotations", current.GetAnnotations(), version.Metadata.Annotations)
+	plan.add(change)
+
+	for _, spec := range version.Data.ApiSpecs {
+		if err := planApiSpecPatch(ctx, client, spec, versionName, dir, exists, plan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func planApiSpecPatch(ctx context.Context, client connection.RegistryClient, spec *models.ApiSpec, versionName names.Version, dir string, parentExists bool, plan *Plan) error {
+	specName := versionName.Spec(spec.Metadata.Name)
+	current, exists, err := getIfParentExists(parentExists, func() (*rpc.ApiSpec, error) {
+		return client.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: specName.String()})
+	})
+	if err != nil {
+		return err
+	}
+	change := &Change{Name: specName.String()}
+	if !exists {
+		change.Action = Create
+	}
+	change.compare("filename", current.GetFilename(), spec.Data.FileName)
+	change.compare("description", current.GetDescription(), spec.Data.Description)
+	change.compare("mime_type", current.GetMimeType(), spec.Data.MimeType)
+	change.compare("source_uri", current.GetSourceUri(), spec.Data.SourceURI)
+	change.compareMap("labels", current.GetLabels(), spec.Metadata.Labels)
+	change.compareMap("annotations", current.GetAnnotations(), spec.Metadata.Annotations)
+	contents, err := specContents(spec, dir)
+	if err != nil {
+		return err
+	}
+	if contents != nil {
+		hash, err := contentsHash(spec.Data.MimeType, contents)
+		if err != nil {
+			return err
+		}
+		change.compareHash("contents", current.GetHash(), hash)
+	}
+	plan.add(change)
+	return nil
+}
+
+func planApiDeploymentPatch(ctx context.Context, client connection.RegistryClient, deployment *models.ApiDeployment, apiName names.Api, parentExists bool, plan *Plan) error {
+	deploymentName := apiName.Deployment(deployment.Metadata.Name)
+	current, exists, err := getIfParentExists(parentExists, func() (*rpc.ApiDeployment, error) {
+		return client.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: deploymentName.String()})
+	})
+	if err != nil {
+		return err
+	}
+	change := &Change{Name: deploymentName.String()}
+	if !exists {
+		change.Action = Create
+	}
+	change.compare("display_name", current.GetDisplayName(), deployment.Data.DisplayName)
+	change.compare("description", current.GetDescription(), deployment.Data.Description)
+	change.compare("endpoint_uri", current.GetEndpointUri(), deployment.Data.EndpointURI)
+	change.compare("external_channel_uri", current.GetExternalChannelUri(), deployment.Data.ExternalChannelURI)
+	change.compare("intended_audience", current.GetIntendedAudience(), deployment.Data.IntendedAudience)
+	change.compare("access_guidance", current.GetAccessGuidance(), deployment.Data.AccessGuidance)
+	change.compare("api_spec_revision", relativeSpecRevisionName(apiName, current.GetApiSpecRevision()), deployment.Data.ApiSpecRevision)
+	change.compareMap("labels", current.GetLabels(), deployment.Metadata.Labels)
+	change.compareMap("annotations", current.GetAnnotations(), deployment.Metadata.Annotations)
+	plan.add(change)
+	return nil
+}
+
+func planArtifactPatch(ctx context.Context, client connection.RegistryClient, artifact *models.Artifact, parent string, parentExists bool, plan *Plan) error {
+	name := fmt.Sprintf("%s/artifacts/%s", parent, artifact.Metadata.Name)
+	current, exists, err := getIfParentExists(parentExists, func() (*rpc.Artifact, error) {
+		return client.GetArtifact(ctx, &rpc.GetArtifactRequest{Name: name})
+	})
+	if err != nil {
+		return err
+	}
+	contents, err := artifactContents(artifact)
+	if err != nil {
+		return err
+	}
+	hash, err := contentsHash("", contents)
+	if err != nil {
+		return err
+	}
+	change := &Change{Name: name}
+	if !exists {
+		change.Action = Create
+	}
+	change.compare("mime_type", current.GetMimeType(), MimeTypeForKind(artifact.Kind))
+	change.compareHash("contents", current.GetHash(), hash)
+	plan.add(change)
+	return nil
+}
+
+// getIfParentExists gets a resource unless its parent is known to be missing.
+// It returns a nil resource and false if the resource doesn't exist.
+func getIfParentExists[T any](parentExists bool, get func() (*T, error)) (*T, bool, error) {
+	if !parentExists {
+		return nil, false, nil
+	}
+	current, err := get()
+	if notFound(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return current, true, nil
+}
+
+// contentsHash computes a hash of contents the same way that the registry
+// server does, so planned contents can be compared with stored contents.
+func contentsHash(mimeType string, contents []byte) (string, error) {
+	var r io.Reader = bytes.NewReader(contents)
+	if strings.Contains(mimeType, "+gzip") && len(contents) > 0 {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return "", err
+		}
+		r = zr
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", err
+	}
+	if n == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
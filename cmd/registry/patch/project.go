@@ -15,30 +15,56 @@
 package patch
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
 
 	"github.com/apigee/registry/cmd/registry/core"
 	"github.com/apigee/registry/gapic"
 	"github.com/apigee/registry/log"
 	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/pkg/models"
 	"github.com/apigee/registry/rpc"
 	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
 )
 
+// ExportOptions control what is included in exported projects.
+type ExportOptions struct {
+	// AdminClient is used to export the project's own fields.
+	// If it is nil, only the contents of the project are exported.
+	AdminClient connection.AdminClient
+	// Contents causes spec contents to be written next to the API YAML files.
+	Contents bool
+}
+
 // ExportProject writes a project into a directory of YAML files.
-func ExportProject(ctx context.Context, client *gapic.RegistryClient, projectName names.Project, taskQueue chan<- core.Task) error {
+func ExportProject(ctx context.Context, client *gapic.RegistryClient, projectName names.Project, opts ExportOptions, taskQueue chan<- core.Task) error {
+	if err := os.MkdirAll(projectName.ProjectID, 0777); err != nil {
+		return err
+	}
+	if opts.AdminClient != nil {
+		if err := exportProjectPatch(ctx, opts.AdminClient, projectName); err != nil {
+			return err
+		}
+	}
+
 	apisDir := fmt.Sprintf("%s/apis", projectName.ProjectID)
 	if err := os.MkdirAll(apisDir, 0777); err != nil {
 		return err
 	}
 	err := core.ListAPIs(ctx, client, projectName.Api(""), "", func(message *rpc.Api) error {
 		taskQueue <- &exportAPITask{
-			client:  client,
-			message: message,
-			dir:     apisDir,
+			client:   client,
+			message:  message,
+			dir:      apisDir,
+			contents: opts.Contents,
 		}
 		return nil
 	})
@@ -61,10 +87,119 @@ func ExportProject(ctx context.Context, client *gapic.RegistryClient, projectNam
 	})
 }
 
+func newProject(message *rpc.Project) (*models.Project, error) {
+	projectName, err := names.ParseProject(message.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Project{
+		Header: models.Header{
+			ApiVersion: RegistryV1,
+			Kind:       "Project",
+			Metadata: models.Metadata{
+				Name: projectName.ProjectID,
+			},
+		},
+		Data: models.ProjectData{
+			DisplayName: message.DisplayName,
+			Description: message.Description,
+		},
+	}, nil
+}
+
+// exportProjectPatch writes the project's own fields to project.yaml.
+// Registries without the Admin service are exported without it.
+func exportProjectPatch(ctx context.Context, client connection.AdminClient, projectName names.Project) error {
+	message, err := client.GetProject(ctx, &rpc.GetProjectRequest{Name: projectName.String()})
+	if status.Code(err) == codes.Unimplemented {
+		log.FromContext(ctx).Warnf("Skipped %s: %s", projectName, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	project, err := newProject(message)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if err := yamlEncoder(&b).Encode(project); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Infof("Exported %s", projectName)
+	return os.WriteFile(filepath.Join(projectName.ProjectID, "project.yaml"), b.Bytes(), 0644)
+}
+
+// applyProjectPatch applies a project patch to the project that contains parent.
+// The name in the patch is ignored so that exported projects can be applied to other projects.
+func applyProjectPatch(ctx context.Context, bytes []byte, parent string) error {
+	var project models.Project
+	if err := yaml.Unmarshal(bytes, &project); err != nil {
+		return err
+	}
+	location, err := names.ParseLocation(parent)
+	if err != nil {
+		return err
+	}
+	client, err := connection.NewAdminClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	_, err = client.UpdateProject(ctx, &rpc.UpdateProjectRequest{
+		Project: &rpc.Project{
+			Name:        location.Project().String(),
+			DisplayName: project.Data.DisplayName,
+			Description: project.Data.Description,
+		},
+		AllowMissing: true,
+	})
+	return err
+}
+
+// exportSpecContents writes the contents of an API's specs to files in a
+// directory named for the API and refers to them with relative file: URIs.
+func exportSpecContents(ctx context.Context, client connection.RegistryClient, apiName names.Api, api *models.Api, dir string) error {
+	// The listed specs include the sizes and revisions used to read their contents.
+	specs := make(map[string]*rpc.ApiSpec)
+	if err := core.ListSpecs(ctx, client, apiName.Version("-").Spec("-"), "", func(spec *rpc.ApiSpec) error {
+		specs[spec.GetName()] = spec
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, version := range api.Data.ApiVersions {
+		for _, spec := range version.Data.ApiSpecs {
+			specName := apiName.Version(version.Metadata.Name).Spec(spec.Metadata.Name)
+			listed, ok := specs[specName.String()]
+			if !ok {
+				return fmt.Errorf("%s was not found", specName)
+			}
+			body, err := core.GetSpecContents(ctx, client, listed)
+			if err != nil {
+				return err
+			}
+			if len(body.GetData()) == 0 {
+				continue
+			}
+			rel := path.Join(apiName.ApiID, version.Metadata.Name, spec.Metadata.Name)
+			filename := filepath.Join(dir, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filename, body.GetData(), 0644); err != nil {
+				return err
+			}
+			spec.Data.ContentsURI = "file:" + rel
+		}
+	}
+	return nil
+}
+
 type exportAPITask struct {
-	client  connection.RegistryClient
-	message *rpc.Api
-	dir     string
+	client   connection.RegistryClient
+	message  *rpc.Api
+	dir      string
+	contents bool
 }
 
 func (task *exportAPITask) String() string {
@@ -72,13 +207,26 @@ func (task *exportAPITask) String() string {
 }
 
 func (task *exportAPITask) Run(ctx context.Context) error {
-	bytes, header, err := ExportAPI(ctx, task.client, task.message)
+	api, err := newApi(ctx, task.client, task.message)
 	if err != nil {
 		return err
 	}
+	if task.contents {
+		apiName, err := names.ParseApi(task.message.Name)
+		if err != nil {
+			return err
+		}
+		if err := exportSpecContents(ctx, task.client, apiName, api, task.dir); err != nil {
+			return err
+		}
+	}
+	var b bytes.Buffer
+	if err := yamlEncoder(&b).Encode(api); err != nil {
+		return err
+	}
 	log.FromContext(ctx).Infof("Exported %s", task.message.Name)
-	filename := fmt.Sprintf("%s/%s.yaml", task.dir, header.Metadata.Name)
-	return os.WriteFile(filename, bytes, 0644)
+	filename := fmt.Sprintf("%s/%s.yaml", task.dir, api.Metadata.Name)
+	return os.WriteFile(filename, b.Bytes(), 0644)
 }
 
 type exportArtifactTask struct {
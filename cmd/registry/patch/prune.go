@@ -0,0 +1,274 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package patch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apigee/registry/cmd/registry/core"
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/pkg/connection"
+	"github.com/apigee/registry/pkg/models"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"gopkg.in/yaml.v3"
+)
+
+// ManagedByLabel is the label that identifies the tool or team that manages a resource.
+// Pruning never deletes resources that are labeled with a different manager.
+const ManagedByLabel = "managed-by"
+
+// ErrUnrestrictedPrune is returned when pruning is requested without a selector or manager.
+var ErrUnrestrictedPrune = errors.New("pruning requires a label selector or a " + ManagedByLabel + " value")
+
+// ErrNothingToKeep is returned when pruning is requested with patches that describe no APIs.
+// Pruning with them would delete every API in the parent.
+var ErrNothingToKeep = errors.New("the patches describe no APIs, refusing to prune every API in the parent")
+
+// PruneOptions control which resources can be deleted by pruning.
+type PruneOptions struct {
+	// Selector restricts pruning to resources that have all of these labels.
+	Selector map[string]string
+	// Manager is the managed-by label value of resources that may be pruned.
+	// Resources without a managed-by label may always be pruned.
+	Manager string
+}
+
+// Validate returns an error if the options would allow every resource to be pruned.
+func (o PruneOptions) Validate() error {
+	if len(o.Selector) == 0 && o.Manager == "" {
+		return ErrUnrestrictedPrune
+	}
+	return nil
+}
+
+// prunable returns true if a resource with the specified labels may be deleted.
+func (o PruneOptions) prunable(ctx context.Context, name string, labels map[string]string) bool {
+	for k, v := range o.Selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	if manager, ok := labels[ManagedByLabel]; ok && manager != o.Manager {
+		log.FromContext(ctx).Warnf("Not pruning %s: it is managed by %q", name, manager)
+		return false
+	}
+	return true
+}
+
+// ParseSelector parses a label selector of the form "key1=value1,key2=value2".
+func ParseSelector(selector string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if selector == "" {
+		return labels, nil
+	}
+	for _, term := range strings.Split(selector, ",") {
+		kv := strings.SplitN(term, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid selector %q, must be a list of key=value pairs", selector)
+		}
+		labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return labels, nil
+}
+
+// PlanPrune treats the patches at path as the complete contents of parent and records
+// the deletion of every API, version, spec, deployment, and artifact that they don't describe.
+// Artifacts are only pruned if their type can be represented in a patch.
+// Pruning is refused if the options don't restrict it or the patches describe no APIs.
+func PlanPrune(ctx context.Context, client connection.RegistryClient, path, parent string, recursive bool, opts PruneOptions, plan *Plan) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	desired, err := patchedNames(path, parent, recursive)
+	if err != nil {
+		return err
+	}
+	if !describesApis(desired) {
+		return ErrNothingToKeep
+	}
+	location, err := names.ParseLocation(parent)
+	if err != nil {
+		return err
+	}
+	if err := core.ListAPIs(ctx, client, location.Api("-"), "", func(api *rpc.Api) error {
+		if !desired[api.Name] {
+			if opts.prunable(ctx, api.Name, api.Labels) {
+				plan.add(&Change{Action: Delete, Name: api.Name})
+			}
+			return nil
+		}
+		return planPruneApi(ctx, client, api.Name, desired, opts, plan)
+	}); err != nil {
+		return err
+	}
+	return planPruneArtifacts(ctx, client, parent, desired, opts, plan)
+}
+
+func planPruneApi(ctx context.Context, client connection.RegistryClient, name string, desired map[string]bool, opts PruneOptions, plan *Plan) error {
+	apiName, err := names.ParseApi(name)
+	if err != nil {
+		return err
+	}
+	if err := core.ListVersions(ctx, client, apiName.Version("-"), "", func(version *rpc.ApiVersion) error {
+		if !desired[version.Name] {
+			if opts.prunable(ctx, version.Name, version.Labels) {
+				plan.add(&Change{Action: Delete, Name: version.Name})
+			}
+			return nil
+		}
+		versionName, err := names.ParseVersion(version.Name)
+		if err != nil {
+			return err
+		}
+		return core.ListSpecs(ctx, client, versionName.Spec("-"), "", func(spec *rpc.ApiSpec) error {
+			if !desired[spec.Name] && opts.prunable(ctx, spec.Name, spec.Labels) {
+				plan.add(&Change{Action: Delete, Name: spec.Name})
+			}
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	if err := core.ListDeployments(ctx, client, apiName.Deployment("-"), "", func(deployment *rpc.ApiDeployment) error {
+		if !desired[deployment.Name] && opts.prunable(ctx, deployment.Name, deployment.Labels) {
+			plan.add(&Change{Action: Delete, Name: deployment.Name})
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return planPruneArtifacts(ctx, client, name, desired, opts, plan)
+}
+
+func planPruneArtifacts(ctx context.Context, client connection.RegistryClient, parent string, desired map[string]bool, opts PruneOptions, plan *Plan) error {
+	artifactName, err := names.ParseArtifact(parent + "/artifacts/-")
+	if err != nil {
+		return err
+	}
+	return core.ListArtifacts(ctx, client, artifactName, "", false, func(artifact *rpc.Artifact) error {
+		if desired[artifact.Name] {
+			return nil
+		}
+		// Artifacts that can't be written as patches or that are maintained by
+		// the server are never managed by apply.
+		if _, err := protoMessageForMimeType(artifact.MimeType); err != nil {
+			return nil
+		}
+		if name, err := names.ParseArtifact(artifact.Name); err == nil && name.Reserved() {
+			return nil
+		}
+		// Artifacts have no labels, so they can only be selected by an empty selector.
+		if opts.prunable(ctx, artifact.Name, nil) {
+			plan.add(&Change{Action: Delete, Name: artifact.Name})
+		}
+		return nil
+	})
+}
+
+// patchedNames returns the names of all resources described by the patches at path.
+func patchedNames(path, parent string, recursive bool) (map[string]bool, error) {
+	location, err := names.ParseLocation(parent)
+	if err != nil {
+		return nil, err
+	}
+	files, err := patchFiles(path, recursive)
+	if err != nil {
+		return nil, err
+	}
+	desired := make(map[string]bool)
+	for _, f := range files {
+		bytes, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		header, err := readHeader(bytes)
+		if errors.Is(err, errNotPatch) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if header.Kind == "Project" {
+			continue
+		} else if header.Kind != "API" {
+			desired[fmt.Sprintf("%s/artifacts/%s", parent, header.Metadata.Name)] = true
+			continue
+		}
+		var api models.Api
+		if err := yaml.Unmarshal(bytes, &api); err != nil {
+			return nil, err
+		}
+		apiName := location.Api(api.Metadata.Name)
+		desired[apiName.String()] = true
+		for _, version := range api.Data.ApiVersions {
+			versionName := apiName.Version(version.Metadata.Name)
+			desired[versionName.String()] = true
+			for _, spec := range version.Data.ApiSpecs {
+				desired[versionName.Spec(spec.Metadata.Name).String()] = true
+			}
+		}
+		for _, deployment := range api.Data.ApiDeployments {
+			desired[apiName.Deployment(deployment.Metadata.Name).String()] = true
+		}
+		for _, artifact := range api.Data.Artifacts {
+			desired[apiName.Artifact(artifact.Metadata.Name).String()] = true
+		}
+	}
+	return desired, nil
+}
+
+// describesApis returns true if any of the desired names is an API.
+func describesApis(desired map[string]bool) bool {
+	for name := range desired {
+		if _, err := names.ParseApi(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Prune deletes the resources that a plan would delete.
+func Prune(ctx context.Context, client connection.RegistryClient, plan *Plan) error {
+	for _, c := range plan.Changes() {
+		if c.Action != Delete {
+			continue
+		}
+		log.FromContext(ctx).Infof("Deleting %s", c.Name)
+		if err := deleteResource(ctx, client, c.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteResource deletes a resource with everything that it contains.
+func deleteResource(ctx context.Context, client connection.RegistryClient, name string) error {
+	if _, err := names.ParseApi(name); err == nil {
+		return client.DeleteApi(ctx, &rpc.DeleteApiRequest{Name: name, Force: true})
+	} else if _, err := names.ParseVersion(name); err == nil {
+		return client.DeleteApiVersion(ctx, &rpc.DeleteApiVersionRequest{Name: name, Force: true})
+	} else if _, err := names.ParseSpec(name); err == nil {
+		return client.DeleteApiSpec(ctx, &rpc.DeleteApiSpecRequest{Name: name, Force: true})
+	} else if _, err := names.ParseDeployment(name); err == nil {
+		return client.DeleteApiDeployment(ctx, &rpc.DeleteApiDeploymentRequest{Name: name, Force: true})
+	} else if _, err := names.ParseArtifact(name); err == nil {
+		return client.DeleteArtifact(ctx, &rpc.DeleteArtifactRequest{Name: name})
+	}
+	return fmt.Errorf("unsupported resource name %q", name)
+}
@@ -0,0 +1,355 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package patch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenderOptions describe how patches are rendered before they are applied.
+type RenderOptions struct {
+	// Overlay is a directory of partial patches that are merged into the base patches.
+	// Overlay patches are matched with base patches by kind and name; those that
+	// match no base patch are added as new patches.
+	Overlay string
+	// Values are substituted for ${NAME} references in patch values.
+	Values map[string]string
+	// Lookup is called for references that aren't in Values. It is typically os.LookupEnv.
+	Lookup func(string) (string, bool)
+}
+
+// RenderedPatch is a patch produced by rendering.
+type RenderedPatch struct {
+	// Path is the path of the patch relative to the base or overlay directory.
+	Path string
+	// Bytes is the YAML representation of the rendered patch.
+	Bytes []byte
+	// Source is the path of the file that the patch was read from.
+	Source string
+}
+
+// ReadValues reads a values file, which is a YAML mapping of names to values.
+func ReadValues(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("invalid values file %s: %s", path, err)
+	}
+	return values, nil
+}
+
+// renderDocument is a patch being rendered.
+type renderDocument struct {
+	path   string
+	source string     // file that the patch was read from
+	node   *yaml.Node // mapping node of the patch
+}
+
+// key identifies the resource described by a document.
+func (d *renderDocument) key() string {
+	kind := mappingValue(d.node, "kind")
+	name := mappingValue(mappingValue(d.node, "metadata"), "name")
+	return scalar(kind) + "/" + scalar(name)
+}
+
+// Render reads the patches at path, merges in overlays, and substitutes values.
+// Patches are returned in path order.
+func Render(path string, recursive bool, opts RenderOptions) ([]*RenderedPatch, error) {
+	base, err := readDocuments(path, recursive)
+	if err != nil {
+		return nil, err
+	}
+	docs := base
+	if opts.Overlay != "" {
+		overlays, err := readDocuments(opts.Overlay, true)
+		if err != nil {
+			return nil, err
+		}
+		index := make(map[string]*renderDocument)
+		for _, d := range base {
+			index[d.key()] = d
+		}
+		for _, o := range overlays {
+			if d, ok := index[o.key()]; ok {
+				mergeNode(d.node, o.node)
+			} else {
+				o.path = filepath.Join("overlay", o.path)
+				docs = append(docs, o)
+			}
+		}
+	}
+	patches := make([]*RenderedPatch, 0, len(docs))
+	for _, d := range docs {
+		if err := substitute(d.node, opts); err != nil {
+			return nil, fmt.Errorf("%s: %s", d.source, err)
+		}
+		var b bytes.Buffer
+		if err := yamlEncoder(&b).Encode(d.node); err != nil {
+			return nil, err
+		}
+		patches = append(patches, &RenderedPatch{Path: d.path, Bytes: b.Bytes(), Source: d.source})
+	}
+	sort.SliceStable(patches, func(i, j int) bool {
+		return patches[i].Path < patches[j].Path
+	})
+	return patches, nil
+}
+
+// WriteRendered writes rendered patches to a directory so that they can be applied.
+// Each file records the path of its source so that it can be reported in place
+// of the rendered file when the patch is applied.
+func WriteRendered(dir string, patches []*RenderedPatch) error {
+	for _, p := range patches {
+		name := filepath.Join(dir, p.Path)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		b := append([]byte(renderedFrom+p.Source+"\n"), p.Bytes...)
+		if err := os.WriteFile(name, b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderedFrom begins the first line of a rendered patch written by WriteRendered.
+const renderedFrom = "# Rendered from: "
+
+// patchSource returns the path of the file that a patch was rendered from,
+// or path if the patch was not rendered.
+func patchSource(path string, b []byte) string {
+	if !bytes.HasPrefix(b, []byte(renderedFrom)) {
+		return path
+	}
+	line := b[len(renderedFrom):]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	return string(line)
+}
+
+// PrintRendered writes rendered patches as a single stream of YAML documents.
+func PrintRendered(w io.Writer, patches []*RenderedPatch) error {
+	for i, p := range patches {
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# Source: %s\n", p.Path); err != nil {
+			return err
+		}
+		if _, err := w.Write(p.Bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDocuments reads the patches found at path.
+// Document paths are relative to path, or the file name if path is a file.
+// Relative file: URIs are rewritten so that they don't depend on the location of the patch.
+func readDocuments(path string, recursive bool) ([]*renderDocument, error) {
+	files, err := patchFiles(path, recursive)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*renderDocument, 0, len(files))
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := readHeader(b); errors.Is(err, errNotPatch) {
+			continue
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %s", f, err)
+		}
+		if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s: patches must be YAML mappings", f)
+		}
+		resolveFileURIs(doc.Content[0], filepath.Dir(f))
+		rel, err := filepath.Rel(path, f)
+		if err != nil || rel == "." {
+			rel = filepath.Base(f)
+		}
+		docs = append(docs, &renderDocument{path: rel, source: f, node: doc.Content[0]})
+	}
+	return docs, nil
+}
+
+// resolveFileURIs rewrites relative file: URIs in spec sources and contents.
+func resolveFileURIs(node *yaml.Node, dir string) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if (k.Value == "sourceURI" || k.Value == "contentsURI") && v.Kind == yaml.ScalarNode {
+				v.Value = resolveFileURI(v.Value, dir)
+			}
+		}
+	}
+	for _, n := range node.Content {
+		resolveFileURIs(n, dir)
+	}
+}
+
+// mergeNode merges an overlay into a base mapping node.
+// Mappings are merged recursively, null values remove fields, lists of named
+// resources are merged by name, and all other values are replaced.
+func mergeNode(base, overlay *yaml.Node) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		j := mappingIndex(base, key.Value)
+		switch {
+		case value.Tag == "!!null":
+			if j >= 0 {
+				base.Content = append(base.Content[:j], base.Content[j+2:]...)
+			}
+		case j < 0:
+			base.Content = append(base.Content, key, value)
+		case value.Kind == yaml.MappingNode && base.Content[j+1].Kind == yaml.MappingNode:
+			mergeNode(base.Content[j+1], value)
+		case value.Kind == yaml.SequenceNode && base.Content[j+1].Kind == yaml.SequenceNode && namedItems(value):
+			mergeNamedItems(base.Content[j+1], value)
+		default:
+			base.Content[j+1] = value
+		}
+	}
+}
+
+// mergeNamedItems merges a list of resources into another, matching them by metadata.name.
+func mergeNamedItems(base, overlay *yaml.Node) {
+	for _, item := range overlay.Content {
+		name := itemName(item)
+		merged := false
+		for _, b := range base.Content {
+			if itemName(b) == name {
+				mergeNode(b, item)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			base.Content = append(base.Content, item)
+		}
+	}
+}
+
+// namedItems returns true if every element of a list is a resource with a name.
+func namedItems(list *yaml.Node) bool {
+	for _, item := range list.Content {
+		if itemName(item) == "" {
+			return false
+		}
+	}
+	return len(list.Content) > 0
+}
+
+func itemName(item *yaml.Node) string {
+	if item.Kind != yaml.MappingNode {
+		return ""
+	}
+	return scalar(mappingValue(mappingValue(item, "metadata"), "name"))
+}
+
+// mappingIndex returns the index of a key in a mapping node, or -1 if it isn't present.
+func mappingIndex(node *yaml.Node, key string) int {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil if it isn't present.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(node, key); i >= 0 {
+		return node.Content[i+1]
+	}
+	return nil
+}
+
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// reference matches ${NAME} references and $$ escapes.
+var reference = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// substitute replaces references in the scalar values of a node tree.
+func substitute(node *yaml.Node, opts RenderOptions) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return nil
+		}
+		var missing []string
+		node.Value = reference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			if ref == "$$" {
+				return "$"
+			}
+			name := ref[2 : len(ref)-1]
+			if v, ok := opts.Values[name]; ok {
+				return v
+			}
+			if opts.Lookup != nil {
+				if v, ok := opts.Lookup(name); ok {
+					return v
+				}
+			}
+			missing = append(missing, name)
+			return ref
+		})
+		if len(missing) > 0 {
+			return fmt.Errorf("no value for %s", strings.Join(missing, ", "))
+		}
+	case yaml.MappingNode:
+		// Substitute values but not keys.
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := substitute(node.Content[i], opts); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, n := range node.Content {
+			if err := substitute(n, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/apigee/registry/pkg/models"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// idPattern matches valid resource IDs: up to 80 lowercase letters, numbers,
+// hyphens and periods that begin and end with a letter or number.
+const idPattern = "^[a-z0-9]([a-z0-9-.]{0,78}[a-z0-9])?$"
+
+// Schema is a JSON Schema describing patches or parts of patches.
+// Only the subset of JSON Schema needed to describe patches is supported.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // a string or a list of strings
+	Const                string             `json:"const,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false or a *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+
+	// artifact marks schemas of artifacts nested in other patches,
+	// whose data is validated with the schema of their kind.
+	artifact bool
+}
+
+// types returns the JSON types allowed by a schema.
+func (s *Schema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	default:
+		return nil
+	}
+}
+
+// SchemaKinds returns the kinds of patches that have schemas.
+func SchemaKinds() []string {
+	return append([]string{"API", "Project"}, artifactKinds()...)
+}
+
+// artifactKinds returns the sorted kinds of artifacts that can be represented in patches.
+func artifactKinds() []string {
+	kinds := make([]string, 0, len(artifactMessageTypes))
+	for k := range artifactMessageTypes {
+		kinds = append(kinds, kindForMimeType(k))
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// SchemaForKind returns the JSON Schema of patches of the specified kind.
+func SchemaForKind(kind string) (*Schema, error) {
+	var s *Schema
+	switch kind {
+	case "API":
+		s = modelSchema(reflect.TypeOf(models.Api{}))
+	case "Project":
+		s = modelSchema(reflect.TypeOf(models.Project{}))
+	default:
+		m, err := protoMessageForKind(kind)
+		if err != nil {
+			return nil, err
+		}
+		s = modelSchema(reflect.TypeOf(models.Artifact{}))
+		defs := make(map[string]*Schema)
+		data := messageSchema(m.ProtoReflect().Descriptor(), defs)
+		// The id and kind fields of artifact messages are taken from the header.
+		delete(data.Properties, "id")
+		delete(data.Properties, "kind")
+		s.Properties["data"] = data
+		s.artifact = false // data is validated by this schema
+		if len(defs) > 0 {
+			s.Definitions = defs
+		}
+	}
+	s.Schema = jsonSchemaDraft
+	s.Title = kind
+	s.Properties["apiVersion"] = &Schema{Type: "string", Const: RegistryV1}
+	s.Properties["kind"] = &Schema{Type: "string", Const: kind}
+	s.Required = []string{"apiVersion", "kind", "metadata"}
+	return s, nil
+}
+
+// MarshalSchema returns the indented JSON representation of a schema.
+func MarshalSchema(s *Schema) ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// SchemaFileName returns the name of the file that contains the schema of a kind.
+func SchemaFileName(kind string) string {
+	return strings.ToLower(kind) + ".json"
+}
+
+// WriteSchemas writes the schemas of all kinds to files in a directory.
+func WriteSchemas(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, kind := range SchemaKinds() {
+		s, err := SchemaForKind(kind)
+		if err != nil {
+			return err
+		}
+		b, err := MarshalSchema(s)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, SchemaFileName(kind)), b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var yamlNodeType = reflect.TypeOf(yaml.Node{})
+
+// modelSchema returns the schema of a type in the models package.
+func modelSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: modelSchema(t.Elem())}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: modelSchema(t.Elem())}
+	case reflect.Struct:
+		if t == yamlNodeType {
+			return &Schema{Type: "object"}
+		}
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		addModelFields(s, t)
+		switch t {
+		case reflect.TypeOf(models.Metadata{}):
+			s.Properties["name"].Pattern = idPattern
+			s.Required = []string{"name"}
+		case reflect.TypeOf(models.Artifact{}):
+			s.Properties["kind"] = &Schema{Type: "string", Enum: artifactKinds()}
+			s.Required = []string{"kind", "metadata"}
+			s.artifact = true
+		default:
+			if _, ok := s.Properties["metadata"]; ok {
+				s.Required = []string{"metadata"}
+			}
+		}
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// addModelFields adds the properties of the fields of a struct to a schema.
+func addModelFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("yaml"), ",")
+		if len(tag) > 1 && tag[1] == "inline" {
+			addModelFields(s, f.Type)
+			continue
+		}
+		name := tag[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		s.Properties[name] = modelSchema(f.Type)
+	}
+}
+
+// messageSchema returns the schema of the JSON representation of a protobuf message.
+// Nested messages are added to defs and referenced by their full names.
+func messageSchema(md protoreflect.MessageDescriptor, defs map[string]*Schema) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		var fs *Schema
+		switch {
+		case fd.IsMap():
+			fs = &Schema{Type: "object", AdditionalProperties: fieldSchema(fd.MapValue(), defs)}
+		case fd.IsList():
+			fs = &Schema{Type: "array", Items: fieldSchema(fd, defs)}
+		default:
+			fs = fieldSchema(fd, defs)
+		}
+		s.Properties[fd.JSONName()] = fs
+	}
+	return s
+}
+
+// fieldSchema returns the schema of a single value of a protobuf field.
+func fieldSchema(fd protoreflect.FieldDescriptor, defs map[string]*Schema) *Schema {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return &Schema{Type: "string"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &Schema{Type: "integer"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// 64-bit integers are represented as strings in JSON.
+		return &Schema{Type: []string{"integer", "string"}}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &Schema{Type: "number"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return &Schema{Type: "string", Enum: names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		md := fd.Message()
+		name := string(md.FullName())
+		switch name {
+		case "google.protobuf.Timestamp", "google.protobuf.Duration", "google.protobuf.FieldMask":
+			return &Schema{Type: "string"}
+		}
+		if strings.HasPrefix(name, "google.protobuf.") {
+			return &Schema{} // other well-known types may have any JSON value
+		}
+		if _, ok := defs[name]; !ok {
+			defs[name] = nil // mark as visited to support recursive messages
+			defs[name] = messageSchema(md, defs)
+		}
+		return &Schema{Ref: "#/definitions/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// definitionName returns the name of the definition referenced by a schema.
+func definitionName(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "#/definitions/") {
+		return "", fmt.Errorf("unsupported reference %q", ref)
+	}
+	return strings.TrimPrefix(ref, "#/definitions/"), nil
+}
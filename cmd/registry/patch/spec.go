@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -59,7 +60,8 @@ func applyApiSpecPatch(
 	ctx context.Context,
 	client connection.RegistryClient,
 	spec *models.ApiSpec,
-	parent string) error {
+	parent string,
+	dir string) error {
 	name := fmt.Sprintf("%s/specs/%s", parent, spec.Metadata.Name)
 	req := &rpc.UpdateApiSpecRequest{
 		ApiSpec: &rpc.ApiSpec{
@@ -74,66 +76,99 @@ func applyApiSpecPatch(
 		AllowMissing: true,
 	}
 	// TODO: verify mime type
-	if spec.Data.SourceURI != "" {
-		u, err := url.ParseRequestURI(spec.Data.SourceURI)
+	contents, err := specContents(spec, dir)
+	if err != nil {
+		return err
+	}
+	req.ApiSpec.Contents = contents
+	_, err = client.UpdateApiSpec(ctx, req)
+	return err
+}
+
+// specContents loads the contents of a spec from its contents or source URI.
+// It returns nil if the spec has neither or the scheme is not supported.
+// Relative file: URIs are resolved from dir, the directory containing the patch.
+func specContents(spec *models.ApiSpec, dir string) ([]byte, error) {
+	uri := spec.Data.SourceURI
+	if spec.Data.ContentsURI != "" {
+		uri = spec.Data.ContentsURI
+	}
+	if uri == "" {
+		return nil, nil
+	}
+	u, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		resp, err := http.Get(uri)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		switch u.Scheme {
-		case "http", "https":
-			resp, err := http.Get(spec.Data.SourceURI)
-			if err != nil {
-				return err
-			}
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return err
-			}
-			if strings.Contains(spec.Data.MimeType, "+gzip") {
-				body, err = core.GZippedBytes(body)
-				if err != nil {
-					return err
-				}
-			}
-			req.ApiSpec.Contents = body
-		case "file":
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(spec.Data.MimeType, "+gzip") {
+			return core.GZippedBytes(body)
+		}
+		return body, nil
+	case "file":
+		var path string
+		if u.Opaque != "" {
+			// Relative paths such as file:v1/openapi.yaml are relative to the patch.
+			path = filepath.Join(dir, filepath.FromSlash(u.Opaque))
+		} else {
 			// Remove leading slash from path.
 			// We expect to load from paths relative to the working directory,
 			// but users can add an additional slash to specify a global path.
-			path := strings.TrimPrefix(u.Path, "/")
-			info, err := os.Stat(path)
-			if err != nil {
-				return err
-			}
-			if info.IsDir() {
-				recursive := true
-				d := u.Query()["recursive"]
-				if len(d) > 0 {
-					recursive, err = strconv.ParseBool(d[0])
-					if err != nil {
-						return err
-					}
-				}
-				contents, err := core.ZipArchiveOfPath(path, "", recursive)
-				if err != nil {
-					return err
-				}
-				req.ApiSpec.Contents = contents.Bytes()
-			} else {
-				body, err := os.ReadFile(path)
+			path = strings.TrimPrefix(u.Path, "/")
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			recursive := true
+			d := u.Query()["recursive"]
+			if len(d) > 0 {
+				recursive, err = strconv.ParseBool(d[0])
 				if err != nil {
-					return err
+					return nil, err
 				}
-				if strings.Contains(spec.Data.MimeType, "+gzip") {
-					body, err = core.GZippedBytes(body)
-					if err != nil {
-						return err
-					}
-				}
-				req.ApiSpec.Contents = body
 			}
+			contents, err := core.ZipArchiveOfPath(path, "", recursive)
+			if err != nil {
+				return nil, err
+			}
+			return contents.Bytes(), nil
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
 		}
+		if strings.Contains(spec.Data.MimeType, "+gzip") {
+			return core.GZippedBytes(body)
+		}
+		return body, nil
 	}
-	_, err := client.UpdateApiSpec(ctx, req)
-	return err
+	return nil, nil
+}
+
+// resolveFileURI rewrites a relative file: URI, which is relative to the directory
+// of its patch, so that it is relative to the working directory instead.
+// Other URIs are returned unchanged.
+func resolveFileURI(uri, dir string) string {
+	u, err := url.ParseRequestURI(uri)
+	if err != nil || u.Scheme != "file" || u.Opaque == "" {
+		return uri
+	}
+	path := filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(u.Opaque)))
+	resolved := "file:///" + path
+	if u.RawQuery != "" {
+		resolved += "?" + u.RawQuery
+	}
+	return resolved
 }
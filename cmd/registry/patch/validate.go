@@ -0,0 +1,251 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package patch
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError describes a problem found in a patch.
+type ValidationError struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Message)
+}
+
+// Validate checks the patches at path against the schemas of their kinds.
+// It doesn't contact a registry. Files that aren't patches are skipped.
+func Validate(path string, recursive bool) ([]*ValidationError, error) {
+	files, err := patchFiles(path, recursive)
+	if err != nil {
+		return nil, err
+	}
+	var problems []*ValidationError
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, ValidateBytes(f, b)...)
+	}
+	return problems, nil
+}
+
+// yamlErrorLine matches the line numbers in errors returned by the YAML parser.
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// ValidateBytes checks a single patch. The path is only used to report problems.
+func ValidateBytes(path string, b []byte) []*ValidationError {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		problem := &ValidationError{Path: path, Line: 1, Message: err.Error()}
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			problem.Line, _ = strconv.Atoi(m[1])
+			problem.Message = m[2]
+		}
+		return []*ValidationError{problem}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 {
+		return nil // empty files aren't patches
+	}
+	root := doc.Content[0]
+	v := &validator{path: path}
+	if root.Kind != yaml.MappingNode {
+		v.errorf(root, "", "patches must be YAML mappings")
+		return v.problems
+	}
+	apiVersion, kind := mappingValue(root, "apiVersion"), mappingValue(root, "kind")
+	if apiVersion == nil && kind == nil {
+		return nil // not a patch
+	}
+	if kind == nil {
+		v.errorf(root, "", "missing required field \"kind\"")
+		return v.problems
+	}
+	s, err := SchemaForKind(kind.Value)
+	if err != nil {
+		v.errorf(kind, "kind", "unknown kind %q, expected one of %s", kind.Value, strings.Join(SchemaKinds(), ", "))
+		return v.problems
+	}
+	v.defs = s.Definitions
+	v.validate(root, s, "")
+	return v.problems
+}
+
+// validator checks YAML nodes against schemas and collects the problems that it finds.
+type validator struct {
+	path     string
+	defs     map[string]*Schema // definitions of the schema being checked
+	problems []*ValidationError
+}
+
+func (v *validator) errorf(node *yaml.Node, field, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if field != "" {
+		message = field + ": " + message
+	}
+	v.problems = append(v.problems, &ValidationError{
+		Path:    v.path,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: message,
+	})
+}
+
+// validate checks a node against a schema. The field is the dotted path
+// of the node in the patch and is used to report problems.
+func (v *validator) validate(node *yaml.Node, s *Schema, field string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if s.Ref != "" {
+		name, err := definitionName(s.Ref)
+		if err != nil || v.defs[name] == nil {
+			v.errorf(node, field, "schema has unresolved reference %q", s.Ref)
+			return
+		}
+		s = v.defs[name]
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return // null values leave fields unset
+	}
+	if types := s.types(); len(types) > 0 && !matchesType(node, types) {
+		v.errorf(node, field, "expected %s, got %s", strings.Join(types, " or "), nodeType(node))
+		return
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, value := node.Content[i], node.Content[i+1]
+			f := k.Value
+			if field != "" {
+				f = field + "." + k.Value
+			}
+			if p, ok := s.Properties[k.Value]; ok {
+				v.validate(value, p, f)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case *Schema:
+				v.validate(value, additional, f)
+			case bool:
+				if !additional {
+					v.errorf(k, field, "unknown field %q", k.Value)
+				}
+			}
+		}
+		for _, r := range s.Required {
+			if mappingValue(node, r) == nil {
+				v.errorf(node, field, "missing required field %q", r)
+			}
+		}
+		if s.artifact {
+			v.validateArtifactData(node, field)
+		}
+	case yaml.SequenceNode:
+		if s.Items != nil {
+			for i, item := range node.Content {
+				v.validate(item, s.Items, fmt.Sprintf("%s[%d]", field, i))
+			}
+		}
+	case yaml.ScalarNode:
+		if s.Const != "" && node.Value != s.Const {
+			v.errorf(node, field, "must be %q, got %q", s.Const, node.Value)
+		} else if len(s.Enum) > 0 && !contains(s.Enum, node.Value) {
+			v.errorf(node, field, "%q must be one of %s", node.Value, strings.Join(s.Enum, ", "))
+		} else if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(node.Value) {
+			v.errorf(node, field, "invalid identifier %q: must match %q", node.Value, s.Pattern)
+		}
+	}
+}
+
+// validateArtifactData checks the data of an artifact nested in another patch
+// against the schema of the artifact's kind. Unknown kinds have already been reported.
+func (v *validator) validateArtifactData(node *yaml.Node, field string) {
+	data := mappingValue(node, "data")
+	if data == nil {
+		return
+	}
+	s, err := SchemaForKind(scalar(mappingValue(node, "kind")))
+	if err != nil {
+		return
+	}
+	defs := v.defs
+	v.defs = s.Definitions
+	if field != "" {
+		field += "."
+	}
+	v.validate(data, s.Properties["data"], field+"data")
+	v.defs = defs
+}
+
+// nodeType returns the JSON type of a YAML node.
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.Tag {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	default:
+		return "string"
+	}
+}
+
+// matchesType returns true if a node has one of the listed JSON types.
+// Any scalar matches a string, because unquoted numbers and booleans are
+// read as strings when they are applied.
+func matchesType(node *yaml.Node, types []string) bool {
+	actual := nodeType(node)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") ||
+			(t == "string" && node.Kind == yaml.ScalarNode) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -70,7 +70,8 @@ func applyApiVersionPatch(
 	ctx context.Context,
 	client connection.RegistryClient,
 	version *models.ApiVersion,
-	parent string) error {
+	parent string,
+	dir string) error {
 	name := fmt.Sprintf("%s/versions/%s", parent, version.Metadata.Name)
 	req := &rpc.UpdateApiVersionRequest{
 		ApiVersion: &rpc.ApiVersion{
@@ -88,7 +89,7 @@ func applyApiVersionPatch(
 		return err
 	}
 	for _, specPatch := range version.Data.ApiSpecs {
-		err := applyApiSpecPatch(ctx, client, specPatch, name)
+		err := applyApiSpecPatch(ctx, client, specPatch, name, dir)
 		if err != nil {
 			return err
 		}
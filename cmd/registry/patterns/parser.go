@@ -43,8 +43,8 @@ func parseResourceCollection(resourcePattern string) (ResourceName, error) {
 func parseResource(resourcePattern string) (ResourceName, error) {
 	if project, err := names.ParseProject(resourcePattern); err == nil {
 		return ProjectName{Name: project}, nil
-	} else if project, err := names.ParseProjectWithLocation(resourcePattern); err == nil {
-		return ProjectName{Name: project}, nil
+	} else if location, err := names.ParseLocation(resourcePattern); err == nil {
+		return LocationName{Name: location}, nil
 	} else if api, err := names.ParseApi(resourcePattern); err == nil {
 		return ApiName{Name: api}, nil
 	} else if version, err := names.ParseVersion(resourcePattern); err == nil {
@@ -93,7 +93,7 @@ func SubstituteReferenceEntity(resourcePattern string, referred ResourceName) (R
 	// no $resource reference present
 	// simply prepend the projectname and return full resource name
 	if entityType == "default" {
-		resourceName, err := ParseResourcePattern(fmt.Sprintf("%s/locations/global/%s", referred.Project(), resourcePattern))
+		resourceName, err := ParseResourcePattern(fmt.Sprintf("%s/%s", referredLocation(referred), resourcePattern))
 		if err != nil {
 			return nil, err
 		}
@@ -112,6 +112,19 @@ func SubstituteReferenceEntity(resourcePattern string, referred ResourceName) (R
 	return extendedName, nil
 }
 
+// referredLocation returns the name of the location of the referred resource.
+// Example:
+// referred: "projects/demo/locations/us/apis/petstore"
+// returns "projects/demo/locations/us"
+func referredLocation(referred ResourceName) string {
+	if m := locationPrefixRegexp.FindString(referred.String()); m != "" {
+		return m
+	}
+	return fmt.Sprintf("%s/locations/%s", referred.Project(), names.DefaultLocation)
+}
+
+var locationPrefixRegexp = regexp.MustCompile("^projects/[^/]+/locations/[^/]+")
+
 func GetReferenceEntityType(resourcePattern string) (entity, entityType string, err error) {
 	// Reads the resourcePattern, finds out entity type in the $resource reference
 	// Example:
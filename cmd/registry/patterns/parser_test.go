@@ -84,6 +84,12 @@ func TestSubstituteReferenceEntity(t *testing.T) {
 			dependencyPattern: "apis/-/versions/-",
 			want:              "projects/demo/locations/global/apis/-/versions/-",
 		},
+		{
+			desc:              "no reference in another location",
+			resourcePattern:   "projects/demo/locations/us/apis/-/artifacts/lintstats",
+			dependencyPattern: "apis/-/versions/-",
+			want:              "projects/demo/locations/us/apis/-/versions/-",
+		},
 	}
 
 	for _, test := range tests {
@@ -166,6 +172,22 @@ func TestFullResourceNameFromParent(t *testing.T) {
 				Name: generateArtifact(t, "projects/demo/locations/global/apis/petstore/versions/1.0.0/specs/openapi.yaml/artifacts/complexity"),
 			},
 		},
+		{
+			desc:            "api pattern in another location",
+			resourcePattern: "projects/demo/locations/us/apis/petstore",
+			parent:          "projects/demo/locations/us",
+			want: ApiName{
+				Name: names.Api{ProjectID: "demo", LocationID: "us", ApiID: "petstore"},
+			},
+		},
+		{
+			desc:            "project artifact pattern in another location",
+			resourcePattern: "projects/demo/locations/us/artifacts/complexity",
+			parent:          "projects/demo/locations/us",
+			want: ArtifactName{
+				Name: generateArtifact(t, "projects/demo/locations/us/artifacts/complexity"),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -159,9 +159,9 @@ func (a ApiName) ParentName() ResourceName {
 		return ProjectName{
 			Name: project,
 		}
-	} else if project, err := names.ParseProjectWithLocation(a.Name.Parent()); err == nil {
-		return ProjectName{
-			Name: project,
+	} else if location, err := names.ParseLocation(a.Name.Parent()); err == nil {
+		return LocationName{
+			Name: location,
 		}
 	} else if project, err := names.ParseProjectCollection(a.Name.Parent()); err == nil {
 		return ProjectName{
@@ -204,6 +204,40 @@ func (p ProjectName) ParentName() ResourceName {
 	return nil
 }
 
+type LocationName struct {
+	Name names.ProjectLocation
+}
+
+func (l LocationName) Artifact() string {
+	return ""
+}
+
+func (l LocationName) Spec() string {
+	return ""
+}
+
+func (l LocationName) Version() string {
+	return ""
+}
+
+func (l LocationName) Api() string {
+	return ""
+}
+
+func (l LocationName) Project() string {
+	return l.Name.Project().String()
+}
+
+func (l LocationName) String() string {
+	return l.Name.String()
+}
+
+func (l LocationName) ParentName() ResourceName {
+	return ProjectName{
+		Name: l.Name.Project(),
+	}
+}
+
 type ArtifactName struct {
 	Name names.Artifact
 }
@@ -214,10 +248,11 @@ func (ar ArtifactName) Artifact() string {
 
 func (ar ArtifactName) Spec() string {
 	specPattern := names.Spec{
-		ProjectID: ar.Name.ProjectID(),
-		ApiID:     ar.Name.ApiID(),
-		VersionID: ar.Name.VersionID(),
-		SpecID:    ar.Name.SpecID(),
+		ProjectID:  ar.Name.ProjectID(),
+		LocationID: ar.Name.LocationID(),
+		ApiID:      ar.Name.ApiID(),
+		VersionID:  ar.Name.VersionID(),
+		SpecID:     ar.Name.SpecID(),
 	}
 
 	// Validate the generated name
@@ -232,9 +267,10 @@ func (ar ArtifactName) Spec() string {
 
 func (ar ArtifactName) Version() string {
 	versionPattern := names.Version{
-		ProjectID: ar.Name.ProjectID(),
-		ApiID:     ar.Name.ApiID(),
-		VersionID: ar.Name.VersionID(),
+		ProjectID:  ar.Name.ProjectID(),
+		LocationID: ar.Name.LocationID(),
+		ApiID:      ar.Name.ApiID(),
+		VersionID:  ar.Name.VersionID(),
 	}
 	// Validate the generated name
 	if version, err := names.ParseVersion(versionPattern.String()); err == nil {
@@ -248,8 +284,9 @@ func (ar ArtifactName) Version() string {
 
 func (ar ArtifactName) Api() string {
 	apiPattern := names.Api{
-		ProjectID: ar.Name.ProjectID(),
-		ApiID:     ar.Name.ApiID(),
+		ProjectID:  ar.Name.ProjectID(),
+		LocationID: ar.Name.LocationID(),
+		ApiID:      ar.Name.ApiID(),
 	}
 	// Validate the generated name
 	if _, err := names.ParseApi(apiPattern.String()); err == nil {
@@ -305,9 +342,9 @@ func (ar ArtifactName) ParentName() ResourceName {
 		return ProjectName{
 			Name: project,
 		}
-	} else if project, err := names.ParseProjectWithLocation(parent); err == nil {
-		return ProjectName{
-			Name: project,
+	} else if location, err := names.ParseLocation(parent); err == nil {
+		return LocationName{
+			Name: location,
 		}
 	} else if api, err := names.ParseApi(parent); err == nil {
 		return ApiName{
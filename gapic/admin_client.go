@@ -50,6 +50,13 @@ type AdminCallOptions struct {
 	CreateProject   []gax.CallOption
 	UpdateProject   []gax.CallOption
 	DeleteProject   []gax.CallOption
+	ListReferences  []gax.CallOption
+	ExportProject   []gax.CallOption
+	ImportProject   []gax.CallOption
+	StreamArchive   []gax.CallOption
+	UploadArchive   []gax.CallOption
+	BulkDelete      []gax.CallOption
+	ListAuditEvents []gax.CallOption
 }
 
 func defaultAdminGRPCClientOptions() []option.ClientOption {
@@ -74,6 +81,13 @@ func defaultAdminCallOptions() *AdminCallOptions {
 		CreateProject:   []gax.CallOption{},
 		UpdateProject:   []gax.CallOption{},
 		DeleteProject:   []gax.CallOption{},
+		ListReferences:  []gax.CallOption{},
+		ExportProject:   []gax.CallOption{},
+		ImportProject:   []gax.CallOption{},
+		StreamArchive:   []gax.CallOption{},
+		UploadArchive:   []gax.CallOption{},
+		BulkDelete:      []gax.CallOption{},
+		ListAuditEvents: []gax.CallOption{},
 	}
 }
 
@@ -91,6 +105,16 @@ type internalAdminClient interface {
 	CreateProject(context.Context, *rpcpb.CreateProjectRequest, ...gax.CallOption) (*rpcpb.Project, error)
 	UpdateProject(context.Context, *rpcpb.UpdateProjectRequest, ...gax.CallOption) (*rpcpb.Project, error)
 	DeleteProject(context.Context, *rpcpb.DeleteProjectRequest, ...gax.CallOption) error
+	ListReferences(context.Context, *rpcpb.ListReferencesRequest, ...gax.CallOption) (*rpcpb.ListReferencesResponse, error)
+	ExportProject(context.Context, *rpcpb.ExportProjectRequest, ...gax.CallOption) (*ExportProjectOperation, error)
+	ExportProjectOperation(name string) *ExportProjectOperation
+	ImportProject(context.Context, *rpcpb.ImportProjectRequest, ...gax.CallOption) (*ImportProjectOperation, error)
+	ImportProjectOperation(name string) *ImportProjectOperation
+	StreamArchive(context.Context, *rpcpb.StreamArchiveRequest, ...gax.CallOption) (rpcpb.Admin_StreamArchiveClient, error)
+	UploadArchive(context.Context, ...gax.CallOption) (rpcpb.Admin_UploadArchiveClient, error)
+	BulkDelete(context.Context, *rpcpb.BulkDeleteRequest, ...gax.CallOption) (*BulkDeleteOperation, error)
+	BulkDeleteOperation(name string) *BulkDeleteOperation
+	ListAuditEvents(context.Context, *rpcpb.ListAuditEventsRequest, ...gax.CallOption) *AuditEventIterator
 }
 
 // AdminClient is a client for interacting with .
@@ -145,7 +169,8 @@ func (c *AdminClient) GetStatus(ctx context.Context, req *emptypb.Empty, opts ..
 	return c.internalClient.GetStatus(ctx, req, opts...)
 }
 
-// GetStorage getStorage returns information about the storage used by the service.
+// GetStorage getStorage returns information about the storage used by the service,
+// including the usage and quotas of each project.
 // (– api-linter: core::0131::request-message-name=disabled
 // aip.dev/not-precedent (at http://aip.dev/not-precedent): Not in the official API. –)
 // (– api-linter: core::0131::method-signature=disabled
@@ -201,6 +226,83 @@ func (c *AdminClient) DeleteProject(ctx context.Context, req *rpcpb.DeleteProjec
 	return c.internalClient.DeleteProject(ctx, req, opts...)
 }
 
+// ListReferences listReferences returns the resources that refer to a specified resource
+// or to any of its children.
+// (– api-linter: core::0132::http-uri-parent=disabled
+// aip.dev/not-precedent (at http://aip.dev/not-precedent): Not in the official API. –)
+// (– api-linter: core::0132::request-parent-required=disabled
+// aip.dev/not-precedent (at http://aip.dev/not-precedent): Not in the official API. –)
+// (– api-linter: core::0132::request-unknown-fields=disabled
+// aip.dev/not-precedent (at http://aip.dev/not-precedent): Not in the official API. –)
+// (– api-linter: core::0132::response-unknown-fields=disabled
+// aip.dev/not-precedent (at http://aip.dev/not-precedent): Not in the official API. –)
+// (– api-linter: core::0158::request-page-size-field=disabled
+// aip.dev/not-precedent (at http://aip.dev/not-precedent): Not in the official API. –)
+func (c *AdminClient) ListReferences(ctx context.Context, req *rpcpb.ListReferencesRequest, opts ...gax.CallOption) (*rpcpb.ListReferencesResponse, error) {
+	return c.internalClient.ListReferences(ctx, req, opts...)
+}
+
+// ExportProject exportProject serializes a project and all of the resources that it owns,
+// including revisions, tags and contents, into a portable archive. The
+// archive is saved in chunks and can be read with StreamArchive.
+func (c *AdminClient) ExportProject(ctx context.Context, req *rpcpb.ExportProjectRequest, opts ...gax.CallOption) (*ExportProjectOperation, error) {
+	return c.internalClient.ExportProject(ctx, req, opts...)
+}
+
+// ExportProjectOperation returns a new ExportProjectOperation from a given name.
+// The name must be that of a previously created ExportProjectOperation, possibly from a different process.
+func (c *AdminClient) ExportProjectOperation(name string) *ExportProjectOperation {
+	return c.internalClient.ExportProjectOperation(name)
+}
+
+// ImportProject importProject restores a project from an archive produced by ExportProject
+// or uploaded with UploadArchive.
+func (c *AdminClient) ImportProject(ctx context.Context, req *rpcpb.ImportProjectRequest, opts ...gax.CallOption) (*ImportProjectOperation, error) {
+	return c.internalClient.ImportProject(ctx, req, opts...)
+}
+
+// ImportProjectOperation returns a new ImportProjectOperation from a given name.
+// The name must be that of a previously created ImportProjectOperation, possibly from a different process.
+func (c *AdminClient) ImportProjectOperation(name string) *ImportProjectOperation {
+	return c.internalClient.ImportProjectOperation(name)
+}
+
+// StreamArchive streamArchive returns the contents of an archive in a stream of chunks.
+// Interrupted downloads can be resumed from their received size.
+func (c *AdminClient) StreamArchive(ctx context.Context, req *rpcpb.StreamArchiveRequest, opts ...gax.CallOption) (rpcpb.Admin_StreamArchiveClient, error) {
+	return c.internalClient.StreamArchive(ctx, req, opts...)
+}
+
+// UploadArchive uploadArchive uploads an archive in a stream of chunks so that it can be
+// imported with ImportProject. Interrupted uploads can be resumed from their
+// committed size.
+func (c *AdminClient) UploadArchive(ctx context.Context, opts ...gax.CallOption) (rpcpb.Admin_UploadArchiveClient, error) {
+	return c.internalClient.UploadArchive(ctx, opts...)
+}
+
+// BulkDelete bulkDelete deletes a project or an API and all of the resources that it
+// owns. Resources are deleted in batches, children before their parents, so
+// an interrupted deletion leaves no orphans and can be resumed by calling
+// BulkDelete again.
+func (c *AdminClient) BulkDelete(ctx context.Context, req *rpcpb.BulkDeleteRequest, opts ...gax.CallOption) (*BulkDeleteOperation, error) {
+	return c.internalClient.BulkDelete(ctx, req, opts...)
+}
+
+// BulkDeleteOperation returns a new BulkDeleteOperation from a given name.
+// The name must be that of a previously created BulkDeleteOperation, possibly from a different process.
+func (c *AdminClient) BulkDeleteOperation(name string) *BulkDeleteOperation {
+	return c.internalClient.BulkDeleteOperation(name)
+}
+
+// ListAuditEvents listAuditEvents returns the audit events recorded for changes to the
+// resources of a project. Events are listed in the order that they were
+// recorded unless another order is specified.
+// (– api-linter: core::0132::method-signature=disabled
+// aip.dev/not-precedent (at http://aip.dev/not-precedent): Not in the official API. –)
+func (c *AdminClient) ListAuditEvents(ctx context.Context, req *rpcpb.ListAuditEventsRequest, opts ...gax.CallOption) *AuditEventIterator {
+	return c.internalClient.ListAuditEvents(ctx, req, opts...)
+}
+
 // adminGRPCClient is a client for interacting with  over gRPC transport.
 //
 // Methods, except Close, may be called concurrently. However, fields must not be modified concurrently with method calls.
@@ -451,6 +553,293 @@ func (c *adminGRPCClient) DeleteProject(ctx context.Context, req *rpcpb.DeletePr
 	return err
 }
 
+func (c *adminGRPCClient) ListReferences(ctx context.Context, req *rpcpb.ListReferencesRequest, opts ...gax.CallOption) (*rpcpb.ListReferencesResponse, error) {
+	md := metadata.Pairs("x-goog-request-params", fmt.Sprintf("%s=%v", "name", url.QueryEscape(req.GetName())))
+
+	ctx = insertMetadata(ctx, c.xGoogMetadata, md)
+	opts = append((*c.CallOptions).ListReferences[0:len((*c.CallOptions).ListReferences):len((*c.CallOptions).ListReferences)], opts...)
+	var resp *rpcpb.ListReferencesResponse
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.adminClient.ListReferences(ctx, req, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *adminGRPCClient) ExportProject(ctx context.Context, req *rpcpb.ExportProjectRequest, opts ...gax.CallOption) (*ExportProjectOperation, error) {
+	md := metadata.Pairs("x-goog-request-params", fmt.Sprintf("%s=%v", "name", url.QueryEscape(req.GetName())))
+
+	ctx = insertMetadata(ctx, c.xGoogMetadata, md)
+	opts = append((*c.CallOptions).ExportProject[0:len((*c.CallOptions).ExportProject):len((*c.CallOptions).ExportProject)], opts...)
+	var resp *longrunningpb.Operation
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.adminClient.ExportProject(ctx, req, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ExportProjectOperation{
+		lro: longrunning.InternalNewOperation(*c.LROClient, resp),
+	}, nil
+}
+
+func (c *adminGRPCClient) ImportProject(ctx context.Context, req *rpcpb.ImportProjectRequest, opts ...gax.CallOption) (*ImportProjectOperation, error) {
+	ctx = insertMetadata(ctx, c.xGoogMetadata)
+	opts = append((*c.CallOptions).ImportProject[0:len((*c.CallOptions).ImportProject):len((*c.CallOptions).ImportProject)], opts...)
+	var resp *longrunningpb.Operation
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.adminClient.ImportProject(ctx, req, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportProjectOperation{
+		lro: longrunning.InternalNewOperation(*c.LROClient, resp),
+	}, nil
+}
+
+func (c *adminGRPCClient) StreamArchive(ctx context.Context, req *rpcpb.StreamArchiveRequest, opts ...gax.CallOption) (rpcpb.Admin_StreamArchiveClient, error) {
+	md := metadata.Pairs("x-goog-request-params", fmt.Sprintf("%s=%v", "name", url.QueryEscape(req.GetName())))
+
+	ctx = insertMetadata(ctx, c.xGoogMetadata, md)
+	opts = append((*c.CallOptions).StreamArchive[0:len((*c.CallOptions).StreamArchive):len((*c.CallOptions).StreamArchive)], opts...)
+	var resp rpcpb.Admin_StreamArchiveClient
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.adminClient.StreamArchive(ctx, req, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *adminGRPCClient) UploadArchive(ctx context.Context, opts ...gax.CallOption) (rpcpb.Admin_UploadArchiveClient, error) {
+	ctx = insertMetadata(ctx, c.xGoogMetadata)
+	var resp rpcpb.Admin_UploadArchiveClient
+	opts = append((*c.CallOptions).UploadArchive[0:len((*c.CallOptions).UploadArchive):len((*c.CallOptions).UploadArchive)], opts...)
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.adminClient.UploadArchive(ctx, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *adminGRPCClient) BulkDelete(ctx context.Context, req *rpcpb.BulkDeleteRequest, opts ...gax.CallOption) (*BulkDeleteOperation, error) {
+	md := metadata.Pairs("x-goog-request-params", fmt.Sprintf("%s=%v", "name", url.QueryEscape(req.GetName())))
+
+	ctx = insertMetadata(ctx, c.xGoogMetadata, md)
+	opts = append((*c.CallOptions).BulkDelete[0:len((*c.CallOptions).BulkDelete):len((*c.CallOptions).BulkDelete)], opts...)
+	var resp *longrunningpb.Operation
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.adminClient.BulkDelete(ctx, req, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkDeleteOperation{
+		lro: longrunning.InternalNewOperation(*c.LROClient, resp),
+	}, nil
+}
+
+// ExportProjectOperation manages a long-running operation from ExportProject.
+type ExportProjectOperation struct {
+	lro *longrunning.Operation
+}
+
+func (c *adminGRPCClient) ListAuditEvents(ctx context.Context, req *rpcpb.ListAuditEventsRequest, opts ...gax.CallOption) *AuditEventIterator {
+	md := metadata.Pairs("x-goog-request-params", fmt.Sprintf("%s=%v", "parent", url.QueryEscape(req.GetParent())))
+
+	ctx = insertMetadata(ctx, c.xGoogMetadata, md)
+	opts = append((*c.CallOptions).ListAuditEvents[0:len((*c.CallOptions).ListAuditEvents):len((*c.CallOptions).ListAuditEvents)], opts...)
+	it := &AuditEventIterator{}
+	req = proto.Clone(req).(*rpcpb.ListAuditEventsRequest)
+	it.InternalFetch = func(pageSize int, pageToken string) ([]*rpcpb.AuditEvent, string, error) {
+		resp := &rpcpb.ListAuditEventsResponse{}
+		if pageToken != "" {
+			req.PageToken = pageToken
+		}
+		if pageSize > math.MaxInt32 {
+			req.PageSize = math.MaxInt32
+		} else if pageSize != 0 {
+			req.PageSize = int32(pageSize)
+		}
+		err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+			var err error
+			resp, err = c.adminClient.ListAuditEvents(ctx, req, settings.GRPC...)
+			return err
+		}, opts...)
+		if err != nil {
+			return nil, "", err
+		}
+
+		it.Response = resp
+		return resp.GetAuditEvents(), resp.GetNextPageToken(), nil
+	}
+	fetch := func(pageSize int, pageToken string) (string, error) {
+		items, nextPageToken, err := it.InternalFetch(pageSize, pageToken)
+		if err != nil {
+			return "", err
+		}
+		it.items = append(it.items, items...)
+		return nextPageToken, nil
+	}
+
+	it.pageInfo, it.nextFunc = iterator.NewPageInfo(fetch, it.bufLen, it.takeBuf)
+	it.pageInfo.MaxSize = int(req.GetPageSize())
+	it.pageInfo.Token = req.GetPageToken()
+
+	return it
+}
+
+// ExportProjectOperation returns a new ExportProjectOperation from a given name.
+// The name must be that of a previously created ExportProjectOperation, possibly from a different process.
+func (c *adminGRPCClient) ExportProjectOperation(name string) *ExportProjectOperation {
+	return &ExportProjectOperation{
+		lro: longrunning.InternalNewOperation(*c.LROClient, &longrunningpb.Operation{Name: name}),
+	}
+}
+
+// Wait blocks until the long-running operation is completed, returning the response and any errors encountered.
+//
+// See documentation of Poll for error-handling information.
+func (op *ExportProjectOperation) Wait(ctx context.Context, opts ...gax.CallOption) (*rpcpb.ExportProjectResponse, error) {
+	var resp rpcpb.ExportProjectResponse
+	if err := op.lro.WaitWithInterval(ctx, &resp, time.Minute, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Poll fetches the latest state of the long-running operation.
+//
+// Poll also fetches the latest metadata, which can be retrieved by Metadata.
+//
+// If Poll fails, the error is returned and op is unmodified. If Poll succeeds and
+// the operation has completed with failure, the error is returned and op.Done will return true.
+// If Poll succeeds and the operation has completed successfully,
+// op.Done will return true, and the response of the operation is returned.
+// If Poll succeeds and the operation has not completed, the returned response and error are both nil.
+func (op *ExportProjectOperation) Poll(ctx context.Context, opts ...gax.CallOption) (*rpcpb.ExportProjectResponse, error) {
+	var resp rpcpb.ExportProjectResponse
+	if err := op.lro.Poll(ctx, &resp, opts...); err != nil {
+		return nil, err
+	}
+	if !op.Done() {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+// Metadata returns metadata associated with the long-running operation.
+// Metadata itself does not contact the server, but Poll does.
+// To get the latest metadata, call this method after a successful call to Poll.
+// If the metadata is not available, the returned metadata and error are both nil.
+func (op *ExportProjectOperation) Metadata() (*rpcpb.ExportProjectMetadata, error) {
+	var meta rpcpb.ExportProjectMetadata
+	if err := op.lro.Metadata(&meta); err == longrunning.ErrNoMetadata {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// Done reports whether the long-running operation has completed.
+func (op *ExportProjectOperation) Done() bool {
+	return op.lro.Done()
+}
+
+// Name returns the name of the long-running operation.
+// The name is assigned by the server and is unique within the service from which the operation is created.
+func (op *ExportProjectOperation) Name() string {
+	return op.lro.Name()
+}
+
+// ImportProjectOperation manages a long-running operation from ImportProject.
+type ImportProjectOperation struct {
+	lro *longrunning.Operation
+}
+
+// ImportProjectOperation returns a new ImportProjectOperation from a given name.
+// The name must be that of a previously created ImportProjectOperation, possibly from a different process.
+func (c *adminGRPCClient) ImportProjectOperation(name string) *ImportProjectOperation {
+	return &ImportProjectOperation{
+		lro: longrunning.InternalNewOperation(*c.LROClient, &longrunningpb.Operation{Name: name}),
+	}
+}
+
+// Wait blocks until the long-running operation is completed, returning the response and any errors encountered.
+//
+// See documentation of Poll for error-handling information.
+func (op *ImportProjectOperation) Wait(ctx context.Context, opts ...gax.CallOption) (*rpcpb.ImportProjectResponse, error) {
+	var resp rpcpb.ImportProjectResponse
+	if err := op.lro.WaitWithInterval(ctx, &resp, time.Minute, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Poll fetches the latest state of the long-running operation.
+//
+// Poll also fetches the latest metadata, which can be retrieved by Metadata.
+//
+// If Poll fails, the error is returned and op is unmodified. If Poll succeeds and
+// the operation has completed with failure, the error is returned and op.Done will return true.
+// If Poll succeeds and the operation has completed successfully,
+// op.Done will return true, and the response of the operation is returned.
+// If Poll succeeds and the operation has not completed, the returned response and error are both nil.
+func (op *ImportProjectOperation) Poll(ctx context.Context, opts ...gax.CallOption) (*rpcpb.ImportProjectResponse, error) {
+	var resp rpcpb.ImportProjectResponse
+	if err := op.lro.Poll(ctx, &resp, opts...); err != nil {
+		return nil, err
+	}
+	if !op.Done() {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+// Metadata returns metadata associated with the long-running operation.
+// Metadata itself does not contact the server, but Poll does.
+// To get the latest metadata, call this method after a successful call to Poll.
+// If the metadata is not available, the returned metadata and error are both nil.
+func (op *ImportProjectOperation) Metadata() (*rpcpb.ImportProjectMetadata, error) {
+	var meta rpcpb.ImportProjectMetadata
+	if err := op.lro.Metadata(&meta); err == longrunning.ErrNoMetadata {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// Done reports whether the long-running operation has completed.
+func (op *ImportProjectOperation) Done() bool {
+	return op.lro.Done()
+}
+
+// Name returns the name of the long-running operation.
+// The name is assigned by the server and is unique within the service from which the operation is created.
+func (op *ImportProjectOperation) Name() string {
+	return op.lro.Name()
+}
+
 // MigrateDatabaseOperation manages a long-running operation from MigrateDatabase.
 type MigrateDatabaseOperation struct {
 	lro *longrunning.Operation
@@ -520,6 +909,53 @@ func (op *MigrateDatabaseOperation) Name() string {
 	return op.lro.Name()
 }
 
+// AuditEventIterator manages a stream of *rpcpb.AuditEvent.
+type AuditEventIterator struct {
+	items    []*rpcpb.AuditEvent
+	pageInfo *iterator.PageInfo
+	nextFunc func() error
+
+	// Response is the raw response for the current page.
+	// It must be cast to the RPC response type.
+	// Calling Next() or InternalFetch() updates this value.
+	Response interface{}
+
+	// InternalFetch is for use by the Google Cloud Libraries only.
+	// It is not part of the stable interface of this package.
+	//
+	// InternalFetch returns results from a single call to the underlying RPC.
+	// The number of results is no greater than pageSize.
+	// If there are no more results, nextPageToken is empty and err is nil.
+	InternalFetch func(pageSize int, pageToken string) (results []*rpcpb.AuditEvent, nextPageToken string, err error)
+}
+
+// PageInfo supports pagination. See the google.golang.org/api/iterator package for details.
+func (it *AuditEventIterator) PageInfo() *iterator.PageInfo {
+	return it.pageInfo
+}
+
+// Next returns the next result. Its second return value is iterator.Done if there are no more
+// results. Once Next returns Done, all subsequent calls will return Done.
+func (it *AuditEventIterator) Next() (*rpcpb.AuditEvent, error) {
+	var item *rpcpb.AuditEvent
+	if err := it.nextFunc(); err != nil {
+		return item, err
+	}
+	item = it.items[0]
+	it.items = it.items[1:]
+	return item, nil
+}
+
+func (it *AuditEventIterator) bufLen() int {
+	return len(it.items)
+}
+
+func (it *AuditEventIterator) takeBuf() interface{} {
+	b := it.items
+	it.items = nil
+	return b
+}
+
 // ProjectIterator manages a stream of *rpcpb.Project.
 type ProjectIterator struct {
 	items    []*rpcpb.Project
@@ -570,3 +1006,72 @@ func (it *ProjectIterator) takeBuf() interface{} {
 func (c *AdminClient) GrpcClient() rpcpb.AdminClient {
 	return c.internalClient.(*adminGRPCClient).adminClient
 }
+
+// BulkDeleteOperation manages a long-running operation from BulkDelete.
+type BulkDeleteOperation struct {
+	lro *longrunning.Operation
+}
+
+// BulkDeleteOperation returns a new BulkDeleteOperation from a given name.
+// The name must be that of a previously created BulkDeleteOperation, possibly from a different process.
+func (c *adminGRPCClient) BulkDeleteOperation(name string) *BulkDeleteOperation {
+	return &BulkDeleteOperation{
+		lro: longrunning.InternalNewOperation(*c.LROClient, &longrunningpb.Operation{Name: name}),
+	}
+}
+
+// Wait blocks until the long-running operation is completed, returning the response and any errors encountered.
+//
+// See documentation of Poll for error-handling information.
+func (op *BulkDeleteOperation) Wait(ctx context.Context, opts ...gax.CallOption) (*rpcpb.BulkDeleteResponse, error) {
+	var resp rpcpb.BulkDeleteResponse
+	if err := op.lro.WaitWithInterval(ctx, &resp, time.Minute, opts...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Poll fetches the latest state of the long-running operation.
+//
+// Poll also fetches the latest metadata, which can be retrieved by Metadata.
+//
+// If Poll fails, the error is returned and op is unmodified. If Poll succeeds and
+// the operation has completed with failure, the error is returned and op.Done will return true.
+// If Poll succeeds and the operation has completed successfully,
+// op.Done will return true, and the response of the operation is returned.
+// If Poll succeeds and the operation has not completed, the returned response and error are both nil.
+func (op *BulkDeleteOperation) Poll(ctx context.Context, opts ...gax.CallOption) (*rpcpb.BulkDeleteResponse, error) {
+	var resp rpcpb.BulkDeleteResponse
+	if err := op.lro.Poll(ctx, &resp, opts...); err != nil {
+		return nil, err
+	}
+	if !op.Done() {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+// Metadata returns metadata associated with the long-running operation.
+// Metadata itself does not contact the server, but Poll does.
+// To get the latest metadata, call this method after a successful call to Poll.
+// If the metadata is not available, the returned metadata and error are both nil.
+func (op *BulkDeleteOperation) Metadata() (*rpcpb.BulkDeleteMetadata, error) {
+	var meta rpcpb.BulkDeleteMetadata
+	if err := op.lro.Metadata(&meta); err == longrunning.ErrNoMetadata {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// Done reports whether the long-running operation has completed.
+func (op *BulkDeleteOperation) Done() bool {
+	return op.lro.Done()
+}
+
+// Name returns the name of the long-running operation.
+// The name is assigned by the server and is unique within the service from which the operation is created.
+func (op *BulkDeleteOperation) Name() string {
+	return op.lro.Name()
+}
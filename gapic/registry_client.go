@@ -53,6 +53,8 @@ type RegistryCallOptions struct {
 	ListApiSpecs                []gax.CallOption
 	GetApiSpec                  []gax.CallOption
 	GetApiSpecContents          []gax.CallOption
+	StreamApiSpecContents       []gax.CallOption
+	UploadApiSpecContents       []gax.CallOption
 	CreateApiSpec               []gax.CallOption
 	UpdateApiSpec               []gax.CallOption
 	DeleteApiSpec               []gax.CallOption
@@ -72,6 +74,7 @@ type RegistryCallOptions struct {
 	ListArtifacts               []gax.CallOption
 	GetArtifact                 []gax.CallOption
 	GetArtifactContents         []gax.CallOption
+	UploadArtifactContents      []gax.CallOption
 	CreateArtifact              []gax.CallOption
 	ReplaceArtifact             []gax.CallOption
 	DeleteArtifact              []gax.CallOption
@@ -273,6 +276,8 @@ func defaultRegistryCallOptions() *RegistryCallOptions {
 				})
 			}),
 		},
+		StreamApiSpecContents: []gax.CallOption{},
+		UploadApiSpecContents: []gax.CallOption{},
 		CreateApiSpec: []gax.CallOption{
 			gax.WithRetry(func() gax.Retryer {
 				return gax.OnCodes([]codes.Code{
@@ -539,6 +544,7 @@ func defaultRegistryCallOptions() *RegistryCallOptions {
 				})
 			}),
 		},
+		UploadArtifactContents: []gax.CallOption{},
 		CreateArtifact: []gax.CallOption{
 			gax.WithRetry(func() gax.Retryer {
 				return gax.OnCodes([]codes.Code{
@@ -602,6 +608,8 @@ type internalRegistryClient interface {
 	ListApiSpecs(context.Context, *rpcpb.ListApiSpecsRequest, ...gax.CallOption) *ApiSpecIterator
 	GetApiSpec(context.Context, *rpcpb.GetApiSpecRequest, ...gax.CallOption) (*rpcpb.ApiSpec, error)
 	GetApiSpecContents(context.Context, *rpcpb.GetApiSpecContentsRequest, ...gax.CallOption) (*httpbodypb.HttpBody, error)
+	StreamApiSpecContents(context.Context, *rpcpb.StreamApiSpecContentsRequest, ...gax.CallOption) (rpcpb.Registry_StreamApiSpecContentsClient, error)
+	UploadApiSpecContents(context.Context, ...gax.CallOption) (rpcpb.Registry_UploadApiSpecContentsClient, error)
 	CreateApiSpec(context.Context, *rpcpb.CreateApiSpecRequest, ...gax.CallOption) (*rpcpb.ApiSpec, error)
 	UpdateApiSpec(context.Context, *rpcpb.UpdateApiSpecRequest, ...gax.CallOption) (*rpcpb.ApiSpec, error)
 	DeleteApiSpec(context.Context, *rpcpb.DeleteApiSpecRequest, ...gax.CallOption) error
@@ -621,6 +629,7 @@ type internalRegistryClient interface {
 	ListArtifacts(context.Context, *rpcpb.ListArtifactsRequest, ...gax.CallOption) *ArtifactIterator
 	GetArtifact(context.Context, *rpcpb.GetArtifactRequest, ...gax.CallOption) (*rpcpb.Artifact, error)
 	GetArtifactContents(context.Context, *rpcpb.GetArtifactContentsRequest, ...gax.CallOption) (*httpbodypb.HttpBody, error)
+	UploadArtifactContents(context.Context, ...gax.CallOption) (rpcpb.Registry_UploadArtifactContentsClient, error)
 	CreateArtifact(context.Context, *rpcpb.CreateArtifactRequest, ...gax.CallOption) (*rpcpb.Artifact, error)
 	ReplaceArtifact(context.Context, *rpcpb.ReplaceArtifactRequest, ...gax.CallOption) (*rpcpb.Artifact, error)
 	DeleteArtifact(context.Context, *rpcpb.DeleteArtifactRequest, ...gax.CallOption) error
@@ -731,6 +740,22 @@ func (c *RegistryClient) GetApiSpecContents(ctx context.Context, req *rpcpb.GetA
 	return c.internalClient.GetApiSpecContents(ctx, req, opts...)
 }
 
+// StreamApiSpecContents streamApiSpecContents returns the contents of a specified spec in a
+// stream of chunks. It should be used instead of GetApiSpecContents for
+// contents that approach gRPC message size limits. Contents are returned
+// uncompressed following the same rules as GetApiSpecContents.
+func (c *RegistryClient) StreamApiSpecContents(ctx context.Context, req *rpcpb.StreamApiSpecContentsRequest, opts ...gax.CallOption) (rpcpb.Registry_StreamApiSpecContentsClient, error) {
+	return c.internalClient.StreamApiSpecContents(ctx, req, opts...)
+}
+
+// UploadApiSpecContents uploadApiSpecContents uploads the contents of a spec in a stream of
+// chunks and then creates or updates the spec. It should be used instead
+// of CreateApiSpec and UpdateApiSpec for contents that approach gRPC message
+// size limits. Interrupted uploads can be resumed from their committed size.
+func (c *RegistryClient) UploadApiSpecContents(ctx context.Context, opts ...gax.CallOption) (rpcpb.Registry_UploadApiSpecContentsClient, error) {
+	return c.internalClient.UploadApiSpecContents(ctx, opts...)
+}
+
 // CreateApiSpec createApiSpec creates a specified spec.
 func (c *RegistryClient) CreateApiSpec(ctx context.Context, req *rpcpb.CreateApiSpecRequest, opts ...gax.CallOption) (*rpcpb.ApiSpec, error) {
 	return c.internalClient.CreateApiSpec(ctx, req, opts...)
@@ -836,6 +861,14 @@ func (c *RegistryClient) GetArtifactContents(ctx context.Context, req *rpcpb.Get
 	return c.internalClient.GetArtifactContents(ctx, req, opts...)
 }
 
+// UploadArtifactContents uploadArtifactContents uploads the contents of an artifact in a stream of
+// chunks and then creates or replaces the artifact. It should be used instead
+// of CreateArtifact and ReplaceArtifact for contents that approach gRPC message
+// size limits. Interrupted uploads can be resumed from their committed size.
+func (c *RegistryClient) UploadArtifactContents(ctx context.Context, opts ...gax.CallOption) (rpcpb.Registry_UploadArtifactContentsClient, error) {
+	return c.internalClient.UploadArtifactContents(ctx, opts...)
+}
+
 // CreateArtifact createArtifact creates a specified artifact.
 func (c *RegistryClient) CreateArtifact(ctx context.Context, req *rpcpb.CreateArtifactRequest, opts ...gax.CallOption) (*rpcpb.Artifact, error) {
 	return c.internalClient.CreateArtifact(ctx, req, opts...)
@@ -1279,6 +1312,38 @@ func (c *registryGRPCClient) GetApiSpecContents(ctx context.Context, req *rpcpb.
 	return resp, nil
 }
 
+func (c *registryGRPCClient) StreamApiSpecContents(ctx context.Context, req *rpcpb.StreamApiSpecContentsRequest, opts ...gax.CallOption) (rpcpb.Registry_StreamApiSpecContentsClient, error) {
+	md := metadata.Pairs("x-goog-request-params", fmt.Sprintf("%s=%v", "name", url.QueryEscape(req.GetName())))
+
+	ctx = insertMetadata(ctx, c.xGoogMetadata, md)
+	opts = append((*c.CallOptions).StreamApiSpecContents[0:len((*c.CallOptions).StreamApiSpecContents):len((*c.CallOptions).StreamApiSpecContents)], opts...)
+	var resp rpcpb.Registry_StreamApiSpecContentsClient
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.registryClient.StreamApiSpecContents(ctx, req, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *registryGRPCClient) UploadApiSpecContents(ctx context.Context, opts ...gax.CallOption) (rpcpb.Registry_UploadApiSpecContentsClient, error) {
+	ctx = insertMetadata(ctx, c.xGoogMetadata)
+	var resp rpcpb.Registry_UploadApiSpecContentsClient
+	opts = append((*c.CallOptions).UploadApiSpecContents[0:len((*c.CallOptions).UploadApiSpecContents):len((*c.CallOptions).UploadApiSpecContents)], opts...)
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.registryClient.UploadApiSpecContents(ctx, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (c *registryGRPCClient) CreateApiSpec(ctx context.Context, req *rpcpb.CreateApiSpecRequest, opts ...gax.CallOption) (*rpcpb.ApiSpec, error) {
 	if _, ok := ctx.Deadline(); !ok && !c.disableDeadlines {
 		cctx, cancel := context.WithTimeout(ctx, 20000*time.Millisecond)
@@ -1781,6 +1846,21 @@ func (c *registryGRPCClient) GetArtifactContents(ctx context.Context, req *rpcpb
 	return resp, nil
 }
 
+func (c *registryGRPCClient) UploadArtifactContents(ctx context.Context, opts ...gax.CallOption) (rpcpb.Registry_UploadArtifactContentsClient, error) {
+	ctx = insertMetadata(ctx, c.xGoogMetadata)
+	var resp rpcpb.Registry_UploadArtifactContentsClient
+	opts = append((*c.CallOptions).UploadArtifactContents[0:len((*c.CallOptions).UploadArtifactContents):len((*c.CallOptions).UploadArtifactContents)], opts...)
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resp, err = c.registryClient.UploadArtifactContents(ctx, settings.GRPC...)
+		return err
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (c *registryGRPCClient) CreateArtifact(ctx context.Context, req *rpcpb.CreateArtifactRequest, opts ...gax.CallOption) (*rpcpb.Artifact, error) {
 	if _, ok := ctx.Deadline(); !ok && !c.disableDeadlines {
 		cctx, cancel := context.WithTimeout(ctx, 20000*time.Millisecond)
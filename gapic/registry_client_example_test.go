@@ -18,6 +18,7 @@ package gapic_test
 
 import (
 	"context"
+	"io"
 
 	gapic "github.com/apigee/registry/gapic"
 	rpcpb "github.com/apigee/registry/rpc"
@@ -380,6 +381,72 @@ func ExampleRegistryClient_GetApiSpecContents() {
 	_ = resp
 }
 
+func ExampleRegistryClient_StreamApiSpecContents() {
+	ctx := context.Background()
+	// This snippet has been automatically generated and should be regarded as a code template only.
+	// It will require modifications to work:
+	// - It may require correct/in-range values for request initialization.
+	// - It may require specifying regional endpoints when creating the service client as shown in:
+	//   https://pkg.go.dev/cloud.google.com/go#hdr-Client_Options
+	c, err := gapic.NewRegistryClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer c.Close()
+
+	req := &rpcpb.StreamApiSpecContentsRequest{
+		// TODO: Fill request struct fields.
+		// See https://pkg.go.dev/github.com/apigee/registry/rpc#StreamApiSpecContentsRequest.
+	}
+	stream, err := c.StreamApiSpecContents(ctx, req)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// TODO: handle error.
+		}
+		// TODO: Use resp.
+		_ = resp
+	}
+}
+
+func ExampleRegistryClient_UploadApiSpecContents() {
+	ctx := context.Background()
+	// This snippet has been automatically generated and should be regarded as a code template only.
+	// It will require modifications to work:
+	// - It may require correct/in-range values for request initialization.
+	// - It may require specifying regional endpoints when creating the service client as shown in:
+	//   https://pkg.go.dev/cloud.google.com/go#hdr-Client_Options
+	c, err := gapic.NewRegistryClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer c.Close()
+	stream, err := c.UploadApiSpecContents(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	reqs := []*rpcpb.UploadApiSpecContentsRequest{
+		// TODO: Create requests.
+	}
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			// TODO: Handle error.
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// TODO: Use resp.
+	_ = resp
+}
+
 func ExampleRegistryClient_CreateApiSpec() {
 	ctx := context.Background()
 	// This snippet has been automatically generated and should be regarded as a code template only.
@@ -875,6 +942,38 @@ func ExampleRegistryClient_GetArtifactContents() {
 	_ = resp
 }
 
+func ExampleRegistryClient_UploadArtifactContents() {
+	ctx := context.Background()
+	// This snippet has been automatically generated and should be regarded as a code template only.
+	// It will require modifications to work:
+	// - It may require correct/in-range values for request initialization.
+	// - It may require specifying regional endpoints when creating the service client as shown in:
+	//   https://pkg.go.dev/cloud.google.com/go#hdr-Client_Options
+	c, err := gapic.NewRegistryClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	defer c.Close()
+	stream, err := c.UploadArtifactContents(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
+	reqs := []*rpcpb.UploadArtifactContentsRequest{
+		// TODO: Create requests.
+	}
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			// TODO: Handle error.
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		// TODO: Handle error.
+	}
+	// TODO: Use resp.
+	_ = resp
+}
+
 func ExampleRegistryClient_CreateArtifact() {
 	ctx := context.Background()
 	// This snippet has been automatically generated and should be regarded as a code template only.
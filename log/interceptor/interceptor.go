@@ -25,9 +25,14 @@ import (
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+// RequestIDKey is the metadata key of request identifiers. Callers may set it
+// to choose the identifier of a request; otherwise one is generated.
+const RequestIDKey = "x-request-id"
+
 type (
 	resourceOperation   interface{ GetName() string }
 	collectionOperation interface{ GetParent() string }
@@ -39,8 +44,17 @@ func CallLogger(opts ...log.Option) grpc.UnaryServerInterceptor {
 	// Each request will share this logger as a base template.
 	sharedLogger := log.NewLogger(opts...)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Identify the request and make the identifier available to handlers.
+		md, _ := metadata.FromIncomingContext(ctx)
+		requestID := fmt.Sprintf("%.8s", uuid.New())
+		if v := md.Get(RequestIDKey); len(v) > 0 && v[0] != "" {
+			requestID = v[0]
+		} else {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Join(md, metadata.Pairs(RequestIDKey, requestID)))
+		}
+
 		reqInfo := map[string]interface{}{
-			"request_id": fmt.Sprintf("%.8s", uuid.New()),
+			"request_id": requestID,
 			"method":     filepath.Base(info.FullMethod),
 		}
 
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/apigee/registry/pkg/config"
+	"github.com/apigee/registry/server/registry/names"
 )
 
 // Config configures the client.
@@ -61,15 +62,24 @@ func ReadConfig(name string) (Config, error) {
 
 // FQName ensures the project and location, if available,
 // are properly included to make ensure the resource name
-// is fully qualified.
+// is fully qualified. When a project is configured without
+// a location, the "global" location is used.
 func (c Config) FQName(name string) string {
 	name = strings.TrimPrefix(name, "/")
 	if !strings.HasPrefix(name, "projects") && c.Project != "" {
 		if strings.HasPrefix(name, "locations") {
 			name = path.Join("projects", c.Project, name)
-		} else if c.Location != "" {
-			name = path.Join("projects", c.Project, "locations", c.Location, name)
+		} else {
+			name = path.Join("projects", c.Project, "locations", c.location(), name)
 		}
 	}
 	return name
 }
+
+// location returns the configured location or the default location.
+func (c Config) location() string {
+	if c.Location == "" {
+		return names.DefaultLocation
+	}
+	return c.Location
+}
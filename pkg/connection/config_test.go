@@ -70,3 +70,25 @@ func TestFQNameFullConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestFQNameDefaultLocation(t *testing.T) {
+	data := []struct {
+		input string
+		want  string
+	}{
+		{"", "projects/project1/locations/global"},
+		{"apis/foo", "projects/project1/locations/global/apis/foo"},
+		{"locations/-/apis", "projects/project1/locations/-/apis"},
+		{"projects/foo/locations/us/apis/bar", "projects/foo/locations/us/apis/bar"},
+	}
+
+	c := Config{
+		Project: "project1",
+	}
+	for _, d := range data {
+		got := c.FQName(d.input)
+		if d.want != got {
+			t.Errorf("for: %q, want: %q, got: %q", d.input, d.want, got)
+		}
+	}
+}
@@ -24,4 +24,8 @@ type ApiSpecData struct {
 	Description string `yaml:"description,omitempty"`
 	MimeType    string `yaml:"mimeType,omitempty"`
 	SourceURI   string `yaml:"sourceURI,omitempty"`
+	// ContentsURI locates the contents of the spec. If it is set, contents are
+	// read from it instead of SourceURI. Relative "file:" URIs are resolved
+	// from the directory containing the YAML file.
+	ContentsURI string `yaml:"contentsURI,omitempty"`
 }
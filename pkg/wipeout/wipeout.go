@@ -27,7 +27,7 @@ import (
 // Errors are currently not returned but are logged by the task queue as fatal errors.
 func Wipeout(ctx context.Context, client connection.RegistryClient, projectID string, jobs int) {
 	log.Debugf(ctx, "Deleting everything in project %s", projectID)
-	project := "projects/" + projectID + "/locations/global"
+	project := "projects/" + projectID + "/locations/-"
 	{
 		log.Debugf(ctx, "Deleting apis")
 		taskQueue, wait := core.WorkerPool(ctx, jobs)
@@ -40,7 +40,10 @@ func TestWipeout(t *testing.T) {
 	projectID := "wipeout-test"
 	project := names.Project{ProjectID: projectID}
 	parent := project.String() + "/locations/global"
-	parentName, nil := names.ParseProjectWithLocation(parent)
+	parentName, err := names.ParseLocation(parent)
+	if err != nil {
+		t.Fatalf("Setup: failed to parse parent: %s", err)
+	}
 
 	ctx := context.Background()
 	adminClient, err := connection.NewAdminClient(ctx)
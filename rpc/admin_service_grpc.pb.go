@@ -32,7 +32,8 @@ type AdminClient interface {
 	// (-- api-linter: core::0131::http-uri-name=disabled
 	//     aip.dev/not-precedent: Not in the official API. --)
 	GetStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Status, error)
-	// GetStorage returns information about the storage used by the service.
+	// GetStorage returns information about the storage used by the service,
+	// including the usage and quotas of each project.
 	// (-- api-linter: core::0131::request-message-name=disabled
 	//     aip.dev/not-precedent: Not in the official API. --)
 	// (-- api-linter: core::0131::method-signature=disabled
@@ -61,6 +62,44 @@ type AdminClient interface {
 	// DeleteProject removes a specified project and all of the resources that it
 	// owns.
 	DeleteProject(ctx context.Context, in *DeleteProjectRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListReferences returns the resources that refer to a specified resource
+	// or to any of its children.
+	// (-- api-linter: core::0132::http-uri-parent=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	// (-- api-linter: core::0132::request-parent-required=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	// (-- api-linter: core::0132::request-unknown-fields=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	// (-- api-linter: core::0132::response-unknown-fields=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	// (-- api-linter: core::0158::request-page-size-field=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	ListReferences(ctx context.Context, in *ListReferencesRequest, opts ...grpc.CallOption) (*ListReferencesResponse, error)
+	// ExportProject serializes a project and all of the resources that it owns,
+	// including revisions, tags and contents, into a portable archive. The
+	// archive is saved in chunks and can be read with StreamArchive.
+	ExportProject(ctx context.Context, in *ExportProjectRequest, opts ...grpc.CallOption) (*longrunning.Operation, error)
+	// ImportProject restores a project from an archive produced by ExportProject
+	// or uploaded with UploadArchive.
+	ImportProject(ctx context.Context, in *ImportProjectRequest, opts ...grpc.CallOption) (*longrunning.Operation, error)
+	// StreamArchive returns the contents of an archive in a stream of chunks.
+	// Interrupted downloads can be resumed from their received size.
+	StreamArchive(ctx context.Context, in *StreamArchiveRequest, opts ...grpc.CallOption) (Admin_StreamArchiveClient, error)
+	// UploadArchive uploads an archive in a stream of chunks so that it can be
+	// imported with ImportProject. Interrupted uploads can be resumed from their
+	// committed size.
+	UploadArchive(ctx context.Context, opts ...grpc.CallOption) (Admin_UploadArchiveClient, error)
+	// BulkDelete deletes a project or an API and all of the resources that it
+	// owns. Resources are deleted in batches, children before their parents, so
+	// an interrupted deletion leaves no orphans and can be resumed by calling
+	// BulkDelete again.
+	BulkDelete(ctx context.Context, in *BulkDeleteRequest, opts ...grpc.CallOption) (*longrunning.Operation, error)
+	// ListAuditEvents returns the audit events recorded for changes to the
+	// resources of a project. Events are listed in the order that they were
+	// recorded unless another order is specified.
+	// (-- api-linter: core::0132::method-signature=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error)
 }
 
 type adminClient struct {
@@ -143,6 +182,117 @@ func (c *adminClient) DeleteProject(ctx context.Context, in *DeleteProjectReques
 	return out, nil
 }
 
+func (c *adminClient) ListReferences(ctx context.Context, in *ListReferencesRequest, opts ...grpc.CallOption) (*ListReferencesResponse, error) {
+	out := new(ListReferencesResponse)
+	err := c.cc.Invoke(ctx, "/google.cloud.apigeeregistry.v1.Admin/ListReferences", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ExportProject(ctx context.Context, in *ExportProjectRequest, opts ...grpc.CallOption) (*longrunning.Operation, error) {
+	out := new(longrunning.Operation)
+	err := c.cc.Invoke(ctx, "/google.cloud.apigeeregistry.v1.Admin/ExportProject", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ImportProject(ctx context.Context, in *ImportProjectRequest, opts ...grpc.CallOption) (*longrunning.Operation, error) {
+	out := new(longrunning.Operation)
+	err := c.cc.Invoke(ctx, "/google.cloud.apigeeregistry.v1.Admin/ImportProject", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) StreamArchive(ctx context.Context, in *StreamArchiveRequest, opts ...grpc.CallOption) (Admin_StreamArchiveClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[0], "/google.cloud.apigeeregistry.v1.Admin/StreamArchive", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminStreamArchiveClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_StreamArchiveClient interface {
+	Recv() (*StreamArchiveResponse, error)
+	grpc.ClientStream
+}
+
+type adminStreamArchiveClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminStreamArchiveClient) Recv() (*StreamArchiveResponse, error) {
+	m := new(StreamArchiveResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminClient) UploadArchive(ctx context.Context, opts ...grpc.CallOption) (Admin_UploadArchiveClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[1], "/google.cloud.apigeeregistry.v1.Admin/UploadArchive", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminUploadArchiveClient{stream}
+	return x, nil
+}
+
+type Admin_UploadArchiveClient interface {
+	Send(*UploadArchiveRequest) error
+	CloseAndRecv() (*UploadArchiveResponse, error)
+	grpc.ClientStream
+}
+
+type adminUploadArchiveClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminUploadArchiveClient) Send(m *UploadArchiveRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *adminUploadArchiveClient) CloseAndRecv() (*UploadArchiveResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadArchiveResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminClient) BulkDelete(ctx context.Context, in *BulkDeleteRequest, opts ...grpc.CallOption) (*longrunning.Operation, error) {
+	out := new(longrunning.Operation)
+	err := c.cc.Invoke(ctx, "/google.cloud.apigeeregistry.v1.Admin/BulkDelete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error) {
+	out := new(ListAuditEventsResponse)
+	err := c.cc.Invoke(ctx, "/google.cloud.apigeeregistry.v1.Admin/ListAuditEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AdminServer is the server API for Admin service.
 // All implementations must embed UnimplementedAdminServer
 // for forward compatibility
@@ -155,7 +305,8 @@ type AdminServer interface {
 	// (-- api-linter: core::0131::http-uri-name=disabled
 	//     aip.dev/not-precedent: Not in the official API. --)
 	GetStatus(context.Context, *emptypb.Empty) (*Status, error)
-	// GetStorage returns information about the storage used by the service.
+	// GetStorage returns information about the storage used by the service,
+	// including the usage and quotas of each project.
 	// (-- api-linter: core::0131::request-message-name=disabled
 	//     aip.dev/not-precedent: Not in the official API. --)
 	// (-- api-linter: core::0131::method-signature=disabled
@@ -184,6 +335,44 @@ type AdminServer interface {
 	// DeleteProject removes a specified project and all of the resources that it
 	// owns.
 	DeleteProject(context.Context, *DeleteProjectRequest) (*emptypb.Empty, error)
+	// ListReferences returns the resources that refer to a specified resource
+	// or to any of its children.
+	// (-- api-linter: core::0132::http-uri-parent=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	// (-- api-linter: core::0132::request-parent-required=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	// (-- api-linter: core::0132::request-unknown-fields=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	// (-- api-linter: core::0132::response-unknown-fields=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	// (-- api-linter: core::0158::request-page-size-field=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	ListReferences(context.Context, *ListReferencesRequest) (*ListReferencesResponse, error)
+	// ExportProject serializes a project and all of the resources that it owns,
+	// including revisions, tags and contents, into a portable archive. The
+	// archive is saved in chunks and can be read with StreamArchive.
+	ExportProject(context.Context, *ExportProjectRequest) (*longrunning.Operation, error)
+	// ImportProject restores a project from an archive produced by ExportProject
+	// or uploaded with UploadArchive.
+	ImportProject(context.Context, *ImportProjectRequest) (*longrunning.Operation, error)
+	// StreamArchive returns the contents of an archive in a stream of chunks.
+	// Interrupted downloads can be resumed from their received size.
+	StreamArchive(*StreamArchiveRequest, Admin_StreamArchiveServer) error
+	// UploadArchive uploads an archive in a stream of chunks so that it can be
+	// imported with ImportProject. Interrupted uploads can be resumed from their
+	// committed size.
+	UploadArchive(Admin_UploadArchiveServer) error
+	// BulkDelete deletes a project or an API and all of the resources that it
+	// owns. Resources are deleted in batches, children before their parents, so
+	// an interrupted deletion leaves no orphans and can be resumed by calling
+	// BulkDelete again.
+	BulkDelete(context.Context, *BulkDeleteRequest) (*longrunning.Operation, error)
+	// ListAuditEvents returns the audit events recorded for changes to the
+	// resources of a project. Events are listed in the order that they were
+	// recorded unless another order is specified.
+	// (-- api-linter: core::0132::method-signature=disabled
+	//     aip.dev/not-precedent: Not in the official API. --)
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error)
 	mustEmbedUnimplementedAdminServer()
 }
 
@@ -215,6 +404,27 @@ func (UnimplementedAdminServer) UpdateProject(context.Context, *UpdateProjectReq
 func (UnimplementedAdminServer) DeleteProject(context.Context, *DeleteProjectRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteProject not implemented")
 }
+func (UnimplementedAdminServer) ListReferences(context.Context, *ListReferencesRequest) (*ListReferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReferences not implemented")
+}
+func (UnimplementedAdminServer) ExportProject(context.Context, *ExportProjectRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportProject not implemented")
+}
+func (UnimplementedAdminServer) ImportProject(context.Context, *ImportProjectRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportProject not implemented")
+}
+func (UnimplementedAdminServer) StreamArchive(*StreamArchiveRequest, Admin_StreamArchiveServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamArchive not implemented")
+}
+func (UnimplementedAdminServer) UploadArchive(Admin_UploadArchiveServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadArchive not implemented")
+}
+func (UnimplementedAdminServer) BulkDelete(context.Context, *BulkDeleteRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkDelete not implemented")
+}
+func (UnimplementedAdminServer) ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
 func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
 
 // UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
@@ -372,6 +582,143 @@ func _Admin_DeleteProject_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Admin_ListReferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListReferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.cloud.apigeeregistry.v1.Admin/ListReferences",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListReferences(ctx, req.(*ListReferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ExportProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ExportProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.cloud.apigeeregistry.v1.Admin/ExportProject",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ExportProject(ctx, req.(*ExportProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ImportProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ImportProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.cloud.apigeeregistry.v1.Admin/ImportProject",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ImportProject(ctx, req.(*ImportProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_StreamArchive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamArchiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).StreamArchive(m, &adminStreamArchiveServer{stream})
+}
+
+type Admin_StreamArchiveServer interface {
+	Send(*StreamArchiveResponse) error
+	grpc.ServerStream
+}
+
+type adminStreamArchiveServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminStreamArchiveServer) Send(m *StreamArchiveResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Admin_UploadArchive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AdminServer).UploadArchive(&adminUploadArchiveServer{stream})
+}
+
+type Admin_UploadArchiveServer interface {
+	SendAndClose(*UploadArchiveResponse) error
+	Recv() (*UploadArchiveRequest, error)
+	grpc.ServerStream
+}
+
+type adminUploadArchiveServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminUploadArchiveServer) SendAndClose(m *UploadArchiveResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *adminUploadArchiveServer) Recv() (*UploadArchiveRequest, error) {
+	m := new(UploadArchiveRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Admin_BulkDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).BulkDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.cloud.apigeeregistry.v1.Admin/BulkDelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).BulkDelete(ctx, req.(*BulkDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.cloud.apigeeregistry.v1.Admin/ListAuditEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -411,7 +758,38 @@ var Admin_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteProject",
 			Handler:    _Admin_DeleteProject_Handler,
 		},
+		{
+			MethodName: "ListReferences",
+			Handler:    _Admin_ListReferences_Handler,
+		},
+		{
+			MethodName: "ExportProject",
+			Handler:    _Admin_ExportProject_Handler,
+		},
+		{
+			MethodName: "ImportProject",
+			Handler:    _Admin_ImportProject_Handler,
+		},
+		{
+			MethodName: "BulkDelete",
+			Handler:    _Admin_BulkDelete_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _Admin_ListAuditEvents_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamArchive",
+			Handler:       _Admin_StreamArchive_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadArchive",
+			Handler:       _Admin_UploadArchive_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "google/cloud/apigeeregistry/v1/admin_service.proto",
 }
@@ -55,6 +55,16 @@ type RegistryClient interface {
 	// is to return the spec uncompressed (the mime_type response field
 	// indicates the exact format returned).
 	GetApiSpecContents(ctx context.Context, in *GetApiSpecContentsRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+	// StreamApiSpecContents returns the contents of a specified spec in a
+	// stream of chunks. It should be used instead of GetApiSpecContents for
+	// contents that approach gRPC message size limits. Contents are returned
+	// uncompressed following the same rules as GetApiSpecContents.
+	StreamApiSpecContents(ctx context.Context, in *StreamApiSpecContentsRequest, opts ...grpc.CallOption) (Registry_StreamApiSpecContentsClient, error)
+	// UploadApiSpecContents uploads the contents of a spec in a stream of
+	// chunks and then creates or updates the spec. It should be used instead
+	// of CreateApiSpec and UpdateApiSpec for contents that approach gRPC message
+	// size limits. Interrupted uploads can be resumed from their committed size.
+	UploadApiSpecContents(ctx context.Context, opts ...grpc.CallOption) (Registry_UploadApiSpecContentsClient, error)
 	// CreateApiSpec creates a specified spec.
 	CreateApiSpec(ctx context.Context, in *CreateApiSpecRequest, opts ...grpc.CallOption) (*ApiSpec, error)
 	// UpdateApiSpec can be used to modify a specified spec.
@@ -103,6 +113,11 @@ type RegistryClient interface {
 	// is to return the artifact uncompressed (the mime_type response field
 	// indicates the exact format returned).
 	GetArtifactContents(ctx context.Context, in *GetArtifactContentsRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+	// UploadArtifactContents uploads the contents of an artifact in a stream of
+	// chunks and then creates or replaces the artifact. It should be used instead
+	// of CreateArtifact and ReplaceArtifact for contents that approach gRPC message
+	// size limits. Interrupted uploads can be resumed from their committed size.
+	UploadArtifactContents(ctx context.Context, opts ...grpc.CallOption) (Registry_UploadArtifactContentsClient, error)
 	// CreateArtifact creates a specified artifact.
 	CreateArtifact(ctx context.Context, in *CreateArtifactRequest, opts ...grpc.CallOption) (*Artifact, error)
 	// ReplaceArtifact can be used to replace a specified artifact.
@@ -236,6 +251,72 @@ func (c *registryClient) GetApiSpecContents(ctx context.Context, in *GetApiSpecC
 	return out, nil
 }
 
+func (c *registryClient) StreamApiSpecContents(ctx context.Context, in *StreamApiSpecContentsRequest, opts ...grpc.CallOption) (Registry_StreamApiSpecContentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Registry_ServiceDesc.Streams[0], "/google.cloud.apigeeregistry.v1.Registry/StreamApiSpecContents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &registryStreamApiSpecContentsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Registry_StreamApiSpecContentsClient interface {
+	Recv() (*StreamApiSpecContentsResponse, error)
+	grpc.ClientStream
+}
+
+type registryStreamApiSpecContentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *registryStreamApiSpecContentsClient) Recv() (*StreamApiSpecContentsResponse, error) {
+	m := new(StreamApiSpecContentsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *registryClient) UploadApiSpecContents(ctx context.Context, opts ...grpc.CallOption) (Registry_UploadApiSpecContentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Registry_ServiceDesc.Streams[1], "/google.cloud.apigeeregistry.v1.Registry/UploadApiSpecContents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &registryUploadApiSpecContentsClient{stream}
+	return x, nil
+}
+
+type Registry_UploadApiSpecContentsClient interface {
+	Send(*UploadApiSpecContentsRequest) error
+	CloseAndRecv() (*UploadApiSpecContentsResponse, error)
+	grpc.ClientStream
+}
+
+type registryUploadApiSpecContentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *registryUploadApiSpecContentsClient) Send(m *UploadApiSpecContentsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *registryUploadApiSpecContentsClient) CloseAndRecv() (*UploadApiSpecContentsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadApiSpecContentsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *registryClient) CreateApiSpec(ctx context.Context, in *CreateApiSpecRequest, opts ...grpc.CallOption) (*ApiSpec, error) {
 	out := new(ApiSpec)
 	err := c.cc.Invoke(ctx, "/google.cloud.apigeeregistry.v1.Registry/CreateApiSpec", in, out, opts...)
@@ -407,6 +488,40 @@ func (c *registryClient) GetArtifactContents(ctx context.Context, in *GetArtifac
 	return out, nil
 }
 
+func (c *registryClient) UploadArtifactContents(ctx context.Context, opts ...grpc.CallOption) (Registry_UploadArtifactContentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Registry_ServiceDesc.Streams[2], "/google.cloud.apigeeregistry.v1.Registry/UploadArtifactContents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &registryUploadArtifactContentsClient{stream}
+	return x, nil
+}
+
+type Registry_UploadArtifactContentsClient interface {
+	Send(*UploadArtifactContentsRequest) error
+	CloseAndRecv() (*UploadArtifactContentsResponse, error)
+	grpc.ClientStream
+}
+
+type registryUploadArtifactContentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *registryUploadArtifactContentsClient) Send(m *UploadArtifactContentsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *registryUploadArtifactContentsClient) CloseAndRecv() (*UploadArtifactContentsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadArtifactContentsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *registryClient) CreateArtifact(ctx context.Context, in *CreateArtifactRequest, opts ...grpc.CallOption) (*Artifact, error) {
 	out := new(Artifact)
 	err := c.cc.Invoke(ctx, "/google.cloud.apigeeregistry.v1.Registry/CreateArtifact", in, out, opts...)
@@ -469,6 +584,16 @@ type RegistryServer interface {
 	// is to return the spec uncompressed (the mime_type response field
 	// indicates the exact format returned).
 	GetApiSpecContents(context.Context, *GetApiSpecContentsRequest) (*httpbody.HttpBody, error)
+	// StreamApiSpecContents returns the contents of a specified spec in a
+	// stream of chunks. It should be used instead of GetApiSpecContents for
+	// contents that approach gRPC message size limits. Contents are returned
+	// uncompressed following the same rules as GetApiSpecContents.
+	StreamApiSpecContents(*StreamApiSpecContentsRequest, Registry_StreamApiSpecContentsServer) error
+	// UploadApiSpecContents uploads the contents of a spec in a stream of
+	// chunks and then creates or updates the spec. It should be used instead
+	// of CreateApiSpec and UpdateApiSpec for contents that approach gRPC message
+	// size limits. Interrupted uploads can be resumed from their committed size.
+	UploadApiSpecContents(Registry_UploadApiSpecContentsServer) error
 	// CreateApiSpec creates a specified spec.
 	CreateApiSpec(context.Context, *CreateApiSpecRequest) (*ApiSpec, error)
 	// UpdateApiSpec can be used to modify a specified spec.
@@ -517,6 +642,11 @@ type RegistryServer interface {
 	// is to return the artifact uncompressed (the mime_type response field
 	// indicates the exact format returned).
 	GetArtifactContents(context.Context, *GetArtifactContentsRequest) (*httpbody.HttpBody, error)
+	// UploadArtifactContents uploads the contents of an artifact in a stream of
+	// chunks and then creates or replaces the artifact. It should be used instead
+	// of CreateArtifact and ReplaceArtifact for contents that approach gRPC message
+	// size limits. Interrupted uploads can be resumed from their committed size.
+	UploadArtifactContents(Registry_UploadArtifactContentsServer) error
 	// CreateArtifact creates a specified artifact.
 	CreateArtifact(context.Context, *CreateArtifactRequest) (*Artifact, error)
 	// ReplaceArtifact can be used to replace a specified artifact.
@@ -569,6 +699,12 @@ func (UnimplementedRegistryServer) GetApiSpec(context.Context, *GetApiSpecReques
 func (UnimplementedRegistryServer) GetApiSpecContents(context.Context, *GetApiSpecContentsRequest) (*httpbody.HttpBody, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetApiSpecContents not implemented")
 }
+func (UnimplementedRegistryServer) StreamApiSpecContents(*StreamApiSpecContentsRequest, Registry_StreamApiSpecContentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamApiSpecContents not implemented")
+}
+func (UnimplementedRegistryServer) UploadApiSpecContents(Registry_UploadApiSpecContentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadApiSpecContents not implemented")
+}
 func (UnimplementedRegistryServer) CreateApiSpec(context.Context, *CreateApiSpecRequest) (*ApiSpec, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateApiSpec not implemented")
 }
@@ -626,6 +762,9 @@ func (UnimplementedRegistryServer) GetArtifact(context.Context, *GetArtifactRequ
 func (UnimplementedRegistryServer) GetArtifactContents(context.Context, *GetArtifactContentsRequest) (*httpbody.HttpBody, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetArtifactContents not implemented")
 }
+func (UnimplementedRegistryServer) UploadArtifactContents(Registry_UploadArtifactContentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadArtifactContents not implemented")
+}
 func (UnimplementedRegistryServer) CreateArtifact(context.Context, *CreateArtifactRequest) (*Artifact, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateArtifact not implemented")
 }
@@ -882,6 +1021,53 @@ func _Registry_GetApiSpecContents_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Registry_StreamApiSpecContents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamApiSpecContentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RegistryServer).StreamApiSpecContents(m, &registryStreamApiSpecContentsServer{stream})
+}
+
+type Registry_StreamApiSpecContentsServer interface {
+	Send(*StreamApiSpecContentsResponse) error
+	grpc.ServerStream
+}
+
+type registryStreamApiSpecContentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *registryStreamApiSpecContentsServer) Send(m *StreamApiSpecContentsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Registry_UploadApiSpecContents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RegistryServer).UploadApiSpecContents(&registryUploadApiSpecContentsServer{stream})
+}
+
+type Registry_UploadApiSpecContentsServer interface {
+	SendAndClose(*UploadApiSpecContentsResponse) error
+	Recv() (*UploadApiSpecContentsRequest, error)
+	grpc.ServerStream
+}
+
+type registryUploadApiSpecContentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *registryUploadApiSpecContentsServer) SendAndClose(m *UploadApiSpecContentsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *registryUploadApiSpecContentsServer) Recv() (*UploadApiSpecContentsRequest, error) {
+	m := new(UploadApiSpecContentsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Registry_CreateApiSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateApiSpecRequest)
 	if err := dec(in); err != nil {
@@ -1224,6 +1410,32 @@ func _Registry_GetArtifactContents_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Registry_UploadArtifactContents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RegistryServer).UploadArtifactContents(&registryUploadArtifactContentsServer{stream})
+}
+
+type Registry_UploadArtifactContentsServer interface {
+	SendAndClose(*UploadArtifactContentsResponse) error
+	Recv() (*UploadArtifactContentsRequest, error)
+	grpc.ServerStream
+}
+
+type registryUploadArtifactContentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *registryUploadArtifactContentsServer) SendAndClose(m *UploadArtifactContentsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *registryUploadArtifactContentsServer) Recv() (*UploadArtifactContentsRequest, error) {
+	m := new(UploadArtifactContentsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Registry_CreateArtifact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateArtifactRequest)
 	if err := dec(in); err != nil {
@@ -1426,6 +1638,22 @@ var Registry_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Registry_DeleteArtifact_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamApiSpecContents",
+			Handler:       _Registry_StreamApiSpecContents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadApiSpecContents",
+			Handler:       _Registry_UploadApiSpecContents_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UploadArtifactContents",
+			Handler:       _Registry_UploadArtifactContents_Handler,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "google/cloud/apigeeregistry/v1/registry_service.proto",
 }
@@ -29,7 +29,7 @@ import (
 // CreateApi handles the corresponding API request.
 func (s *RegistryServer) CreateApi(ctx context.Context, req *rpc.CreateApiRequest) (*rpc.Api, error) {
 	// Parent name must be valid.
-	parent, err := names.ParseProjectWithLocation(req.GetParent())
+	parent, err := names.ParseLocation(req.GetParent())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
@@ -42,6 +42,9 @@ func (s *RegistryServer) CreateApi(ctx context.Context, req *rpc.CreateApiReques
 	if err := name.Validate(); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "CreateApi", name.String(), req.GetApi()); err != nil {
+		return nil, err
+	}
 	var response *rpc.Api
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		var err error
@@ -60,6 +63,14 @@ func (s *RegistryServer) createApi(ctx context.Context, db *storage.Client, name
 		return nil, err
 	}
 
+	if err := s.checkApiQuota(ctx, db, name.ProjectID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateApiReferences(ctx, db, body, []string{"recommended_version", "recommended_deployment"}); err != nil {
+		return nil, err
+	}
+
 	api, err := models.NewApi(name, body)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -79,22 +90,36 @@ func (s *RegistryServer) DeleteApi(ctx context.Context, req *rpc.DeleteApiReques
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "DeleteApi", name.String(), nil); err != nil {
+		return nil, err
+	}
+	var updated []string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		var err error
+		updated, err = s.handleDeletedReferences(ctx, db, name)
+		if err != nil {
+			return err
+		}
 		return db.DeleteApi(ctx, name, req.GetForce())
 	}); err != nil {
 		return nil, err
 	}
 	s.notify(ctx, rpc.Notification_DELETED, req.GetName())
+	s.notifyUpdated(ctx, updated)
 	return &emptypb.Empty{}, nil
 }
 
 // GetApi handles the corresponding API request.
 func (s *RegistryServer) GetApi(ctx context.Context, req *rpc.GetApiRequest) (*rpc.Api, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.Api{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	name, err := names.ParseApi(req.GetName())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -110,12 +135,13 @@ func (s *RegistryServer) GetApi(ctx context.Context, req *rpc.GetApiRequest) (*r
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	applyReadMask(message, req.GetReadMask())
 	return message, nil
 }
 
 // ListApis handles the corresponding API request.
 func (s *RegistryServer) ListApis(ctx context.Context, req *rpc.ListApisRequest) (*rpc.ListApisResponse, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -128,16 +154,21 @@ func (s *RegistryServer) ListApis(ctx context.Context, req *rpc.ListApisRequest)
 		req.PageSize = 50
 	}
 
-	parent, err := names.ParseProjectWithLocation(req.GetParent())
+	parent, err := names.ParseLocation(req.GetParent())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.Api{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	listing, err := db.ListApis(ctx, parent, storage.PageOptions{
 		Size:   req.GetPageSize(),
 		Filter: req.GetFilter(),
 		Order:  req.GetOrderBy(),
 		Token:  req.GetPageToken(),
+		Fields: readMaskFields(req.GetReadMask()),
 	})
 	if err != nil {
 		return nil, err
@@ -153,6 +184,7 @@ func (s *RegistryServer) ListApis(ctx context.Context, req *rpc.ListApisRequest)
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
+		applyReadMask(response.Apis[i], req.GetReadMask())
 	}
 
 	return response, nil
@@ -173,6 +205,9 @@ func (s *RegistryServer) UpdateApi(ctx context.Context, req *rpc.UpdateApiReques
 	if err := models.ValidateMask(req.GetApi(), req.GetUpdateMask()); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid update_mask %v: %s", req.GetUpdateMask(), err)
 	}
+	if err := s.admit(ctx, "UpdateApi", name.String(), req.GetApi()); err != nil {
+		return nil, err
+	}
 	var response *rpc.Api
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		db.LockApis(ctx)
@@ -181,6 +216,9 @@ func (s *RegistryServer) UpdateApi(ctx context.Context, req *rpc.UpdateApiReques
 			return err
 		}
 		mask := models.ExpandMask(req.GetApi(), req.GetUpdateMask())
+		if err := s.validateApiReferences(ctx, db, req.GetApi(), mask.GetPaths()); err != nil {
+			return err
+		}
 		if err := db.SaveApi(ctx, api, mask); err != nil {
 			if status.Code(err) == codes.NotFound && req.GetAllowMissing() {
 				response, err = s.createApi(ctx, db, name, req.GetApi())
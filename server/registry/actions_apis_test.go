@@ -1041,6 +1041,50 @@ func TestUpdateApi(t *testing.T) {
 	}
 }
 
+func TestUpdateApiTimes(t *testing.T) {
+	ctx := context.Background()
+	server := defaultTestServer(t)
+	seed := &rpc.Api{Name: "projects/my-project/locations/global/apis/a"}
+	if err := seeder.SeedApis(ctx, server, seed); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	created, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: seed.GetName()})
+	if err != nil {
+		t.Fatalf("Setup: GetApi returned error: %s", err)
+	}
+
+	for _, mask := range [][]string{{"display_name"}, {"*"}} {
+		// Spare capacity would let an append write into the request's mask.
+		paths := append(make([]string, 0, len(mask)+1), mask...)
+		req := &rpc.UpdateApiRequest{
+			Api:        &rpc.Api{Name: seed.GetName(), DisplayName: mask[0]},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+		}
+		before, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: seed.GetName()})
+		if err != nil {
+			t.Fatalf("GetApi returned error: %s", err)
+		}
+		if _, err := server.UpdateApi(ctx, req); err != nil {
+			t.Fatalf("UpdateApi(%+v) returned error: %s", req, err)
+		}
+		got, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: seed.GetName()})
+		if err != nil {
+			t.Fatalf("GetApi returned error: %s", err)
+		}
+		if !got.GetUpdateTime().AsTime().After(before.GetUpdateTime().AsTime()) {
+			t.Errorf("UpdateApi(%+v) left update_time at %v, want a time after %v",
+				req, got.GetUpdateTime().AsTime(), before.GetUpdateTime().AsTime())
+		}
+		if !got.GetCreateTime().AsTime().Equal(created.GetCreateTime().AsTime()) {
+			t.Errorf("UpdateApi(%+v) changed create_time to %v, want %v",
+				req, got.GetCreateTime().AsTime(), created.GetCreateTime().AsTime())
+		}
+		if extra := paths[:cap(paths)][len(paths)]; extra != "" {
+			t.Errorf("UpdateApi(%+v) wrote %q into the backing array of the update mask", req, extra)
+		}
+	}
+}
+
 func TestUpdateApiResponseCodes(t *testing.T) {
 	tests := []struct {
 		desc string
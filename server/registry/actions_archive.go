@@ -0,0 +1,728 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProjectArchiveFormatVersion is the version of the archives written by ExportProject.
+const ProjectArchiveFormatVersion = 1
+
+// ExportProject handles the corresponding API request.
+// The archive is written in chunks by a long-running operation and its name is returned.
+func (s *RegistryServer) ExportProject(ctx context.Context, req *rpc.ExportProjectRequest) (*longrunning.Operation, error) {
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	name, err := names.ParseProject(req.GetName())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if _, err := db.GetProject(ctx, name); err != nil {
+		return nil, err
+	}
+
+	metadata := &rpc.ExportProjectMetadata{}
+	return s.startOperation(ctx, "", "ExportProject", metadata, func(ctx context.Context, report func()) (proto.Message, error) {
+		// Records are read and the archive is written in a transaction, which gives
+		// a consistent snapshot and discards the archive if the export fails.
+		response := &rpc.ExportProjectResponse{
+			Archive: names.Archive{ArchiveID: uuid.New().String()}.String(),
+		}
+		var count int
+		if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+			project, err := db.GetProject(ctx, name)
+			if err != nil {
+				return err
+			}
+			w := newArchiveWriter(ctx, db, response.Archive)
+			if err := w.writeMessage(&rpc.ProjectArchive{
+				FormatVersion: ProjectArchiveFormatVersion,
+				CreateTime:    timestamppb.Now(),
+				Project:       project.Message(),
+			}); err != nil {
+				return err
+			}
+			count = 1
+			if err := db.ReadProjectRecords(ctx, name, projectRecordBatchSize, func(r *storage.ProjectRecords) error {
+				archive, err := archiveForRecords(r)
+				if err != nil {
+					return status.Error(codes.Internal, err.Error())
+				}
+				count += r.Count()
+				return w.writeMessage(archive)
+			}, func(done, total int) {
+				metadata.ProgressPercent = progressPercent(done, total)
+				report()
+			}); err != nil {
+				return err
+			}
+			if err := w.close(); err != nil {
+				return err
+			}
+			response.SizeBytes = w.size
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		metadata.ProgressPercent = 100
+		metadata.ExportedCount = int32(count)
+		return response, nil
+	})
+}
+
+// projectRecordBatchSize is the number of records that are held in memory at a
+// time when projects are exported and imported.
+const projectRecordBatchSize = 100
+
+// archiveWriter compresses an archive and saves it in chunks of StreamChunkSize bytes.
+// Archives are saved like pending uploads, so they can be read with StreamArchive
+// and are deleted when uploads expire.
+type archiveWriter struct {
+	ctx  context.Context
+	db   *storage.Client
+	key  string
+	zw   *gzip.Writer
+	buf  []byte
+	size int64
+}
+
+func newArchiveWriter(ctx context.Context, db *storage.Client, key string) *archiveWriter {
+	w := &archiveWriter{ctx: ctx, db: db, key: key}
+	w.zw = gzip.NewWriter(w)
+	return w
+}
+
+// writeMessage appends the fields of a partial archive to the archive.
+// Repeated fields of serialized messages are concatenated when they are parsed,
+// so an archive written in parts is read as a single ProjectArchive message.
+func (w *archiveWriter) writeMessage(m *rpc.ProjectArchive) error {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	_, err = w.zw.Write(b)
+	return err
+}
+
+// close finishes the archive and saves its last chunk.
+func (w *archiveWriter) close() error {
+	if err := w.zw.Close(); err != nil {
+		return err
+	}
+	return w.flush(len(w.buf))
+}
+
+func (w *archiveWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= StreamChunkSize {
+		if err := w.flush(StreamChunkSize); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *archiveWriter) flush(n int) error {
+	if n == 0 {
+		return nil
+	}
+	if err := w.db.SaveUploadChunk(w.ctx, &models.UploadChunk{
+		UploadID:    w.key,
+		WriteOffset: w.size,
+		Key:         w.key,
+		Data:        w.buf[:n],
+		CreateTime:  time.Now().Round(time.Microsecond),
+	}); err != nil {
+		return err
+	}
+	w.size += int64(n)
+	w.buf = append([]byte(nil), w.buf[n:]...)
+	return nil
+}
+
+// archiveReader reads a saved archive one top-level field at a time. Apart from
+// the fields that describe the archive and its project, each field is a record,
+// so archives can be imported without holding all of their records in memory.
+type archiveReader struct {
+	upload  *uploadReader
+	r       *bufio.Reader
+	pending []byte // A record field that was read but not returned.
+}
+
+// openArchive opens a saved archive and reads the fields that precede its records.
+func openArchive(ctx context.Context, db *storage.Client, name names.Archive) (*archiveReader, *rpc.ProjectArchive, error) {
+	size, err := db.GetUploadSize(ctx, name.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	if size == 0 {
+		return nil, nil, status.Errorf(codes.NotFound, "%q not found", name)
+	}
+	upload := &uploadReader{ctx: ctx, db: db, id: name.String(), length: size}
+	zr, err := gzip.NewReader(upload)
+	if err != nil {
+		return nil, nil, archiveError(err)
+	}
+	r := &archiveReader{upload: upload, r: bufio.NewReader(zr)}
+
+	header := &rpc.ProjectArchive{}
+	for {
+		num, field, err := r.readField()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, archiveError(err)
+		}
+		if !isArchiveHeaderField(num) {
+			r.pending = field
+			break
+		}
+		if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(field, header); err != nil {
+			return nil, nil, archiveError(err)
+		}
+	}
+	return r, header, nil
+}
+
+// isArchiveHeaderField returns true for the fields of an archive that aren't records:
+// format_version, create_time and project.
+func isArchiveHeaderField(num protowire.Number) bool {
+	return num >= 1 && num <= 3
+}
+
+// next returns the next record field of the archive, or io.EOF at its end.
+func (r *archiveReader) next() ([]byte, error) {
+	if field := r.pending; field != nil {
+		r.pending = nil
+		return field, nil
+	}
+	num, field, err := r.readField()
+	if err != nil {
+		return nil, err
+	}
+	if isArchiveHeaderField(num) {
+		return nil, fmt.Errorf("field %d follows the records of the archive", num)
+	}
+	return field, nil
+}
+
+// readField reads the encoding of a field, returning io.EOF if there are no more fields.
+func (r *archiveReader) readField() (protowire.Number, []byte, error) {
+	tag, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, nil, err
+	}
+	num, typ := protowire.DecodeTag(tag)
+	if num < protowire.MinValidNumber {
+		return 0, nil, fmt.Errorf("invalid field number %d", num)
+	}
+	field := protowire.AppendVarint(nil, tag)
+	switch typ {
+	case protowire.VarintType:
+		v, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return 0, nil, unexpectedEOF(err)
+		}
+		field = protowire.AppendVarint(field, v)
+	case protowire.Fixed32Type, protowire.Fixed64Type:
+		v := make([]byte, 4)
+		if typ == protowire.Fixed64Type {
+			v = make([]byte, 8)
+		}
+		if _, err := io.ReadFull(r.r, v); err != nil {
+			return 0, nil, unexpectedEOF(err)
+		}
+		field = append(field, v...)
+	case protowire.BytesType:
+		n, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			return 0, nil, unexpectedEOF(err)
+		}
+		// Values are read as they arrive rather than into a buffer of the
+		// declared length, which may be wrong.
+		v, err := io.ReadAll(io.LimitReader(r.r, int64(n)))
+		if err != nil {
+			return 0, nil, err
+		}
+		if uint64(len(v)) != n {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		field = protowire.AppendBytes(field, v)
+	default:
+		return 0, nil, fmt.Errorf("unsupported wire type %d of field %d", typ, num)
+	}
+	return num, field, nil
+}
+
+// progress returns the percentage of the compressed archive that has been read.
+func (r *archiveReader) progress() int32 {
+	return progressPercent(int(r.upload.offset), int(r.upload.length))
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// archiveError returns an InvalidArgument error for an archive that can't be
+// read, unless the error is already a status, such as a storage error.
+func archiveError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.InvalidArgument, "invalid archive: %s", err)
+}
+
+// StreamArchive handles the corresponding API request.
+func (s *RegistryServer) StreamArchive(req *rpc.StreamArchiveRequest, stream rpc.Admin_StreamArchiveServer) error {
+	ctx := stream.Context()
+	name, err := names.ParseArchive(req.GetName())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.GetReadOffset() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid read_offset %d: must not be negative", req.GetReadOffset())
+	}
+
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	size, err := db.GetUploadSize(ctx, name.String())
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return status.Errorf(codes.NotFound, "%q not found", name)
+	}
+	if req.GetReadOffset() > size {
+		return status.Errorf(codes.OutOfRange, "read_offset %d is beyond the end of the archive", req.GetReadOffset())
+	}
+
+	// Chunks are read from storage one at a time as they are streamed.
+	for offset := req.GetReadOffset(); offset < size; {
+		chunk, err := db.GetUploadChunk(ctx, name.String(), offset)
+		if err != nil {
+			return err
+		}
+		data := chunk.Data[offset-chunk.WriteOffset:]
+		if err := stream.Send(&rpc.StreamArchiveResponse{
+			Offset: offset,
+			Data:   data,
+		}); err != nil {
+			return err
+		}
+		offset += int64(len(data))
+	}
+	return nil
+}
+
+// UploadArchive handles the corresponding API request.
+func (s *RegistryServer) UploadArchive(stream rpc.Admin_UploadArchiveServer) error {
+	ctx := stream.Context()
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "upload must contain at least one request")
+	} else if err != nil {
+		return err
+	}
+	name, err := names.ParseArchive(req.GetName())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Archives are identified by their names, which are unique, so they are
+	// uploaded with their names as IDs. Archives don't belong to a project,
+	// so they aren't counted in project quotas.
+	upload, err := s.receiveUpload(ctx, name.String(), name.String(), "", req, func() (uploadRequest, error) {
+		return stream.Recv()
+	})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&rpc.UploadArchiveResponse{CommittedSize: upload.committed})
+}
+
+// ImportProject handles the corresponding API request.
+// Records are read from the archive and written in batches by a long-running operation.
+func (s *RegistryServer) ImportProject(ctx context.Context, req *rpc.ImportProjectRequest) (*longrunning.Operation, error) {
+	name, err := names.ParseArchive(req.GetArchive())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	_, header, err := openArchive(ctx, db, name)
+	if err != nil {
+		return nil, err
+	}
+	if v := header.GetFormatVersion(); v < 1 || v > ProjectArchiveFormatVersion {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported archive format version %d", v)
+	}
+	source, err := names.ParseProject(header.GetProject().GetName())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid archive: %s", err)
+	}
+	target := source
+	if req.GetProjectId() != "" {
+		target = names.Project{ProjectID: req.GetProjectId()}
+	}
+	if err := target.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	mode := storage.ConflictFail
+	switch req.GetConflictPolicy() {
+	case rpc.ImportProjectRequest_CONFLICT_POLICY_UNSPECIFIED, rpc.ImportProjectRequest_FAIL, rpc.ImportProjectRequest_REPLACE:
+	case rpc.ImportProjectRequest_SKIP:
+		mode = storage.ConflictSkip
+	case rpc.ImportProjectRequest_OVERWRITE:
+		mode = storage.ConflictOverwrite
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported conflict policy %s", req.GetConflictPolicy())
+	}
+
+	// The archived project is admitted under the name that it is imported with.
+	admitted := proto.Clone(header.GetProject()).(*rpc.Project)
+	admitted.Name = target.String()
+	if err := s.admit(ctx, "ImportProject", target.String(), admitted); err != nil {
+		return nil, err
+	}
+
+	metadata := &rpc.ImportProjectMetadata{}
+	return s.startOperation(ctx, "", "ImportProject", metadata, func(ctx context.Context, report func()) (proto.Message, error) {
+		response := &rpc.ImportProjectResponse{}
+		if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+			archive, _, err := openArchive(ctx, db, name)
+			if err != nil {
+				return err
+			}
+			project := models.NewProject(target, admitted)
+			project.CreateTime, project.UpdateTime = timeOf(admitted.GetCreateTime()), timeOf(admitted.GetUpdateTime())
+			if req.GetConflictPolicy() == rpc.ImportProjectRequest_REPLACE {
+				if err := db.DeleteProject(ctx, target, true); err != nil && !isNotFound(err) {
+					return err
+				}
+			}
+
+			// Quotas are checked after each batch so that imports that exceed them stop early.
+			insert := func(r *storage.ProjectRecords) error {
+				written, skipped, err := db.InsertProjectRecords(ctx, r, mode)
+				metadata.ImportedCount += int32(written)
+				metadata.SkippedCount += int32(skipped)
+				if err != nil {
+					return err
+				}
+				if err := s.checkProjectQuotas(ctx, db, target.ProjectID); err != nil {
+					return err
+				}
+				// Progress is recorded once per percent rather than once per batch.
+				if p := archive.progress(); p != metadata.ProgressPercent {
+					metadata.ProgressPercent = p
+					report()
+				}
+				return nil
+			}
+			if err := insert(&storage.ProjectRecords{Project: project}); err != nil {
+				return err
+			}
+			batch, n := &rpc.ProjectArchive{}, 0
+			for done := false; !done; {
+				field, err := archive.next()
+				if err == io.EOF {
+					done = true
+				} else if err != nil {
+					return archiveError(err)
+				} else if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(field, batch); err != nil {
+					return archiveError(err)
+				} else {
+					n++
+				}
+				if n == projectRecordBatchSize || (done && n > 0) {
+					records, err := recordsForArchive(batch, source, target)
+					if err != nil {
+						return status.Errorf(codes.InvalidArgument, "invalid archive: %s", err)
+					}
+					if err := insert(records); err != nil {
+						return err
+					}
+					batch, n = &rpc.ProjectArchive{}, 0
+				}
+			}
+
+			// References are checked once all records are written,
+			// since they can refer to records later in the archive.
+			if err := s.validateProjectReferences(ctx, db, target); err != nil {
+				return err
+			}
+			response.ImportedCount, response.SkippedCount = metadata.ImportedCount, metadata.SkippedCount
+			imported, err := db.GetProject(ctx, target)
+			if err != nil {
+				return err
+			}
+			response.Project = imported.Message()
+			return nil
+		}); err != nil {
+			// Nothing was written, so the counts don't describe the result.
+			metadata.ImportedCount, metadata.SkippedCount = 0, 0
+			return nil, err
+		}
+		s.notify(ctx, rpc.Notification_UPDATED, target.String())
+		return response, nil
+	})
+}
+
+// archiveForRecords converts a batch of the stored records of a project into
+// a partial archive that contains the records.
+func archiveForRecords(r *storage.ProjectRecords) (*rpc.ProjectArchive, error) {
+	archive := &rpc.ProjectArchive{}
+
+	contents := make(map[string][]byte, len(r.Blobs))
+	for _, b := range r.Blobs {
+		contents[b.Key] = b.Contents
+	}
+
+	for _, v := range r.Apis {
+		m, err := v.Message()
+		if err != nil {
+			return nil, err
+		}
+		archive.Apis = append(archive.Apis, m)
+	}
+	for _, v := range r.Versions {
+		m, err := v.Message()
+		if err != nil {
+			return nil, err
+		}
+		archive.Versions = append(archive.Versions, m)
+	}
+	for _, v := range r.Specs {
+		m, err := v.BasicMessage(v.RevisionName())
+		if err != nil {
+			return nil, err
+		}
+		m.Contents = contents[v.Key]
+		archive.SpecRevisions = append(archive.SpecRevisions, m)
+	}
+	for _, v := range r.Deployments {
+		m, err := v.BasicMessage(v.RevisionName())
+		if err != nil {
+			return nil, err
+		}
+		archive.DeploymentRevisions = append(archive.DeploymentRevisions, m)
+	}
+	for _, v := range r.Artifacts {
+		m := v.Message()
+		m.Contents = contents[v.Key]
+		archive.Artifacts = append(archive.Artifacts, m)
+	}
+	for _, v := range r.SpecTags {
+		archive.SpecRevisionTags = append(archive.SpecRevisionTags, &rpc.ProjectArchive_Tag{
+			Revision: names.SpecRevision{
+				ProjectID:  v.ProjectID,
+				LocationID: v.LocationID,
+				ApiID:      v.ApiID,
+				VersionID:  v.VersionID,
+				SpecID:     v.SpecID,
+				RevisionID: v.RevisionID,
+			}.String(),
+			Tag:        v.Tag,
+			CreateTime: timestamppb.New(v.CreateTime),
+			UpdateTime: timestamppb.New(v.UpdateTime),
+		})
+	}
+	for _, v := range r.DeploymentTags {
+		archive.DeploymentRevisionTags = append(archive.DeploymentRevisionTags, &rpc.ProjectArchive_Tag{
+			Revision: names.DeploymentRevision{
+				ProjectID:    v.ProjectID,
+				LocationID:   v.LocationID,
+				ApiID:        v.ApiID,
+				DeploymentID: v.DeploymentID,
+				RevisionID:   v.RevisionID,
+			}.String(),
+			Tag:        v.Tag,
+			CreateTime: timestamppb.New(v.CreateTime),
+			UpdateTime: timestamppb.New(v.UpdateTime),
+		})
+	}
+	return archive, nil
+}
+
+// recordsForArchive converts the records of a partial archive into records of
+// the target project. Resource names and references that refer to the source
+// project are moved to the target project, and all timestamps and revision IDs
+// are preserved.
+func recordsForArchive(archive *rpc.ProjectArchive, source, target names.Project) (*storage.ProjectRecords, error) {
+	rename := func(name string) string {
+		if prefix := source.String() + "/"; strings.HasPrefix(name, prefix) {
+			return target.String() + "/" + strings.TrimPrefix(name, prefix)
+		}
+		return name
+	}
+	r := &storage.ProjectRecords{}
+
+	for _, m := range archive.GetApis() {
+		name, err := names.ParseApi(rename(m.GetName()))
+		if err != nil {
+			return nil, err
+		}
+		if name.ProjectID != target.ProjectID {
+			return nil, fmt.Errorf("%s is not in %s", m.GetName(), source)
+		}
+		m = proto.Clone(m).(*rpc.Api)
+		m.RecommendedVersion, m.RecommendedDeployment = rename(m.GetRecommendedVersion()), rename(m.GetRecommendedDeployment())
+		v, err := models.NewApi(name, m)
+		if err != nil {
+			return nil, err
+		}
+		v.CreateTime, v.UpdateTime = timeOf(m.GetCreateTime()), timeOf(m.GetUpdateTime())
+		r.Apis = append(r.Apis, v)
+	}
+	for _, m := range archive.GetVersions() {
+		name, err := names.ParseVersion(rename(m.GetName()))
+		if err != nil {
+			return nil, err
+		}
+		if name.ProjectID != target.ProjectID {
+			return nil, fmt.Errorf("%s is not in %s", m.GetName(), source)
+		}
+		v, err := models.NewVersion(name, m)
+		if err != nil {
+			return nil, err
+		}
+		v.CreateTime, v.UpdateTime = timeOf(m.GetCreateTime()), timeOf(m.GetUpdateTime())
+		r.Versions = append(r.Versions, v)
+	}
+	for _, m := range archive.GetSpecRevisions() {
+		name, err := names.ParseSpecRevision(rename(m.GetName()))
+		if err != nil {
+			return nil, err
+		}
+		if name.ProjectID != target.ProjectID || name.RevisionID == "" {
+			return nil, fmt.Errorf("%s is not a spec revision in %s", m.GetName(), source)
+		}
+		v, err := models.NewSpec(name.Spec(), m)
+		if err != nil {
+			return nil, err
+		}
+		v.RevisionID = name.RevisionID
+		v.CreateTime = timeOf(m.GetCreateTime())
+		v.RevisionCreateTime, v.RevisionUpdateTime = timeOf(m.GetRevisionCreateTime()), timeOf(m.GetRevisionUpdateTime())
+		r.Specs = append(r.Specs, v)
+		if m.GetContents() != nil {
+			b := models.NewBlobForSpec(v, m.GetContents())
+			b.CreateTime, b.UpdateTime = v.RevisionCreateTime, v.RevisionUpdateTime
+			r.Blobs = append(r.Blobs, b)
+		}
+	}
+	for _, m := range archive.GetDeploymentRevisions() {
+		name, err := names.ParseDeploymentRevision(rename(m.GetName()))
+		if err != nil {
+			return nil, err
+		}
+		if name.ProjectID != target.ProjectID || name.RevisionID == "" {
+			return nil, fmt.Errorf("%s is not a deployment revision in %s", m.GetName(), source)
+		}
+		m = proto.Clone(m).(*rpc.ApiDeployment)
+		m.ApiSpecRevision = rename(m.GetApiSpecRevision())
+		v, err := models.NewDeployment(name.Deployment(), m)
+		if err != nil {
+			return nil, err
+		}
+		v.RevisionID = name.RevisionID
+		v.CreateTime = timeOf(m.GetCreateTime())
+		v.RevisionCreateTime, v.RevisionUpdateTime = timeOf(m.GetRevisionCreateTime()), timeOf(m.GetRevisionUpdateTime())
+		r.Deployments = append(r.Deployments, v)
+	}
+	for _, m := range archive.GetArtifacts() {
+		name, err := names.ParseArtifact(rename(m.GetName()))
+		if err != nil {
+			return nil, err
+		}
+		if name.ProjectID() != target.ProjectID {
+			return nil, fmt.Errorf("%s is not in %s", m.GetName(), source)
+		}
+		v, err := models.NewArtifact(name, m)
+		if err != nil {
+			return nil, err
+		}
+		v.CreateTime, v.UpdateTime = timeOf(m.GetCreateTime()), timeOf(m.GetUpdateTime())
+		r.Artifacts = append(r.Artifacts, v)
+		if m.GetContents() != nil {
+			b := models.NewBlobForArtifact(v, m.GetContents())
+			b.CreateTime, b.UpdateTime = v.CreateTime, v.UpdateTime
+			r.Blobs = append(r.Blobs, b)
+		}
+	}
+	for _, t := range archive.GetSpecRevisionTags() {
+		name, err := names.ParseSpecRevision(rename(t.GetRevision()))
+		if err != nil {
+			return nil, err
+		}
+		if name.ProjectID != target.ProjectID {
+			return nil, fmt.Errorf("%s is not in %s", t.GetRevision(), source)
+		}
+		v := models.NewSpecRevisionTag(name, t.GetTag())
+		v.CreateTime, v.UpdateTime = timeOf(t.GetCreateTime()), timeOf(t.GetUpdateTime())
+		r.SpecTags = append(r.SpecTags, v)
+	}
+	for _, t := range archive.GetDeploymentRevisionTags() {
+		name, err := names.ParseDeploymentRevision(rename(t.GetRevision()))
+		if err != nil {
+			return nil, err
+		}
+		if name.ProjectID != target.ProjectID {
+			return nil, fmt.Errorf("%s is not in %s", t.GetRevision(), source)
+		}
+		v := models.NewDeploymentRevisionTag(name, t.GetTag())
+		v.CreateTime, v.UpdateTime = timeOf(t.GetCreateTime()), timeOf(t.GetUpdateTime())
+		r.DeploymentTags = append(r.DeploymentTags, v)
+	}
+	return r, nil
+}
+
+// timeOf converts an archived timestamp to the precision used in storage.
+func timeOf(t *timestamppb.Timestamp) time.Time {
+	return t.AsTime().Round(time.Microsecond)
+}
@@ -0,0 +1,650 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// exportProject exports a project and returns the name of its archive.
+func exportProject(ctx context.Context, t *testing.T, server *RegistryServer, name string) string {
+	t.Helper()
+	op, err := server.ExportProject(ctx, &rpc.ExportProjectRequest{Name: name})
+	if err != nil {
+		t.Fatalf("ExportProject(%s) returned error: %s", name, err)
+	}
+	op, err = waitForOperation(ctx, t, server, op)
+	if err != nil {
+		t.Fatalf("ExportProject(%s) operation returned error: %s", name, err)
+	}
+	response := &rpc.ExportProjectResponse{}
+	if err := op.GetResponse().UnmarshalTo(response); err != nil {
+		t.Fatalf("ExportProject(%s) returned unexpected response: %s", name, err)
+	}
+	return response.GetArchive()
+}
+
+// readArchive reads an archive saved by the server.
+func readArchive(ctx context.Context, t *testing.T, server *RegistryServer, name string) *rpc.ProjectArchive {
+	t.Helper()
+	b, err := server.storageClient.GetUploadContents(ctx, name)
+	if err != nil {
+		t.Fatalf("GetUploadContents(%s) returned error: %s", name, err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Archive %s isn't compressed: %s", name, err)
+	}
+	b, err = io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to uncompress archive %s: %s", name, err)
+	}
+	archive := &rpc.ProjectArchive{}
+	if err := proto.Unmarshal(b, archive); err != nil {
+		t.Fatalf("Failed to unmarshal archive %s: %s", name, err)
+	}
+	return archive
+}
+
+// saveArchive saves the contents of an archive like an upload and returns its name.
+func saveArchive(ctx context.Context, t *testing.T, server *RegistryServer, id string, b []byte) string {
+	t.Helper()
+	name := "archives/" + id
+	if err := server.storageClient.SaveUploadChunk(ctx, &models.UploadChunk{UploadID: name, Key: name, Data: b, CreateTime: time.Now()}); err != nil {
+		t.Fatalf("Setup: SaveUploadChunk(%s) returned error: %s", name, err)
+	}
+	return name
+}
+
+func importProject(ctx context.Context, t *testing.T, server *RegistryServer, req *rpc.ImportProjectRequest) (*rpc.ImportProjectResponse, error) {
+	t.Helper()
+	op, err := server.ImportProject(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	op, err = waitForOperation(ctx, t, server, op)
+	if err != nil {
+		return nil, err
+	}
+	response := &rpc.ImportProjectResponse{}
+	return response, op.GetResponse().UnmarshalTo(response)
+}
+
+func TestProjectExportImport(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithReferences(t, false, "")
+	spec := seedReferences(ctx, t, server)
+	if _, err := server.TagApiSpecRevision(ctx, &rpc.TagApiSpecRevisionRequest{
+		Name: spec.GetName() + "@" + spec.GetRevisionId(),
+		Tag:  "prod",
+	}); err != nil {
+		t.Fatalf("Setup: TagApiSpecRevision() returned error: %s", err)
+	}
+	if _, err := server.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+		ApiSpec: &rpc.ApiSpec{
+			Name:     spec.GetName(),
+			Contents: []byte("second revision"),
+		},
+	}); err != nil {
+		t.Fatalf("Setup: UpdateApiSpec() returned error: %s", err)
+	}
+	if _, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     "projects/my-project/locations/global/apis/a",
+		ArtifactId: "x",
+		Artifact:   &rpc.Artifact{MimeType: "text/plain", Contents: []byte("artifact contents")},
+	}); err != nil {
+		t.Fatalf("Setup: CreateArtifact() returned error: %s", err)
+	}
+
+	archive := exportProject(ctx, t, server, "projects/my-project")
+	response, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{
+		Archive:   archive,
+		ProjectId: "copy",
+	})
+	if err != nil {
+		t.Fatalf("ImportProject() returned error: %s", err)
+	}
+	if response.GetProject().GetName() != "projects/copy" || response.GetImportedCount() == 0 || response.GetSkippedCount() != 0 {
+		t.Errorf("ImportProject() returned unexpected response %+v", response)
+	}
+
+	// The copy should be identical to the original except for its names.
+	want := readArchive(ctx, t, server, archive)
+	got := readArchive(ctx, t, server, exportProject(ctx, t, server, "projects/copy"))
+	opts := cmp.Options{
+		protocmp.Transform(),
+		protocmp.IgnoreFields(&rpc.ProjectArchive{}, "create_time"),
+		cmp.Transformer("rename", func(s string) string {
+			return strings.ReplaceAll(s, "projects/my-project/", "projects/copy/")
+		}),
+	}
+	want.Project.Name = "projects/copy"
+	if !cmp.Equal(want, got, opts) {
+		t.Errorf("ImportProject() created unexpected diff (-want +got):\n%s", cmp.Diff(want, got, opts))
+	}
+	if len(got.GetSpecRevisions()) != 2 || len(got.GetSpecRevisionTags()) != 1 || len(got.GetArtifacts()) != 1 {
+		t.Errorf("ImportProject() did not import all revisions, tags and artifacts: %+v", got)
+	}
+
+	deployment, err := server.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{
+		Name: "projects/copy/locations/global/apis/a/deployments/d",
+	})
+	if err != nil {
+		t.Fatalf("GetApiDeployment() returned error: %s", err)
+	}
+	if want := "projects/copy/locations/global/apis/a/versions/v/specs/s@" + spec.GetRevisionId(); deployment.GetApiSpecRevision() != want {
+		t.Errorf("GetApiDeployment() returned api_spec_revision %q, want %q", deployment.GetApiSpecRevision(), want)
+	}
+	tagged, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{
+		Name: "projects/copy/locations/global/apis/a/versions/v/specs/s@prod",
+	})
+	if err != nil {
+		t.Fatalf("GetApiSpec() returned error: %s", err)
+	}
+	if tagged.GetRevisionId() != spec.GetRevisionId() {
+		t.Errorf("GetApiSpec() returned revision %q for the tagged revision, want %q", tagged.GetRevisionId(), spec.GetRevisionId())
+	}
+	contents, err := server.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{
+		Name: "projects/copy/locations/global/apis/a/versions/v/specs/s",
+	})
+	if err != nil {
+		t.Fatalf("GetApiSpecContents() returned error: %s", err)
+	}
+	if string(contents.GetData()) != "second revision" {
+		t.Errorf("GetApiSpecContents() returned %q, want %q", contents.GetData(), "second revision")
+	}
+}
+
+func TestProjectImportConflicts(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithReferences(t, false, "")
+	seedReferences(ctx, t, server)
+	archive := exportProject(ctx, t, server, "projects/my-project")
+
+	if _, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{
+		Archive: archive,
+	}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("ImportProject() with FAIL policy returned status code %s, want %s: %v", status.Code(err), codes.AlreadyExists, err)
+	}
+
+	response, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{
+		Archive:        archive,
+		ConflictPolicy: rpc.ImportProjectRequest_SKIP,
+	})
+	if err != nil {
+		t.Fatalf("ImportProject() with SKIP policy returned error: %s", err)
+	}
+	if response.GetImportedCount() != 0 || response.GetSkippedCount() == 0 {
+		t.Errorf("ImportProject() with SKIP policy returned unexpected counts %+v", response)
+	}
+
+	if _, err := server.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api: &rpc.Api{Name: "projects/my-project/locations/global/apis/a", DisplayName: "Changed"},
+	}); err != nil {
+		t.Fatalf("Setup: UpdateApi() returned error: %s", err)
+	}
+	if _, err := server.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: "projects/my-project/locations/global",
+		ApiId:  "extra",
+		Api:    &rpc.Api{},
+	}); err != nil {
+		t.Fatalf("Setup: CreateApi() returned error: %s", err)
+	}
+
+	response, err = importProject(ctx, t, server, &rpc.ImportProjectRequest{
+		Archive:        archive,
+		ConflictPolicy: rpc.ImportProjectRequest_OVERWRITE,
+	})
+	if err != nil {
+		t.Fatalf("ImportProject() with OVERWRITE policy returned error: %s", err)
+	}
+	if response.GetImportedCount() == 0 || response.GetSkippedCount() != 0 {
+		t.Errorf("ImportProject() with OVERWRITE policy returned unexpected counts %+v", response)
+	}
+	api, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: "projects/my-project/locations/global/apis/a"})
+	if err != nil {
+		t.Fatalf("GetApi() returned error: %s", err)
+	}
+	if api.GetDisplayName() != "" {
+		t.Errorf("ImportProject() with OVERWRITE policy kept display name %q", api.GetDisplayName())
+	}
+	if _, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: "projects/my-project/locations/global/apis/extra"}); err != nil {
+		t.Errorf("ImportProject() with OVERWRITE policy deleted an unarchived API: %s", err)
+	}
+
+	if _, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{
+		Archive:        archive,
+		ConflictPolicy: rpc.ImportProjectRequest_REPLACE,
+	}); err != nil {
+		t.Fatalf("ImportProject() with REPLACE policy returned error: %s", err)
+	}
+	if _, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: "projects/my-project/locations/global/apis/extra"}); status.Code(err) != codes.NotFound {
+		t.Errorf("ImportProject() with REPLACE policy kept an unarchived API: %v", err)
+	}
+}
+
+func TestProjectImportInvalid(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithReferences(t, false, "")
+	seedReferences(ctx, t, server)
+	archive := exportProject(ctx, t, server, "projects/my-project")
+
+	future := readArchive(ctx, t, server, archive)
+	future.FormatVersion = ProjectArchiveFormatVersion + 1
+	b, err := proto.Marshal(future)
+	if err != nil {
+		t.Fatalf("Setup: failed to marshal archive: %s", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatalf("Setup: failed to compress archive: %s", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Setup: failed to compress archive: %s", err)
+	}
+
+	tests := []struct {
+		desc string
+		req  *rpc.ImportProjectRequest
+		want codes.Code
+	}{
+		{
+			desc: "missing archive",
+			req:  &rpc.ImportProjectRequest{},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "invalid archive name",
+			req:  &rpc.ImportProjectRequest{Archive: "projects/my-project"},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "unknown archive",
+			req:  &rpc.ImportProjectRequest{Archive: "archives/unknown"},
+			want: codes.NotFound,
+		},
+		{
+			desc: "uncompressed archive",
+			req:  &rpc.ImportProjectRequest{Archive: saveArchive(ctx, t, server, "uncompressed", b)},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "unsupported format version",
+			req:  &rpc.ImportProjectRequest{Archive: saveArchive(ctx, t, server, "future", buf.Bytes())},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "invalid project id",
+			req:  &rpc.ImportProjectRequest{Archive: archive, ProjectId: "Invalid Project"},
+			want: codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := server.ImportProject(ctx, test.req); status.Code(err) != test.want {
+				t.Errorf("ImportProject() returned status code %s, want %s: %v", status.Code(err), test.want, err)
+			}
+		})
+	}
+
+	if _, err := server.ExportProject(ctx, &rpc.ExportProjectRequest{Name: "projects/missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("ExportProject() returned status code %s, want %s: %v", status.Code(err), codes.NotFound, err)
+	}
+}
+
+func TestProjectExportImportBatches(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithReferences(t, false, "")
+	// More APIs than fit in a batch, so that records are read and written in several batches.
+	count := 2*projectRecordBatchSize + 1
+	apis := make([]*rpc.Api, count)
+	for i := range apis {
+		apis[i] = &rpc.Api{Name: fmt.Sprintf("projects/my-project/locations/global/apis/a%03d", i)}
+	}
+	if err := seeder.SeedApis(ctx, server, apis...); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+
+	archive := exportProject(ctx, t, server, "projects/my-project")
+	if got := len(readArchive(ctx, t, server, archive).GetApis()); got != count {
+		t.Errorf("ExportProject() archived %d APIs, want %d", got, count)
+	}
+	response, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{
+		Archive:   archive,
+		ProjectId: "copy",
+	})
+	if err != nil {
+		t.Fatalf("ImportProject() returned error: %s", err)
+	}
+	// The project is imported with its APIs.
+	if got, want := response.GetImportedCount(), int32(count+1); got != want {
+		t.Errorf("ImportProject() imported %d records, want %d", got, want)
+	}
+	if got := len(readArchive(ctx, t, server, exportProject(ctx, t, server, "projects/copy")).GetApis()); got != count {
+		t.Errorf("ImportProject() imported %d APIs, want %d", got, count)
+	}
+}
+
+func TestProjectImportQuotas(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithQuotas(t, QuotaConfig{
+		Projects: map[string]QuotaLimits{
+			"few-apis":      {MaxApis: 1},
+			"few-revisions": {MaxRevisionsPerSpec: 1},
+			"few-bytes":     {MaxBlobBytes: 10},
+		},
+	})
+	spec := seedReferences(ctx, t, server)
+	if _, err := server.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: "projects/my-project/locations/global",
+		ApiId:  "b",
+		Api:    &rpc.Api{},
+	}); err != nil {
+		t.Fatalf("Setup: CreateApi() returned error: %s", err)
+	}
+	if _, err := server.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+		ApiSpec: &rpc.ApiSpec{
+			Name:     spec.GetName(),
+			Contents: []byte("contents of the second revision"),
+		},
+	}); err != nil {
+		t.Fatalf("Setup: UpdateApiSpec() returned error: %s", err)
+	}
+	archive := exportProject(ctx, t, server, "projects/my-project")
+
+	for _, project := range []string{"few-apis", "few-revisions", "few-bytes"} {
+		t.Run(project, func(t *testing.T) {
+			_, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{
+				Archive:   archive,
+				ProjectId: project,
+			})
+			checkQuotaExceeded(t, "ImportProject", err)
+			if _, err := server.GetProject(ctx, &rpc.GetProjectRequest{Name: "projects/" + project}); status.Code(err) != codes.NotFound {
+				t.Errorf("GetProject() returned status code %s, want %s: %v", status.Code(err), codes.NotFound, err)
+			}
+		})
+	}
+}
+
+func TestProjectImportReferences(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithReferences(t, true, ReferenceDeletionIgnore)
+	seedReferences(ctx, t, server)
+	valid := exportProject(ctx, t, server, "projects/my-project")
+
+	// Deleting the version leaves the references to it and its spec dangling.
+	if _, err := server.DeleteApiVersion(ctx, &rpc.DeleteApiVersionRequest{
+		Name:  "projects/my-project/locations/global/apis/a/versions/v",
+		Force: true,
+	}); err != nil {
+		t.Fatalf("Setup: DeleteApiVersion() returned error: %s", err)
+	}
+	dangling := exportProject(ctx, t, server, "projects/my-project")
+
+	if _, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{
+		Archive:   valid,
+		ProjectId: "valid",
+	}); err != nil {
+		t.Errorf("ImportProject() returned error: %s", err)
+	}
+	if _, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{
+		Archive:   dangling,
+		ProjectId: "dangling",
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ImportProject() returned status code %s, want %s: %v", status.Code(err), codes.InvalidArgument, err)
+	}
+	if _, err := server.GetProject(ctx, &rpc.GetProjectRequest{Name: "projects/dangling"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProject() returned status code %s, want %s: %v", status.Code(err), codes.NotFound, err)
+	}
+}
+
+func TestProjectExportImportLocation(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithReferences(t, false, "")
+	const location = "projects/my-project/locations/us-east1"
+	if _, err := server.CreateProject(ctx, &rpc.CreateProjectRequest{ProjectId: "my-project", Project: &rpc.Project{}}); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+	if _, err := server.CreateApi(ctx, &rpc.CreateApiRequest{Parent: location, ApiId: "a", Api: &rpc.Api{}}); err != nil {
+		t.Fatalf("Setup: CreateApi() returned error: %s", err)
+	}
+	if _, err := server.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent: location + "/apis/a", ApiVersionId: "v", ApiVersion: &rpc.ApiVersion{},
+	}); err != nil {
+		t.Fatalf("Setup: CreateApiVersion() returned error: %s", err)
+	}
+	spec, err := server.CreateApiSpec(ctx, &rpc.CreateApiSpecRequest{
+		Parent:    location + "/apis/a/versions/v",
+		ApiSpecId: "s",
+		ApiSpec:   &rpc.ApiSpec{MimeType: "text/plain", Contents: []byte("spec contents")},
+	})
+	if err != nil {
+		t.Fatalf("Setup: CreateApiSpec() returned error: %s", err)
+	}
+	if _, err := server.TagApiSpecRevision(ctx, &rpc.TagApiSpecRevisionRequest{
+		Name: spec.GetName() + "@" + spec.GetRevisionId(),
+		Tag:  "prod",
+	}); err != nil {
+		t.Fatalf("Setup: TagApiSpecRevision() returned error: %s", err)
+	}
+	deployment, err := server.CreateApiDeployment(ctx, &rpc.CreateApiDeploymentRequest{
+		Parent: location + "/apis/a", ApiDeploymentId: "d", ApiDeployment: &rpc.ApiDeployment{},
+	})
+	if err != nil {
+		t.Fatalf("Setup: CreateApiDeployment() returned error: %s", err)
+	}
+	if _, err := server.TagApiDeploymentRevision(ctx, &rpc.TagApiDeploymentRevisionRequest{
+		Name: deployment.GetName() + "@" + deployment.GetRevisionId(),
+		Tag:  "prod",
+	}); err != nil {
+		t.Fatalf("Setup: TagApiDeploymentRevision() returned error: %s", err)
+	}
+	if _, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     location + "/apis/a",
+		ArtifactId: "x",
+		Artifact:   &rpc.Artifact{MimeType: "text/plain", Contents: []byte("artifact contents")},
+	}); err != nil {
+		t.Fatalf("Setup: CreateArtifact() returned error: %s", err)
+	}
+
+	archive := exportProject(ctx, t, server, "projects/my-project")
+	exported := readArchive(ctx, t, server, archive)
+	for _, tag := range append(exported.GetSpecRevisionTags(), exported.GetDeploymentRevisionTags()...) {
+		if !strings.HasPrefix(tag.GetRevision(), location+"/") {
+			t.Errorf("ExportProject() returned tag of %q, want a revision in %s", tag.GetRevision(), location)
+		}
+	}
+	if _, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{Archive: archive, ProjectId: "copy"}); err != nil {
+		t.Fatalf("ImportProject() returned error: %s", err)
+	}
+
+	const copied = "projects/copy/locations/us-east1/apis/a"
+	contents, err := server.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{Name: copied + "/versions/v/specs/s@prod"})
+	if err != nil {
+		t.Fatalf("GetApiSpecContents() returned error: %s", err)
+	}
+	if string(contents.GetData()) != "spec contents" {
+		t.Errorf("GetApiSpecContents() returned %q, want %q", contents.GetData(), "spec contents")
+	}
+	if _, err := server.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: copied + "/deployments/d@prod"}); err != nil {
+		t.Errorf("GetApiDeployment() returned error: %s", err)
+	}
+	artifact, err := server.GetArtifactContents(ctx, &rpc.GetArtifactContentsRequest{Name: copied + "/artifacts/x"})
+	if err != nil {
+		t.Fatalf("GetArtifactContents() returned error: %s", err)
+	}
+	if string(artifact.GetData()) != "artifact contents" {
+		t.Errorf("GetArtifactContents() returned %q, want %q", artifact.GetData(), "artifact contents")
+	}
+}
+
+func receiveArchive(t *testing.T, stream rpc.Admin_StreamArchiveClient) ([]byte, int, error) {
+	t.Helper()
+	var contents []byte
+	var chunks int
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return contents, chunks, nil
+		} else if err != nil {
+			return nil, 0, err
+		}
+		if resp.GetOffset() != int64(len(contents)) {
+			t.Errorf("Recv() returned offset %d, want %d", resp.GetOffset(), len(contents))
+		}
+		chunks++
+		contents = append(contents, resp.GetData()...)
+	}
+}
+
+func TestArchiveStreaming(t *testing.T) {
+	ctx := context.Background()
+	server, conn := streamingTestConn(t)
+	client := rpc.NewAdminClient(conn)
+	if _, err := server.CreateProject(ctx, &rpc.CreateProjectRequest{ProjectId: "my-project", Project: &rpc.Project{}}); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+	// Random contents don't compress, so the archive is saved in multiple chunks.
+	contents := make([]byte, StreamChunkSize*3/2)
+	rand.New(rand.NewSource(1)).Read(contents)
+	if _, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     "projects/my-project/locations/global",
+		ArtifactId: "x",
+		Artifact:   &rpc.Artifact{MimeType: "application/octet-stream", Contents: contents},
+	}); err != nil {
+		t.Fatalf("Setup: CreateArtifact() returned error: %s", err)
+	}
+
+	op, err := server.ExportProject(ctx, &rpc.ExportProjectRequest{Name: "projects/my-project"})
+	if err != nil {
+		t.Fatalf("ExportProject() returned error: %s", err)
+	}
+	if op, err = waitForOperation(ctx, t, server, op); err != nil {
+		t.Fatalf("ExportProject() operation returned error: %s", err)
+	}
+	response := &rpc.ExportProjectResponse{}
+	if err := op.GetResponse().UnmarshalTo(response); err != nil {
+		t.Fatalf("ExportProject() returned unexpected response: %s", err)
+	}
+	if len(op.GetResponse().GetValue()) >= StreamChunkSize {
+		t.Errorf("ExportProject() returned a response of %d bytes, want a reference to the archive", len(op.GetResponse().GetValue()))
+	}
+	saved, err := server.storageClient.GetUploadContents(ctx, response.GetArchive())
+	if err != nil {
+		t.Fatalf("GetUploadContents() returned error: %s", err)
+	}
+	if int64(len(saved)) != response.GetSizeBytes() {
+		t.Errorf("ExportProject() returned size %d, want %d", response.GetSizeBytes(), len(saved))
+	}
+
+	stream, err := client.StreamArchive(ctx, &rpc.StreamArchiveRequest{Name: response.GetArchive()})
+	if err != nil {
+		t.Fatalf("StreamArchive() returned error: %s", err)
+	}
+	got, chunks, err := receiveArchive(t, stream)
+	if err != nil {
+		t.Fatalf("StreamArchive() returned error: %s", err)
+	}
+	if !bytes.Equal(got, saved) || chunks < 2 {
+		t.Errorf("StreamArchive() returned %d bytes in %d chunks, want %d bytes in multiple chunks", len(got), chunks, len(saved))
+	}
+
+	offset := int64(StreamChunkSize + 10)
+	stream, err = client.StreamArchive(ctx, &rpc.StreamArchiveRequest{Name: response.GetArchive(), ReadOffset: offset})
+	if err != nil {
+		t.Fatalf("StreamArchive() returned error: %s", err)
+	}
+	if resp, err := stream.Recv(); err != nil {
+		t.Fatalf("StreamArchive() returned error: %s", err)
+	} else if resp.GetOffset() != offset || !bytes.Equal(resp.GetData(), saved[offset:offset+int64(len(resp.GetData()))]) {
+		t.Errorf("StreamArchive() with read_offset %d returned %d bytes at offset %d", offset, len(resp.GetData()), resp.GetOffset())
+	}
+	for _, test := range []struct {
+		desc string
+		req  *rpc.StreamArchiveRequest
+		want codes.Code
+	}{
+		{desc: "invalid name", req: &rpc.StreamArchiveRequest{Name: "projects/my-project"}, want: codes.InvalidArgument},
+		{desc: "unknown archive", req: &rpc.StreamArchiveRequest{Name: "archives/unknown"}, want: codes.NotFound},
+		{desc: "negative offset", req: &rpc.StreamArchiveRequest{Name: response.GetArchive(), ReadOffset: -1}, want: codes.InvalidArgument},
+		{desc: "offset beyond end", req: &rpc.StreamArchiveRequest{Name: response.GetArchive(), ReadOffset: response.GetSizeBytes() + 1}, want: codes.OutOfRange},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			stream, err := client.StreamArchive(ctx, test.req)
+			if err == nil {
+				_, _, err = receiveArchive(t, stream)
+			}
+			if status.Code(err) != test.want {
+				t.Errorf("StreamArchive() returned status code %s, want %s: %v", status.Code(err), test.want, err)
+			}
+		})
+	}
+
+	// Upload the archive in two streams, resuming at the committed size.
+	const name = "archives/uploaded"
+	upload, err := client.UploadArchive(ctx)
+	if err != nil {
+		t.Fatalf("UploadArchive() returned error: %s", err)
+	}
+	if err := upload.Send(&rpc.UploadArchiveRequest{Name: name, Data: saved[:offset]}); err != nil {
+		t.Fatalf("Send() returned error: %s", err)
+	}
+	if resp, err := upload.CloseAndRecv(); err != nil {
+		t.Fatalf("UploadArchive() returned error: %s", err)
+	} else if resp.GetCommittedSize() != offset {
+		t.Errorf("UploadArchive() returned committed size %d, want %d", resp.GetCommittedSize(), offset)
+	}
+	upload, err = client.UploadArchive(ctx)
+	if err != nil {
+		t.Fatalf("UploadArchive() returned error: %s", err)
+	}
+	if err := upload.Send(&rpc.UploadArchiveRequest{Name: name, WriteOffset: offset, Data: saved[offset:], FinishWrite: true}); err != nil {
+		t.Fatalf("Send() returned error: %s", err)
+	}
+	if resp, err := upload.CloseAndRecv(); err != nil {
+		t.Fatalf("UploadArchive() returned error: %s", err)
+	} else if resp.GetCommittedSize() != int64(len(saved)) {
+		t.Errorf("UploadArchive() returned committed size %d, want %d", resp.GetCommittedSize(), len(saved))
+	}
+
+	if _, err := importProject(ctx, t, server, &rpc.ImportProjectRequest{Archive: name, ProjectId: "copy"}); err != nil {
+		t.Fatalf("ImportProject() returned error: %s", err)
+	}
+	artifact, err := server.GetArtifactContents(ctx, &rpc.GetArtifactContentsRequest{Name: "projects/copy/locations/global/artifacts/x"})
+	if err != nil {
+		t.Fatalf("GetArtifactContents() returned error: %s", err)
+	}
+	if !bytes.Equal(artifact.GetData(), contents) {
+		t.Errorf("GetArtifactContents() returned %d bytes that differ from the exported contents", len(artifact.GetData()))
+	}
+}
@@ -42,7 +42,7 @@ func parseArtifactParent(name string) (artifactParent, error) {
 		return d, nil
 	} else if a, err := names.ParseApi(name); err == nil {
 		return a, nil
-	} else if p, err := names.ParseProjectWithLocation(name); err == nil {
+	} else if p, err := names.ParseLocation(name); err == nil {
 		return p, nil
 	}
 
@@ -65,13 +65,16 @@ func (s *RegistryServer) CreateArtifact(ctx context.Context, req *rpc.CreateArti
 	if err := name.Validate(); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "CreateArtifact", name.String(), req.GetArtifact()); err != nil {
+		return nil, err
+	}
 	var response *rpc.Artifact
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		// Creation should only succeed when the parent exists.
 		var err error
 		switch parent := parent.(type) {
-		case names.Project:
-			_, err = db.GetProject(ctx, parent)
+		case names.ProjectLocation:
+			_, err = db.GetProject(ctx, parent.Project())
 		case names.Api:
 			_, err = db.GetApi(ctx, parent)
 		case names.Version:
@@ -88,6 +91,9 @@ func (s *RegistryServer) CreateArtifact(ctx context.Context, req *rpc.CreateArti
 		if err != nil {
 			return err
 		}
+		if err := s.checkBlobQuota(ctx, db, artifact.ProjectID, artifact.Name(), len(req.Artifact.GetContents())); err != nil {
+			return err
+		}
 		if err := db.CreateArtifact(ctx, artifact); err != nil {
 			return err
 		}
@@ -111,6 +117,9 @@ func (s *RegistryServer) DeleteArtifact(ctx context.Context, req *rpc.DeleteArti
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "DeleteArtifact", name.String(), nil); err != nil {
+		return nil, err
+	}
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		return db.DeleteArtifact(ctx, name)
 	}); err != nil {
@@ -122,11 +131,15 @@ func (s *RegistryServer) DeleteArtifact(ctx context.Context, req *rpc.DeleteArti
 
 // GetArtifact handles the corresponding API request.
 func (s *RegistryServer) GetArtifact(ctx context.Context, req *rpc.GetArtifactRequest) (*rpc.Artifact, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.Artifact{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	name, err := names.ParseArtifact(req.GetName())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -137,12 +150,14 @@ func (s *RegistryServer) GetArtifact(ctx context.Context, req *rpc.GetArtifactRe
 		return nil, err
 	}
 
-	return artifact.Message(), nil
+	message := artifact.Message()
+	applyReadMask(message, req.GetReadMask())
+	return message, nil
 }
 
 // GetArtifactContents handles the corresponding API request.
 func (s *RegistryServer) GetArtifactContents(ctx context.Context, req *rpc.GetArtifactContentsRequest) (*httpbody.HttpBody, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -178,7 +193,7 @@ func (s *RegistryServer) GetArtifactContents(ctx context.Context, req *rpc.GetAr
 
 // ListArtifacts handles the corresponding API request.
 func (s *RegistryServer) ListArtifacts(ctx context.Context, req *rpc.ListArtifactsRequest) (*rpc.ListArtifactsResponse, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -196,14 +211,19 @@ func (s *RegistryServer) ListArtifacts(ctx context.Context, req *rpc.ListArtifac
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.Artifact{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	var listing storage.ArtifactList
 	switch parent := parent.(type) {
-	case names.Project:
+	case names.ProjectLocation:
 		listing, err = db.ListProjectArtifacts(ctx, parent, storage.PageOptions{
 			Size:   req.GetPageSize(),
 			Filter: req.GetFilter(),
 			Order:  req.GetOrderBy(),
 			Token:  req.GetPageToken(),
+			Fields: readMaskFields(req.GetReadMask()),
 		})
 	case names.Api:
 		listing, err = db.ListApiArtifacts(ctx, parent, storage.PageOptions{
@@ -211,6 +231,7 @@ func (s *RegistryServer) ListArtifacts(ctx context.Context, req *rpc.ListArtifac
 			Filter: req.GetFilter(),
 			Order:  req.GetOrderBy(),
 			Token:  req.GetPageToken(),
+			Fields: readMaskFields(req.GetReadMask()),
 		})
 	case names.Version:
 		listing, err = db.ListVersionArtifacts(ctx, parent, storage.PageOptions{
@@ -218,6 +239,7 @@ func (s *RegistryServer) ListArtifacts(ctx context.Context, req *rpc.ListArtifac
 			Filter: req.GetFilter(),
 			Order:  req.GetOrderBy(),
 			Token:  req.GetPageToken(),
+			Fields: readMaskFields(req.GetReadMask()),
 		})
 	case names.Spec:
 		listing, err = db.ListSpecArtifacts(ctx, parent, storage.PageOptions{
@@ -225,6 +247,7 @@ func (s *RegistryServer) ListArtifacts(ctx context.Context, req *rpc.ListArtifac
 			Filter: req.GetFilter(),
 			Order:  req.GetOrderBy(),
 			Token:  req.GetPageToken(),
+			Fields: readMaskFields(req.GetReadMask()),
 		})
 	case names.Deployment:
 		listing, err = db.ListDeploymentArtifacts(ctx, parent, storage.PageOptions{
@@ -232,6 +255,7 @@ func (s *RegistryServer) ListArtifacts(ctx context.Context, req *rpc.ListArtifac
 			Filter: req.GetFilter(),
 			Order:  req.GetOrderBy(),
 			Token:  req.GetPageToken(),
+			Fields: readMaskFields(req.GetReadMask()),
 		})
 	}
 	if err != nil {
@@ -245,6 +269,7 @@ func (s *RegistryServer) ListArtifacts(ctx context.Context, req *rpc.ListArtifac
 
 	for i, artifact := range listing.Artifacts {
 		response.Artifacts[i] = artifact.Message()
+		applyReadMask(response.Artifacts[i], req.GetReadMask())
 	}
 
 	return response, nil
@@ -262,6 +287,9 @@ func (s *RegistryServer) ReplaceArtifact(ctx context.Context, req *rpc.ReplaceAr
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := s.admit(ctx, "ReplaceArtifact", name.String(), req.GetArtifact()); err != nil {
+		return nil, err
+	}
 	var artifact *models.Artifact
 	err = db.Transaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		db.LockArtifacts(ctx)
@@ -273,6 +301,9 @@ func (s *RegistryServer) ReplaceArtifact(ctx context.Context, req *rpc.ReplaceAr
 		if err != nil {
 			return err
 		}
+		if err := s.checkBlobQuota(ctx, db, artifact.ProjectID, artifact.Name(), len(req.Artifact.GetContents())); err != nil {
+			return err
+		}
 		if err := db.SaveArtifact(ctx, artifact); err != nil {
 			return err
 		}
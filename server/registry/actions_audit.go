@@ -0,0 +1,70 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListAuditEvents handles the corresponding API request.
+func (s *RegistryServer) ListAuditEvents(ctx context.Context, req *rpc.ListAuditEventsRequest) (*rpc.ListAuditEventsResponse, error) {
+	db, err := s.getReadClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	if req.GetPageSize() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_size %d: must not be negative", req.GetPageSize())
+	} else if req.GetPageSize() > 1000 {
+		req.PageSize = 1000
+	} else if req.GetPageSize() == 0 {
+		req.PageSize = 50
+	}
+
+	parent, err := names.ParseProject(req.GetParent())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	listing, err := db.ListAuditEvents(ctx, parent, storage.PageOptions{
+		Size:   req.GetPageSize(),
+		Filter: req.GetFilter(),
+		Order:  req.GetOrderBy(),
+		Token:  req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &rpc.ListAuditEventsResponse{
+		AuditEvents:   make([]*rpc.AuditEvent, len(listing.AuditEvents)),
+		NextPageToken: listing.Token,
+	}
+
+	for i, event := range listing.AuditEvents {
+		response.AuditEvents[i], err = event.Message()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return response, nil
+}
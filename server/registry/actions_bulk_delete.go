@@ -0,0 +1,151 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultBulkDeleteBatchSize is the number of records deleted in each transaction by default.
+const defaultBulkDeleteBatchSize = 500
+
+// BulkDelete handles the corresponding API request.
+// Resources are deleted by a long-running operation. Interrupted deletions
+// aren't resumed automatically, they are resumed by calling BulkDelete again.
+func (s *RegistryServer) BulkDelete(ctx context.Context, req *rpc.BulkDeleteRequest) (*longrunning.Operation, error) {
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	var (
+		name names.Name
+		tree storage.Tree
+	)
+	if project, err := names.ParseProject(req.GetName()); err == nil {
+		if project.ProjectID == "-" {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid name %q: must name a single project", req.GetName())
+		}
+		if _, err := db.GetProject(ctx, project); err != nil {
+			return nil, err
+		}
+		name, tree = project, storage.ProjectTree(project)
+	} else if api, err := names.ParseApi(req.GetName()); err == nil {
+		if api.ProjectID == "-" || api.LocationID == "-" || api.ApiID == "-" {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid name %q: must name a single API", req.GetName())
+		}
+		if _, err := db.GetApi(ctx, api); err != nil {
+			return nil, err
+		}
+		name, tree = api, storage.ApiTree(api)
+	} else {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid name %q: must name a project or an API", req.GetName())
+	}
+
+	size := int(req.GetBatchSize())
+	if size < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid batch_size %d: must not be negative", size)
+	} else if size == 0 {
+		size = defaultBulkDeleteBatchSize
+	}
+
+	total, err := db.CountTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &rpc.BulkDeleteMetadata{
+		Deleted: &rpc.ResourceCounts{},
+		Total:   resourceCountsMessage(total),
+	}
+	if req.GetValidateOnly() {
+		return s.startOperation(ctx, "", "BulkDelete", metadata, func(ctx context.Context, report func()) (proto.Message, error) {
+			metadata.ProgressPercent = 100
+			return &rpc.BulkDeleteResponse{Deleted: resourceCountsMessage(total)}, nil
+		})
+	}
+
+	// Deletions that would leave references are rejected before anything is deleted.
+	if err := s.admit(ctx, "BulkDelete", name.String(), nil); err != nil {
+		return nil, err
+	}
+	if s.refDeletion == ReferenceDeletionReject {
+		if _, err := s.handleDeletedReferences(ctx, db, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.startOperation(ctx, "", "BulkDelete", metadata, func(ctx context.Context, report func()) (proto.Message, error) {
+		var deleted storage.ResourceCounts
+		for {
+			var (
+				counts  storage.ResourceCounts
+				more    bool
+				updated []string
+			)
+			if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+				var err error
+				if counts, more, err = db.DeleteTreeBatch(ctx, tree, size); err != nil {
+					return err
+				}
+				// References are handled in the batch that deletes the named
+				// resource, so they are kept if the deletion doesn't finish.
+				if rootDeleted(tree, counts) {
+					updated, err = s.handleDeletedReferences(ctx, db, name)
+				}
+				return err
+			}); err != nil {
+				return nil, err
+			}
+			s.notifyUpdated(ctx, updated)
+			if !more {
+				break
+			}
+			deleted.Add(counts)
+			metadata.Deleted = resourceCountsMessage(deleted)
+			metadata.ProgressPercent = progressPercent(int(deleted.Total()), int(total.Total()))
+			report()
+		}
+		metadata.ProgressPercent = 100
+		s.notify(ctx, rpc.Notification_DELETED, name.String())
+		return &rpc.BulkDeleteResponse{Deleted: resourceCountsMessage(deleted)}, nil
+	})
+}
+
+// rootDeleted returns true if a batch of deleted resources includes the project or API of a tree.
+func rootDeleted(tree storage.Tree, counts storage.ResourceCounts) bool {
+	if tree.IsApi() {
+		return counts.Apis > 0
+	}
+	return counts.Projects > 0
+}
+
+func resourceCountsMessage(c storage.ResourceCounts) *rpc.ResourceCounts {
+	return &rpc.ResourceCounts{
+		Projects:            int32(c.Projects),
+		Apis:                int32(c.Apis),
+		Versions:            int32(c.Versions),
+		SpecRevisions:       int32(c.SpecRevisions),
+		DeploymentRevisions: int32(c.DeploymentRevisions),
+		Artifacts:           int32(c.Artifacts),
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func seedBulkDelete(ctx context.Context, t *testing.T, server *RegistryServer) {
+	t.Helper()
+	if err := seeder.SeedRegistry(ctx, server,
+		&rpc.ApiSpec{Name: "projects/my-project/locations/global/apis/a/versions/v1/specs/s"},
+		&rpc.ApiSpec{Name: "projects/my-project/locations/global/apis/a/versions/v2/specs/s"},
+		&rpc.ApiDeployment{Name: "projects/my-project/locations/global/apis/a/deployments/d"},
+		&rpc.Artifact{Name: "projects/my-project/locations/global/apis/a/artifacts/x"},
+		&rpc.ApiVersion{Name: "projects/my-project/locations/global/apis/b/versions/v1"},
+		&rpc.Artifact{Name: "projects/my-project/locations/global/artifacts/x"},
+	); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	if _, err := server.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+		ApiSpec: &rpc.ApiSpec{
+			Name:     "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+			Contents: []byte("new revision"),
+		},
+	}); err != nil {
+		t.Fatalf("Setup: UpdateApiSpec() returned error: %s", err)
+	}
+}
+
+func bulkDelete(ctx context.Context, t *testing.T, server *RegistryServer, req *rpc.BulkDeleteRequest) *rpc.ResourceCounts {
+	t.Helper()
+	op, err := server.BulkDelete(ctx, req)
+	if err != nil {
+		t.Fatalf("BulkDelete(%+v) returned error: %s", req, err)
+	}
+	op, err = waitForOperation(ctx, t, server, op)
+	if err != nil {
+		t.Fatalf("BulkDelete(%+v) operation returned error: %s", req, err)
+	}
+	response := &rpc.BulkDeleteResponse{}
+	if err := op.GetResponse().UnmarshalTo(response); err != nil {
+		t.Fatalf("BulkDelete(%+v) returned unexpected response: %s", req, err)
+	}
+	return response.GetDeleted()
+}
+
+func TestBulkDeleteProject(t *testing.T) {
+	ctx := context.Background()
+	server, err := serverWithSQLite(t)
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	seedBulkDelete(ctx, t, server)
+
+	want := &rpc.ResourceCounts{
+		Projects:            1,
+		Apis:                2,
+		Versions:            3,
+		SpecRevisions:       3,
+		DeploymentRevisions: 1,
+		Artifacts:           2,
+	}
+	got := bulkDelete(ctx, t, server, &rpc.BulkDeleteRequest{Name: "projects/my-project", ValidateOnly: true})
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("BulkDelete(validate_only) returned unexpected counts (-want +got):\n%s", diff)
+	}
+	if _, err := server.GetProject(ctx, &rpc.GetProjectRequest{Name: "projects/my-project"}); err != nil {
+		t.Fatalf("BulkDelete(validate_only) deleted the project: %s", err)
+	}
+
+	got = bulkDelete(ctx, t, server, &rpc.BulkDeleteRequest{Name: "projects/my-project", BatchSize: 2})
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("BulkDelete() returned unexpected counts (-want +got):\n%s", diff)
+	}
+	if _, err := server.GetProject(ctx, &rpc.GetProjectRequest{Name: "projects/my-project"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProject() after BulkDelete() returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+	for _, table := range []string{"apis", "versions", "specs", "spec_revision_tags", "deployments", "artifacts", "blobs"} {
+		if n, err := server.storageClient.RowCount(ctx, table); err != nil || n != 0 {
+			t.Errorf("BulkDelete() left %d rows in %s: %v", n, table, err)
+		}
+	}
+}
+
+func TestBulkDeleteApiResumes(t *testing.T) {
+	ctx := context.Background()
+	server, err := serverWithSQLite(t)
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	seedBulkDelete(ctx, t, server)
+
+	// An interrupted deletion leaves the API and some of its children.
+	api := names.Api{ProjectID: "my-project", LocationID: "global", ApiID: "a"}
+	first, _, err := server.storageClient.DeleteTreeBatch(ctx, storage.ApiTree(api), 1)
+	if err != nil {
+		t.Fatalf("DeleteTreeBatch() returned error: %s", err)
+	}
+	if first.Artifacts != 1 {
+		t.Fatalf("DeleteTreeBatch() deleted %+v, want one artifact", first)
+	}
+
+	want := &rpc.ResourceCounts{
+		Apis:                1,
+		Versions:            2,
+		SpecRevisions:       3,
+		DeploymentRevisions: 1,
+	}
+	got := bulkDelete(ctx, t, server, &rpc.BulkDeleteRequest{Name: api.String()})
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("BulkDelete() returned unexpected counts (-want +got):\n%s", diff)
+	}
+	if _, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: api.String()}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetApi() after BulkDelete() returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+	if _, err := server.GetApiVersion(ctx, &rpc.GetApiVersionRequest{Name: "projects/my-project/locations/global/apis/b/versions/v1"}); err != nil {
+		t.Errorf("BulkDelete() of API a deleted a version of API b: %s", err)
+	}
+	if _, err := server.GetArtifact(ctx, &rpc.GetArtifactRequest{Name: "projects/my-project/locations/global/artifacts/x"}); err != nil {
+		t.Errorf("BulkDelete() of API a deleted a project artifact: %s", err)
+	}
+}
+
+func TestBulkDeleteRequests(t *testing.T) {
+	ctx := context.Background()
+	server, err := serverWithSQLite(t)
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	seedBulkDelete(ctx, t, server)
+
+	tests := []struct {
+		desc string
+		req  *rpc.BulkDeleteRequest
+		want codes.Code
+	}{
+		{"missing name", &rpc.BulkDeleteRequest{}, codes.InvalidArgument},
+		{"version name", &rpc.BulkDeleteRequest{Name: "projects/my-project/locations/global/apis/a/versions/v1"}, codes.InvalidArgument},
+		{"all projects", &rpc.BulkDeleteRequest{Name: "projects/-"}, codes.InvalidArgument},
+		{"all apis", &rpc.BulkDeleteRequest{Name: "projects/my-project/locations/global/apis/-"}, codes.InvalidArgument},
+		{"negative batch size", &rpc.BulkDeleteRequest{Name: "projects/my-project", BatchSize: -1}, codes.InvalidArgument},
+		{"missing project", &rpc.BulkDeleteRequest{Name: "projects/missing"}, codes.NotFound},
+		{"missing api", &rpc.BulkDeleteRequest{Name: "projects/my-project/locations/global/apis/missing"}, codes.NotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := server.BulkDelete(ctx, test.req); status.Code(err) != test.want {
+				t.Errorf("BulkDelete(%+v) returned status code %q, want %q: %v", test.req, status.Code(err), test.want, err)
+			}
+		})
+	}
+}
+
+func TestBulkDeleteReferences(t *testing.T) {
+	ctx := context.Background()
+	for _, deletion := range []string{ReferenceDeletionClear, ReferenceDeletionReject} {
+		t.Run(deletion, func(t *testing.T) {
+			server := serverWithReferences(t, false, deletion)
+			spec := seedReferences(ctx, t, server)
+			// A deployment of another API refers to a spec revision of the deleted API.
+			const holder = "projects/my-project/locations/global/apis/b/deployments/d"
+			if _, err := server.CreateApi(ctx, &rpc.CreateApiRequest{
+				Parent: "projects/my-project/locations/global",
+				ApiId:  "b",
+				Api:    &rpc.Api{},
+			}); err != nil {
+				t.Fatalf("Setup: CreateApi() returned error: %s", err)
+			}
+			if _, err := server.UpdateApiDeployment(ctx, &rpc.UpdateApiDeploymentRequest{
+				ApiDeployment: &rpc.ApiDeployment{
+					Name:            holder,
+					ApiSpecRevision: spec.GetName() + "@" + spec.GetRevisionId(),
+				},
+				AllowMissing: true,
+			}); err != nil {
+				t.Fatalf("Setup: UpdateApiDeployment() returned error: %s", err)
+			}
+
+			api := "projects/my-project/locations/global/apis/a"
+			if deletion == ReferenceDeletionReject {
+				if _, err := server.BulkDelete(ctx, &rpc.BulkDeleteRequest{Name: api}); status.Code(err) != codes.FailedPrecondition {
+					t.Errorf("BulkDelete() returned status code %q, want %q: %v", status.Code(err), codes.FailedPrecondition, err)
+				}
+				if _, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: spec.GetName()}); err != nil {
+					t.Errorf("Rejected BulkDelete() deleted a referenced spec: %s", err)
+				}
+				return
+			}
+
+			// Batches that don't delete the API keep references to it.
+			if _, _, err := server.storageClient.DeleteTreeBatch(ctx, storage.ApiTree(names.Api{ProjectID: "my-project", LocationID: "global", ApiID: "a"}), 1); err != nil {
+				t.Fatalf("DeleteTreeBatch() returned error: %s", err)
+			}
+			deployment, err := server.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: holder})
+			if err != nil {
+				t.Fatalf("GetApiDeployment() returned error: %s", err)
+			}
+			if deployment.GetApiSpecRevision() == "" {
+				t.Errorf("An interrupted deletion cleared the reference from %s", holder)
+			}
+
+			bulkDelete(ctx, t, server, &rpc.BulkDeleteRequest{Name: api, BatchSize: 1})
+			deployment, err = server.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: holder})
+			if err != nil {
+				t.Fatalf("GetApiDeployment() returned error: %s", err)
+			}
+			if deployment.GetApiSpecRevision() != "" {
+				t.Errorf("BulkDelete() kept reference %q from %s", deployment.GetApiSpecRevision(), holder)
+			}
+		})
+	}
+}
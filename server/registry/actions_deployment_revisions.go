@@ -27,7 +27,7 @@ import (
 
 // ListApiDeploymentRevisions handles the corresponding API request.
 func (s *RegistryServer) ListApiDeploymentRevisions(ctx context.Context, req *rpc.ListApiDeploymentRevisionsRequest) (*rpc.ListApiDeploymentRevisionsResponse, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -45,9 +45,14 @@ func (s *RegistryServer) ListApiDeploymentRevisions(ctx context.Context, req *rp
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.ApiDeployment{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	listing, err := db.ListDeploymentRevisions(ctx, parent, storage.PageOptions{
-		Size:  req.GetPageSize(),
-		Token: req.GetPageToken(),
+		Size:   req.GetPageSize(),
+		Token:  req.GetPageToken(),
+		Fields: readMaskFields(req.GetReadMask()),
 	})
 	if err != nil {
 		return nil, err
@@ -63,6 +68,18 @@ func (s *RegistryServer) ListApiDeploymentRevisions(ctx context.Context, req *rp
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
+		applyReadMask(response.ApiDeployments[i], req.GetReadMask())
+	}
+
+	tags, err := db.ListDeploymentRevisionTags(ctx, listing.Deployments)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		response.RevisionTags = append(response.RevisionTags, &rpc.RevisionTag{
+			Revision: t.RevisionName(),
+			Tag:      t.Tag,
+		})
 	}
 
 	return response, nil
@@ -75,8 +92,17 @@ func (s *RegistryServer) DeleteApiDeploymentRevision(ctx context.Context, req *r
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "DeleteApiDeploymentRevision", name.String(), nil); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiDeployment
+	var updated []string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		var err error
+		updated, err = s.handleDeletedReferences(ctx, db, name)
+		if err != nil {
+			return err
+		}
 		if err := db.DeleteDeploymentRevision(ctx, name); err != nil {
 			return err
 		}
@@ -85,12 +111,13 @@ func (s *RegistryServer) DeleteApiDeploymentRevision(ctx context.Context, req *r
 		return nil, err
 	}
 	// return the latest revision of the current deployment
-	response, err = s.getApiDeployment(ctx, name.Deployment())
+	response, err = s.getApiDeployment(withPrimaryReads(ctx), name.Deployment())
 	if err != nil {
 		// The get will fail if we are deleting the only revision.
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	s.notify(ctx, rpc.Notification_DELETED, name.String())
+	s.notifyUpdated(ctx, updated)
 	return response, nil
 }
 
@@ -109,6 +136,9 @@ func (s *RegistryServer) TagApiDeploymentRevision(ctx context.Context, req *rpc.
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "TagApiDeploymentRevision", name.String(), nil); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiDeployment
 	var revisionName string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
@@ -151,6 +181,9 @@ func (s *RegistryServer) RollbackApiDeployment(ctx context.Context, req *rpc.Rol
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "RollbackApiDeployment", req.GetName(), nil); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiDeployment
 	var revisionName string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
@@ -141,6 +141,26 @@ func TestTagApiDeploymentRevision(t *testing.T) {
 		})
 	})
 
+	t.Run("ListApiDeploymentRevisions", func(t *testing.T) {
+		req := &rpc.ListApiDeploymentRevisionsRequest{
+			Name: revision.GetName(),
+		}
+
+		got, err := server.ListApiDeploymentRevisions(ctx, req)
+		if err != nil {
+			t.Fatalf("ListApiDeploymentRevisions(%+v) returned error: %s", req, err)
+		}
+
+		tagged := fmt.Sprintf("%s@%s", revision.GetName(), revision.GetRevisionId())
+		want := []*rpc.RevisionTag{
+			{Revision: tagged, Tag: "my-second-tag"},
+			{Revision: tagged, Tag: "my-tag"},
+		}
+		if diff := cmp.Diff(want, got.GetRevisionTags(), protocmp.Transform()); diff != "" {
+			t.Errorf("ListApiDeploymentRevisions(%+v) returned unexpected tags (-want +got):\n%s", req, diff)
+		}
+	})
+
 	t.Run("DeleteApiDeploymentRevision", func(t *testing.T) {
 		req := &rpc.DeleteApiDeploymentRevisionRequest{
 			Name: got.GetName(),
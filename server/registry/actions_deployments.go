@@ -42,6 +42,9 @@ func (s *RegistryServer) CreateApiDeployment(ctx context.Context, req *rpc.Creat
 	if err := name.Validate(); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "CreateApiDeployment", name.String(), req.GetApiDeployment()); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiDeployment
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		var err error
@@ -67,6 +70,10 @@ func (s *RegistryServer) createDeployment(ctx context.Context, db *storage.Clien
 		return nil, err
 	}
 
+	if err := s.validateDeploymentReferences(ctx, db, body, []string{"api_spec_revision"}); err != nil {
+		return nil, err
+	}
+
 	deployment, err := models.NewDeployment(name, body)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -87,28 +94,52 @@ func (s *RegistryServer) DeleteApiDeployment(ctx context.Context, req *rpc.Delet
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := s.admit(ctx, "DeleteApiDeployment", name.String(), nil); err != nil {
+		return nil, err
+	}
+	var updated []string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		var err error
+		updated, err = s.handleDeletedReferences(ctx, db, name)
+		if err != nil {
+			return err
+		}
 		return db.DeleteDeployment(ctx, name, req.GetForce())
 	}); err != nil {
 		return nil, err
 	}
 	s.notify(ctx, rpc.Notification_DELETED, req.GetName())
+	s.notifyUpdated(ctx, updated)
 	return &emptypb.Empty{}, nil
 }
 
 // GetApiDeployment handles the corresponding API request.
 func (s *RegistryServer) GetApiDeployment(ctx context.Context, req *rpc.GetApiDeploymentRequest) (*rpc.ApiDeployment, error) {
-	if name, err := names.ParseDeployment(req.GetName()); err == nil {
-		return s.getApiDeployment(ctx, name)
-	} else if name, err := names.ParseDeploymentRevision(req.GetName()); err == nil {
-		return s.getApiDeploymentRevision(ctx, name)
+	if err := validateReadMask(&rpc.ApiDeployment{}, req.GetReadMask()); err != nil {
+		return nil, err
 	}
 
-	return nil, status.Errorf(codes.InvalidArgument, "invalid resource name %q, must be an API deployment or revision", req.GetName())
+	var (
+		message *rpc.ApiDeployment
+		err     error
+	)
+	if name, perr := names.ParseDeployment(req.GetName()); perr == nil {
+		message, err = s.getApiDeployment(ctx, name)
+	} else if name, perr := names.ParseDeploymentRevision(req.GetName()); perr == nil {
+		message, err = s.getApiDeploymentRevision(ctx, name)
+	} else {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid resource name %q, must be an API deployment or revision", req.GetName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applyReadMask(message, req.GetReadMask())
+	return message, nil
 }
 
 func (s *RegistryServer) getApiDeployment(ctx context.Context, name names.Deployment) (*rpc.ApiDeployment, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -127,7 +158,7 @@ func (s *RegistryServer) getApiDeployment(ctx context.Context, name names.Deploy
 }
 
 func (s *RegistryServer) getApiDeploymentRevision(ctx context.Context, name names.DeploymentRevision) (*rpc.ApiDeployment, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -147,7 +178,7 @@ func (s *RegistryServer) getApiDeploymentRevision(ctx context.Context, name name
 
 // ListApiDeployments handles the corresponding API request.
 func (s *RegistryServer) ListApiDeployments(ctx context.Context, req *rpc.ListApiDeploymentsRequest) (*rpc.ListApiDeploymentsResponse, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -165,11 +196,16 @@ func (s *RegistryServer) ListApiDeployments(ctx context.Context, req *rpc.ListAp
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.ApiDeployment{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	listing, err := db.ListDeployments(ctx, parent, storage.PageOptions{
 		Size:   req.GetPageSize(),
 		Filter: req.GetFilter(),
 		Order:  req.GetOrderBy(),
 		Token:  req.GetPageToken(),
+		Fields: readMaskFields(req.GetReadMask()),
 	})
 	if err != nil {
 		return nil, err
@@ -185,6 +221,7 @@ func (s *RegistryServer) ListApiDeployments(ctx context.Context, req *rpc.ListAp
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
+		applyReadMask(response.ApiDeployments[i], req.GetReadMask())
 	}
 
 	return response, nil
@@ -205,6 +242,9 @@ func (s *RegistryServer) UpdateApiDeployment(ctx context.Context, req *rpc.Updat
 	if err := models.ValidateMask(req.GetApiDeployment(), req.GetUpdateMask()); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid update_mask %v: %s", req.GetUpdateMask(), err)
 	}
+	if err := s.admit(ctx, "UpdateApiDeployment", name.String(), req.GetApiDeployment()); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiDeployment
 	if err = s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		db.LockDeployments(ctx)
@@ -212,6 +252,9 @@ func (s *RegistryServer) UpdateApiDeployment(ctx context.Context, req *rpc.Updat
 		if err == nil {
 			// Apply the update to the deployment - possibly changing the revision ID.
 			maskExpansion := models.ExpandMask(req.GetApiDeployment(), req.GetUpdateMask())
+			if err := s.validateDeploymentReferences(ctx, db, req.GetApiDeployment(), maskExpansion.GetPaths()); err != nil {
+				return err
+			}
 			if err := deployment.Update(req.GetApiDeployment(), maskExpansion); err != nil {
 				return status.Error(codes.Internal, err.Error())
 			}
@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CreateInstance handles the corresponding API request.
+// The instance's database is created by a long-running operation.
+func (s *RegistryServer) CreateInstance(ctx context.Context, req *rpc.CreateInstanceRequest) (*longrunning.Operation, error) {
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	parent, err := names.ParseLocation(req.GetParent())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if parent.ProjectID == "-" || parent.LocationID == "-" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid parent %q: must name a single location", req.GetParent())
+	}
+
+	if req.GetInstance() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid instance %+v: body must be provided", req.GetInstance())
+	}
+
+	name := names.Instance{
+		ProjectID:  parent.ProjectID,
+		LocationID: parent.LocationID,
+		InstanceID: req.GetInstanceId(),
+	}
+	if err := name.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	instance := models.NewInstance(name, req.GetInstance())
+	instance.Database = s.instanceDatabase()
+	if err := db.CreateInstance(ctx, instance); status.Code(err) == codes.AlreadyExists {
+		return nil, status.Errorf(codes.AlreadyExists, "instance %q already exists", name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	metadata := &rpc.OperationMetadata{
+		Target:     instance.Name(),
+		Verb:       "create",
+		ApiVersion: "v1",
+	}
+	return s.startOperation(ctx, name.Location().String(), "CreateInstance", metadata, func(ctx context.Context, report func()) (proto.Message, error) {
+		// Provisioning isn't interrupted, so the instance isn't left half-created.
+		ctx = detachedContext{ctx}
+		err := s.provisionInstance(ctx, instance)
+		if status.Code(err) == codes.AlreadyExists {
+			// The existing database isn't the instance's, so deleting the instance must leave it.
+			instance.Database = ""
+		}
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("Failed to provision %s", instance.Name())
+			instance.SetState(rpc.Instance_FAILED, err.Error())
+		} else {
+			instance.SetState(rpc.Instance_ACTIVE, "")
+		}
+		if serr := db.SaveInstance(ctx, instance); serr != nil && err == nil {
+			err = serr
+		}
+		return instance.Message(), err
+	})
+}
+
+// DeleteInstance handles the corresponding API request.
+// The instance's database is deleted by a long-running operation.
+func (s *RegistryServer) DeleteInstance(ctx context.Context, req *rpc.DeleteInstanceRequest) (*longrunning.Operation, error) {
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	name, err := names.ParseInstance(req.GetName())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	instance, err := db.GetInstance(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	switch instance.State {
+	case rpc.Instance_CREATING.String(), rpc.Instance_DELETING.String():
+		return nil, status.Errorf(codes.FailedPrecondition, "instance %q is %s", name, instance.State)
+	}
+
+	instance.SetState(rpc.Instance_DELETING, "")
+	if err := db.SaveInstance(ctx, instance); err != nil {
+		return nil, err
+	}
+
+	metadata := &rpc.OperationMetadata{
+		Target:     instance.Name(),
+		Verb:       "delete",
+		ApiVersion: "v1",
+	}
+	return s.startOperation(ctx, name.Location().String(), "DeleteInstance", metadata, func(ctx context.Context, report func()) (proto.Message, error) {
+		ctx = detachedContext{ctx}
+		err := s.deprovisionInstance(ctx, instance)
+		if err == nil {
+			err = db.DeleteInstance(ctx, name)
+		} else {
+			log.FromContext(ctx).WithError(err).Errorf("Failed to deprovision %s", instance.Name())
+			instance.SetState(rpc.Instance_FAILED, err.Error())
+			_ = db.SaveInstance(ctx, instance)
+		}
+		return &emptypb.Empty{}, err
+	})
+}
+
+// GetInstance handles the corresponding API request.
+func (s *RegistryServer) GetInstance(ctx context.Context, req *rpc.GetInstanceRequest) (*rpc.Instance, error) {
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	name, err := names.ParseInstance(req.GetName())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	instance, err := db.GetInstance(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance.Message(), nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apigee/registry/gapic"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestInstances(t *testing.T) {
+	dir := t.TempDir()
+	server, err := New(Config{
+		Database:    "sqlite3",
+		DBConfig:    dir + "/registry.db",
+		InstanceDir: dir + "/instances",
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	listener, grpcServer, err := server.ServeGRPC(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Setup: failed to serve: %s", err)
+	}
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Setup: failed to dial server: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	provisioning, err := gapic.NewProvisioningClient(ctx, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("Setup: failed to create client: %s", err)
+	}
+	admin := rpc.NewAdminClient(conn)
+
+	const name = "projects/my-project/locations/global/instances/tenant"
+	instanceCtx := metadata.AppendToOutgoingContext(ctx, InstanceHeader, name)
+
+	if _, err := admin.ListProjects(instanceCtx, &rpc.ListProjectsRequest{}); status.Code(err) != codes.NotFound {
+		t.Errorf("ListProjects() in missing instance returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+
+	create, err := provisioning.CreateInstance(ctx, &rpc.CreateInstanceRequest{
+		Parent:     "projects/my-project/locations/global",
+		InstanceId: "tenant",
+		Instance:   &rpc.Instance{Config: &rpc.Instance_Config{CmekKeyName: "key"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateInstance() returned error: %s", err)
+	}
+	instance, err := create.Wait(ctx)
+	if err != nil {
+		t.Fatalf("CreateInstance() operation returned error: %s", err)
+	}
+	if instance.GetName() != name || instance.GetState() != rpc.Instance_ACTIVE {
+		t.Errorf("CreateInstance() returned %v, want active instance %q", instance, name)
+	}
+	databases, err := filepath.Glob(dir + "/instances/*.db")
+	if err != nil || len(databases) != 1 {
+		t.Fatalf("CreateInstance() created instance databases %v, want one: %v", databases, err)
+	}
+	if got, err := provisioning.GetInstance(ctx, &rpc.GetInstanceRequest{Name: name}); err != nil {
+		t.Errorf("GetInstance() returned error: %s", err)
+	} else if got.GetState() != rpc.Instance_ACTIVE || got.GetConfig().GetCmekKeyName() != "key" {
+		t.Errorf("GetInstance() returned %v, want active instance", got)
+	}
+
+	_, err = provisioning.CreateInstance(ctx, &rpc.CreateInstanceRequest{
+		Parent:     "projects/my-project/locations/global",
+		InstanceId: "tenant",
+		Instance:   &rpc.Instance{},
+	})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("CreateInstance() of existing instance returned status code %q, want %q: %v", status.Code(err), codes.AlreadyExists, err)
+	}
+
+	// Resources in an instance are isolated from the server's database.
+	if _, err := admin.CreateProject(instanceCtx, &rpc.CreateProjectRequest{ProjectId: "isolated", Project: &rpc.Project{}}); err != nil {
+		t.Fatalf("CreateProject() in instance returned error: %s", err)
+	}
+	if _, err := admin.GetProject(instanceCtx, &rpc.GetProjectRequest{Name: "projects/isolated"}); err != nil {
+		t.Errorf("GetProject() in instance returned error: %s", err)
+	}
+	if _, err := admin.GetProject(ctx, &rpc.GetProjectRequest{Name: "projects/isolated"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProject() outside instance returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+
+	del, err := provisioning.DeleteInstance(ctx, &rpc.DeleteInstanceRequest{Name: name})
+	if err != nil {
+		t.Fatalf("DeleteInstance() returned error: %s", err)
+	}
+	if err := del.Wait(ctx); err != nil {
+		t.Fatalf("DeleteInstance() operation returned error: %s", err)
+	}
+	if _, err := provisioning.GetInstance(ctx, &rpc.GetInstanceRequest{Name: name}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetInstance() of deleted instance returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+	if _, err := os.Stat(databases[0]); !os.IsNotExist(err) {
+		t.Errorf("DeleteInstance() didn't delete instance database: %v", err)
+	}
+	if _, err := admin.GetProject(instanceCtx, &rpc.GetProjectRequest{Name: "projects/isolated"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProject() in deleted instance returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+}
+
+func TestProvisionExistingInstanceDatabase(t *testing.T) {
+	dir := t.TempDir()
+	server, err := New(Config{
+		Database:    "sqlite3",
+		DBConfig:    dir + "/registry.db",
+		InstanceDir: dir + "/instances",
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+
+	database := server.instanceDatabase()
+	if other := server.instanceDatabase(); other == database {
+		t.Errorf("instanceDatabase() returned %q twice, want unique names", database)
+	}
+	if err := os.MkdirAll(filepath.Dir(database), 0o755); err != nil {
+		t.Fatalf("Setup: failed to create directory: %s", err)
+	}
+	if err := os.WriteFile(database, []byte("another instance"), 0o644); err != nil {
+		t.Fatalf("Setup: failed to create database: %s", err)
+	}
+
+	instance := &models.Instance{ProjectID: "my-project", LocationID: "global", InstanceID: "tenant", Database: database}
+	if err := server.provisionInstance(context.Background(), instance); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("provisionInstance() of existing database returned status code %q, want %q: %v", status.Code(err), codes.AlreadyExists, err)
+	}
+	if contents, err := os.ReadFile(database); err != nil || string(contents) != "another instance" {
+		t.Errorf("provisionInstance() changed existing database to %q: %v", contents, err)
+	}
+}
+
+func TestCreateInstanceRequests(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithQuotas(t, QuotaConfig{})
+	tests := []struct {
+		desc string
+		req  *rpc.CreateInstanceRequest
+	}{
+		{"invalid parent", &rpc.CreateInstanceRequest{Parent: "projects/p", InstanceId: "i", Instance: &rpc.Instance{}}},
+		{"all locations", &rpc.CreateInstanceRequest{Parent: "projects/p/locations/-", InstanceId: "i", Instance: &rpc.Instance{}}},
+		{"invalid id", &rpc.CreateInstanceRequest{Parent: "projects/p/locations/global", InstanceId: "I!", Instance: &rpc.Instance{}}},
+		{"missing body", &rpc.CreateInstanceRequest{Parent: "projects/p/locations/global", InstanceId: "i"}},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := server.CreateInstance(ctx, test.req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("CreateInstance(%+v) returned status code %q, want %q: %v", test.req, status.Code(err), codes.InvalidArgument, err)
+			}
+		})
+	}
+
+	if _, err := server.GetOperation(ctx, nil); status.Code(err) != codes.NotFound {
+		t.Errorf("GetOperation() of missing operation returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+}
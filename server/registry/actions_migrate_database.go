@@ -21,10 +21,11 @@ import (
 	"google.golang.org/genproto/googleapis/longrunning"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/proto"
 )
 
 // MigrateDatabase handles the corresponding API request.
+// Tables are migrated by a long-running operation.
 func (s *RegistryServer) MigrateDatabase(ctx context.Context, req *rpc.MigrateDatabaseRequest) (*longrunning.Operation, error) {
 	if req.Kind != "" && req.Kind != "auto" {
 		return nil, status.Errorf(codes.InvalidArgument, "unsupported migration kind %q", req.Kind)
@@ -34,25 +35,16 @@ func (s *RegistryServer) MigrateDatabase(ctx context.Context, req *rpc.MigrateDa
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
 
-	err = db.Migrate(ctx)
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
-	}
-
-	metadata, err := anypb.New(&rpc.MigrateDatabaseMetadata{})
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
-	}
-	response, err := anypb.New(&rpc.MigrateDatabaseResponse{
-		Message: "OK",
+	metadata := &rpc.MigrateDatabaseMetadata{}
+	return s.startOperation(ctx, "", "MigrateDatabase", metadata, func(ctx context.Context, report func()) (proto.Message, error) {
+		if err := db.Migrate(ctx, func(done, total int) {
+			metadata.ProgressPercent = progressPercent(done, total)
+			report()
+		}); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return &rpc.MigrateDatabaseResponse{
+			Message: "OK",
+		}, nil
 	})
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
-	}
-	return &longrunning.Operation{
-		Name:     "migrate",
-		Metadata: metadata,
-		Done:     true,
-		Result:   &longrunning.Operation_Response{Response: response},
-	}, nil
 }
@@ -35,10 +35,6 @@ func TestMigrateDatabase(t *testing.T) {
 	server := defaultTestServer(t)
 
 	req := &rpc.MigrateDatabaseRequest{}
-	metadata, err := anypb.New(&rpc.MigrateDatabaseMetadata{})
-	if err != nil {
-		t.Fatalf("MigrateDatabase(%+v) test failed to build expected response metadata: %s", req, err)
-	}
 	response, err := anypb.New(&rpc.MigrateDatabaseResponse{
 		Message: "OK",
 	})
@@ -46,19 +42,22 @@ func TestMigrateDatabase(t *testing.T) {
 		t.Fatalf("MigrateDatabase(%+v) test failed to build expected response message: %s", req, err)
 	}
 	want := &longrunning.Operation{
-		Name:     "migrate",
-		Done:     true,
-		Metadata: metadata,
-		Result:   &longrunning.Operation_Response{Response: response},
+		Done:   true,
+		Result: &longrunning.Operation_Response{Response: response},
 	}
 
-	got, err := server.MigrateDatabase(ctx, req)
+	op, err := server.MigrateDatabase(ctx, req)
 	if err != nil {
 		t.Fatalf("MigrateDatabase(%+v) returned error: %s", req, err)
 	}
+	got, err := waitForOperation(ctx, t, server, op)
+	if err != nil {
+		t.Fatalf("MigrateDatabase(%+v) operation returned error: %s", req, err)
+	}
 
 	opts := cmp.Options{
 		protocmp.Transform(),
+		protocmp.IgnoreFields(&longrunning.Operation{}, "name", "metadata"),
 	}
 
 	if !cmp.Equal(want, got, opts) {
@@ -37,6 +37,9 @@ func (s *RegistryServer) CreateProject(ctx context.Context, req *rpc.CreateProje
 	if err := name.Validate(); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "CreateProject", name.String(), req.GetProject()); err != nil {
+		return nil, err
+	}
 	var response *rpc.Project
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		var err error
@@ -66,22 +69,36 @@ func (s *RegistryServer) DeleteProject(ctx context.Context, req *rpc.DeleteProje
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "DeleteProject", name.String(), nil); err != nil {
+		return nil, err
+	}
+	var updated []string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		var err error
+		updated, err = s.handleDeletedReferences(ctx, db, name)
+		if err != nil {
+			return err
+		}
 		return db.DeleteProject(ctx, name, req.GetForce())
 	}); err != nil {
 		return nil, err
 	}
 	s.notify(ctx, rpc.Notification_DELETED, req.GetName())
+	s.notifyUpdated(ctx, updated)
 	return &emptypb.Empty{}, nil
 }
 
 // GetProject handles the corresponding API request.
 func (s *RegistryServer) GetProject(ctx context.Context, req *rpc.GetProjectRequest) (*rpc.Project, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.Project{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	name, err := names.ParseProject(req.GetName())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -92,12 +109,14 @@ func (s *RegistryServer) GetProject(ctx context.Context, req *rpc.GetProjectRequ
 		return nil, err
 	}
 
-	return project.Message(), nil
+	message := project.Message()
+	applyReadMask(message, req.GetReadMask())
+	return message, nil
 }
 
 // ListProjects handles the corresponding API request.
 func (s *RegistryServer) ListProjects(ctx context.Context, req *rpc.ListProjectsRequest) (*rpc.ListProjectsResponse, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -110,11 +129,16 @@ func (s *RegistryServer) ListProjects(ctx context.Context, req *rpc.ListProjects
 		req.PageSize = 50
 	}
 
+	if err := validateReadMask(&rpc.Project{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	listing, err := db.ListProjects(ctx, storage.PageOptions{
 		Size:   req.GetPageSize(),
 		Filter: req.GetFilter(),
 		Order:  req.GetOrderBy(),
 		Token:  req.GetPageToken(),
+		Fields: readMaskFields(req.GetReadMask()),
 	})
 	if err != nil {
 		return nil, err
@@ -127,6 +151,7 @@ func (s *RegistryServer) ListProjects(ctx context.Context, req *rpc.ListProjects
 
 	for i, project := range listing.Projects {
 		response.Projects[i] = project.Message()
+		applyReadMask(response.Projects[i], req.GetReadMask())
 	}
 
 	return response, nil
@@ -147,6 +172,9 @@ func (s *RegistryServer) UpdateProject(ctx context.Context, req *rpc.UpdateProje
 	if err := models.ValidateMask(req.GetProject(), req.GetUpdateMask()); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid update_mask %v: %s", req.GetUpdateMask(), err)
 	}
+	if err := s.admit(ctx, "UpdateProject", name.String(), req.GetProject()); err != nil {
+		return nil, err
+	}
 	var response *rpc.Project
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		db.LockProjects(ctx)
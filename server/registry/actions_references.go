@@ -0,0 +1,230 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Options for the handling of references to deleted resources.
+const (
+	// ReferenceDeletionIgnore leaves references to deleted resources in place.
+	ReferenceDeletionIgnore = "ignore"
+	// ReferenceDeletionReject rejects deletions of referenced resources.
+	ReferenceDeletionReject = "reject"
+	// ReferenceDeletionClear clears references to deleted resources.
+	ReferenceDeletionClear = "clear"
+)
+
+// ListReferences handles the corresponding API request.
+func (s *RegistryServer) ListReferences(ctx context.Context, req *rpc.ListReferencesRequest) (*rpc.ListReferencesResponse, error) {
+	db, err := s.getReadClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	name, err := parseReferencedName(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := db.ListReferences(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &rpc.ListReferencesResponse{
+		References: make([]*rpc.ListReferencesResponse_Reference, len(refs)),
+	}
+	for i, ref := range refs {
+		response.References[i] = &rpc.ListReferencesResponse_Reference{
+			Name:  ref.Name,
+			Field: ref.Field,
+			Value: ref.Value,
+		}
+	}
+
+	return response, nil
+}
+
+// parseReferencedName parses the name of a resource that can be referenced
+// by other resources, either directly or through one of its children.
+func parseReferencedName(name string) (names.Name, error) {
+	if n, err := names.ParseProject(name); err == nil {
+		return n, nil
+	} else if n, err := names.ParseApi(name); err == nil {
+		return n, nil
+	} else if n, err := names.ParseVersion(name); err == nil {
+		return n, nil
+	} else if n, err := names.ParseSpec(name); err == nil {
+		return n, nil
+	} else if n, err := names.ParseSpecRevision(name); err == nil {
+		return n, nil
+	} else if n, err := names.ParseDeployment(name); err == nil {
+		return n, nil
+	} else if n, err := names.ParseDeploymentRevision(name); err == nil {
+		return n, nil
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "invalid resource name %q, must be a project, API, version, spec, deployment, or revision", name)
+}
+
+// validateApiReferences checks the references in the listed fields of an API.
+func (s *RegistryServer) validateApiReferences(ctx context.Context, db *storage.Client, api *rpc.Api, fields []string) error {
+	if !s.validateRefs {
+		return nil
+	}
+	for _, field := range fields {
+		switch field {
+		case "recommended_version":
+			if err := validateVersionReference(ctx, db, api.GetRecommendedVersion()); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid recommended_version %q: %s", api.GetRecommendedVersion(), status.Convert(err).Message())
+			}
+		case "recommended_deployment":
+			if err := validateDeploymentReference(ctx, db, api.GetRecommendedDeployment()); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid recommended_deployment %q: %s", api.GetRecommendedDeployment(), status.Convert(err).Message())
+			}
+		}
+	}
+	return nil
+}
+
+// validateDeploymentReferences checks the references in the listed fields of a deployment.
+func (s *RegistryServer) validateDeploymentReferences(ctx context.Context, db *storage.Client, deployment *rpc.ApiDeployment, fields []string) error {
+	if !s.validateRefs {
+		return nil
+	}
+	for _, field := range fields {
+		if field == "api_spec_revision" {
+			if err := validateSpecReference(ctx, db, deployment.GetApiSpecRevision()); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid api_spec_revision %q: %s", deployment.GetApiSpecRevision(), status.Convert(err).Message())
+			}
+		}
+	}
+	return nil
+}
+
+// validateProjectReferences checks the references held by all of the resources
+// of a project, as they are checked when the resources are created or updated.
+func (s *RegistryServer) validateProjectReferences(ctx context.Context, db *storage.Client, name names.Project) error {
+	if !s.validateRefs {
+		return nil
+	}
+	refs, err := db.ListProjectReferences(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		var err error
+		switch ref.Field {
+		case "recommended_version":
+			err = validateVersionReference(ctx, db, ref.Value)
+		case "recommended_deployment":
+			err = validateDeploymentReference(ctx, db, ref.Value)
+		case "api_spec_revision":
+			err = validateSpecReference(ctx, db, ref.Value)
+		}
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid %s %q of %s: %s", ref.Field, ref.Value, ref.Name, status.Convert(err).Message())
+		}
+	}
+	return nil
+}
+
+func validateVersionReference(ctx context.Context, db *storage.Client, ref string) error {
+	if ref == "" {
+		return nil
+	}
+	name, err := names.ParseVersion(ref)
+	if err != nil {
+		return err
+	}
+	_, err = db.GetVersion(ctx, name)
+	return err
+}
+
+func validateDeploymentReference(ctx context.Context, db *storage.Client, ref string) error {
+	if ref == "" {
+		return nil
+	}
+	if name, err := names.ParseDeployment(ref); err == nil {
+		_, err = db.GetDeployment(ctx, name)
+		return err
+	} else if name, err := names.ParseDeploymentRevision(ref); err == nil {
+		_, err = db.GetDeploymentRevision(ctx, name)
+		return err
+	}
+	return status.Error(codes.InvalidArgument, "must be a deployment or deployment revision")
+}
+
+func validateSpecReference(ctx context.Context, db *storage.Client, ref string) error {
+	if ref == "" {
+		return nil
+	}
+	if name, err := names.ParseSpec(ref); err == nil {
+		_, err = db.GetSpec(ctx, name)
+		return err
+	} else if name, err := names.ParseSpecRevision(ref); err == nil {
+		_, err = db.GetSpecRevision(ctx, name)
+		return err
+	}
+	return status.Error(codes.InvalidArgument, "must be a spec or spec revision")
+}
+
+// handleDeletedReferences applies the configured reference deletion policy
+// to references to a resource that is about to be deleted.
+// It returns the names of any resources that were updated.
+func (s *RegistryServer) handleDeletedReferences(ctx context.Context, db *storage.Client, name names.Name) ([]string, error) {
+	if s.refDeletion == "" || s.refDeletion == ReferenceDeletionIgnore {
+		return nil, nil
+	}
+
+	refs, err := db.ListExternalReferences(ctx, name)
+	if err != nil || len(refs) == 0 {
+		return nil, err
+	}
+
+	if s.refDeletion == ReferenceDeletionReject {
+		holders := make([]string, len(refs))
+		for i, ref := range refs {
+			holders[i] = ref.Name + "." + ref.Field
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot delete %s, it is referenced by %s", name, strings.Join(holders, ", "))
+	}
+
+	if err := db.ClearReferences(ctx, refs); err != nil {
+		return nil, err
+	}
+	updated := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if len(updated) == 0 || updated[len(updated)-1] != ref.Name {
+			updated = append(updated, ref.Name)
+		}
+	}
+	return updated, nil
+}
+
+// notifyUpdated sends update notifications for a list of resources.
+func (s *RegistryServer) notifyUpdated(ctx context.Context, updated []string) {
+	for _, name := range updated {
+		s.notify(ctx, rpc.Notification_UPDATED, name)
+	}
+}
@@ -0,0 +1,371 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// serverWithReferences will call server.Close() when test completes
+func serverWithReferences(t *testing.T, validate bool, deletion string) *RegistryServer {
+	t.Helper()
+	server, err := New(Config{
+		Database:           "sqlite3",
+		DBConfig:           fmt.Sprintf("%s/registry.db", t.TempDir()),
+		ValidateReferences: validate,
+		ReferenceDeletion:  deletion,
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	return server
+}
+
+// seedReferences creates an API with a recommended version and deployment,
+// where the deployment refers to a spec revision in the recommended version.
+func seedReferences(ctx context.Context, t *testing.T, server *RegistryServer) *rpc.ApiSpec {
+	t.Helper()
+	if err := seeder.SeedSpecs(ctx, server, &rpc.ApiSpec{
+		Name: "projects/my-project/locations/global/apis/a/versions/v/specs/s",
+	}); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	spec, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{
+		Name: "projects/my-project/locations/global/apis/a/versions/v/specs/s",
+	})
+	if err != nil {
+		t.Fatalf("Setup: GetApiSpec() returned error: %s", err)
+	}
+	if _, err := server.UpdateApiDeployment(ctx, &rpc.UpdateApiDeploymentRequest{
+		ApiDeployment: &rpc.ApiDeployment{
+			Name:            "projects/my-project/locations/global/apis/a/deployments/d",
+			ApiSpecRevision: fmt.Sprintf("%s@%s", spec.GetName(), spec.GetRevisionId()),
+		},
+		AllowMissing: true,
+	}); err != nil {
+		t.Fatalf("Setup: UpdateApiDeployment() returned error: %s", err)
+	}
+	if _, err := server.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api: &rpc.Api{
+			Name:                  "projects/my-project/locations/global/apis/a",
+			RecommendedVersion:    "projects/my-project/locations/global/apis/a/versions/v",
+			RecommendedDeployment: "projects/my-project/locations/global/apis/a/deployments/d",
+		},
+	}); err != nil {
+		t.Fatalf("Setup: UpdateApi() returned error: %s", err)
+	}
+	return spec
+}
+
+func TestListReferences(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithReferences(t, true, "")
+	spec := seedReferences(ctx, t, server)
+	specRevision := fmt.Sprintf("%s@%s", spec.GetName(), spec.GetRevisionId())
+
+	if _, err := server.TagApiSpecRevision(ctx, &rpc.TagApiSpecRevisionRequest{
+		Name: specRevision,
+		Tag:  "prod",
+	}); err != nil {
+		t.Fatalf("Setup: TagApiSpecRevision() returned error: %s", err)
+	}
+
+	var (
+		recommendedVersion = &rpc.ListReferencesResponse_Reference{
+			Name:  "projects/my-project/locations/global/apis/a",
+			Field: "recommended_version",
+			Value: "projects/my-project/locations/global/apis/a/versions/v",
+		}
+		recommendedDeployment = &rpc.ListReferencesResponse_Reference{
+			Name:  "projects/my-project/locations/global/apis/a",
+			Field: "recommended_deployment",
+			Value: "projects/my-project/locations/global/apis/a/deployments/d",
+		}
+		apiSpecRevision = &rpc.ListReferencesResponse_Reference{
+			Name:  "projects/my-project/locations/global/apis/a/deployments/d",
+			Field: "api_spec_revision",
+			Value: specRevision,
+		}
+	)
+
+	tests := []struct {
+		desc string
+		name string
+		want []*rpc.ListReferencesResponse_Reference
+	}{
+		{
+			desc: "project",
+			name: "projects/my-project",
+			want: []*rpc.ListReferencesResponse_Reference{recommendedDeployment, recommendedVersion, apiSpecRevision},
+		},
+		{
+			desc: "version",
+			name: "projects/my-project/locations/global/apis/a/versions/v",
+			want: []*rpc.ListReferencesResponse_Reference{recommendedVersion, apiSpecRevision},
+		},
+		{
+			desc: "spec",
+			name: spec.GetName(),
+			want: []*rpc.ListReferencesResponse_Reference{apiSpecRevision},
+		},
+		{
+			desc: "spec revision",
+			name: specRevision,
+			want: []*rpc.ListReferencesResponse_Reference{apiSpecRevision},
+		},
+		{
+			desc: "tagged spec revision",
+			name: spec.GetName() + "@prod",
+			want: []*rpc.ListReferencesResponse_Reference{apiSpecRevision},
+		},
+		{
+			desc: "deployment",
+			name: "projects/my-project/locations/global/apis/a/deployments/d",
+			want: []*rpc.ListReferencesResponse_Reference{recommendedDeployment},
+		},
+		{
+			desc: "unreferenced spec revision",
+			name: spec.GetName() + "@00000000",
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := &rpc.ListReferencesRequest{Name: test.name}
+			got, err := server.ListReferences(ctx, req)
+			if err != nil {
+				t.Fatalf("ListReferences(%+v) returned error: %s", req, err)
+			}
+
+			want := &rpc.ListReferencesResponse{References: test.want}
+			if !cmp.Equal(want, got, protocmp.Transform()) {
+				t.Errorf("ListReferences(%+v) returned unexpected diff (-want +got):\n%s", req, cmp.Diff(want, got, protocmp.Transform()))
+			}
+		})
+	}
+
+	t.Run("invalid name", func(t *testing.T) {
+		req := &rpc.ListReferencesRequest{Name: "invalid"}
+		if _, err := server.ListReferences(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ListReferences(%+v) returned status code %q, want %q: %v", req, status.Code(err), codes.InvalidArgument, err)
+		}
+	})
+
+	t.Run("superseded deployment revision", func(t *testing.T) {
+		if _, err := server.UpdateApiDeployment(ctx, &rpc.UpdateApiDeploymentRequest{
+			ApiDeployment: &rpc.ApiDeployment{Name: "projects/my-project/locations/global/apis/a/deployments/d"},
+			UpdateMask:    &fieldmaskpb.FieldMask{Paths: []string{"api_spec_revision"}},
+		}); err != nil {
+			t.Fatalf("Setup: UpdateApiDeployment() returned error: %s", err)
+		}
+		req := &rpc.ListReferencesRequest{Name: spec.GetName()}
+		got, err := server.ListReferences(ctx, req)
+		if err != nil {
+			t.Fatalf("ListReferences(%+v) returned error: %s", req, err)
+		}
+		if len(got.GetReferences()) != 0 {
+			t.Errorf("ListReferences(%+v) returned %v, want no references", req, got.GetReferences())
+		}
+	})
+}
+
+func TestReferenceValidation(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithReferences(t, true, "")
+	spec := seedReferences(ctx, t, server)
+
+	tests := []struct {
+		desc string
+		req  interface{}
+		want codes.Code
+	}{
+		{
+			desc: "missing recommended version",
+			req: &rpc.UpdateApiRequest{
+				Api: &rpc.Api{
+					Name:               "projects/my-project/locations/global/apis/a",
+					RecommendedVersion: "projects/my-project/locations/global/apis/a/versions/missing",
+				},
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "invalid recommended deployment",
+			req: &rpc.CreateApiRequest{
+				Parent: "projects/my-project/locations/global",
+				ApiId:  "b",
+				Api: &rpc.Api{
+					RecommendedDeployment: "projects/my-project/locations/global/apis/a/versions/v",
+				},
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "missing spec revision",
+			req: &rpc.UpdateApiDeploymentRequest{
+				ApiDeployment: &rpc.ApiDeployment{
+					Name:            "projects/my-project/locations/global/apis/a/deployments/d",
+					ApiSpecRevision: spec.GetName() + "@missing",
+				},
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "missing spec",
+			req: &rpc.CreateApiDeploymentRequest{
+				Parent:          "projects/my-project/locations/global/apis/a",
+				ApiDeploymentId: "d2",
+				ApiDeployment: &rpc.ApiDeployment{
+					ApiSpecRevision: "projects/my-project/locations/global/apis/a/versions/v/specs/missing",
+				},
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "existing spec",
+			req: &rpc.CreateApiDeploymentRequest{
+				Parent:          "projects/my-project/locations/global/apis/a",
+				ApiDeploymentId: "d3",
+				ApiDeployment: &rpc.ApiDeployment{
+					ApiSpecRevision: spec.GetName(),
+				},
+			},
+			want: codes.OK,
+		},
+		{
+			desc: "cleared recommended version",
+			req: &rpc.UpdateApiRequest{
+				Api: &rpc.Api{
+					Name: "projects/my-project/locations/global/apis/a",
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"recommended_version"}},
+			},
+			want: codes.OK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var err error
+			switch req := test.req.(type) {
+			case *rpc.CreateApiRequest:
+				_, err = server.CreateApi(ctx, req)
+			case *rpc.UpdateApiRequest:
+				_, err = server.UpdateApi(ctx, req)
+			case *rpc.CreateApiDeploymentRequest:
+				_, err = server.CreateApiDeployment(ctx, req)
+			case *rpc.UpdateApiDeploymentRequest:
+				_, err = server.UpdateApiDeployment(ctx, req)
+			}
+			if status.Code(err) != test.want {
+				t.Errorf("request %+v returned status code %q, want %q: %v", test.req, status.Code(err), test.want, err)
+			}
+		})
+	}
+}
+
+func TestDeleteReferencedResources(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ignore", func(t *testing.T) {
+		server := serverWithReferences(t, false, ReferenceDeletionIgnore)
+		seedReferences(ctx, t, server)
+
+		req := &rpc.DeleteApiVersionRequest{Name: "projects/my-project/locations/global/apis/a/versions/v", Force: true}
+		if _, err := server.DeleteApiVersion(ctx, req); err != nil {
+			t.Fatalf("DeleteApiVersion(%+v) returned error: %s", req, err)
+		}
+
+		api, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: "projects/my-project/locations/global/apis/a"})
+		if err != nil {
+			t.Fatalf("GetApi() returned error: %s", err)
+		}
+		if api.GetRecommendedVersion() == "" {
+			t.Errorf("GetApi() returned cleared recommended_version, expected dangling reference")
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		server := serverWithReferences(t, false, ReferenceDeletionReject)
+		spec := seedReferences(ctx, t, server)
+
+		req := &rpc.DeleteApiVersionRequest{Name: "projects/my-project/locations/global/apis/a/versions/v", Force: true}
+		if _, err := server.DeleteApiVersion(ctx, req); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("DeleteApiVersion(%+v) returned status code %q, want %q: %v", req, status.Code(err), codes.FailedPrecondition, err)
+		}
+
+		specReq := &rpc.DeleteApiSpecRequest{Name: spec.GetName(), Force: true}
+		if _, err := server.DeleteApiSpec(ctx, specReq); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("DeleteApiSpec(%+v) returned status code %q, want %q: %v", specReq, status.Code(err), codes.FailedPrecondition, err)
+		}
+
+		// References held within the deleted resource don't block its deletion.
+		apiReq := &rpc.DeleteApiRequest{Name: "projects/my-project/locations/global/apis/a", Force: true}
+		if _, err := server.DeleteApi(ctx, apiReq); err != nil {
+			t.Errorf("DeleteApi(%+v) returned error: %s", apiReq, err)
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		server := serverWithReferences(t, false, ReferenceDeletionClear)
+		seedReferences(ctx, t, server)
+
+		before, err := server.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: "projects/my-project/locations/global/apis/a/deployments/d"})
+		if err != nil {
+			t.Fatalf("GetApiDeployment() returned error: %s", err)
+		}
+
+		req := &rpc.DeleteApiVersionRequest{Name: "projects/my-project/locations/global/apis/a/versions/v", Force: true}
+		if _, err := server.DeleteApiVersion(ctx, req); err != nil {
+			t.Fatalf("DeleteApiVersion(%+v) returned error: %s", req, err)
+		}
+
+		api, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: "projects/my-project/locations/global/apis/a"})
+		if err != nil {
+			t.Fatalf("GetApi() returned error: %s", err)
+		}
+		if api.GetRecommendedVersion() != "" {
+			t.Errorf("GetApi() returned recommended_version %q, want it to be cleared", api.GetRecommendedVersion())
+		}
+		if api.GetRecommendedDeployment() == "" {
+			t.Errorf("GetApi() returned cleared recommended_deployment, want it to be unchanged")
+		}
+
+		after, err := server.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: "projects/my-project/locations/global/apis/a/deployments/d"})
+		if err != nil {
+			t.Fatalf("GetApiDeployment() returned error: %s", err)
+		}
+		if after.GetApiSpecRevision() != "" {
+			t.Errorf("GetApiDeployment() returned api_spec_revision %q, want it to be cleared", after.GetApiSpecRevision())
+		}
+		if after.GetRevisionId() == before.GetRevisionId() {
+			t.Errorf("GetApiDeployment() returned revision %q, want a new revision", after.GetRevisionId())
+		}
+	})
+}
@@ -27,7 +27,7 @@ import (
 
 // ListApiSpecRevisions handles the corresponding API request.
 func (s *RegistryServer) ListApiSpecRevisions(ctx context.Context, req *rpc.ListApiSpecRevisionsRequest) (*rpc.ListApiSpecRevisionsResponse, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -45,9 +45,14 @@ func (s *RegistryServer) ListApiSpecRevisions(ctx context.Context, req *rpc.List
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.ApiSpec{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	listing, err := db.ListSpecRevisions(ctx, parent, storage.PageOptions{
-		Size:  req.GetPageSize(),
-		Token: req.GetPageToken(),
+		Size:   req.GetPageSize(),
+		Token:  req.GetPageToken(),
+		Fields: readMaskFields(req.GetReadMask()),
 	})
 	if err != nil {
 		return nil, err
@@ -63,6 +68,18 @@ func (s *RegistryServer) ListApiSpecRevisions(ctx context.Context, req *rpc.List
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
+		applyReadMask(response.ApiSpecs[i], req.GetReadMask())
+	}
+
+	tags, err := db.ListSpecRevisionTags(ctx, listing.Specs)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		response.RevisionTags = append(response.RevisionTags, &rpc.RevisionTag{
+			Revision: t.RevisionName(),
+			Tag:      t.Tag,
+		})
 	}
 
 	return response, nil
@@ -75,22 +92,32 @@ func (s *RegistryServer) DeleteApiSpecRevision(ctx context.Context, req *rpc.Del
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "DeleteApiSpecRevision", name.String(), nil); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiSpec
+	var updated []string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		var err error
+		updated, err = s.handleDeletedReferences(ctx, db, name)
+		if err != nil {
+			return err
+		}
 		if err := db.DeleteSpecRevision(ctx, name); err != nil {
 			return err
 		}
-		return nil
+		return s.updateSpecSummary(ctx, db, name.Spec())
 	}); err != nil {
 		return nil, err
 	}
 	// return the latest revision of the current spec
-	response, err = s.getApiSpec(ctx, name.Spec())
+	response, err = s.getApiSpec(withPrimaryReads(ctx), name.Spec())
 	if err != nil {
 		// The get will fail if we are deleting the only revision.
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	s.notify(ctx, rpc.Notification_DELETED, name.String())
+	s.notifyUpdated(ctx, updated)
 	return response, nil
 }
 
@@ -109,6 +136,9 @@ func (s *RegistryServer) TagApiSpecRevision(ctx context.Context, req *rpc.TagApi
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "TagApiSpecRevision", name.String(), nil); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiSpec
 	var revisionName string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
@@ -151,6 +181,9 @@ func (s *RegistryServer) RollbackApiSpec(ctx context.Context, req *rpc.RollbackA
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "RollbackApiSpec", req.GetName(), nil); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiSpec
 	var revisionName string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
@@ -160,6 +193,9 @@ func (s *RegistryServer) RollbackApiSpec(ctx context.Context, req *rpc.RollbackA
 		if err != nil {
 			return err
 		}
+		if err := s.checkRevisionQuota(ctx, db, parent); err != nil {
+			return err
+		}
 		// Save a new rollback revision based on the target revision.
 		rollback := target.NewRevision()
 		if err := db.SaveSpecRevision(ctx, rollback); err != nil {
@@ -169,11 +205,17 @@ func (s *RegistryServer) RollbackApiSpec(ctx context.Context, req *rpc.RollbackA
 		if err != nil {
 			return err
 		}
+		if err := s.checkBlobQuota(ctx, db, parent.ProjectID, rollback.RevisionName(), len(blob.Contents)); err != nil {
+			return err
+		}
 		// Save a new copy of the target revision blob for the rollback revision.
 		blob.RevisionID = name.RevisionID
 		if err := db.SaveSpecRevisionContents(ctx, rollback, blob.Contents); err != nil {
 			return err
 		}
+		if err := s.updateSpecSummary(ctx, db, parent); err != nil {
+			return err
+		}
 		response, err = rollback.BasicMessage(rollback.RevisionName())
 		if err != nil {
 			return err
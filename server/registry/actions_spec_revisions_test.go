@@ -136,6 +136,26 @@ func TestTagApiSpecRevision(t *testing.T) {
 		})
 	})
 
+	t.Run("ListApiSpecRevisions", func(t *testing.T) {
+		req := &rpc.ListApiSpecRevisionsRequest{
+			Name: revision.GetName(),
+		}
+
+		got, err := server.ListApiSpecRevisions(ctx, req)
+		if err != nil {
+			t.Fatalf("ListApiSpecRevisions(%+v) returned error: %s", req, err)
+		}
+
+		tagged := fmt.Sprintf("%s@%s", revision.GetName(), revision.GetRevisionId())
+		want := []*rpc.RevisionTag{
+			{Revision: tagged, Tag: "my-second-tag"},
+			{Revision: tagged, Tag: "my-tag"},
+		}
+		if diff := cmp.Diff(want, got.GetRevisionTags(), protocmp.Transform()); diff != "" {
+			t.Errorf("ListApiSpecRevisions(%+v) returned unexpected tags (-want +got):\n%s", req, diff)
+		}
+	})
+
 	t.Run("DeleteApiSpecRevision", func(t *testing.T) {
 		req := &rpc.DeleteApiSpecRevisionRequest{
 			Name: got.GetName(),
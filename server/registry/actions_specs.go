@@ -46,6 +46,9 @@ func (s *RegistryServer) CreateApiSpec(ctx context.Context, req *rpc.CreateApiSp
 	if err := name.Validate(); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "CreateApiSpec", name.String(), req.GetApiSpec()); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiSpec
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		var err error
@@ -71,11 +74,19 @@ func (s *RegistryServer) createSpec(ctx context.Context, db *storage.Client, nam
 		return nil, err
 	}
 
+	if err := s.validateSpecContents(ctx, db, name, body.GetMimeType(), body.GetContents()); err != nil {
+		return nil, err
+	}
+
 	spec, err := models.NewSpec(name, body)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := s.checkBlobQuota(ctx, db, name.ProjectID, spec.RevisionName(), len(body.GetContents())); err != nil {
+		return nil, err
+	}
+
 	if err := db.CreateSpecRevision(ctx, spec); err != nil {
 		return nil, err
 	}
@@ -84,6 +95,10 @@ func (s *RegistryServer) createSpec(ctx context.Context, db *storage.Client, nam
 		return nil, err
 	}
 
+	if err := s.updateSpecSummary(ctx, db, name); err != nil {
+		return nil, err
+	}
+
 	return spec.BasicMessage(name.String())
 }
 
@@ -95,28 +110,52 @@ func (s *RegistryServer) DeleteApiSpec(ctx context.Context, req *rpc.DeleteApiSp
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := s.admit(ctx, "DeleteApiSpec", name.String(), nil); err != nil {
+		return nil, err
+	}
+	var updated []string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		var err error
+		updated, err = s.handleDeletedReferences(ctx, db, name)
+		if err != nil {
+			return err
+		}
 		return db.DeleteSpec(ctx, name, req.GetForce())
 	}); err != nil {
 		return nil, err
 	}
 	s.notify(ctx, rpc.Notification_DELETED, req.GetName())
+	s.notifyUpdated(ctx, updated)
 	return &emptypb.Empty{}, nil
 }
 
 // GetApiSpec handles the corresponding API request.
 func (s *RegistryServer) GetApiSpec(ctx context.Context, req *rpc.GetApiSpecRequest) (*rpc.ApiSpec, error) {
-	if name, err := names.ParseSpec(req.GetName()); err == nil {
-		return s.getApiSpec(ctx, name)
-	} else if name, err := names.ParseSpecRevision(req.GetName()); err == nil {
-		return s.getApiSpecRevision(ctx, name)
+	if err := validateReadMask(&rpc.ApiSpec{}, req.GetReadMask()); err != nil {
+		return nil, err
 	}
 
-	return nil, status.Errorf(codes.InvalidArgument, "invalid resource name %q, must be an API spec or revision", req.GetName())
+	var (
+		message *rpc.ApiSpec
+		err     error
+	)
+	if name, perr := names.ParseSpec(req.GetName()); perr == nil {
+		message, err = s.getApiSpec(ctx, name)
+	} else if name, perr := names.ParseSpecRevision(req.GetName()); perr == nil {
+		message, err = s.getApiSpecRevision(ctx, name)
+	} else {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid resource name %q, must be an API spec or revision", req.GetName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applyReadMask(message, req.GetReadMask())
+	return message, nil
 }
 
 func (s *RegistryServer) getApiSpec(ctx context.Context, name names.Spec) (*rpc.ApiSpec, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -135,7 +174,7 @@ func (s *RegistryServer) getApiSpec(ctx context.Context, name names.Spec) (*rpc.
 }
 
 func (s *RegistryServer) getApiSpecRevision(ctx context.Context, name names.SpecRevision) (*rpc.ApiSpec, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -155,28 +194,12 @@ func (s *RegistryServer) getApiSpecRevision(ctx context.Context, name names.Spec
 
 // GetApiSpecContents handles the corresponding API request.
 func (s *RegistryServer) GetApiSpecContents(ctx context.Context, req *rpc.GetApiSpecContentsRequest) (*httpbody.HttpBody, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
 
-	var specName = req.GetName()
-	var spec *models.Spec
-	var revisionName names.SpecRevision
-	if name, err := names.ParseSpec(specName); err == nil {
-		if spec, err = db.GetSpec(ctx, name); err != nil {
-			return nil, err
-		}
-		revisionName = name.Revision(spec.RevisionID)
-	} else if name, err := names.ParseSpecRevision(specName); err == nil {
-		if spec, err = db.GetSpecRevision(ctx, name); err != nil {
-			return nil, err
-		}
-		revisionName = name
-	} else {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid resource name %q, must be an API spec or revision", specName)
-	}
-	blob, err := db.GetSpecRevisionContents(ctx, revisionName)
+	spec, blob, err := getSpecContents(ctx, db, req.GetName())
 	if err != nil {
 		return nil, err
 	}
@@ -197,9 +220,40 @@ func (s *RegistryServer) GetApiSpecContents(ctx context.Context, req *rpc.GetApi
 	}, nil
 }
 
+// getSpecContents gets a spec or spec revision and its contents.
+func getSpecContents(ctx context.Context, db *storage.Client, specName string) (*models.Spec, *models.Blob, error) {
+	spec, revisionName, err := getSpecRevision(ctx, db, specName)
+	if err != nil {
+		return nil, nil, err
+	}
+	blob, err := db.GetSpecRevisionContents(ctx, revisionName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return spec, blob, nil
+}
+
+// getSpecRevision gets a spec or spec revision and the name of the revision.
+func getSpecRevision(ctx context.Context, db *storage.Client, specName string) (*models.Spec, names.SpecRevision, error) {
+	if name, err := names.ParseSpec(specName); err == nil {
+		spec, err := db.GetSpec(ctx, name)
+		if err != nil {
+			return nil, names.SpecRevision{}, err
+		}
+		return spec, name.Revision(spec.RevisionID), nil
+	} else if name, err := names.ParseSpecRevision(specName); err == nil {
+		spec, err := db.GetSpecRevision(ctx, name)
+		if err != nil {
+			return nil, names.SpecRevision{}, err
+		}
+		return spec, name, nil
+	}
+	return nil, names.SpecRevision{}, status.Errorf(codes.InvalidArgument, "invalid resource name %q, must be an API spec or revision", specName)
+}
+
 // ListApiSpecs handles the corresponding API request.
 func (s *RegistryServer) ListApiSpecs(ctx context.Context, req *rpc.ListApiSpecsRequest) (*rpc.ListApiSpecsResponse, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -217,11 +271,16 @@ func (s *RegistryServer) ListApiSpecs(ctx context.Context, req *rpc.ListApiSpecs
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.ApiSpec{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	listing, err := db.ListSpecs(ctx, parent, storage.PageOptions{
 		Size:   req.GetPageSize(),
 		Filter: req.GetFilter(),
 		Order:  req.GetOrderBy(),
 		Token:  req.GetPageToken(),
+		Fields: readMaskFields(req.GetReadMask()),
 	})
 	if err != nil {
 		return nil, err
@@ -237,6 +296,7 @@ func (s *RegistryServer) ListApiSpecs(ctx context.Context, req *rpc.ListApiSpecs
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
+		applyReadMask(response.ApiSpecs[i], req.GetReadMask())
 	}
 
 	return response, nil
@@ -257,6 +317,9 @@ func (s *RegistryServer) UpdateApiSpec(ctx context.Context, req *rpc.UpdateApiSp
 	if err := models.ValidateMask(req.GetApiSpec(), req.GetUpdateMask()); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid update_mask %v: %s", req.GetUpdateMask(), err)
 	}
+	if err := s.admit(ctx, "UpdateApiSpec", name.String(), req.GetApiSpec()); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiSpec
 	if err = s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		db.LockSpecs(ctx)
@@ -264,19 +327,50 @@ func (s *RegistryServer) UpdateApiSpec(ctx context.Context, req *rpc.UpdateApiSp
 		if err == nil {
 			// Apply the update to the spec - possibly changing the revision ID.
 			maskExpansion := models.ExpandMask(req.GetApiSpec(), req.GetUpdateMask())
+			previousRevision, previousMimeType := spec.RevisionID, spec.MimeType
 			if err := spec.Update(req.GetApiSpec(), maskExpansion); err != nil {
 				return err
 			}
+			if spec.RevisionID != previousRevision {
+				if err := s.checkRevisionQuota(ctx, db, name); err != nil {
+					return err
+				}
+			}
 			// Save the updated/current spec. This creates a new revision or updates the previous one.
 			if err := db.SaveSpecRevision(ctx, spec); err != nil {
 				return err
 			}
-			// If the spec contents were updated, save a new blob.
-			if len(fieldmaskpb.Intersect(maskExpansion, &fieldmaskpb.FieldMask{Paths: []string{"contents"}}).GetPaths()) > 0 {
+			// If only the type of the contents changed, validate the current contents with the new type.
+			updatesContents := len(fieldmaskpb.Intersect(maskExpansion, &fieldmaskpb.FieldMask{Paths: []string{"contents"}}).GetPaths()) > 0
+			if !updatesContents && spec.MimeType != previousMimeType {
+				blob, err := db.GetSpecRevisionContents(ctx, name.Revision(previousRevision))
+				if err != nil && !isNotFound(err) {
+					return err
+				}
+				if blob != nil {
+					if err := s.validateSpecContents(ctx, db, name, spec.MimeType, blob.Contents); err != nil {
+						return err
+					}
+				}
+			}
+			// If the spec contents were updated, validate them and save a new blob.
+			if updatesContents {
+				if err := s.validateSpecContents(ctx, db, name, spec.MimeType, req.ApiSpec.GetContents()); err != nil {
+					return err
+				}
+				if err := s.checkBlobQuota(ctx, db, name.ProjectID, spec.RevisionName(), len(req.ApiSpec.GetContents())); err != nil {
+					return err
+				}
 				if err := db.SaveSpecRevisionContents(ctx, spec, req.ApiSpec.GetContents()); err != nil {
 					return err
 				}
 			}
+			// If the contents or their type changed, update the spec summary.
+			if len(fieldmaskpb.Intersect(maskExpansion, &fieldmaskpb.FieldMask{Paths: []string{"contents", "mime_type"}}).GetPaths()) > 0 {
+				if err := s.updateSpecSummary(ctx, db, name); err != nil {
+					return err
+				}
+			}
 			response, err = spec.BasicMessage(name.String())
 			return err
 		} else if status.Code(err) == codes.NotFound && req.GetAllowMissing() {
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -25,7 +26,7 @@ import (
 
 // GetStorage handles the corresponding API request.
 func (s *RegistryServer) GetStorage(ctx context.Context, req *emptypb.Empty) (*rpc.Storage, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -46,8 +47,26 @@ func (s *RegistryServer) GetStorage(ctx context.Context, req *emptypb.Empty) (*r
 			},
 		)
 	}
+	usage, err := db.ListProjectUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projectUsage := make([]*rpc.Storage_ProjectUsage, len(usage))
+	for i, u := range usage {
+		limits := s.quotas.limits(u.ProjectID)
+		projectUsage[i] = &rpc.Storage_ProjectUsage{
+			Project:              names.Project{ProjectID: u.ProjectID}.String(),
+			ApiCount:             u.ApiCount,
+			ApiLimit:             limits.MaxApis,
+			MaxSpecRevisionCount: u.MaxSpecRevisionCount,
+			SpecRevisionLimit:    limits.MaxRevisionsPerSpec,
+			BlobBytes:            u.BlobBytes,
+			BlobBytesLimit:       limits.MaxBlobBytes,
+		}
+	}
 	return &rpc.Storage{
-		Description: db.DatabaseName(ctx),
-		Collections: collections,
+		Description:  db.DatabaseName(ctx),
+		Collections:  collections,
+		ProjectUsage: projectUsage,
 	}, nil
 }
@@ -0,0 +1,456 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamChunkSize is the maximum size of the data in each StreamApiSpecContents response.
+const StreamChunkSize = 1 << 20
+
+// StreamApiSpecContents handles the corresponding API request.
+func (s *RegistryServer) StreamApiSpecContents(req *rpc.StreamApiSpecContentsRequest, stream rpc.Registry_StreamApiSpecContentsServer) error {
+	ctx := stream.Context()
+	if req.GetReadOffset() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid read_offset %d: must not be negative", req.GetReadOffset())
+	}
+	if req.GetReadLimit() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid read_limit %d: must not be negative", req.GetReadLimit())
+	}
+
+	db, err := s.getReadClient(ctx)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	spec, revisionName, err := getSpecRevision(ctx, db, req.GetName())
+	if err != nil {
+		return err
+	}
+	blob, err := db.GetSpecRevisionBlob(ctx, revisionName)
+	if err != nil {
+		return err
+	}
+
+	// Contents are read from storage in chunks as they are streamed, and
+	// gzipped contents are uncompressed as they are read.
+	contentType := spec.MimeType
+	length, err := db.GetBlobLength(ctx, blob.Key)
+	if err != nil {
+		return err
+	}
+	contents := &blobReader{ctx: ctx, db: db, key: blob.Key, length: length}
+	var r io.Reader = contents
+	if strings.Contains(spec.MimeType, "+gzip") && !incomingContextAllowsGZIP(ctx) && length > 0 {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return status.Errorf(codes.FailedPrecondition, "failed to unzip contents with gzip MIME type: %s", err)
+		}
+		r = zr
+		contentType = strings.Replace(spec.MimeType, "+gzip", "", 1)
+		if _, err := io.CopyN(io.Discard, r, req.GetReadOffset()); err == io.EOF {
+			return status.Errorf(codes.OutOfRange, "read_offset %d is beyond the end of the contents", req.GetReadOffset())
+		} else if err != nil {
+			return status.Errorf(codes.FailedPrecondition, "failed to read contents: %s", err)
+		}
+	} else if req.GetReadOffset() > length {
+		return status.Errorf(codes.OutOfRange, "read_offset %d is beyond the end of the contents", req.GetReadOffset())
+	} else {
+		contents.offset = req.GetReadOffset()
+	}
+	if req.GetReadLimit() > 0 {
+		r = io.LimitReader(r, req.GetReadLimit())
+	}
+
+	offset := req.GetReadOffset()
+	buf := make([]byte, StreamChunkSize)
+	for first := true; ; first = false {
+		n, err := io.ReadFull(r, buf)
+		if _, ok := status.FromError(err); ok && err != nil {
+			return err
+		} else if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return status.Errorf(codes.FailedPrecondition, "failed to read contents: %s", err)
+		}
+		if n > 0 || first {
+			response := &rpc.StreamApiSpecContentsResponse{
+				Offset: offset,
+				Data:   buf[:n],
+			}
+			if first {
+				response.ContentType = contentType
+			}
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// blobReader reads the contents of a blob from storage in chunks.
+type blobReader struct {
+	ctx    context.Context
+	db     *storage.Client
+	key    string
+	offset int64
+	length int64
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	if r.offset >= r.length {
+		return 0, io.EOF
+	}
+	length := int64(len(p))
+	if length > StreamChunkSize {
+		length = StreamChunkSize
+	}
+	data, err := r.db.ReadBlob(r.ctx, r.key, r.offset, length)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n := copy(p, data)
+	r.offset += int64(n)
+	return n, nil
+}
+
+// UploadApiSpecContents handles the corresponding API request.
+func (s *RegistryServer) UploadApiSpecContents(stream rpc.Registry_UploadApiSpecContentsServer) error {
+	ctx := stream.Context()
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "upload must contain at least one request")
+	} else if err != nil {
+		return err
+	}
+	name, err := names.ParseSpec(req.GetApiSpec().GetName())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	id, err := uploadID(req.GetUploadId())
+	if err != nil {
+		return err
+	}
+	upload, err := s.receiveUpload(ctx, id, name.String(), name.ProjectID, req, func() (uploadRequest, error) {
+		return stream.Recv()
+	})
+	if err != nil {
+		return err
+	}
+	if !upload.finished {
+		return stream.SendAndClose(&rpc.UploadApiSpecContentsResponse{CommittedSize: upload.committed, UploadId: upload.id})
+	}
+
+	body := proto.Clone(req.GetApiSpec()).(*rpc.ApiSpec)
+	if body.Contents, err = s.uploadedContents(ctx, upload); err != nil {
+		return err
+	}
+	spec, err := s.finishUpload(ctx, "UploadApiSpecContents", upload.id, req, func(ctx context.Context) (proto.Message, error) {
+		return s.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+			ApiSpec:      body,
+			AllowMissing: true,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&rpc.UploadApiSpecContentsResponse{
+		CommittedSize: upload.committed,
+		ApiSpec:       spec.(*rpc.ApiSpec),
+		UploadId:      upload.id,
+	})
+}
+
+// UploadArtifactContents handles the corresponding API request.
+func (s *RegistryServer) UploadArtifactContents(stream rpc.Registry_UploadArtifactContentsServer) error {
+	ctx := stream.Context()
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "upload must contain at least one request")
+	} else if err != nil {
+		return err
+	}
+	name, err := names.ParseArtifact(req.GetArtifact().GetName())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	id, err := uploadID(req.GetUploadId())
+	if err != nil {
+		return err
+	}
+	upload, err := s.receiveUpload(ctx, id, name.String(), name.ProjectID(), req, func() (uploadRequest, error) {
+		return stream.Recv()
+	})
+	if err != nil {
+		return err
+	}
+	if !upload.finished {
+		return stream.SendAndClose(&rpc.UploadArtifactContentsResponse{CommittedSize: upload.committed, UploadId: upload.id})
+	}
+
+	body := proto.Clone(req.GetArtifact()).(*rpc.Artifact)
+	if body.Contents, err = s.uploadedContents(ctx, upload); err != nil {
+		return err
+	}
+	artifact, err := s.finishUpload(ctx, "UploadArtifactContents", upload.id, req, func(ctx context.Context) (proto.Message, error) {
+		artifact, err := s.ReplaceArtifact(ctx, &rpc.ReplaceArtifactRequest{Artifact: body})
+		if status.Code(err) == codes.NotFound {
+			artifact, err = s.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+				Parent:     name.Parent(),
+				ArtifactId: name.ArtifactID(),
+				Artifact:   body,
+			})
+		}
+		return artifact, err
+	})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&rpc.UploadArtifactContentsResponse{
+		CommittedSize: upload.committed,
+		Artifact:      artifact.(*rpc.Artifact),
+		UploadId:      upload.id,
+	})
+}
+
+// uploadRequest contains the fields that are common to all upload requests.
+type uploadRequest interface {
+	GetWriteOffset() int64
+	GetData() []byte
+	GetFinishWrite() bool
+}
+
+// uploadID returns the ID of a requested upload, or a new ID if none was requested.
+func uploadID(requested string) (string, error) {
+	if requested == "" {
+		return uuid.New().String(), nil
+	}
+	if _, err := uuid.Parse(requested); err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid upload_id %q: %s", requested, err)
+	}
+	return requested, nil
+}
+
+// upload describes the state of a pending upload.
+type upload struct {
+	id        string // Identifies the upload.
+	key       string // Name of the uploaded resource.
+	committed int64  // Number of bytes that have been saved.
+	finished  bool   // True if the client finished the upload.
+}
+
+// receiveUpload saves the chunks of an upload as they are received.
+// Chunks are saved with the upload's ID, so concurrent uploads of a resource are independent.
+func (s *RegistryServer) receiveUpload(ctx context.Context, id, key, projectID string, req uploadRequest, recv func() (uploadRequest, error)) (upload, error) {
+	u := upload{id: id, key: key}
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return u, status.Error(codes.Unavailable, err.Error())
+	}
+	if u.committed, err = db.GetUploadSize(ctx, id); err != nil {
+		return u, err
+	}
+	if u.committed > 0 {
+		first, err := db.GetUploadChunk(ctx, id, 0)
+		if err != nil {
+			return u, err
+		}
+		if first.Key != key {
+			return u, status.Errorf(codes.InvalidArgument, "invalid upload_id %q: not an upload of %q", id, key)
+		}
+	}
+
+	for started := false; ; {
+		if len(req.GetData()) > 0 || req.GetFinishWrite() {
+			// Restarting an upload discards its previously saved chunks.
+			if !started && req.GetWriteOffset() == 0 && u.committed > 0 {
+				if err := db.DeleteUpload(ctx, id); err != nil {
+					return u, err
+				}
+				u.committed = 0
+			}
+			started = true
+
+			if req.GetWriteOffset() != u.committed {
+				return u, status.Errorf(codes.OutOfRange, "invalid write_offset %d: must equal the committed size %d", req.GetWriteOffset(), u.committed)
+			}
+			if len(req.GetData()) > 0 {
+				if projectID != "" {
+					if err := s.checkBlobQuota(ctx, db, projectID, key, len(req.GetData())); err != nil {
+						return u, err
+					}
+				}
+				if err := db.SaveUploadChunk(ctx, &models.UploadChunk{
+					UploadID:    id,
+					WriteOffset: u.committed,
+					Key:         key,
+					ProjectID:   projectID,
+					Data:        req.GetData(),
+					CreateTime:  time.Now().Round(time.Microsecond),
+				}); err != nil {
+					return u, err
+				}
+				u.committed += int64(len(req.GetData()))
+			}
+			if req.GetFinishWrite() {
+				u.finished = true
+				return u, nil
+			}
+		}
+
+		next, err := recv()
+		if errors.Is(err, io.EOF) {
+			return u, nil
+		} else if err != nil {
+			return u, err
+		}
+		req = next
+	}
+}
+
+// uploadedContents returns the contents of a finished upload, which are read
+// from storage one chunk at a time into a buffer of the size of the upload.
+func (s *RegistryServer) uploadedContents(ctx context.Context, u upload) ([]byte, error) {
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	contents := make([]byte, u.committed)
+	if _, err := io.ReadFull(&uploadReader{ctx: ctx, db: db, id: u.id, length: u.committed}, contents); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "failed to read upload %q: %s", u.id, err)
+	}
+	return contents, nil
+}
+
+// uploadReader reads the contents of a pending upload from storage one chunk at a time.
+type uploadReader struct {
+	ctx    context.Context
+	db     *storage.Client
+	id     string
+	offset int64
+	length int64
+	chunk  *models.UploadChunk
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	if r.offset >= r.length {
+		return 0, io.EOF
+	}
+	if c := r.chunk; c == nil || r.offset >= c.WriteOffset+int64(len(c.Data)) {
+		chunk, err := r.db.GetUploadChunk(r.ctx, r.id, r.offset)
+		if err != nil {
+			return 0, err
+		}
+		if r.offset >= chunk.WriteOffset+int64(len(chunk.Data)) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		r.chunk = chunk
+	}
+	n := copy(p, r.chunk.Data[r.offset-r.chunk.WriteOffset:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+// finishUpload deletes the saved chunks of a finished upload and commits it
+// with fn in the same transaction, recording an audit event if auditing is enabled.
+// Chunks are deleted first so that quotas don't count the uploaded contents twice.
+func (s *RegistryServer) finishUpload(ctx context.Context, method, uploadID string, request proto.Message, fn func(context.Context) (proto.Message, error)) (proto.Message, error) {
+	commit := func(ctx context.Context) (proto.Message, error) {
+		db, err := s.getStorageClient(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		if err := db.DeleteUpload(ctx, uploadID); err != nil {
+			return nil, err
+		}
+		return fn(ctx)
+	}
+	if s.auditEnabled {
+		return s.audited(ctx, method, request, commit)
+	}
+	var m proto.Message
+	err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		var err error
+		m, err = commit(withTransaction(ctx, db))
+		return err
+	})
+	return m, err
+}
+
+// DefaultUploadExpiration is the default time after which unfinished uploads are deleted.
+const DefaultUploadExpiration = 24 * time.Hour
+
+// sweepUploads periodically deletes unfinished uploads that have expired until the server is closed.
+func (s *RegistryServer) sweepUploads() {
+	defer s.sweeping.Done()
+	interval := s.uploadExpiration / 4
+	if interval > time.Hour {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.deleteExpiredUploads(context.Background())
+		case <-s.stopSweeping:
+			return
+		}
+	}
+}
+
+// deleteExpiredUploads deletes the expired uploads of the server and of its open instances.
+func (s *RegistryServer) deleteExpiredUploads(ctx context.Context) {
+	clients := []*storage.Client{s.storageClient}
+	s.instances.mu.Lock()
+	for _, c := range s.instances.clients {
+		clients = append(clients, c)
+	}
+	s.instances.mu.Unlock()
+
+	before := time.Now().Add(-s.uploadExpiration)
+	for _, db := range clients {
+		if n, err := db.DeleteExpiredUploads(ctx, before); err != nil {
+			log.FromContext(ctx).WithError(err).Error("Failed to delete expired uploads")
+		} else if n > 0 {
+			log.FromContext(ctx).Infof("Deleted %d chunks of expired uploads", n)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// streamingTestClient serves a registry server over gRPC, which is needed to
+// exercise streaming methods, and returns the server and a client for it.
+func streamingTestClient(t *testing.T) (*RegistryServer, rpc.RegistryClient) {
+	t.Helper()
+	server, conn := streamingTestConn(t)
+	return server, rpc.NewRegistryClient(conn)
+}
+
+// streamingTestConn serves a registry server over gRPC and returns the server and a connection to it.
+func streamingTestConn(t *testing.T) (*RegistryServer, *grpc.ClientConn) {
+	t.Helper()
+	server, err := New(Config{
+		Database: "sqlite3",
+		DBConfig: fmt.Sprintf("%s/registry.db", t.TempDir()),
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	listener, grpcServer, err := server.ServeGRPC(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Setup: failed to serve: %s", err)
+	}
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Setup: failed to dial server: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return server, conn
+}
+
+// largeContents returns contents that require multiple streamed chunks.
+func largeContents(size int) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte('a' + i%26)
+	}
+	return b
+}
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func receiveContents(t *testing.T, stream rpc.Registry_StreamApiSpecContentsClient) (string, []byte, int, error) {
+	t.Helper()
+	var contentType string
+	var contents []byte
+	var chunks int
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return contentType, contents, chunks, nil
+		} else if err != nil {
+			return "", nil, 0, err
+		}
+		if chunks == 0 {
+			contentType = resp.GetContentType()
+		}
+		chunks++
+		contents = append(contents, resp.GetData()...)
+	}
+}
+
+func TestStreamApiSpecContents(t *testing.T) {
+	ctx := context.Background()
+	server, client := streamingTestClient(t)
+	contents := largeContents(2*StreamChunkSize + 100)
+	spec := &rpc.ApiSpec{
+		Name:     "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+		MimeType: "application/x.openapi+gzip;version=3",
+		Contents: gzipBytes(t, contents),
+	}
+	if err := seeder.SeedSpecs(ctx, server, spec); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+
+	tests := []struct {
+		desc   string
+		offset int64
+		limit  int64
+		want   []byte
+		chunks int
+	}{
+		{
+			desc:   "all",
+			want:   contents,
+			chunks: 3,
+		},
+		{
+			desc:   "offset",
+			offset: StreamChunkSize,
+			want:   contents[StreamChunkSize:],
+			chunks: 2,
+		},
+		{
+			desc:   "offset and limit",
+			offset: 10,
+			limit:  20,
+			want:   contents[10:30],
+			chunks: 1,
+		},
+		{
+			desc:   "end",
+			offset: int64(len(contents)),
+			want:   nil,
+			chunks: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := &rpc.StreamApiSpecContentsRequest{
+				Name:       spec.GetName(),
+				ReadOffset: test.offset,
+				ReadLimit:  test.limit,
+			}
+			stream, err := client.StreamApiSpecContents(ctx, req)
+			if err != nil {
+				t.Fatalf("StreamApiSpecContents(%+v) returned error: %s", req, err)
+			}
+			contentType, got, chunks, err := receiveContents(t, stream)
+			if err != nil {
+				t.Fatalf("StreamApiSpecContents(%+v) returned error: %s", req, err)
+			}
+			if want := "application/x.openapi;version=3"; contentType != want {
+				t.Errorf("StreamApiSpecContents(%+v) returned content type %q, want %q", req, contentType, want)
+			}
+			if !bytes.Equal(got, test.want) {
+				t.Errorf("StreamApiSpecContents(%+v) returned %d bytes, want %d matching bytes", req, len(got), len(test.want))
+			}
+			if chunks != test.chunks {
+				t.Errorf("StreamApiSpecContents(%+v) returned %d chunks, want %d", req, chunks, test.chunks)
+			}
+		})
+	}
+
+	errorTests := []struct {
+		desc string
+		req  *rpc.StreamApiSpecContentsRequest
+		want codes.Code
+	}{
+		{
+			desc: "offset beyond end",
+			req:  &rpc.StreamApiSpecContentsRequest{Name: spec.GetName(), ReadOffset: int64(len(contents) + 1)},
+			want: codes.OutOfRange,
+		},
+		{
+			desc: "negative offset",
+			req:  &rpc.StreamApiSpecContentsRequest{Name: spec.GetName(), ReadOffset: -1},
+			want: codes.InvalidArgument,
+		},
+		{
+			desc: "missing spec",
+			req:  &rpc.StreamApiSpecContentsRequest{Name: spec.GetName() + "-missing"},
+			want: codes.NotFound,
+		},
+	}
+
+	for _, test := range errorTests {
+		t.Run(test.desc, func(t *testing.T) {
+			stream, err := client.StreamApiSpecContents(ctx, test.req)
+			if err == nil {
+				_, _, _, err = receiveContents(t, stream)
+			}
+			if status.Code(err) != test.want {
+				t.Errorf("StreamApiSpecContents(%+v) returned status code %q, want %q: %v", test.req, status.Code(err), test.want, err)
+			}
+		})
+	}
+}
+
+func TestUploadApiSpecContents(t *testing.T) {
+	ctx := context.Background()
+	server, client := streamingTestClient(t)
+	if err := seeder.SeedVersions(ctx, server, &rpc.ApiVersion{Name: "projects/my-project/locations/global/apis/a/versions/v1"}); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	spec := &rpc.ApiSpec{
+		Name:     "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+		MimeType: "application/x.openapi;version=3",
+	}
+	contents := largeContents(1000)
+
+	upload := func(reqs ...*rpc.UploadApiSpecContentsRequest) (*rpc.UploadApiSpecContentsResponse, error) {
+		t.Helper()
+		stream, err := client.UploadApiSpecContents(ctx)
+		if err != nil {
+			t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+		}
+		for _, req := range reqs {
+			if err := stream.Send(req); err != nil {
+				break
+			}
+		}
+		return stream.CloseAndRecv()
+	}
+
+	// Upload part of the contents without finishing, as if interrupted.
+	resp, err := upload(
+		&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, WriteOffset: 0, Data: contents[:300]},
+		&rpc.UploadApiSpecContentsRequest{WriteOffset: 300, Data: contents[300:400]},
+	)
+	if err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	if resp.GetCommittedSize() != 400 || resp.GetApiSpec() != nil || resp.GetUploadId() == "" {
+		t.Errorf("UploadApiSpecContents() returned %+v, want committed size 400, an upload ID, and no spec", resp)
+	}
+	id := resp.GetUploadId()
+	if _, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: spec.GetName()}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetApiSpec() returned status code %q, want %q for unfinished upload", status.Code(err), codes.NotFound)
+	}
+
+	// Query the committed size.
+	resp, err = upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id})
+	if err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	if resp.GetCommittedSize() != 400 || resp.GetUploadId() != id {
+		t.Errorf("UploadApiSpecContents() returned %+v, want committed size %d of upload %q", resp, 400, id)
+	}
+
+	// Another upload of the same spec is independent and can be restarted
+	// without discarding the chunks of the first upload.
+	other, err := upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, Data: contents[:100]})
+	if err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	if other.GetUploadId() == id || other.GetCommittedSize() != 100 {
+		t.Errorf("UploadApiSpecContents() returned %+v, want a new upload with committed size 100", other)
+	}
+	if _, err := upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: other.GetUploadId(), Data: []byte("restarted")}); err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	resp, err = upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id})
+	if err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	if resp.GetCommittedSize() != 400 {
+		t.Errorf("UploadApiSpecContents() returned committed size %d after another upload restarted, want %d", resp.GetCommittedSize(), 400)
+	}
+
+	// Uploads can't be resumed with invalid IDs or for other resources.
+	_, err = upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: "invalid"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UploadApiSpecContents() with invalid upload ID returned status code %q, want %q: %v", status.Code(err), codes.InvalidArgument, err)
+	}
+	_, err = upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: &rpc.ApiSpec{Name: spec.GetName() + "2"}, UploadId: id})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UploadApiSpecContents() of another spec returned status code %q, want %q: %v", status.Code(err), codes.InvalidArgument, err)
+	}
+
+	// Resuming at the wrong offset fails.
+	_, err = upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id, WriteOffset: 500, Data: contents[500:]})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("UploadApiSpecContents() returned status code %q, want %q: %v", status.Code(err), codes.OutOfRange, err)
+	}
+
+	// Resume and finish the upload.
+	resp, err = upload(
+		&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id, WriteOffset: 400, Data: contents[400:]},
+		&rpc.UploadApiSpecContentsRequest{WriteOffset: int64(len(contents)), FinishWrite: true},
+	)
+	if err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	if resp.GetCommittedSize() != int64(len(contents)) {
+		t.Errorf("UploadApiSpecContents() returned committed size %d, want %d", resp.GetCommittedSize(), len(contents))
+	}
+	if resp.GetApiSpec().GetName() != spec.GetName() || resp.GetApiSpec().GetSizeBytes() != int32(len(contents)) {
+		t.Errorf("UploadApiSpecContents() returned spec %+v, want %q with size %d", resp.GetApiSpec(), spec.GetName(), len(contents))
+	}
+
+	got, err := server.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{Name: spec.GetName()})
+	if err != nil {
+		t.Fatalf("GetApiSpecContents() returned error: %s", err)
+	}
+	if !bytes.Equal(got.GetData(), contents) {
+		t.Errorf("GetApiSpecContents() returned %d bytes, want %d uploaded bytes", len(got.GetData()), len(contents))
+	}
+
+	// Finished uploads are discarded.
+	resp, err = upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id})
+	if err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	if resp.GetCommittedSize() != 0 {
+		t.Errorf("UploadApiSpecContents() returned committed size %d after finished upload, want 0", resp.GetCommittedSize())
+	}
+
+	// Restarting at offset zero discards earlier chunks and updates the spec.
+	if _, err := upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id, Data: []byte("discarded")}); err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	resp, err = upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: id, Data: []byte("updated"), FinishWrite: true})
+	if err != nil {
+		t.Fatalf("UploadApiSpecContents() returned error: %s", err)
+	}
+	if resp.GetApiSpec().GetRevisionId() == "" || resp.GetApiSpec().GetSizeBytes() != int32(len("updated")) {
+		t.Errorf("UploadApiSpecContents() returned spec %+v, want updated contents", resp.GetApiSpec())
+	}
+}
+
+func TestUploadArtifactContents(t *testing.T) {
+	ctx := context.Background()
+	server, client := streamingTestClient(t)
+	if err := seeder.SeedProjects(ctx, server, &rpc.Project{Name: "projects/my-project"}); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	artifact := &rpc.Artifact{
+		Name:     "projects/my-project/locations/global/artifacts/a",
+		MimeType: "text/plain",
+	}
+
+	for _, contents := range [][]byte{largeContents(100), largeContents(200)} {
+		stream, err := client.UploadArtifactContents(ctx)
+		if err != nil {
+			t.Fatalf("UploadArtifactContents() returned error: %s", err)
+		}
+		for _, req := range []*rpc.UploadArtifactContentsRequest{
+			{Artifact: artifact, Data: contents[:50]},
+			{WriteOffset: 50, Data: contents[50:], FinishWrite: true},
+		} {
+			if err := stream.Send(req); err != nil {
+				t.Fatalf("Send() returned error: %s", err)
+			}
+		}
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			t.Fatalf("UploadArtifactContents() returned error: %s", err)
+		}
+		if resp.GetArtifact().GetSizeBytes() != int32(len(contents)) {
+			t.Errorf("UploadArtifactContents() returned artifact %+v, want size %d", resp.GetArtifact(), len(contents))
+		}
+
+		got, err := server.GetArtifactContents(ctx, &rpc.GetArtifactContentsRequest{Name: artifact.GetName()})
+		if err != nil {
+			t.Fatalf("GetArtifactContents() returned error: %s", err)
+		}
+		if !bytes.Equal(got.GetData(), contents) {
+			t.Errorf("GetArtifactContents() returned %d bytes, want %d uploaded bytes", len(got.GetData()), len(contents))
+		}
+	}
+}
+
+func TestExpiredUploads(t *testing.T) {
+	ctx := context.Background()
+	server, client := streamingTestClient(t)
+	if err := seeder.SeedProjects(ctx, server, &rpc.Project{Name: "projects/my-project"}); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	name := "projects/my-project/locations/global/artifacts/a"
+	stream, err := client.UploadArtifactContents(ctx)
+	if err != nil {
+		t.Fatalf("UploadArtifactContents() returned error: %s", err)
+	}
+	if err := stream.Send(&rpc.UploadArtifactContentsRequest{Artifact: &rpc.Artifact{Name: name}, Data: largeContents(10)}); err != nil {
+		t.Fatalf("Send() returned error: %s", err)
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("UploadArtifactContents() returned error: %s", err)
+	}
+
+	db, err := server.getStorageClient(ctx)
+	if err != nil {
+		t.Fatalf("Setup: failed to get storage client: %s", err)
+	}
+	for _, test := range []struct {
+		expiration time.Duration
+		want       int64
+	}{
+		{expiration: time.Hour, want: 10},
+		{expiration: -time.Minute, want: 0},
+	} {
+		server.uploadExpiration = test.expiration
+		server.deleteExpiredUploads(ctx)
+		got, err := db.GetUploadSize(ctx, resp.GetUploadId())
+		if err != nil {
+			t.Fatalf("GetUploadSize() returned error: %s", err)
+		}
+		if got != test.want {
+			t.Errorf("GetUploadSize() returned %d after deleting uploads that expire after %s, want %d", got, test.expiration, test.want)
+		}
+	}
+}
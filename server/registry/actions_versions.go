@@ -42,6 +42,9 @@ func (s *RegistryServer) CreateApiVersion(ctx context.Context, req *rpc.CreateAp
 	if err := name.Validate(); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "CreateApiVersion", name.String(), req.GetApiVersion()); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiVersion
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		var err error
@@ -79,22 +82,36 @@ func (s *RegistryServer) DeleteApiVersion(ctx context.Context, req *rpc.DeleteAp
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err := s.admit(ctx, "DeleteApiVersion", name.String(), nil); err != nil {
+		return nil, err
+	}
+	var updated []string
 	if err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		var err error
+		updated, err = s.handleDeletedReferences(ctx, db, name)
+		if err != nil {
+			return err
+		}
 		return db.DeleteVersion(ctx, name, req.GetForce())
 	}); err != nil {
 		return nil, err
 	}
 	s.notify(ctx, rpc.Notification_DELETED, req.GetName())
+	s.notifyUpdated(ctx, updated)
 	return &emptypb.Empty{}, nil
 }
 
 // GetApiVersion handles the corresponding API request.
 func (s *RegistryServer) GetApiVersion(ctx context.Context, req *rpc.GetApiVersionRequest) (*rpc.ApiVersion, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.ApiVersion{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	name, err := names.ParseVersion(req.GetName())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -110,12 +127,13 @@ func (s *RegistryServer) GetApiVersion(ctx context.Context, req *rpc.GetApiVersi
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	applyReadMask(message, req.GetReadMask())
 	return message, nil
 }
 
 // ListApiVersions handles the corresponding API request.
 func (s *RegistryServer) ListApiVersions(ctx context.Context, req *rpc.ListApiVersionsRequest) (*rpc.ListApiVersionsResponse, error) {
-	db, err := s.getStorageClient(ctx)
+	db, err := s.getReadClient(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
 	}
@@ -133,11 +151,16 @@ func (s *RegistryServer) ListApiVersions(ctx context.Context, req *rpc.ListApiVe
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if err := validateReadMask(&rpc.ApiVersion{}, req.GetReadMask()); err != nil {
+		return nil, err
+	}
+
 	listing, err := db.ListVersions(ctx, parent, storage.PageOptions{
 		Size:   req.GetPageSize(),
 		Filter: req.GetFilter(),
 		Order:  req.GetOrderBy(),
 		Token:  req.GetPageToken(),
+		Fields: readMaskFields(req.GetReadMask()),
 	})
 	if err != nil {
 		return nil, err
@@ -153,6 +176,7 @@ func (s *RegistryServer) ListApiVersions(ctx context.Context, req *rpc.ListApiVe
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
+		applyReadMask(response.ApiVersions[i], req.GetReadMask())
 	}
 
 	return response, nil
@@ -173,6 +197,9 @@ func (s *RegistryServer) UpdateApiVersion(ctx context.Context, req *rpc.UpdateAp
 	if err := models.ValidateMask(req.GetApiVersion(), req.GetUpdateMask()); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid update_mask %v: %s", req.GetUpdateMask(), err)
 	}
+	if err := s.admit(ctx, "UpdateApiVersion", name.String(), req.GetApiVersion()); err != nil {
+		return nil, err
+	}
 	var response *rpc.ApiVersion
 	if err = s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
 		db.LockVersions(ctx)
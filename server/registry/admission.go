@@ -0,0 +1,231 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/apigee/registry/log"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultWebhookTimeout is used for admission webhooks that don't specify a timeout.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookConfig configures an admission webhook.
+type WebhookConfig struct {
+	// Name identifies the webhook in logs and error messages.
+	Name string
+	// URL is the endpoint that receives admission reviews.
+	URL string
+	// Methods are the names of the RPC methods that are sent to the webhook,
+	// e.g. "CreateApiSpec". If empty, all mutating methods are sent.
+	Methods []string
+	// Resources are patterns for the names of resources that are sent to the
+	// webhook, e.g. "projects/*/locations/global/apis/*/deployments/*".
+	// Each "*" matches a single name segment. If empty, all resources are sent.
+	Resources []string
+	// Timeout limits the time spent waiting for a response.
+	Timeout time.Duration
+	// FailOpen allows mutations when the webhook can't be reached or returns
+	// an invalid response. By default, these mutations are rejected.
+	// Mutations that replace the resource with an invalid one are always rejected.
+	FailOpen bool
+	// Mutating allows the webhook to modify the proposed resource.
+	// Changes to the name and output-only fields of the resource are rejected.
+	Mutating bool
+}
+
+// AdmissionReview is posted to admission webhooks for each matching mutation.
+type AdmissionReview struct {
+	// Method is the name of the RPC method, e.g. "UpdateApiDeployment".
+	Method string `json:"method"`
+	// Name is the name of the resource being mutated.
+	Name string `json:"name"`
+	// Resource is the proposed resource in protojson format.
+	// It is omitted for deletions.
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+// AdmissionResponse is returned by admission webhooks.
+type AdmissionResponse struct {
+	// Allowed is true if the mutation should proceed.
+	Allowed bool `json:"allowed"`
+	// Message explains why a mutation was rejected.
+	Message string `json:"message,omitempty"`
+	// Resource optionally replaces the proposed resource.
+	// It is only used by mutating webhooks.
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+func (w *WebhookConfig) matches(method, name string) bool {
+	return matchesAny(w.Methods, method) && matchesAny(w.Resources, name)
+}
+
+func matchesAny(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, err := path.Match(p, s); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// admit sends a proposed mutation to all matching admission webhooks.
+// Webhooks are called in the order they are configured, and any changes
+// made by mutating webhooks are applied to resource before calling the next.
+// Deletions are indicated by a nil resource. Mutations are admitted before
+// the transactions that make them, so no database locks are held while
+// webhooks are called.
+func (s *RegistryServer) admit(ctx context.Context, method, name string, resource proto.Message) error {
+	for i := range s.webhooks {
+		hook := &s.webhooks[i]
+		if !hook.matches(method, name) {
+			continue
+		}
+		if err := s.callWebhook(ctx, hook, method, name, resource); err != nil {
+			if status.Code(err) == codes.Unavailable && hook.FailOpen {
+				log.FromContext(ctx).WithError(err).Warnf("Admission webhook %q failed, allowing %s of %s", hook.Name, method, name)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RegistryServer) callWebhook(ctx context.Context, hook *WebhookConfig, method, name string, resource proto.Message) error {
+	review := AdmissionReview{Method: method, Name: name}
+	if resource != nil {
+		b, err := protojson.Marshal(resource)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		review.Resource = b
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	response, err := postReview(ctx, client, hook.URL, body)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "admission webhook %q failed: %s", hook.Name, err)
+	}
+	if !response.Allowed {
+		return status.Errorf(codes.PermissionDenied, "admission webhook %q denied the request: %s", hook.Name, response.Message)
+	}
+	if hook.Mutating && resource != nil && len(response.Resource) > 0 {
+		// Decode into a new message so that resource is unchanged if the payload is invalid.
+		mutated := resource.ProtoReflect().New().Interface()
+		if err := protojson.Unmarshal(response.Resource, mutated); err != nil {
+			return status.Errorf(codes.FailedPrecondition, "admission webhook %q returned an invalid resource: %s", hook.Name, err)
+		}
+		if err := checkMutation(resource, mutated); err != nil {
+			return status.Errorf(codes.FailedPrecondition, "admission webhook %q returned an invalid resource: %s", hook.Name, err)
+		}
+		proto.Reset(resource)
+		proto.Merge(resource, mutated)
+	}
+	return nil
+}
+
+// checkMutation returns an error if a mutating webhook changed the name or an
+// output-only field of a resource, which are set by the server.
+func checkMutation(original, mutated proto.Message) error {
+	o, m := original.ProtoReflect(), mutated.ProtoReflect()
+	fields := o.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.Get(i)
+		if f.Name() != "name" && !isOutputOnly(f) {
+			continue
+		}
+		if !fieldEqual(o, m, f) {
+			return fmt.Errorf("%s can't be changed", f.Name())
+		}
+	}
+	return nil
+}
+
+func isOutputOnly(f protoreflect.FieldDescriptor) bool {
+	behaviors, _ := proto.GetExtension(f.Options(), annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	for _, b := range behaviors {
+		if b == annotations.FieldBehavior_OUTPUT_ONLY {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldEqual compares a field of two messages of the same type.
+func fieldEqual(a, b protoreflect.Message, f protoreflect.FieldDescriptor) bool {
+	x, y := a.New(), b.New()
+	if a.Has(f) {
+		x.Set(f, a.Get(f))
+	}
+	if b.Has(f) {
+		y.Set(f, b.Get(f))
+	}
+	return proto.Equal(x.Interface(), y.Interface())
+}
+
+func postReview(ctx context.Context, client *http.Client, url string, body []byte) (*AdmissionResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+
+	response := new(AdmissionResponse)
+	if err := json.Unmarshal(b, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,369 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// serverWithWebhooks will call server.Close() when test completes
+func serverWithWebhooks(t *testing.T, hooks ...WebhookConfig) *RegistryServer {
+	t.Helper()
+	server, err := New(Config{
+		Database: "sqlite3",
+		DBConfig: fmt.Sprintf("%s/registry.db", t.TempDir()),
+		Webhooks: hooks,
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	return server
+}
+
+// webhookStub records admission reviews and responds with the result of a handler.
+type webhookStub struct {
+	sync.Mutex
+	reviews []AdmissionReview
+	handler func(AdmissionReview) AdmissionResponse
+}
+
+func (w *webhookStub) start(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var review AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Lock()
+		w.reviews = append(w.reviews, review)
+		w.Unlock()
+		if err := json.NewEncoder(rw).Encode(w.handler(review)); err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestAdmissionWebhooks(t *testing.T) {
+	ctx := context.Background()
+	stub := &webhookStub{
+		handler: func(review AdmissionReview) AdmissionResponse {
+			deployment := new(rpc.ApiDeployment)
+			if err := protojson.Unmarshal(review.Resource, deployment); err != nil {
+				return AdmissionResponse{Message: err.Error()}
+			}
+			if !strings.HasSuffix(deployment.GetEndpointUri(), ".example.com") {
+				return AdmissionResponse{Message: "endpoint must be in example.com"}
+			}
+			return AdmissionResponse{Allowed: true}
+		},
+	}
+	server := serverWithWebhooks(t, WebhookConfig{
+		Name:      "endpoints",
+		URL:       stub.start(t),
+		Methods:   []string{"CreateApiDeployment", "UpdateApiDeployment"},
+		Resources: []string{"projects/*/locations/global/apis/*/deployments/*"},
+	})
+	if err := seeder.SeedApis(ctx, server, &rpc.Api{Name: "projects/my-project/locations/global/apis/a"}); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	if len(stub.reviews) != 0 {
+		t.Fatalf("Setup: webhook received %d unexpected reviews", len(stub.reviews))
+	}
+
+	tests := []struct {
+		desc     string
+		endpoint string
+		want     codes.Code
+	}{
+		{
+			desc:     "allowed",
+			endpoint: "https://api.example.com",
+			want:     codes.OK,
+		},
+		{
+			desc:     "denied",
+			endpoint: "https://api.example.org",
+			want:     codes.PermissionDenied,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req := &rpc.UpdateApiDeploymentRequest{
+				ApiDeployment: &rpc.ApiDeployment{
+					Name:        "projects/my-project/locations/global/apis/a/deployments/d",
+					EndpointUri: test.endpoint,
+				},
+				AllowMissing: true,
+			}
+			if _, err := server.UpdateApiDeployment(ctx, req); status.Code(err) != test.want {
+				t.Errorf("UpdateApiDeployment(%+v) returned status code %q, want %q: %v", req, status.Code(err), test.want, err)
+			}
+		})
+	}
+
+	if got, want := len(stub.reviews), len(tests); got != want {
+		t.Fatalf("Webhook received %d reviews, want %d", got, want)
+	}
+	if got, want := stub.reviews[0].Method, "UpdateApiDeployment"; got != want {
+		t.Errorf("Webhook received method %q, want %q", got, want)
+	}
+	if got, want := stub.reviews[0].Name, "projects/my-project/locations/global/apis/a/deployments/d"; got != want {
+		t.Errorf("Webhook received name %q, want %q", got, want)
+	}
+
+	got, err := server.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: "projects/my-project/locations/global/apis/a/deployments/d"})
+	if err != nil {
+		t.Fatalf("GetApiDeployment() returned error: %s", err)
+	}
+	if got.GetEndpointUri() != "https://api.example.com" {
+		t.Errorf("GetApiDeployment() returned endpoint_uri %q, want denied update to be discarded", got.GetEndpointUri())
+	}
+}
+
+func TestAdmissionWebhookFailures(t *testing.T) {
+	ctx := context.Background()
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, "internal error", http.StatusInternalServerError)
+	}))
+	t.Cleanup(failing.Close)
+
+	tests := []struct {
+		desc string
+		hook WebhookConfig
+		want codes.Code
+	}{
+		{
+			desc: "unreachable fail closed",
+			hook: WebhookConfig{URL: unreachable.URL},
+			want: codes.Unavailable,
+		},
+		{
+			desc: "unreachable fail open",
+			hook: WebhookConfig{URL: unreachable.URL, FailOpen: true},
+			want: codes.OK,
+		},
+		{
+			desc: "timeout fail closed",
+			hook: WebhookConfig{URL: slow.URL, Timeout: 50 * time.Millisecond},
+			want: codes.Unavailable,
+		},
+		{
+			desc: "timeout fail open",
+			hook: WebhookConfig{URL: slow.URL, Timeout: 50 * time.Millisecond, FailOpen: true},
+			want: codes.OK,
+		},
+		{
+			desc: "error status fail closed",
+			hook: WebhookConfig{URL: failing.URL},
+			want: codes.Unavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			server := serverWithWebhooks(t, test.hook)
+			req := &rpc.CreateProjectRequest{
+				ProjectId: "my-project",
+				Project:   &rpc.Project{},
+			}
+			if _, err := server.CreateProject(ctx, req); status.Code(err) != test.want {
+				t.Errorf("CreateProject(%+v) returned status code %q, want %q: %v", req, status.Code(err), test.want, err)
+			}
+		})
+	}
+}
+
+func TestMutatingAdmissionWebhooks(t *testing.T) {
+	ctx := context.Background()
+	stub := &webhookStub{
+		handler: func(review AdmissionReview) AdmissionResponse {
+			api := new(rpc.Api)
+			if err := protojson.Unmarshal(review.Resource, api); err != nil {
+				return AdmissionResponse{Message: err.Error()}
+			}
+			api.Labels = map[string]string{"reviewed": "true"}
+			b, _ := protojson.Marshal(api)
+			return AdmissionResponse{Allowed: true, Resource: b}
+		},
+	}
+	url := stub.start(t)
+
+	tests := []struct {
+		desc     string
+		mutating bool
+		want     map[string]string
+	}{
+		{
+			desc:     "mutating",
+			mutating: true,
+			want:     map[string]string{"reviewed": "true"},
+		},
+		{
+			desc:     "validating",
+			mutating: false,
+			want:     nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			server := serverWithWebhooks(t, WebhookConfig{
+				URL:      url,
+				Methods:  []string{"CreateApi"},
+				Mutating: test.mutating,
+			})
+			if err := seeder.SeedProjects(ctx, server, &rpc.Project{Name: "projects/my-project"}); err != nil {
+				t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+			}
+			req := &rpc.CreateApiRequest{
+				Parent: "projects/my-project/locations/global",
+				ApiId:  "a",
+				Api:    &rpc.Api{DisplayName: "My API"},
+			}
+			got, err := server.CreateApi(ctx, req)
+			if err != nil {
+				t.Fatalf("CreateApi(%+v) returned error: %s", req, err)
+			}
+			if got.GetDisplayName() != "My API" {
+				t.Errorf("CreateApi(%+v) returned display_name %q, want %q", req, got.GetDisplayName(), "My API")
+			}
+			if fmt.Sprint(got.GetLabels()) != fmt.Sprint(test.want) {
+				t.Errorf("CreateApi(%+v) returned labels %v, want %v", req, got.GetLabels(), test.want)
+			}
+		})
+	}
+}
+
+func TestInvalidMutatingAdmissionWebhook(t *testing.T) {
+	ctx := context.Background()
+	stub := &webhookStub{
+		handler: func(review AdmissionReview) AdmissionResponse {
+			return AdmissionResponse{Allowed: true, Resource: []byte(`{"displayName": "Mutated", "labels": 1}`)}
+		},
+	}
+	server := serverWithWebhooks(t, WebhookConfig{
+		URL:      stub.start(t),
+		Methods:  []string{"CreateApi"},
+		Mutating: true,
+		FailOpen: true,
+	})
+	if err := seeder.SeedProjects(ctx, server, &rpc.Project{Name: "projects/my-project"}); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	api := &rpc.Api{DisplayName: "My API"}
+	req := &rpc.CreateApiRequest{
+		Parent: "projects/my-project/locations/global",
+		ApiId:  "a",
+		Api:    api,
+	}
+	if _, err := server.CreateApi(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CreateApi(%+v) returned status code %q, want %q: %v", req, status.Code(err), codes.FailedPrecondition, err)
+	}
+	if api.GetDisplayName() != "My API" {
+		t.Errorf("CreateApi(%+v) changed display_name to %q, want the invalid mutation to be discarded", req, api.GetDisplayName())
+	}
+	if _, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: "projects/my-project/locations/global/apis/a"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetApi() returned %v, want NotFound", err)
+	}
+}
+
+func TestMutatingAdmissionWebhookProtectedFields(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc   string
+		mutate func(*rpc.Api)
+	}{
+		{
+			desc:   "name",
+			mutate: func(api *rpc.Api) { api.Name = "projects/my-project/locations/global/apis/b" },
+		},
+		{
+			desc:   "output only",
+			mutate: func(api *rpc.Api) { api.CreateTime = timestamppb.New(time.Unix(0, 0)) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			stub := &webhookStub{
+				handler: func(review AdmissionReview) AdmissionResponse {
+					api := new(rpc.Api)
+					if err := protojson.Unmarshal(review.Resource, api); err != nil {
+						return AdmissionResponse{Message: err.Error()}
+					}
+					test.mutate(api)
+					b, _ := protojson.Marshal(api)
+					return AdmissionResponse{Allowed: true, Resource: b}
+				},
+			}
+			server := serverWithWebhooks(t, WebhookConfig{
+				URL:      stub.start(t),
+				Methods:  []string{"CreateApi"},
+				Mutating: true,
+				FailOpen: true,
+			})
+			if err := seeder.SeedProjects(ctx, server, &rpc.Project{Name: "projects/my-project"}); err != nil {
+				t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+			}
+			req := &rpc.CreateApiRequest{
+				Parent: "projects/my-project/locations/global",
+				ApiId:  "a",
+				Api:    &rpc.Api{DisplayName: "My API"},
+			}
+			if _, err := server.CreateApi(ctx, req); status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("CreateApi(%+v) returned status code %q, want %q: %v", req, status.Code(err), codes.FailedPrecondition, err)
+			}
+			for _, id := range []string{"a", "b"} {
+				name := "projects/my-project/locations/global/apis/" + id
+				if _, err := server.GetApi(ctx, &rpc.GetApiRequest{Name: name}); status.Code(err) != codes.NotFound {
+					t.Errorf("GetApi(%q) returned %v, want NotFound", name, err)
+				}
+			}
+		})
+	}
+}
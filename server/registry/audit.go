@@ -0,0 +1,361 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/log/interceptor"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// auditedPrefixes are the prefixes of the names of methods that change resources.
+var auditedPrefixes = []string{"Create", "Update", "Delete", "Replace", "Tag", "Rollback", "Import", "Upload"}
+
+func isAudited(method string) bool {
+	for _, prefix := range auditedPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditUnary records an audit event for each successful call of a unary method that changes a resource.
+func (s *RegistryServer) auditUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	method := path.Base(info.FullMethod)
+	request, ok := req.(proto.Message)
+	if !ok || !isAudited(method) {
+		return handler(ctx, req)
+	}
+	call := func(ctx context.Context) (proto.Message, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		response, _ := resp.(proto.Message)
+		return response, nil
+	}
+	if returnsOperation(info.FullMethod) {
+		return s.auditOperation(ctx, method, request, call)
+	}
+	return s.audited(ctx, method, request, call)
+}
+
+// audited calls fn in a transaction and records an audit event for the change
+// that it makes in the same transaction, so the change is only committed if
+// the event is saved. Storage calls made by fn use the transaction.
+func (s *RegistryServer) audited(ctx context.Context, method string, request proto.Message, fn func(context.Context) (proto.Message, error)) (proto.Message, error) {
+	var response proto.Message
+	err := s.runInTransaction(ctx, func(ctx context.Context, db *storage.Client) error {
+		ctx = withTransaction(ctx, db)
+		before := s.auditedResource(ctx, requestedName(request))
+		var err error
+		if response, err = fn(ctx); err != nil {
+			return err
+		}
+		return s.audit(ctx, db, method, request, before, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// auditOperation calls fn to start a long-running operation and records an
+// audit event for it. Operations run after their requests are finished, so they
+// can't share a transaction with the event; if the event can't be saved, the
+// operation is cancelled and the request fails.
+func (s *RegistryServer) auditOperation(ctx context.Context, method string, request proto.Message, fn func(context.Context) (proto.Message, error)) (proto.Message, error) {
+	before := s.auditedResource(ctx, requestedName(request))
+	response, err := fn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, err := s.getStorageClient(ctx)
+	if err != nil {
+		err = status.Error(codes.Unavailable, err.Error())
+	} else {
+		err = s.audit(ctx, db, method, request, before, response)
+	}
+	if err != nil {
+		if op, ok := response.(*longrunning.Operation); ok {
+			s.operations.cancel(op.GetName())
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+// returnsOperation returns true if a method returns a long-running operation.
+func returnsOperation(fullMethod string) bool {
+	name := strings.Replace(strings.TrimPrefix(fullMethod, "/"), "/", ".", 1)
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return false
+	}
+	m, ok := d.(protoreflect.MethodDescriptor)
+	return ok && m.Output().FullName() == "google.longrunning.Operation"
+}
+
+// audit saves an audit event for a change.
+func (s *RegistryServer) audit(ctx context.Context, db *storage.Client, method string, request, before, response proto.Message) error {
+	after := changedResource(response)
+	if strings.HasPrefix(method, "Delete") {
+		after = nil
+	}
+
+	name := resourceName(after)
+	if name == "" {
+		name = requestedName(request)
+	}
+	if name == "" {
+		name = resourceName(before)
+	}
+
+	event, err := models.NewAuditEvent(projectOf(name), method, name, principal(ctx, s.principalHeaders), requestID(ctx), fieldChanges(before, after))
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to create audit event for %s of %s: %s", method, name, err)
+	}
+	if err := db.CreateAuditEvent(ctx, event); err != nil {
+		log.FromContext(ctx).WithError(err).Errorf("Failed to save audit event for %s of %s.", method, name)
+		return err
+	}
+	return nil
+}
+
+// auditedResource returns the current state of a named resource, or nil if it doesn't exist.
+func (s *RegistryServer) auditedResource(ctx context.Context, name string) proto.Message {
+	var (
+		m   proto.Message
+		err error
+	)
+	// Reads from a replica could miss recent changes.
+	ctx = withPrimaryReads(ctx)
+	if _, perr := names.ParseArtifact(name); perr == nil {
+		m, err = s.GetArtifact(ctx, &rpc.GetArtifactRequest{Name: name})
+	} else if _, perr := names.ParseProject(name); perr == nil {
+		m, err = s.GetProject(ctx, &rpc.GetProjectRequest{Name: name})
+	} else if _, perr := names.ParseApi(name); perr == nil {
+		m, err = s.GetApi(ctx, &rpc.GetApiRequest{Name: name})
+	} else if _, perr := names.ParseVersion(name); perr == nil {
+		m, err = s.GetApiVersion(ctx, &rpc.GetApiVersionRequest{Name: name})
+	} else if strings.Contains(name, "/specs/") {
+		m, err = s.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: name})
+	} else if strings.Contains(name, "/deployments/") {
+		m, err = s.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{Name: name})
+	} else {
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// requestedName returns the name of the resource identified by a request,
+// which is either its name field or the name of the resource in its body.
+func requestedName(request proto.Message) string {
+	m := request.ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName("name"); fd != nil && fd.Kind() == protoreflect.StringKind {
+		return m.Get(fd).String()
+	}
+	return resourceName(changedResource(request))
+}
+
+// changedResource returns the resource in a response or request body,
+// unpacking the responses of completed operations.
+func changedResource(msg proto.Message) proto.Message {
+	if msg == nil {
+		return nil
+	}
+	if op, ok := msg.(*longrunning.Operation); ok {
+		if op.GetResponse() == nil {
+			return nil
+		}
+		response, err := op.GetResponse().UnmarshalNew()
+		if err != nil {
+			return nil
+		}
+		return changedResource(response)
+	}
+	if resourceName(msg) != "" {
+		return msg
+	}
+	m := msg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() || !m.Has(fd) {
+			continue
+		}
+		if sub := m.Get(fd).Message().Interface(); resourceName(sub) != "" {
+			return sub
+		}
+	}
+	return nil
+}
+
+// resourceName returns the name field of a resource.
+func resourceName(resource proto.Message) string {
+	if resource == nil {
+		return ""
+	}
+	m := resource.ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName("name"); fd != nil && fd.Kind() == protoreflect.StringKind && !fd.IsList() {
+		return m.Get(fd).String()
+	}
+	return ""
+}
+
+// projectOf returns the project ID in a resource name.
+func projectOf(name string) string {
+	rest := strings.TrimPrefix(name, "projects/")
+	if rest == name {
+		return ""
+	}
+	id, _, _ := strings.Cut(rest, "/")
+	return id
+}
+
+// fieldChanges returns the fields that differ between two versions of a resource.
+// Either version may be nil. Contents and timestamps are omitted.
+func fieldChanges(before, after proto.Message) []models.FieldChange {
+	var desc protoreflect.MessageDescriptor
+	switch {
+	case before != nil && after != nil:
+		desc = before.ProtoReflect().Descriptor()
+		if desc.FullName() != after.ProtoReflect().Descriptor().FullName() {
+			return nil
+		}
+	case before != nil:
+		desc = before.ProtoReflect().Descriptor()
+	case after != nil:
+		desc = after.ProtoReflect().Descriptor()
+	default:
+		return nil
+	}
+
+	changes := make([]models.FieldChange, 0)
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Kind() == protoreflect.BytesKind || strings.HasSuffix(string(fd.Name()), "_time") {
+			continue
+		}
+		if oldValue, newValue := fieldValue(before, fd), fieldValue(after, fd); oldValue != newValue {
+			changes = append(changes, models.FieldChange{
+				Field:    string(fd.Name()),
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+	return changes
+}
+
+// fieldValue returns a string representation of a field of a message.
+func fieldValue(msg proto.Message, fd protoreflect.FieldDescriptor) string {
+	if msg == nil || !msg.ProtoReflect().Has(fd) {
+		return ""
+	}
+	v := msg.ProtoReflect().Get(fd)
+	switch {
+	case fd.IsList():
+		list := v.List()
+		values := make([]string, list.Len())
+		for i := range values {
+			values[i] = scalarValue(fd, list.Get(i))
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case fd.IsMap():
+		entries := make([]string, 0, v.Map().Len())
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries = append(entries, k.String()+"="+scalarValue(fd.MapValue(), v))
+			return true
+		})
+		sort.Strings(entries)
+		return "{" + strings.Join(entries, ", ") + "}"
+	default:
+		return scalarValue(fd, v)
+	}
+}
+
+func scalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return fmt.Sprint(v.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		b, _ := protojson.Marshal(v.Message().Interface())
+		return string(b)
+	default:
+		return v.String()
+	}
+}
+
+// principal returns the identity of the caller of a request. It is read from
+// the first of the trusted headers that is set, or from a verified client
+// certificate. Trusted headers are set by an authenticating proxy in front of
+// the server, which must remove them from the requests that it forwards, so
+// they should only be configured when all requests pass through such a proxy.
+// Bearer tokens are not verified by the server, so they are never trusted.
+func principal(ctx context.Context, trustedHeaders []string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range trustedHeaders {
+		if v := md.Get(key); len(v) > 0 && v[0] != "" {
+			// Identity-Aware Proxy prefixes emails with the identity provider.
+			return strings.TrimPrefix(v[0], "accounts.google.com:")
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 && len(info.State.VerifiedChains[0]) > 0 {
+			return info.State.VerifiedChains[0][0].Subject.CommonName
+		}
+	}
+	return ""
+}
+
+// requestID returns the identifier of a request, which is set by the call
+// logger or by the caller. If neither is set, a new identifier is generated.
+func requestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(interceptor.RequestIDKey); len(v) > 0 && v[0] != "" {
+		return v[0]
+	}
+	return fmt.Sprintf("%.8s", uuid.New())
+}
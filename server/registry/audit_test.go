@@ -0,0 +1,355 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// auditTestClients serves a registry server that records audit events
+// and returns clients for it.
+func auditTestClients(t *testing.T) (rpc.RegistryClient, rpc.AdminClient) {
+	t.Helper()
+	server, err := New(Config{
+		Database:         "sqlite3",
+		DBConfig:         fmt.Sprintf("%s/registry.db", t.TempDir()),
+		Audit:            true,
+		PrincipalHeaders: []string{"x-goog-authenticated-user-email"},
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	listener, grpcServer, err := server.ServeGRPC(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Setup: failed to serve: %s", err)
+	}
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Setup: failed to dial server: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return rpc.NewRegistryClient(conn), rpc.NewAdminClient(conn)
+}
+
+func listAuditEvents(ctx context.Context, t *testing.T, admin rpc.AdminClient, req *rpc.ListAuditEventsRequest) []*rpc.AuditEvent {
+	t.Helper()
+	var events []*rpc.AuditEvent
+	for {
+		resp, err := admin.ListAuditEvents(ctx, req)
+		if err != nil {
+			t.Fatalf("ListAuditEvents(%+v) returned error: %s", req, err)
+		}
+		events = append(events, resp.GetAuditEvents()...)
+		if resp.GetNextPageToken() == "" {
+			return events
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+}
+
+func TestAuditEvents(t *testing.T) {
+	client, admin := auditTestClients(t)
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"x-goog-authenticated-user-email", "accounts.google.com:alice@example.com",
+		"x-request-id", "req-1")
+
+	if _, err := admin.CreateProject(ctx, &rpc.CreateProjectRequest{ProjectId: "my-project", Project: &rpc.Project{}}); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: "projects/my-project/locations/global",
+		ApiId:  "a",
+		Api:    &rpc.Api{DisplayName: "Pets", Labels: map[string]string{"team": "red"}},
+	}); err != nil {
+		t.Fatalf("Setup: CreateApi() returned error: %s", err)
+	}
+	// Failed changes and reads are not recorded.
+	if _, err := client.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: "projects/my-project/locations/global",
+		ApiId:  "a",
+		Api:    &rpc.Api{},
+	}); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("Setup: CreateApi() returned status code %q, want %q", status.Code(err), codes.AlreadyExists)
+	}
+	if _, err := client.GetApi(ctx, &rpc.GetApiRequest{Name: "projects/my-project/locations/global/apis/a"}); err != nil {
+		t.Fatalf("Setup: GetApi() returned error: %s", err)
+	}
+	bob := metadata.AppendToOutgoingContext(context.Background(), "x-goog-authenticated-user-email", "bob@example.com")
+	if _, err := client.UpdateApi(bob, &rpc.UpdateApiRequest{
+		Api: &rpc.Api{
+			Name:        "projects/my-project/locations/global/apis/a",
+			DisplayName: "Pet Store",
+			Labels:      map[string]string{"team": "blue"},
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name", "labels"}},
+	}); err != nil {
+		t.Fatalf("Setup: UpdateApi() returned error: %s", err)
+	}
+	if _, err := client.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent:       "projects/my-project/locations/global/apis/a",
+		ApiVersionId: "v1",
+		ApiVersion:   &rpc.ApiVersion{},
+	}); err != nil {
+		t.Fatalf("Setup: CreateApiVersion() returned error: %s", err)
+	}
+	upload := func(reqs ...*rpc.UploadApiSpecContentsRequest) *rpc.UploadApiSpecContentsResponse {
+		t.Helper()
+		stream, err := client.UploadApiSpecContents(ctx)
+		if err != nil {
+			t.Fatalf("Setup: UploadApiSpecContents() returned error: %s", err)
+		}
+		for _, req := range reqs {
+			if err := stream.Send(req); err != nil {
+				t.Fatalf("Setup: Send() returned error: %s", err)
+			}
+		}
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			t.Fatalf("Setup: UploadApiSpecContents() returned error: %s", err)
+		}
+		return resp
+	}
+	spec := &rpc.ApiSpec{
+		Name:     "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+		MimeType: "text/plain",
+	}
+	// Unfinished uploads are not recorded.
+	pending := upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, Data: []byte("hello")})
+	upload(&rpc.UploadApiSpecContentsRequest{ApiSpec: spec, UploadId: pending.GetUploadId(), WriteOffset: 5, FinishWrite: true})
+	if _, err := client.DeleteApi(ctx, &rpc.DeleteApiRequest{Name: "projects/my-project/locations/global/apis/a", Force: true}); err != nil {
+		t.Fatalf("Setup: DeleteApi() returned error: %s", err)
+	}
+	if _, err := admin.DeleteProject(ctx, &rpc.DeleteProjectRequest{Name: "projects/my-project", Force: true}); err != nil {
+		t.Fatalf("Setup: DeleteProject() returned error: %s", err)
+	}
+
+	// Events are kept after their projects are deleted.
+	got := listAuditEvents(ctx, t, admin, &rpc.ListAuditEventsRequest{Parent: "projects/my-project", PageSize: 2})
+	want := []*rpc.AuditEvent{
+		{Method: "CreateProject", Resource: "projects/my-project", Principal: "alice@example.com", RequestId: "req-1",
+			Changes: []*rpc.AuditEvent_FieldChange{{Field: "name", NewValue: "projects/my-project"}}},
+		{Method: "CreateApi", Resource: "projects/my-project/locations/global/apis/a", Principal: "alice@example.com", RequestId: "req-1",
+			Changes: []*rpc.AuditEvent_FieldChange{
+				{Field: "name", NewValue: "projects/my-project/locations/global/apis/a"},
+				{Field: "display_name", NewValue: "Pets"},
+				{Field: "labels", NewValue: "{team=red}"},
+			}},
+		{Method: "UpdateApi", Resource: "projects/my-project/locations/global/apis/a", Principal: "bob@example.com",
+			Changes: []*rpc.AuditEvent_FieldChange{
+				{Field: "display_name", OldValue: "Pets", NewValue: "Pet Store"},
+				{Field: "labels", OldValue: "{team=red}", NewValue: "{team=blue}"},
+			}},
+		{Method: "CreateApiVersion", Resource: "projects/my-project/locations/global/apis/a/versions/v1", Principal: "alice@example.com", RequestId: "req-1",
+			Changes: []*rpc.AuditEvent_FieldChange{{Field: "name", NewValue: "projects/my-project/locations/global/apis/a/versions/v1"}}},
+		{Method: "UploadApiSpecContents", Resource: "projects/my-project/locations/global/apis/a/versions/v1/specs/s", Principal: "alice@example.com", RequestId: "req-1"},
+		{Method: "DeleteApi", Resource: "projects/my-project/locations/global/apis/a", Principal: "alice@example.com", RequestId: "req-1",
+			Changes: []*rpc.AuditEvent_FieldChange{
+				{Field: "name", OldValue: "projects/my-project/locations/global/apis/a"},
+				{Field: "display_name", OldValue: "Pet Store"},
+				{Field: "labels", OldValue: "{team=blue}"},
+			}},
+		{Method: "DeleteProject", Resource: "projects/my-project", Principal: "alice@example.com", RequestId: "req-1",
+			Changes: []*rpc.AuditEvent_FieldChange{{Field: "name", OldValue: "projects/my-project"}}},
+	}
+	opts := cmp.Options{
+		protocmp.Transform(),
+		// Request IDs are generated when they aren't provided, so they are checked below.
+		protocmp.IgnoreFields(&rpc.AuditEvent{}, "id", "create_time", "request_id"),
+		// Spec uploads change many fields, which are checked below.
+		protocmp.FilterField(&rpc.AuditEvent{}, "changes", cmp.Ignore()),
+	}
+	if !cmp.Equal(want, got, opts) {
+		t.Errorf("ListAuditEvents() returned unexpected diff (-want +got):\n%s", cmp.Diff(want, got, opts))
+	}
+	for i, event := range got {
+		if event.GetRequestId() == "" {
+			t.Errorf("ListAuditEvents() returned event %d without a request ID", i)
+		} else if i < len(want) && want[i].GetRequestId() != "" && event.GetRequestId() != want[i].GetRequestId() {
+			t.Errorf("ListAuditEvents() returned request ID %q for event %d, want %q", event.GetRequestId(), i, want[i].GetRequestId())
+		}
+		if i < len(want) && event.GetMethod() != "UploadApiSpecContents" && !cmp.Equal(want[i].GetChanges(), event.GetChanges(), protocmp.Transform()) {
+			t.Errorf("ListAuditEvents() returned unexpected changes for %s (-want +got):\n%s",
+				event.GetMethod(), cmp.Diff(want[i].GetChanges(), event.GetChanges(), protocmp.Transform()))
+		}
+	}
+	if len(got) == len(want) {
+		changed := make(map[string]bool)
+		for _, c := range got[4].GetChanges() {
+			changed[c.GetField()] = true
+		}
+		for _, field := range []string{"name", "mime_type", "size_bytes", "hash", "revision_id"} {
+			if !changed[field] {
+				t.Errorf("ListAuditEvents() returned upload changes %v, want change of %q", got[4].GetChanges(), field)
+			}
+		}
+	}
+
+	filtered := listAuditEvents(ctx, t, admin, &rpc.ListAuditEventsRequest{
+		Parent: "projects/-",
+		Filter: `principal == "bob@example.com"`,
+	})
+	if len(filtered) != 1 || filtered[0].GetMethod() != "UpdateApi" {
+		t.Errorf("ListAuditEvents() with filter returned %v, want the UpdateApi event", filtered)
+	}
+
+	latest := listAuditEvents(ctx, t, admin, &rpc.ListAuditEventsRequest{
+		Parent:   "projects/my-project",
+		OrderBy:  "create_time desc",
+		PageSize: 3,
+	})
+	if len(latest) != len(want) || latest[0].GetMethod() != "DeleteProject" {
+		t.Errorf("ListAuditEvents() in descending order returned %d events starting with %v, want %d starting with DeleteProject", len(latest), latest[0], len(want))
+	}
+
+	if _, err := admin.ListAuditEvents(ctx, &rpc.ListAuditEventsRequest{Parent: "projects/my-project/locations/global"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListAuditEvents() with invalid parent returned status code %q, want %q", status.Code(err), codes.InvalidArgument)
+	}
+	if _, err := admin.ListAuditEvents(ctx, &rpc.ListAuditEventsRequest{Parent: "projects/my-project", Filter: "invalid"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListAuditEvents() with invalid filter returned status code %q, want %q", status.Code(err), codes.InvalidArgument)
+	}
+}
+
+func TestAuditFailure(t *testing.T) {
+	dsn := fmt.Sprintf("%s/registry.db", t.TempDir())
+	server, err := New(Config{Database: "sqlite3", DBConfig: dsn, Audit: true})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	ctx := context.Background()
+	info := &grpc.UnaryServerInfo{FullMethod: "/google.cloud.apigeeregistry.v1.Admin/CreateProject"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.CreateProject(ctx, req.(*rpc.CreateProjectRequest))
+	}
+	if _, err := server.auditUnary(ctx, &rpc.CreateProjectRequest{ProjectId: "audited", Project: &rpc.Project{}}, info, handler); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+
+	// Make writes of audit events fail.
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Setup: failed to open database: %s", err)
+	}
+	if err := db.Exec(`CREATE TRIGGER fail_audit BEFORE INSERT ON audit_events BEGIN SELECT RAISE(ABORT, 'audit log unavailable'); END`).Error; err != nil {
+		t.Fatalf("Setup: failed to create trigger: %s", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	req := &rpc.CreateProjectRequest{ProjectId: "unaudited", Project: &rpc.Project{}}
+	if _, err := server.auditUnary(ctx, req, info, handler); err == nil {
+		t.Errorf("CreateProject(%+v) succeeded, want error when the audit event can't be saved", req)
+	}
+	if _, err := server.GetProject(ctx, &rpc.GetProjectRequest{Name: "projects/unaudited"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProject() returned %v, want NotFound for a change that wasn't audited", err)
+	}
+}
+
+func TestAuditDisabled(t *testing.T) {
+	ctx := context.Background()
+	server := defaultTestServer(t)
+	if _, err := server.CreateProject(ctx, &rpc.CreateProjectRequest{ProjectId: "my-project", Project: &rpc.Project{}}); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+	resp, err := server.ListAuditEvents(ctx, &rpc.ListAuditEventsRequest{Parent: "projects/my-project"})
+	if err != nil {
+		t.Fatalf("ListAuditEvents() returned error: %s", err)
+	}
+	if len(resp.GetAuditEvents()) != 0 {
+		t.Errorf("ListAuditEvents() returned %v, want no events when auditing is disabled", resp.GetAuditEvents())
+	}
+}
+
+func TestAuditPrincipal(t *testing.T) {
+	trusted := []string{"x-goog-authenticated-user-email", "x-forwarded-email"}
+	tests := []struct {
+		desc    string
+		md      metadata.MD
+		trusted []string
+		want    string
+	}{
+		{
+			desc:    "no identity",
+			md:      metadata.MD{},
+			trusted: trusted,
+			want:    "",
+		},
+		{
+			desc:    "proxy header",
+			md:      metadata.Pairs("x-goog-authenticated-user-email", "accounts.google.com:alice@example.com"),
+			trusted: trusted,
+			want:    "alice@example.com",
+		},
+		{
+			desc: "untrusted proxy header",
+			md:   metadata.Pairs("x-goog-authenticated-user-email", "accounts.google.com:alice@example.com"),
+			want: "",
+		},
+		{
+			desc:    "header that isn't trusted",
+			md:      metadata.Pairs("x-forwarded-user", "alice"),
+			trusted: trusted,
+			want:    "",
+		},
+		{
+			desc:    "trusted headers in order",
+			md:      metadata.Pairs("x-forwarded-email", "carol@example.com", "x-goog-authenticated-user-email", "alice@example.com"),
+			trusted: trusted,
+			want:    "alice@example.com",
+		},
+		{
+			desc:    "unverified token",
+			md:      metadata.Pairs("authorization", "Bearer "+testJWT(`{"email":"bob@example.com","sub":"123"}`)),
+			trusted: trusted,
+			want:    "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), test.md)
+			if got := principal(ctx, test.trusted); got != test.want {
+				t.Errorf("principal() returned %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func testJWT(claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(claims)) + "." + encode([]byte("signature"))
+}
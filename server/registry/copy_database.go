@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/apigee/registry/server/registry/internal/storage"
+)
+
+// DatabaseConfig identifies a database.
+type DatabaseConfig struct {
+	Driver string // One of [ sqlite3, postgres, cloudsqlpostgres ].
+	DSN    string // The data source name, which depends on the driver.
+}
+
+// CopyDatabaseOptions configures CopyDatabase.
+type CopyDatabaseOptions struct {
+	// BatchSize is the number of rows read and written at a time.
+	// If unset, a default of 1000 is used.
+	BatchSize int
+	// Progress, if set, is called after each batch with the number of rows
+	// of a table that have been copied.
+	Progress func(table string, rows int64)
+}
+
+// CopyDatabase copies all stored data from one database to another,
+// preserving keys, revision IDs and timestamps. The databases may use
+// different drivers. Rows that already exist in the destination are skipped,
+// so an interrupted copy can be resumed by running it again. When the copy is
+// complete, the row counts and hashes of all tables are compared and an error
+// is returned if they differ.
+func CopyDatabase(ctx context.Context, from, to DatabaseConfig, opts CopyDatabaseOptions) error {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	src, err := storage.NewClient(ctx, from.Driver, from.DSN)
+	if err != nil {
+		return fmt.Errorf("failed to open source database: %s", err)
+	}
+	defer src.Close()
+	dst, err := storage.NewClient(ctx, to.Driver, to.DSN)
+	if err != nil {
+		return fmt.Errorf("failed to open destination database: %s", err)
+	}
+	defer dst.Close()
+
+	if err := src.CopyTo(ctx, dst, opts.BatchSize, opts.Progress); err != nil {
+		return err
+	}
+
+	want, err := src.Checksums(ctx, opts.BatchSize)
+	if err != nil {
+		return err
+	}
+	got, err := dst.Checksums(ctx, opts.BatchSize)
+	if err != nil {
+		return err
+	}
+	tables := make([]string, 0, len(want))
+	for table := range want {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		if w, g := want[table], got[table]; w.Count != g.Count {
+			return fmt.Errorf("verification failed: table %s has %d rows in the source and %d rows in the destination", table, w.Count, g.Count)
+		} else if w.Hash != g.Hash {
+			return fmt.Errorf("verification failed: rows of table %s differ between the source and the destination", table)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestCopyDatabase(t *testing.T) {
+	ctx := context.Background()
+	src := DatabaseConfig{Driver: "sqlite3", DSN: t.TempDir() + "/source.db"}
+	dst := DatabaseConfig{Driver: "sqlite3", DSN: t.TempDir() + "/destination.db"}
+
+	server, err := New(Config{Database: src.Driver, DBConfig: src.DSN})
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	spec := seedReferences(ctx, t, server)
+	if _, err := server.TagApiSpecRevision(ctx, &rpc.TagApiSpecRevisionRequest{
+		Name: spec.GetName() + "@" + spec.GetRevisionId(),
+		Tag:  "prod",
+	}); err != nil {
+		t.Fatalf("Setup: TagApiSpecRevision() returned error: %s", err)
+	}
+	if _, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     "projects/my-project/locations/global",
+		ArtifactId: "x",
+		Artifact:   &rpc.Artifact{MimeType: "text/plain", Contents: []byte("artifact contents")},
+	}); err != nil {
+		t.Fatalf("Setup: CreateArtifact() returned error: %s", err)
+	}
+	want := readArchive(ctx, t, server, exportProject(ctx, t, server, "projects/my-project"))
+	server.Close()
+
+	tables := make(map[string]bool)
+	opts := CopyDatabaseOptions{
+		BatchSize: 2,
+		Progress:  func(table string, rows int64) { tables[table] = true },
+	}
+	if err := CopyDatabase(ctx, src, dst, opts); err != nil {
+		t.Fatalf("CopyDatabase() returned error: %s", err)
+	}
+	for _, table := range []string{"projects", "apis", "specs", "spec_revision_tags", "artifacts", "blobs"} {
+		if !tables[table] {
+			t.Errorf("CopyDatabase() did not report progress for table %s", table)
+		}
+	}
+	// Copying again resumes the copy, which is already complete.
+	if err := CopyDatabase(ctx, src, dst, opts); err != nil {
+		t.Fatalf("CopyDatabase() returned error when repeated: %s", err)
+	}
+
+	copied, err := New(Config{Database: dst.Driver, DBConfig: dst.DSN})
+	if err != nil {
+		t.Fatalf("failed to get server for copy: %s", err)
+	}
+	t.Cleanup(copied.Close)
+	got := readArchive(ctx, t, copied, exportProject(ctx, t, copied, "projects/my-project"))
+	diffOpts := cmp.Options{
+		protocmp.Transform(),
+		protocmp.IgnoreFields(&rpc.ProjectArchive{}, "create_time"),
+	}
+	if !cmp.Equal(want, got, diffOpts) {
+		t.Errorf("CopyDatabase() created unexpected diff (-want +got):\n%s", cmp.Diff(want, got, diffOpts))
+	}
+
+	// Rows that are only in the destination cause verification to fail.
+	if _, err := copied.CreateProject(ctx, &rpc.CreateProjectRequest{
+		ProjectId: "extra",
+		Project:   &rpc.Project{},
+	}); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+	if err := CopyDatabase(ctx, src, dst, opts); err == nil || !strings.Contains(err.Error(), "verification failed") {
+		t.Errorf("CopyDatabase() returned %v, want verification failure", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// InstanceHeader is a request header that directs a call of the Registry or
+// Admin service to a provisioned instance, which is named by its value.
+const InstanceHeader = "x-registry-instance"
+
+// instances holds the storage clients of provisioned instances.
+type instances struct {
+	mu      sync.Mutex
+	clients map[string]*storage.Client
+}
+
+func newInstances() *instances {
+	return &instances{clients: make(map[string]*storage.Client)}
+}
+
+// close closes the storage client of an instance, if it is open.
+func (i *instances) close(name string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if c, ok := i.clients[name]; ok {
+		c.Close()
+		delete(i.clients, name)
+	}
+}
+
+// closeAll closes the storage clients of all instances.
+func (i *instances) closeAll() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for name, c := range i.clients {
+		c.Close()
+		delete(i.clients, name)
+	}
+}
+
+// instanceDatabase returns a new name for the SQLite file or PostgreSQL schema
+// that holds the resources of an instance. Names are random rather than built
+// from instance IDs, which could map different instances to the same name.
+func (s *RegistryServer) instanceDatabase() string {
+	database := "instance_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	if s.database == "sqlite3" {
+		return filepath.Join(s.instanceDir, database+".db")
+	}
+	return database
+}
+
+// instanceDSN returns the data source name of the database of an instance.
+func (s *RegistryServer) instanceDSN(database string) string {
+	switch {
+	case s.database == "sqlite3":
+		return database
+	case strings.Contains(s.dbConfig, "://") && strings.Contains(s.dbConfig, "?"):
+		return s.dbConfig + "&search_path=" + database
+	case strings.Contains(s.dbConfig, "://"):
+		return s.dbConfig + "?search_path=" + database
+	default:
+		return s.dbConfig + " search_path=" + database
+	}
+}
+
+// provisionInstance creates the database of an instance and its tables.
+// It returns AlreadyExists if the database exists, since it may belong to another instance.
+func (s *RegistryServer) provisionInstance(ctx context.Context, instance *models.Instance) error {
+	if s.database != "sqlite3" {
+		if err := s.storageClient.CreateSchema(ctx, instance.Database); err != nil {
+			return err
+		}
+	} else if err := os.MkdirAll(filepath.Dir(instance.Database), 0o755); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	} else if f, err := os.OpenFile(instance.Database, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644); os.IsExist(err) {
+		return status.Errorf(codes.AlreadyExists, "database %q already exists", instance.Database)
+	} else if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	} else if err := f.Close(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	db, err := storage.NewClient(ctx, s.database, s.instanceDSN(instance.Database))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.EnsureTables(ctx)
+}
+
+// deprovisionInstance deletes the database of an instance.
+// Instances that failed before creating a database have none to delete.
+func (s *RegistryServer) deprovisionInstance(ctx context.Context, instance *models.Instance) error {
+	s.instances.close(instance.Name())
+	if instance.Database == "" {
+		return nil
+	}
+	if s.database != "sqlite3" {
+		return s.storageClient.DropSchema(ctx, instance.Database)
+	}
+	if err := os.Remove(instance.Database); err != nil && !os.IsNotExist(err) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// instanceClient returns the storage client of an active instance.
+func (s *RegistryServer) instanceClient(ctx context.Context, name string) (*storage.Client, error) {
+	s.instances.mu.Lock()
+	defer s.instances.mu.Unlock()
+	if c, ok := s.instances.clients[name]; ok {
+		return c, nil
+	}
+
+	parsed, err := names.ParseInstance(name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s header: %s", InstanceHeader, err)
+	}
+	instance, err := s.storageClient.GetInstance(ctx, parsed)
+	if err != nil {
+		return nil, err
+	}
+	if state := instance.State; state != rpc.Instance_ACTIVE.String() {
+		return nil, status.Errorf(codes.FailedPrecondition, "instance %q is %s", name, state)
+	}
+
+	c, err := storage.NewClient(ctx, s.database, s.instanceDSN(instance.Database))
+	if err != nil {
+		return nil, err
+	}
+	s.instances.clients[name] = c
+	return c, nil
+}
+
+type instanceClientKey struct{}
+
+// withInstance returns a context in which storage is read from and written to the
+// instance that is named in the request's InstanceHeader, if there is one.
+// Only calls of the Registry and Admin services are directed to instances.
+func (s *RegistryServer) withInstance(ctx context.Context, method string) (context.Context, error) {
+	if !strings.HasPrefix(method, "/"+rpc.Registry_ServiceDesc.ServiceName+"/") &&
+		!strings.HasPrefix(method, "/"+rpc.Admin_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(InstanceHeader)
+	if len(values) == 0 || values[0] == "" {
+		return ctx, nil
+	}
+	c, err := s.instanceClient(ctx, values[0])
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, instanceClientKey{}, c), nil
+}
+
+func (s *RegistryServer) instanceUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.withInstance(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *RegistryServer) instanceStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.withInstance(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &instanceServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// instanceServerStream is a server stream with a context that refers to an instance.
+type instanceServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *instanceServerStream) Context() context.Context {
+	return s.ctx
+}
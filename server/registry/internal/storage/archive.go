@@ -0,0 +1,229 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"gorm.io/gorm/clause"
+)
+
+// ProjectRecords contains stored records that belong to a project.
+type ProjectRecords struct {
+	Project        *models.Project
+	Apis           []*models.Api
+	Versions       []*models.Version
+	Specs          []*models.Spec // Revisions of specs.
+	SpecTags       []*models.SpecRevisionTag
+	Deployments    []*models.Deployment // Revisions of deployments.
+	DeploymentTags []*models.DeploymentRevisionTag
+	Artifacts      []*models.Artifact
+	Blobs          []*models.Blob
+}
+
+// Count returns the number of records.
+func (r *ProjectRecords) Count() int {
+	n := len(r.Apis) + len(r.Versions) + len(r.Specs) + len(r.SpecTags) +
+		len(r.Deployments) + len(r.DeploymentTags) + len(r.Artifacts) + len(r.Blobs)
+	if r.Project != nil {
+		n++
+	}
+	return n
+}
+
+// ReadProjectRecords reads the records that belong to a project in batches of
+// up to batchSize records of the same table, ordered by key, and calls fn with
+// each batch. Batches of spec revisions and artifacts include their blobs.
+// The project itself isn't included and can be read with GetProject.
+// If progress is not nil, it is called after each table is read.
+func (c *Client) ReadProjectRecords(ctx context.Context, name names.Project, batchSize int, fn func(*ProjectRecords) error, progress func(done, total int)) error {
+	tables := []func(*ProjectRecords) interface{}{
+		func(r *ProjectRecords) interface{} { return &r.Apis },
+		func(r *ProjectRecords) interface{} { return &r.Versions },
+		func(r *ProjectRecords) interface{} { return &r.Specs },
+		func(r *ProjectRecords) interface{} { return &r.SpecTags },
+		func(r *ProjectRecords) interface{} { return &r.Deployments },
+		func(r *ProjectRecords) interface{} { return &r.DeploymentTags },
+		func(r *ProjectRecords) interface{} { return &r.Artifacts },
+	}
+	for i, table := range tables {
+		// Batches are selected by key so that each query starts where the last one ended.
+		after := ""
+		for {
+			r := &ProjectRecords{}
+			op := c.db.WithContext(ctx).
+				Where("project_id = ?", name.ProjectID).
+				Where("key > ?", after).
+				Order("key").
+				Limit(batchSize)
+			if err := op.Find(table(r)).Error; err != nil {
+				return grpcErrorForDBError(ctx, err)
+			}
+			keys := recordKeys(r)
+			if len(keys) == 0 {
+				break
+			}
+			// Blobs are keyed by the names of the spec revisions and artifacts that own them.
+			if len(r.Specs) > 0 || len(r.Artifacts) > 0 {
+				op := c.db.WithContext(ctx).
+					Where("project_id = ?", name.ProjectID).
+					Where("key IN ?", keys).
+					Order("key")
+				if err := op.Find(&r.Blobs).Error; err != nil {
+					return grpcErrorForDBError(ctx, err)
+				}
+			}
+			if err := fn(r); err != nil {
+				return err
+			}
+			if len(keys) < batchSize {
+				break
+			}
+			after = keys[len(keys)-1]
+		}
+		if progress != nil {
+			progress(i+1, len(tables))
+		}
+	}
+	return nil
+}
+
+// recordKeys returns the keys of the records of a batch read from a single table.
+func recordKeys(r *ProjectRecords) []string {
+	var keys []string
+	for _, v := range r.Apis {
+		keys = append(keys, v.Key)
+	}
+	for _, v := range r.Versions {
+		keys = append(keys, v.Key)
+	}
+	for _, v := range r.Specs {
+		keys = append(keys, v.Key)
+	}
+	for _, v := range r.SpecTags {
+		keys = append(keys, v.Key)
+	}
+	for _, v := range r.Deployments {
+		keys = append(keys, v.Key)
+	}
+	for _, v := range r.DeploymentTags {
+		keys = append(keys, v.Key)
+	}
+	for _, v := range r.Artifacts {
+		keys = append(keys, v.Key)
+	}
+	return keys
+}
+
+// ConflictMode describes how records that already exist are handled when
+// records are inserted.
+type ConflictMode int
+
+const (
+	// ConflictFail causes insertions of existing records to fail.
+	ConflictFail ConflictMode = iota
+	// ConflictSkip leaves existing records unchanged.
+	ConflictSkip
+	// ConflictOverwrite replaces existing records.
+	ConflictOverwrite
+)
+
+// InsertProjectRecords writes a set of project records.
+// It returns the number of records that were written and skipped.
+func (c *Client) InsertProjectRecords(ctx context.Context, r *ProjectRecords, mode ConflictMode) (int, int, error) {
+	records := make([]interface{}, 0, r.Count())
+	if r.Project != nil {
+		r.Project.Key = r.Project.Name()
+		records = append(records, r.Project)
+	}
+	for _, v := range r.Apis {
+		v.Key = v.Name()
+		records = append(records, v)
+	}
+	for _, v := range r.Versions {
+		v.Key = v.Name()
+		records = append(records, v)
+	}
+	for _, v := range r.Specs {
+		v.Key = v.RevisionName()
+		records = append(records, v)
+	}
+	for _, v := range r.SpecTags {
+		v.Key = v.String()
+		records = append(records, v)
+	}
+	for _, v := range r.Deployments {
+		v.Key = v.RevisionName()
+		records = append(records, v)
+	}
+	for _, v := range r.DeploymentTags {
+		v.Key = v.String()
+		records = append(records, v)
+	}
+	for _, v := range r.Artifacts {
+		v.Key = v.Name()
+		records = append(records, v)
+	}
+	for _, v := range r.Blobs {
+		v.Key = blobKey(v)
+		records = append(records, v)
+	}
+
+	var written, skipped int
+	for _, v := range records {
+		op := c.db.WithContext(ctx)
+		switch mode {
+		case ConflictSkip:
+			op = op.Clauses(clause.OnConflict{DoNothing: true})
+		case ConflictOverwrite:
+			op = op.Clauses(clause.OnConflict{UpdateAll: true})
+		}
+		if op = op.Create(v); op.Error != nil {
+			return written, skipped, grpcErrorForDBError(ctx, op.Error)
+		}
+		if op.RowsAffected > 0 {
+			written++
+		} else {
+			skipped++
+		}
+	}
+	return written, skipped, nil
+}
+
+// blobKey returns the key of a blob, which is the name of the spec revision
+// or artifact that owns it.
+func blobKey(b *models.Blob) string {
+	if b.ArtifactID != "" {
+		return (&models.Artifact{
+			ProjectID:    b.ProjectID,
+			LocationID:   b.LocationID,
+			ApiID:        b.ApiID,
+			VersionID:    b.VersionID,
+			SpecID:       b.SpecID,
+			DeploymentID: b.DeploymentID,
+			ArtifactID:   b.ArtifactID,
+		}).Name()
+	}
+	return (&models.Spec{
+		ProjectID:  b.ProjectID,
+		LocationID: b.LocationID,
+		ApiID:      b.ApiID,
+		VersionID:  b.VersionID,
+		SpecID:     b.SpecID,
+		RevisionID: b.RevisionID,
+	}).RevisionName()
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/apigee/registry/server/registry/internal/storage/filtering"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var auditEventFields = map[string]filtering.FieldType{
+	"project_id":  filtering.String,
+	"method":      filtering.String,
+	"resource":    filtering.String,
+	"principal":   filtering.String,
+	"request_id":  filtering.String,
+	"create_time": filtering.Timestamp,
+}
+
+// CreateAuditEvent saves an audit event.
+func (c *Client) CreateAuditEvent(ctx context.Context, v *models.AuditEvent) error {
+	return c.create(ctx, v)
+}
+
+// AuditEventList contains a page of audit events.
+type AuditEventList struct {
+	AuditEvents []models.AuditEvent
+	Token       string
+}
+
+// ListAuditEvents lists the audit events of a project. Events are kept after
+// their projects are deleted, so the project is not required to exist.
+func (c *Client) ListAuditEvents(ctx context.Context, parent names.Project, opts PageOptions) (AuditEventList, error) {
+	token, err := decodeToken(opts.Token)
+	if err != nil {
+		return AuditEventList{}, status.Errorf(codes.InvalidArgument, "invalid page token %q: %s", opts.Token, err.Error())
+	}
+
+	if err := token.ValidateFilter(opts.Filter); err != nil {
+		return AuditEventList{}, status.Errorf(codes.InvalidArgument, "invalid filter %q: %s", opts.Filter, err)
+	} else {
+		token.Filter = opts.Filter
+	}
+
+	if err := token.ValidateOrder(opts.Order); err != nil {
+		return AuditEventList{}, status.Errorf(codes.InvalidArgument, "invalid order_by %q: %s", opts.Order, err)
+	} else {
+		token.Order = opts.Order
+	}
+
+	op := c.db.WithContext(ctx).
+		Limit(limit(opts))
+
+	if parent.ProjectID != "-" {
+		op = op.Where("project_id = ?", parent.ProjectID)
+	}
+
+	filter, err := filtering.NewFilter(opts.Filter, auditEventFields)
+	if err != nil {
+		return AuditEventList{}, err
+	}
+
+	// Events are listed in the order that they were recorded by default,
+	// which keeps offsets stable while new events are added.
+	if opts.Order == "" {
+		op = op.Order("create_time,key")
+	} else if order, err := gormOrdering(opts.Order, auditEventFields); err != nil {
+		return AuditEventList{}, err
+	} else {
+		op = op.Order(order)
+	}
+
+	response := AuditEventList{
+		AuditEvents: make([]models.AuditEvent, 0, opts.Size),
+	}
+
+	for {
+		var page []models.AuditEvent
+		err := op.Offset(token.Offset).Find(&page).Error
+
+		if err != nil {
+			return AuditEventList{}, grpcErrorForDBError(ctx, err)
+		} else if len(page) == 0 {
+			break
+		}
+
+		for _, v := range page {
+			match, err := filter.Matches(auditEventMap(v))
+			if err != nil {
+				return AuditEventList{}, err
+			} else if !match {
+				token.Offset++
+				continue
+			}
+
+			if len(response.AuditEvents) == int(opts.Size) {
+				response.Token, err = encodeToken(token)
+				if err != nil {
+					return AuditEventList{}, status.Error(codes.Internal, err.Error())
+				}
+				return response, nil
+			}
+
+			token.Offset++
+			response.AuditEvents = append(response.AuditEvents, v)
+		}
+	}
+
+	return response, nil
+}
+
+func auditEventMap(e models.AuditEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"project_id":  e.ProjectID,
+		"method":      e.Method,
+		"resource":    e.Resource,
+		"principal":   e.Principal,
+		"request_id":  e.RequestID,
+		"create_time": e.CreateTime,
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"gorm.io/gorm"
+)
+
+// Tree is a project or an API and all of the resources that it owns.
+type Tree struct {
+	project names.Project
+	api     *names.Api
+}
+
+// ProjectTree returns the tree of a project.
+func ProjectTree(name names.Project) Tree {
+	return Tree{project: name}
+}
+
+// ApiTree returns the tree of an API.
+func ApiTree(name names.Api) Tree {
+	return Tree{project: name.Project(), api: &name}
+}
+
+// IsApi returns true if the tree is an API rather than a project.
+func (t Tree) IsApi() bool {
+	return t.api != nil
+}
+
+func (t Tree) where(op *gorm.DB) *gorm.DB {
+	op = op.Where("project_id = ?", t.project.ProjectID)
+	if t.api != nil {
+		op = op.Where("location_id = ?", locationID(t.api.LocationID)).
+			Where("api_id = ?", t.api.ApiID)
+	}
+	return op
+}
+
+// ResourceCounts counts resources of each type.
+type ResourceCounts struct {
+	Projects            int64
+	Apis                int64
+	Versions            int64
+	SpecRevisions       int64
+	DeploymentRevisions int64
+	Artifacts           int64
+}
+
+// Total returns the number of resources of all types.
+func (r ResourceCounts) Total() int64 {
+	return r.Projects + r.Apis + r.Versions + r.SpecRevisions + r.DeploymentRevisions + r.Artifacts
+}
+
+// Add adds another set of counts to r.
+func (r *ResourceCounts) Add(o ResourceCounts) {
+	r.Projects += o.Projects
+	r.Apis += o.Apis
+	r.Versions += o.Versions
+	r.SpecRevisions += o.SpecRevisions
+	r.DeploymentRevisions += o.DeploymentRevisions
+	r.Artifacts += o.Artifacts
+}
+
+// treeTable is a table of records that belong to a tree.
+// Records that aren't resources have no count.
+type treeTable struct {
+	model interface{}
+	count func(*ResourceCounts) *int64
+}
+
+// tables returns the tables of a tree in the order that they are deleted.
+// Children are deleted before their parents, so a partial deletion leaves
+// no orphans. Blobs are deleted after the resources that own them, so
+// orphaned blobs are harmless.
+func (t Tree) tables() []treeTable {
+	tables := []treeTable{
+		{models.Artifact{}, func(r *ResourceCounts) *int64 { return &r.Artifacts }},
+		{models.SpecRevisionTag{}, nil},
+		{models.Spec{}, func(r *ResourceCounts) *int64 { return &r.SpecRevisions }},
+		{models.DeploymentRevisionTag{}, nil},
+		{models.Deployment{}, func(r *ResourceCounts) *int64 { return &r.DeploymentRevisions }},
+		{models.Version{}, func(r *ResourceCounts) *int64 { return &r.Versions }},
+		{models.Api{}, func(r *ResourceCounts) *int64 { return &r.Apis }},
+		{models.Blob{}, nil},
+	}
+	if t.api == nil {
+		tables = append(tables,
+			treeTable{models.UploadChunk{}, nil},
+			treeTable{models.Project{}, func(r *ResourceCounts) *int64 { return &r.Projects }},
+		)
+	}
+	return tables
+}
+
+// CountTree counts the resources in a tree.
+func (c *Client) CountTree(ctx context.Context, t Tree) (ResourceCounts, error) {
+	var counts ResourceCounts
+	for _, table := range t.tables() {
+		if table.count == nil {
+			continue
+		}
+		if err := t.where(c.db.WithContext(ctx).Model(table.model)).Count(table.count(&counts)).Error; err != nil {
+			return ResourceCounts{}, grpcErrorForDBError(ctx, err)
+		}
+	}
+	return counts, nil
+}
+
+// DeleteTreeBatch deletes up to size records from a tree and counts the resources that were deleted.
+// It returns false when the tree has been completely deleted.
+func (c *Client) DeleteTreeBatch(ctx context.Context, t Tree, size int) (ResourceCounts, bool, error) {
+	var counts ResourceCounts
+	for _, table := range t.tables() {
+		var keys []string
+		op := t.where(c.db.WithContext(ctx).Model(table.model)).Distinct("key").Order("key").Limit(size)
+		if err := op.Pluck("key", &keys).Error; err != nil {
+			return ResourceCounts{}, false, grpcErrorForDBError(ctx, err)
+		} else if len(keys) == 0 {
+			continue
+		}
+
+		op = t.where(c.db.WithContext(ctx)).Where("key IN ?", keys).Delete(table.model)
+		if err := op.Error; err != nil {
+			return ResourceCounts{}, false, grpcErrorForDBError(ctx, err)
+		}
+		if table.count != nil {
+			*table.count(&counts) = op.RowsAffected
+		}
+		return counts, true, nil
+	}
+	return counts, false, nil
+}
@@ -40,11 +40,16 @@ var entities = []interface{}{
 	&models.DeploymentRevisionTag{},
 	&models.Artifact{},
 	&models.Blob{},
+	&models.UploadChunk{},
+	&models.AuditEvent{},
+	&models.Instance{},
+	&models.Operation{},
 }
 
 // Client represents a connection to a storage provider.
 type Client struct {
-	db *gorm.DB
+	db      *gorm.DB
+	replica *Client // Optional client for a read-only replica of db.
 }
 
 // NewClient creates a new database session using the provided driver and data source name.
@@ -107,8 +112,36 @@ func applyConnectionLimits(db *gorm.DB) error {
 	return nil
 }
 
+// OpenReplica opens a read-only replica of the client's database, which
+// is used by the client returned by Reader. The replica must use the same
+// driver as the primary database.
+func (c *Client) OpenReplica(ctx context.Context, driver, dsn string) error {
+	replica, err := NewClient(ctx, driver, dsn)
+	if err != nil {
+		return err
+	}
+	if c.replica != nil {
+		c.replica.Close()
+	}
+	c.replica = replica
+	return nil
+}
+
+// Reader returns a client for reads that don't need to observe the latest
+// writes. It uses the replica database if one is open and the primary
+// database otherwise. Writes and transactions must use the primary client.
+func (c *Client) Reader() *Client {
+	if c.replica == nil {
+		return c
+	}
+	return c.replica
+}
+
 // Close closes a database session.
 func (c *Client) Close() {
+	if c.replica != nil {
+		c.replica.Close()
+	}
 	c.close()
 }
 
@@ -136,8 +169,18 @@ func (c *Client) EnsureTables(ctx context.Context) error {
 	return nil
 }
 
-func (c *Client) Migrate(ctx context.Context) error {
-	return grpcErrorForDBError(ctx, c.db.WithContext(ctx).AutoMigrate(entities...))
+// Migrate updates the tables of all entities to their current schema.
+// If progress is not nil, it is called after each table is migrated.
+func (c *Client) Migrate(ctx context.Context, progress func(done, total int)) error {
+	for i, entity := range entities {
+		if err := c.db.WithContext(ctx).AutoMigrate(entity); err != nil {
+			return grpcErrorForDBError(ctx, err)
+		}
+		if progress != nil {
+			progress(i+1, len(entities))
+		}
+	}
+	return nil
 }
 
 func (c *Client) DatabaseName(ctx context.Context) string {
@@ -0,0 +1,169 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// TableChecksum summarizes the contents of a table.
+type TableChecksum struct {
+	Count int64  // The number of rows in the table.
+	Hash  string // An order-independent hash of the rows of the table.
+}
+
+// CopyTo copies the rows of every entity table to another database in
+// batches of the specified size, preserving all keys and fields.
+// Rows that already exist in the destination are skipped, so an interrupted
+// copy can be resumed by copying again. The progress function, if non-nil,
+// is called after each batch with the number of rows of the table that have
+// been read.
+func (c *Client) CopyTo(ctx context.Context, dst *Client, batchSize int, progress func(table string, rows int64)) error {
+	if err := dst.EnsureTables(ctx); err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		if !c.db.Migrator().HasTable(entity) {
+			continue
+		}
+		s, err := c.schema(entity)
+		if err != nil {
+			return err
+		}
+		var rows int64
+		err = c.eachBatch(ctx, entity, s, batchSize, func(batch reflect.Value) error {
+			err := dst.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(batch.Interface()).Error
+			})
+			if err != nil {
+				return grpcErrorForDBError(ctx, err)
+			}
+			rows += int64(batch.Elem().Len())
+			if progress != nil {
+				progress(s.Table, rows)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checksums returns checksums of every entity table, keyed by table name.
+// Checksums don't depend on the database driver, so they can be used to
+// verify copies between different kinds of databases.
+func (c *Client) Checksums(ctx context.Context, batchSize int) (map[string]TableChecksum, error) {
+	sums := make(map[string]TableChecksum, len(entities))
+	for _, entity := range entities {
+		s, err := c.schema(entity)
+		if err != nil {
+			return nil, err
+		}
+		if !c.db.Migrator().HasTable(entity) {
+			sums[s.Table] = TableChecksum{Hash: hex.EncodeToString(make([]byte, sha256.Size))}
+			continue
+		}
+		var count int64
+		hash := make([]byte, sha256.Size)
+		err = c.eachBatch(ctx, entity, s, batchSize, func(batch reflect.Value) error {
+			for i := 0; i < batch.Elem().Len(); i++ {
+				row := rowHash(ctx, s, batch.Elem().Index(i))
+				for j := range hash {
+					hash[j] ^= row[j]
+				}
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sums[s.Table] = TableChecksum{Count: count, Hash: hex.EncodeToString(hash)}
+	}
+	return sums, nil
+}
+
+func (c *Client) schema(entity interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: c.db}
+	if err := stmt.Parse(entity); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// eachBatch reads all rows of a table in primary key order and calls fn
+// with a pointer to a slice of each batch of rows.
+func (c *Client) eachBatch(ctx context.Context, entity interface{}, s *schema.Schema, batchSize int, fn func(reflect.Value) error) error {
+	columns := strings.Join(s.PrimaryFieldDBNames, ", ")
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(s.PrimaryFields)), ", ")
+	var after []interface{}
+	for {
+		batch := reflect.New(reflect.SliceOf(reflect.TypeOf(entity).Elem()))
+		op := c.db.WithContext(ctx).Model(entity).Order(columns).Limit(batchSize)
+		if after != nil {
+			op = op.Where(fmt.Sprintf("(%s) > (%s)", columns, placeholders), after...)
+		}
+		if err := op.Find(batch.Interface()).Error; err != nil {
+			return grpcErrorForDBError(ctx, err)
+		}
+		n := batch.Elem().Len()
+		if n == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if n < batchSize {
+			return nil
+		}
+		last := batch.Elem().Index(n - 1)
+		after = make([]interface{}, len(s.PrimaryFields))
+		for i, f := range s.PrimaryFields {
+			after[i], _ = f.ValueOf(ctx, last)
+		}
+	}
+}
+
+// rowHash hashes a normalized representation of a row.
+func rowHash(ctx context.Context, s *schema.Schema, row reflect.Value) []byte {
+	h := sha256.New()
+	for _, f := range s.Fields {
+		if f.DBName == "" {
+			continue
+		}
+		v, _ := f.ValueOf(ctx, row)
+		switch v := v.(type) {
+		case time.Time:
+			fmt.Fprintf(h, "%s=%s\x00", f.DBName, v.UTC().Format(time.RFC3339Nano))
+		case []byte:
+			fmt.Fprintf(h, "%s=%x\x00", f.DBName, v)
+		default:
+			fmt.Fprintf(h, "%s=%v\x00", f.DBName, v)
+		}
+	}
+	return h.Sum(nil)
+}
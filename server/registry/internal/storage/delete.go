@@ -69,10 +69,11 @@ func (c *Client) DeleteProject(ctx context.Context, name names.Project, cascade
 		return status.Errorf(codes.FailedPrecondition, "cannot delete child resources of %s in non-cascading mode", name)
 	}
 
-	// Tags aren't API resources, so they do not block non-cascading deletes.
+	// Tags and pending uploads aren't API resources, so they do not block non-cascading deletes.
 	for _, model := range []interface{}{
 		models.DeploymentRevisionTag{},
 		models.SpecRevisionTag{},
+		models.UploadChunk{},
 	} {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID)
 		if err := op.Delete(model).Error; err != nil {
@@ -96,6 +97,7 @@ func (c *Client) DeleteApi(ctx context.Context, name names.Api, cascade bool) er
 	counts := make([]int64, len(tables))
 	for i, model := range tables {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID)
 		if err := op.Delete(model).Error; err != nil {
 			return err
@@ -115,6 +117,7 @@ func (c *Client) DeleteApi(ctx context.Context, name names.Api, cascade bool) er
 		models.SpecRevisionTag{},
 	} {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID)
 		if err := op.Delete(model).Error; err != nil {
 			return err
@@ -134,6 +137,7 @@ func (c *Client) DeleteVersion(ctx context.Context, name names.Version, cascade
 	counts := make([]int64, len(tables))
 	for i, model := range tables {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID).
 			Where("version_id = ?", name.VersionID)
 		if err := op.Delete(model).Error; err != nil {
@@ -153,6 +157,7 @@ func (c *Client) DeleteVersion(ctx context.Context, name names.Version, cascade
 		models.SpecRevisionTag{},
 	} {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID).
 			Where("version_id = ?", name.VersionID)
 		if err := op.Delete(model).Error; err != nil {
@@ -170,6 +175,7 @@ func (c *Client) DeleteSpec(ctx context.Context, name names.Spec, cascade bool)
 		models.Blob{},
 	} {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID).
 			Where("version_id = ?", name.VersionID).
 			Where("spec_id = ?", name.SpecID)
@@ -188,6 +194,7 @@ func (c *Client) DeleteSpec(ctx context.Context, name names.Spec, cascade bool)
 	counts := make([]int64, len(tables))
 	for i, model := range tables {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID).
 			Where("version_id = ?", name.VersionID).
 			Where("spec_id = ?", name.SpecID)
@@ -216,6 +223,7 @@ func (c *Client) DeleteSpecRevision(ctx context.Context, name names.SpecRevision
 	} {
 		op := c.db.WithContext(ctx).
 			Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID).
 			Where("version_id = ?", name.VersionID).
 			Where("spec_id = ?", name.SpecID).
@@ -231,6 +239,7 @@ func (c *Client) DeleteSpecRevision(ctx context.Context, name names.SpecRevision
 	// if we deleted the last revision, return an error to cancel the transaction
 	op := c.db.WithContext(ctx).
 		Where("project_id = ?", name.ProjectID).
+		Where("location_id = ?", locationID(name.LocationID)).
 		Where("api_id = ?", name.ApiID).
 		Where("version_id = ?", name.VersionID).
 		Where("spec_id = ?", name.SpecID).
@@ -251,6 +260,7 @@ func (c *Client) DeleteDeployment(ctx context.Context, name names.Deployment, ca
 		models.DeploymentRevisionTag{},
 	} {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID).
 			Where("deployment_id = ?", name.DeploymentID)
 		if err := op.Delete(model).Error; err != nil {
@@ -268,6 +278,7 @@ func (c *Client) DeleteDeployment(ctx context.Context, name names.Deployment, ca
 	counts := make([]int64, len(tables))
 	for i, model := range tables {
 		op := c.db.WithContext(ctx).Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID).
 			Where("deployment_id = ?", name.DeploymentID)
 		if err := op.Delete(model).Error; err != nil {
@@ -296,6 +307,7 @@ func (c *Client) DeleteDeploymentRevision(ctx context.Context, name names.Deploy
 	} {
 		op := c.db.WithContext(ctx).
 			Where("project_id = ?", name.ProjectID).
+			Where("location_id = ?", locationID(name.LocationID)).
 			Where("api_id = ?", name.ApiID).
 			Where("deployment_id = ?", name.DeploymentID).
 			Where("revision_id = ?", name.RevisionID)
@@ -310,6 +322,7 @@ func (c *Client) DeleteDeploymentRevision(ctx context.Context, name names.Deploy
 	// if we deleted the last revision, return an error to cancel the transaction
 	op := c.db.WithContext(ctx).
 		Where("project_id = ?", name.ProjectID).
+		Where("location_id = ?", locationID(name.LocationID)).
 		Where("api_id = ?", name.ApiID).
 		Where("deployment_id = ?", name.DeploymentID).
 		Order("revision_create_time desc")
@@ -330,6 +343,7 @@ func (c *Client) DeleteArtifact(ctx context.Context, name names.Artifact) error
 	} {
 		op := c.db.WithContext(ctx).
 			Where("project_id = ?", name.ProjectID()).
+			Where("location_id = ?", locationID(name.LocationID())).
 			Where("api_id = ?", name.ApiID()).
 			Where("version_id = ?", name.VersionID()).
 			Where("spec_id = ?", name.SpecID()).
@@ -38,7 +38,7 @@ func AlreadyExists(err error) bool {
 	}
 	switch v := err.(type) {
 	case *pq.Error:
-		if v.Code.Name() == "unique_violation" {
+		if v.Code.Name() == "unique_violation" || v.Code.Name() == "duplicate_schema" {
 			return true
 		}
 	}
@@ -61,7 +61,7 @@ func grpcErrorForDBError(ctx context.Context, err error) error {
 	// handle all other known error types.
 	switch v := err.(type) {
 	case *pq.Error:
-		if v.Code.Name() == "unique_violation" {
+		if v.Code.Name() == "unique_violation" || v.Code.Name() == "duplicate_schema" {
 			return status.Error(codes.AlreadyExists, err.Error())
 		} else if v.Code.Name() == "too_many_connections" {
 			return status.Error(codes.Unavailable, err.Error())
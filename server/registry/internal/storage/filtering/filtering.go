@@ -31,6 +31,7 @@ const (
 	Int       FieldType = iota
 	Timestamp FieldType = iota
 	StringMap FieldType = iota
+	Bool      FieldType = iota
 )
 
 type Filter struct {
@@ -69,6 +70,8 @@ func NewFilter(filter string, fields map[string]FieldType) (Filter, error) {
 			declarations = append(declarations, decls.NewConst(name, decls.Int, nil))
 		case Timestamp:
 			declarations = append(declarations, decls.NewConst(name, decls.Timestamp, nil))
+		case Bool:
+			declarations = append(declarations, decls.NewConst(name, decls.Bool, nil))
 		case StringMap:
 			declarations = append(declarations, decls.NewConst(name, decls.NewMapType(decls.String, decls.String), nil))
 		default:
@@ -127,6 +127,19 @@ func TestFilter_Matches(t *testing.T) {
 				"k": 321,
 			},
 		},
+		{
+			desc:   "Bool",
+			filter: `k`,
+			fields: map[string]FieldType{
+				"k": Bool,
+			},
+			positive: map[string]interface{}{
+				"k": true,
+			},
+			negative: map[string]interface{}{
+				"k": false,
+			},
+		},
 		{
 			desc:   "less than Timestamp",
 			filter: `k < timestamp("2021-01-01T00:00:00Z")`,
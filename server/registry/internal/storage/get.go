@@ -16,6 +16,7 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/apigee/registry/server/registry/internal/storage/models"
 	"github.com/apigee/registry/server/registry/names"
@@ -61,6 +62,7 @@ func (c *Client) GetSpec(ctx context.Context, name names.Spec) (*models.Spec, er
 	name = name.Normal()
 	op := c.db.WithContext(ctx).
 		Where("project_id = ?", name.ProjectID).
+		Where("location_id = ?", locationID(name.LocationID)).
 		Where("api_id = ?", name.ApiID).
 		Where("version_id = ?", name.VersionID).
 		Where("spec_id = ?", name.SpecID).
@@ -108,10 +110,60 @@ func (c *Client) GetSpecRevisionContents(ctx context.Context, name names.SpecRev
 	return v, nil
 }
 
+// GetSpecRevisionBlob returns the blob of a spec revision without its contents,
+// which can be read in parts with ReadBlob.
+func (c *Client) GetSpecRevisionBlob(ctx context.Context, name names.SpecRevision) (*models.Blob, error) {
+	name, err := c.unwrapSpecRevisionTag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(models.Blob)
+	if err := c.db.WithContext(ctx).Omit("contents").Take(v, "key = ?", name.String()).Error; err == gorm.ErrRecordNotFound {
+		return nil, status.Errorf(codes.NotFound, "%q not found in database", name)
+	} else if err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+
+	return v, nil
+}
+
+// GetBlobLength returns the length of the stored contents of a blob, which
+// differs from its size if the contents are compressed.
+func (c *Client) GetBlobLength(ctx context.Context, key string) (int64, error) {
+	var length int64
+	err := c.db.WithContext(ctx).Model(&models.Blob{}).
+		Select("LENGTH(contents)").
+		Where("key = ?", key).
+		Row().Scan(&length)
+	if err == sql.ErrNoRows {
+		return 0, status.Errorf(codes.NotFound, "%q not found in database", key)
+	} else if err != nil {
+		return 0, grpcErrorForDBError(ctx, err)
+	}
+	return length, nil
+}
+
+// ReadBlob returns up to length bytes of the contents of a blob, starting at offset.
+func (c *Client) ReadBlob(ctx context.Context, key string, offset, length int64) ([]byte, error) {
+	var data []byte
+	err := c.db.WithContext(ctx).Model(&models.Blob{}).
+		Select("SUBSTR(contents, ?, ?)", offset+1, length).
+		Where("key = ?", key).
+		Row().Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "%q not found in database", key)
+	} else if err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	return data, nil
+}
+
 func (c *Client) GetDeployment(ctx context.Context, name names.Deployment) (*models.Deployment, error) {
 	name = name.Normal()
 	op := c.db.WithContext(ctx).
 		Where("project_id = ?", name.ProjectID).
+		Where("location_id = ?", locationID(name.LocationID)).
 		Where("api_id = ?", name.ApiID).
 		Where("deployment_id = ?", name.DeploymentID).
 		Order("revision_create_time desc")
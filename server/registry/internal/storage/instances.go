@@ -0,0 +1,75 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func (c *Client) CreateInstance(ctx context.Context, v *models.Instance) error {
+	v.Key = v.Name()
+	return c.create(ctx, v)
+}
+
+func (c *Client) GetInstance(ctx context.Context, name names.Instance) (*models.Instance, error) {
+	v := new(models.Instance)
+	if err := c.db.WithContext(ctx).Take(v, "key = ?", name.String()).Error; err == gorm.ErrRecordNotFound {
+		return nil, status.Errorf(codes.NotFound, "%q not found in database", name)
+	} else if err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+
+	return v, nil
+}
+
+func (c *Client) SaveInstance(ctx context.Context, v *models.Instance) error {
+	return c.save(ctx, v)
+}
+
+func (c *Client) DeleteInstance(ctx context.Context, name names.Instance) error {
+	op := c.db.WithContext(ctx).Where("key = ?", name.String())
+	if err := op.Delete(models.Instance{}).Error; err != nil {
+		return grpcErrorForDBError(ctx, err)
+	} else if op.RowsAffected == 0 {
+		return status.Errorf(codes.NotFound, "%q not found in database", name)
+	}
+	return nil
+}
+
+// CreateSchema creates a PostgreSQL schema.
+// It returns AlreadyExists if the schema exists.
+func (c *Client) CreateSchema(ctx context.Context, schema string) error {
+	if c.db.Name() != "postgres" {
+		return status.Errorf(codes.Internal, "schemas are unsupported by database %s", c.db.Name())
+	}
+	err := c.db.WithContext(ctx).Exec(fmt.Sprintf("CREATE SCHEMA %q", schema)).Error
+	return grpcErrorForDBError(ctx, err)
+}
+
+// DropSchema deletes a PostgreSQL schema and everything that it contains.
+func (c *Client) DropSchema(ctx context.Context, schema string) error {
+	if c.db.Name() != "postgres" {
+		return status.Errorf(codes.Internal, "schemas are unsupported by database %s", c.db.Name())
+	}
+	err := c.db.WithContext(ctx).Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %q CASCADE", schema)).Error
+	return grpcErrorForDBError(ctx, err)
+}
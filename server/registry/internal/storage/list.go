@@ -38,6 +38,7 @@ var projectFields = map[string]filtering.FieldType{
 var apiFields = map[string]filtering.FieldType{
 	"name":                   filtering.String,
 	"project_id":             filtering.String,
+	"location_id":            filtering.String,
 	"api_id":                 filtering.String,
 	"display_name":           filtering.String,
 	"description":            filtering.String,
@@ -52,6 +53,7 @@ var apiFields = map[string]filtering.FieldType{
 var versionFields = map[string]filtering.FieldType{
 	"name":         filtering.String,
 	"project_id":   filtering.String,
+	"location_id":  filtering.String,
 	"api_id":       filtering.String,
 	"version_id":   filtering.String,
 	"display_name": filtering.String,
@@ -65,6 +67,7 @@ var versionFields = map[string]filtering.FieldType{
 var specFields = map[string]filtering.FieldType{
 	"name":                 filtering.String,
 	"project_id":           filtering.String,
+	"location_id":          filtering.String,
 	"api_id":               filtering.String,
 	"version_id":           filtering.String,
 	"spec_id":              filtering.String,
@@ -82,6 +85,7 @@ var specFields = map[string]filtering.FieldType{
 var deploymentFields = map[string]filtering.FieldType{
 	"name":                 filtering.String,
 	"project_id":           filtering.String,
+	"location_id":          filtering.String,
 	"api_id":               filtering.String,
 	"deployment_id":        filtering.String,
 	"display_name":         filtering.String,
@@ -100,6 +104,7 @@ var deploymentFields = map[string]filtering.FieldType{
 var artifactFields = map[string]filtering.FieldType{
 	"name":        filtering.String,
 	"project_id":  filtering.String,
+	"location_id": filtering.String,
 	"api_id":      filtering.String,
 	"version_id":  filtering.String,
 	"spec_id":     filtering.String,
@@ -170,6 +175,54 @@ func limit(opts PageOptions) int {
 	return 500
 }
 
+// fieldColumns maps message field names to column names where they differ.
+var fieldColumns = map[string]string{
+	"filename":   "file_name",
+	"size_bytes": "size_in_bytes",
+}
+
+// selectFields limits a listing query to the columns of a model that are needed to read
+// the fields named in the listing options. Primary keys and identifiers are always read.
+// All columns are read when filtering because filters are evaluated on complete rows.
+func selectFields(op *gorm.DB, model interface{}, opts PageOptions) *gorm.DB {
+	if len(opts.Fields) == 0 || opts.Filter != "" {
+		return op
+	}
+
+	stmt := &gorm.Statement{DB: op}
+	if err := stmt.Parse(model); err != nil {
+		return op
+	}
+
+	needed := make(map[string]bool, len(opts.Fields))
+	for _, field := range opts.Fields {
+		if column, ok := fieldColumns[field]; ok {
+			field = column
+		}
+		needed[field] = true
+	}
+
+	columns := make([]string, 0, len(stmt.Schema.DBNames))
+	for _, f := range stmt.Schema.Fields {
+		if f.DBName == "" {
+			continue
+		}
+		if f.PrimaryKey || strings.HasSuffix(f.DBName, "_id") || needed[f.DBName] {
+			columns = append(columns, stmt.Schema.Table+"."+f.DBName)
+		}
+	}
+	return op.Select(columns)
+}
+
+// locationID returns the stored form of a location ID from a resource name,
+// in which an empty location ID refers to the default location.
+func locationID(id string) string {
+	if id == "" {
+		return names.DefaultLocation
+	}
+	return id
+}
+
 // ProjectList contains a page of project resources.
 type ProjectList struct {
 	Projects []models.Project
@@ -210,7 +263,7 @@ func (c *Client) ListProjects(ctx context.Context, opts PageOptions) (ProjectLis
 
 	for {
 		var page []models.Project
-		op := c.db.WithContext(ctx).Order(order).Limit(limit(opts))
+		op := selectFields(c.db.WithContext(ctx), &models.Project{}, opts).Order(order).Limit(limit(opts))
 		err := op.Offset(token.Offset).Find(&page).Error
 
 		if err != nil {
@@ -261,7 +314,7 @@ type ApiList struct {
 	Token string
 }
 
-func (c *Client) ListApis(ctx context.Context, parent names.Project, opts PageOptions) (ApiList, error) {
+func (c *Client) ListApis(ctx context.Context, parent names.ProjectLocation, opts PageOptions) (ApiList, error) {
 	token, err := decodeToken(opts.Token)
 	if err != nil {
 		return ApiList{}, status.Errorf(codes.InvalidArgument, "invalid page token %q: %s", opts.Token, err.Error())
@@ -284,10 +337,13 @@ func (c *Client) ListApis(ctx context.Context, parent names.Project, opts PageOp
 
 	if parent.ProjectID != "-" {
 		op = op.Where("project_id = ?", parent.ProjectID)
-		if _, err := c.GetProject(ctx, parent); err != nil {
+		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return ApiList{}, err
 		}
 	}
+	if parent.LocationID != "-" {
+		op = op.Where("location_id = ?", locationID(parent.LocationID))
+	}
 
 	filter, err := filtering.NewFilter(opts.Filter, apiFields)
 	if err != nil {
@@ -300,6 +356,8 @@ func (c *Client) ListApis(ctx context.Context, parent names.Project, opts PageOp
 		op = op.Order(order)
 	}
 
+	op = selectFields(op, &models.Api{}, opts)
+
 	response := ApiList{
 		Apis: make([]models.Api, 0, opts.Size),
 	}
@@ -353,6 +411,7 @@ func apiMap(api models.Api) (map[string]interface{}, error) {
 	return map[string]interface{}{
 		"name":                api.Name(),
 		"project_id":          api.ProjectID,
+		"location_id":         api.LocationID,
 		"api_id":              api.ApiID,
 		"display_name":        api.DisplayName,
 		"description":         api.Description,
@@ -388,11 +447,11 @@ func (c *Client) ListVersions(ctx context.Context, parent names.Api, opts PageOp
 		token.Order = opts.Order
 	}
 
-	if parent.ProjectID != "-" && parent.ApiID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" {
 		if _, err := c.GetApi(ctx, parent); err != nil {
 			return VersionList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return VersionList{}, err
 		}
@@ -407,6 +466,9 @@ func (c *Client) ListVersions(ctx context.Context, parent names.Api, opts PageOp
 	if parent.ProjectID != "-" {
 		op = op.Where("project_id = ?", parent.ProjectID)
 	}
+	if parent.LocationID != "-" {
+		op = op.Where("location_id = ?", locationID(parent.LocationID))
+	}
 	if parent.ApiID != "-" {
 		op = op.Where("api_id = ?", parent.ApiID)
 	}
@@ -417,6 +479,8 @@ func (c *Client) ListVersions(ctx context.Context, parent names.Api, opts PageOp
 		op = op.Order(order)
 	}
 
+	op = selectFields(op, &models.Version{}, opts)
+
 	response := VersionList{
 		Versions: make([]models.Version, 0, opts.Size),
 	}
@@ -470,6 +534,7 @@ func versionMap(version models.Version) (map[string]interface{}, error) {
 	return map[string]interface{}{
 		"name":         version.Name(),
 		"project_id":   version.ProjectID,
+		"location_id":  version.LocationID,
 		"version_id":   version.VersionID,
 		"display_name": version.DisplayName,
 		"description":  version.Description,
@@ -504,15 +569,15 @@ func (c *Client) ListSpecs(ctx context.Context, parent names.Version, opts PageO
 		token.Order = opts.Order
 	}
 
-	if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID != "-" {
 		if _, err := c.GetVersion(ctx, parent); err != nil {
 			return SpecList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID == "-" {
+	} else if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID == "-" {
 		if _, err := c.GetApi(ctx, parent.Api()); err != nil {
 			return SpecList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" && parent.VersionID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return SpecList{}, err
 		}
@@ -528,18 +593,21 @@ func (c *Client) ListSpecs(ctx context.Context, parent names.Version, opts PageO
 	op := c.db.WithContext(ctx).Select("specs.*").
 		Table("specs").
 		// Join missing columns that couldn't be selected in the subquery.
-		Joins("JOIN (?) AS grp ON specs.project_id = grp.project_id AND specs.api_id = grp.api_id AND specs.version_id = grp.version_id AND specs.spec_id = grp.spec_id AND specs.revision_create_time = grp.recent_create_time",
+		Joins("JOIN (?) AS grp ON specs.project_id = grp.project_id AND specs.location_id = grp.location_id AND specs.api_id = grp.api_id AND specs.version_id = grp.version_id AND specs.spec_id = grp.spec_id AND specs.revision_create_time = grp.recent_create_time",
 			// Select spec names and only their most recent revision_create_time
 			// This query cannot select all the columns we want.
 			// See: https://stackoverflow.com/questions/7745609/sql-select-only-rows-with-max-value-on-a-column
-			c.db.WithContext(ctx).Select("project_id, api_id, version_id, spec_id, MAX(revision_create_time) AS recent_create_time").
+			c.db.WithContext(ctx).Select("project_id, location_id, api_id, version_id, spec_id, MAX(revision_create_time) AS recent_create_time").
 				Table("specs").
-				Group("project_id, api_id, version_id, spec_id")).
+				Group("project_id, location_id, api_id, version_id, spec_id")).
 		Limit(limit(opts))
 
 	if parent.ProjectID != "-" {
 		op = op.Where("specs.project_id = ?", parent.ProjectID)
 	}
+	if parent.LocationID != "-" {
+		op = op.Where("specs.location_id = ?", locationID(parent.LocationID))
+	}
 	if parent.ApiID != "-" {
 		op = op.Where("specs.api_id = ?", parent.ApiID)
 	}
@@ -553,6 +621,8 @@ func (c *Client) ListSpecs(ctx context.Context, parent names.Version, opts PageO
 		op = op.Order(order)
 	}
 
+	op = selectFields(op, &models.Spec{}, opts)
+
 	response := SpecList{
 		Specs: make([]models.Spec, 0, opts.Size),
 	}
@@ -606,6 +676,7 @@ func specMap(spec models.Spec) (map[string]interface{}, error) {
 	return map[string]interface{}{
 		"name":                 spec.Name(),
 		"project_id":           spec.ProjectID,
+		"location_id":          spec.LocationID,
 		"api_id":               spec.ApiID,
 		"version_id":           spec.VersionID,
 		"spec_id":              spec.SpecID,
@@ -630,19 +701,19 @@ func (c *Client) ListSpecRevisions(ctx context.Context, parent names.Spec, opts
 	}
 
 	// Check existence of the deepest fully specified resource in the parent name.
-	if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID != "-" && parent.SpecID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID != "-" && parent.SpecID != "-" {
 		if _, err := c.GetSpec(ctx, parent); err != nil {
 			return SpecList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID != "-" && parent.SpecID == "-" {
+	} else if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID != "-" && parent.SpecID == "-" {
 		if _, err := c.GetVersion(ctx, parent.Version()); err != nil {
 			return SpecList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID == "-" && parent.SpecID == "-" {
+	} else if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID == "-" && parent.SpecID == "-" {
 		if _, err := c.GetApi(ctx, parent.Api()); err != nil {
 			return SpecList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" && parent.VersionID == "-" && parent.SpecID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return SpecList{}, err
 		}
@@ -656,6 +727,9 @@ func (c *Client) ListSpecRevisions(ctx context.Context, parent names.Spec, opts
 	if id := parent.ProjectID; id != "-" {
 		op = op.Where("project_id = ?", id)
 	}
+	if id := parent.LocationID; id != "-" {
+		op = op.Where("location_id = ?", locationID(id))
+	}
 	if id := parent.ApiID; id != "-" {
 		op = op.Where("api_id = ?", id)
 	}
@@ -666,6 +740,8 @@ func (c *Client) ListSpecRevisions(ctx context.Context, parent names.Spec, opts
 		op = op.Where("spec_id = ?", id)
 	}
 
+	op = selectFields(op, &models.Spec{}, opts)
+
 	response := SpecList{
 		Specs: make([]models.Spec, 0, opts.Size),
 	}
@@ -713,11 +789,11 @@ func (c *Client) ListDeployments(ctx context.Context, parent names.Api, opts Pag
 		token.Order = opts.Order
 	}
 
-	if parent.ProjectID != "-" && parent.ApiID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" {
 		if _, err := c.GetApi(ctx, parent); err != nil {
 			return DeploymentList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return DeploymentList{}, err
 		}
@@ -733,18 +809,21 @@ func (c *Client) ListDeployments(ctx context.Context, parent names.Api, opts Pag
 	op := c.db.WithContext(ctx).Select("deployments.*").
 		Table("deployments").
 		// Join missing columns that couldn't be selected in the subquery.
-		Joins("JOIN (?) AS grp ON deployments.project_id = grp.project_id AND deployments.api_id = grp.api_id AND deployments.deployment_id = grp.deployment_id AND deployments.revision_create_time = grp.recent_create_time",
+		Joins("JOIN (?) AS grp ON deployments.project_id = grp.project_id AND deployments.location_id = grp.location_id AND deployments.api_id = grp.api_id AND deployments.deployment_id = grp.deployment_id AND deployments.revision_create_time = grp.recent_create_time",
 			// Select deployment names and only their most recent revision_create_time
 			// This query cannot select all the columns we want.
 			// See: https://stackoverflow.com/questions/7745609/sql-select-only-rows-with-max-value-on-a-column
-			c.db.WithContext(ctx).Select("project_id, api_id, deployment_id, MAX(revision_create_time) AS recent_create_time").
+			c.db.WithContext(ctx).Select("project_id, location_id, api_id, deployment_id, MAX(revision_create_time) AS recent_create_time").
 				Table("deployments").
-				Group("project_id, api_id, deployment_id")).
+				Group("project_id, location_id, api_id, deployment_id")).
 		Limit(limit(opts))
 
 	if parent.ProjectID != "-" {
 		op = op.Where("deployments.project_id = ?", parent.ProjectID)
 	}
+	if parent.LocationID != "-" {
+		op = op.Where("deployments.location_id = ?", locationID(parent.LocationID))
+	}
 	if parent.ApiID != "-" {
 		op = op.Where("deployments.api_id = ?", parent.ApiID)
 	}
@@ -755,6 +834,8 @@ func (c *Client) ListDeployments(ctx context.Context, parent names.Api, opts Pag
 		op = op.Order(order)
 	}
 
+	op = selectFields(op, &models.Deployment{}, opts)
+
 	response := DeploymentList{
 		Deployments: make([]models.Deployment, 0, opts.Size),
 	}
@@ -808,6 +889,7 @@ func deploymentMap(deployment models.Deployment) (map[string]interface{}, error)
 	return map[string]interface{}{
 		"name":                 deployment.Name(),
 		"project_id":           deployment.ProjectID,
+		"location_id":          deployment.LocationID,
 		"api_id":               deployment.ApiID,
 		"deployment_id":        deployment.DeploymentID,
 		"revision_id":          deployment.RevisionID,
@@ -831,15 +913,15 @@ func (c *Client) ListDeploymentRevisions(ctx context.Context, parent names.Deplo
 		return DeploymentList{}, status.Errorf(codes.InvalidArgument, "invalid page token %q: %s", opts.Token, err.Error())
 	}
 
-	if parent.ProjectID != "-" && parent.ApiID != "-" && parent.DeploymentID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.DeploymentID != "-" {
 		if _, err := c.GetDeployment(ctx, parent); err != nil {
 			return DeploymentList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID != "-" && parent.DeploymentID == "-" {
+	} else if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.DeploymentID == "-" {
 		if _, err := c.GetApi(ctx, parent.Api()); err != nil {
 			return DeploymentList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" && parent.DeploymentID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return DeploymentList{}, err
 		}
@@ -853,6 +935,9 @@ func (c *Client) ListDeploymentRevisions(ctx context.Context, parent names.Deplo
 	if id := parent.ProjectID; id != "-" {
 		op = op.Where("project_id = ?", id)
 	}
+	if id := parent.LocationID; id != "-" {
+		op = op.Where("location_id = ?", locationID(id))
+	}
 	if id := parent.ApiID; id != "-" {
 		op = op.Where("api_id = ?", id)
 	}
@@ -860,6 +945,8 @@ func (c *Client) ListDeploymentRevisions(ctx context.Context, parent names.Deplo
 		op = op.Where("deployment_id = ?", id)
 	}
 
+	op = selectFields(op, &models.Deployment{}, opts)
+
 	response := DeploymentList{
 		Deployments: make([]models.Deployment, 0, opts.Size),
 	}
@@ -907,19 +994,19 @@ func (c *Client) ListSpecArtifacts(ctx context.Context, parent names.Spec, opts
 		token.Order = opts.Order
 	}
 
-	if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID != "-" && parent.SpecID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID != "-" && parent.SpecID != "-" {
 		if _, err := c.GetSpec(ctx, parent); err != nil {
 			return ArtifactList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID != "-" && parent.SpecID == "-" {
+	} else if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID != "-" && parent.SpecID == "-" {
 		if _, err := c.GetVersion(ctx, parent.Version()); err != nil {
 			return ArtifactList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID == "-" && parent.SpecID == "-" {
+	} else if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID == "-" && parent.SpecID == "-" {
 		if _, err := c.GetApi(ctx, parent.Api()); err != nil {
 			return ArtifactList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" && parent.VersionID == "-" && parent.SpecID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return ArtifactList{}, err
 		}
@@ -929,6 +1016,9 @@ func (c *Client) ListSpecArtifacts(ctx context.Context, parent names.Spec, opts
 	if id := parent.ProjectID; id != "-" {
 		op = op.Where("project_id = ?", id)
 	}
+	if id := parent.LocationID; id != "-" {
+		op = op.Where("location_id = ?", locationID(id))
+	}
 	if id := parent.ApiID; id != "-" {
 		op = op.Where("api_id = ?", id)
 	}
@@ -962,15 +1052,15 @@ func (c *Client) ListVersionArtifacts(ctx context.Context, parent names.Version,
 		token.Order = opts.Order
 	}
 
-	if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID != "-" {
 		if _, err := c.GetVersion(ctx, parent); err != nil {
 			return ArtifactList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID != "-" && parent.VersionID == "-" {
+	} else if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.VersionID == "-" {
 		if _, err := c.GetApi(ctx, parent.Api()); err != nil {
 			return ArtifactList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" && parent.VersionID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return ArtifactList{}, err
 		}
@@ -982,6 +1072,9 @@ func (c *Client) ListVersionArtifacts(ctx context.Context, parent names.Version,
 	if id := parent.ProjectID; id != "-" {
 		op = op.Where("project_id = ?", id)
 	}
+	if id := parent.LocationID; id != "-" {
+		op = op.Where("location_id = ?", locationID(id))
+	}
 	if id := parent.ApiID; id != "-" {
 		op = op.Where("api_id = ?", id)
 	}
@@ -1012,15 +1105,15 @@ func (c *Client) ListDeploymentArtifacts(ctx context.Context, parent names.Deplo
 		token.Order = opts.Order
 	}
 
-	if parent.ProjectID != "-" && parent.ApiID != "-" && parent.DeploymentID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.DeploymentID != "-" {
 		if _, err := c.GetDeployment(ctx, parent); err != nil {
 			return ArtifactList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID != "-" && parent.DeploymentID == "-" {
+	} else if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" && parent.DeploymentID == "-" {
 		if _, err := c.GetApi(ctx, parent.Api()); err != nil {
 			return ArtifactList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" && parent.DeploymentID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return ArtifactList{}, err
 		}
@@ -1032,6 +1125,9 @@ func (c *Client) ListDeploymentArtifacts(ctx context.Context, parent names.Deplo
 	if id := parent.ProjectID; id != "-" {
 		op = op.Where("project_id = ?", id)
 	}
+	if id := parent.LocationID; id != "-" {
+		op = op.Where("location_id = ?", locationID(id))
+	}
 	if id := parent.ApiID; id != "-" {
 		op = op.Where("api_id = ?", id)
 	}
@@ -1062,11 +1158,11 @@ func (c *Client) ListApiArtifacts(ctx context.Context, parent names.Api, opts Pa
 		token.Order = opts.Order
 	}
 
-	if parent.ProjectID != "-" && parent.ApiID != "-" {
+	if parent.ProjectID != "-" && parent.LocationID != "-" && parent.ApiID != "-" {
 		if _, err := c.GetApi(ctx, parent); err != nil {
 			return ArtifactList{}, err
 		}
-	} else if parent.ProjectID != "-" && parent.ApiID == "-" {
+	} else if parent.ProjectID != "-" {
 		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return ArtifactList{}, err
 		}
@@ -1079,6 +1175,9 @@ func (c *Client) ListApiArtifacts(ctx context.Context, parent names.Api, opts Pa
 	if id := parent.ProjectID; id != "-" {
 		op = op.Where("project_id = ?", id)
 	}
+	if id := parent.LocationID; id != "-" {
+		op = op.Where("location_id = ?", locationID(id))
+	}
 	if id := parent.ApiID; id != "-" {
 		op = op.Where("api_id = ?", id)
 	}
@@ -1088,7 +1187,7 @@ func (c *Client) ListApiArtifacts(ctx context.Context, parent names.Api, opts Pa
 	})
 }
 
-func (c *Client) ListProjectArtifacts(ctx context.Context, parent names.Project, opts PageOptions) (ArtifactList, error) {
+func (c *Client) ListProjectArtifacts(ctx context.Context, parent names.ProjectLocation, opts PageOptions) (ArtifactList, error) {
 	token, err := decodeToken(opts.Token)
 	if err != nil {
 		return ArtifactList{}, status.Errorf(codes.InvalidArgument, "invalid page token %q: %s", opts.Token, err.Error())
@@ -1113,10 +1212,13 @@ func (c *Client) ListProjectArtifacts(ctx context.Context, parent names.Project,
 		Where(`spec_id = ''`)
 	if id := parent.ProjectID; id != "-" {
 		op = op.Where("project_id = ?", id)
-		if _, err := c.GetProject(ctx, parent); err != nil {
+		if _, err := c.GetProject(ctx, parent.Project()); err != nil {
 			return ArtifactList{}, err
 		}
 	}
+	if id := parent.LocationID; id != "-" {
+		op = op.Where("location_id = ?", locationID(id))
+	}
 
 	return c.listArtifacts(ctx, op, opts, func(a *models.Artifact) bool {
 		return a.ProjectID != ""
@@ -1142,6 +1244,8 @@ func (c *Client) listArtifacts(ctx context.Context, op *gorm.DB, opts PageOption
 		op = op.Order(order)
 	}
 
+	op = selectFields(op, &models.Artifact{}, opts)
+
 	response := ArtifactList{
 		Artifacts: make([]models.Artifact, 0, opts.Size),
 	}
@@ -1187,6 +1291,7 @@ func artifactMap(artifact models.Artifact) map[string]interface{} {
 	return map[string]interface{}{
 		"name":        artifact.Name(),
 		"project_id":  artifact.ProjectID,
+		"location_id": artifact.LocationID,
 		"api_id":      artifact.ApiID,
 		"version_id":  artifact.VersionID,
 		"spec_id":     artifact.SpecID,
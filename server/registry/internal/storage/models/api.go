@@ -27,6 +27,7 @@ import (
 type Api struct {
 	Key                   string    `gorm:"primaryKey"`
 	ProjectID             string    // Uniquely identifies a project.
+	LocationID            string    `gorm:"default:global"` // Identifies a location within a project.
 	ApiID                 string    // Uniquely identifies an api within a project.
 	DisplayName           string    // A human-friendly name.
 	Description           string    // A detailed description.
@@ -44,6 +45,7 @@ func NewApi(name names.Api, body *rpc.Api) (api *Api, err error) {
 	now := time.Now().Round(time.Microsecond)
 	api = &Api{
 		ProjectID:             name.ProjectID,
+		LocationID:            locationID(name.LocationID),
 		ApiID:                 name.ApiID,
 		Description:           body.GetDescription(),
 		DisplayName:           body.GetDisplayName(),
@@ -70,8 +72,9 @@ func NewApi(name names.Api, body *rpc.Api) (api *Api, err error) {
 // Name returns the resource name of the api.
 func (api *Api) Name() string {
 	return names.Api{
-		ProjectID: api.ProjectID,
-		ApiID:     api.ApiID,
+		ProjectID:  api.ProjectID,
+		LocationID: api.LocationID,
+		ApiID:      api.ApiID,
 	}.String()
 }
 
@@ -16,7 +16,6 @@ package models
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/apigee/registry/rpc"
@@ -30,6 +29,7 @@ import (
 type Artifact struct {
 	Key          string    `gorm:"primaryKey"`
 	ProjectID    string    // Project associated with artifact (required).
+	LocationID   string    `gorm:"default:global"` // Identifies a location within a project.
 	ApiID        string    // Api associated with artifact (if appropriate).
 	VersionID    string    // Version associated with artifact (if appropriate).
 	SpecID       string    // Spec associated with artifact (if appropriate).
@@ -47,6 +47,7 @@ func NewArtifact(name names.Artifact, body *rpc.Artifact) (artifact *Artifact, e
 	now := time.Now().Round(time.Microsecond)
 	artifact = &Artifact{
 		ProjectID:    name.ProjectID(),
+		LocationID:   locationID(name.LocationID()),
 		ApiID:        name.ApiID(),
 		VersionID:    name.VersionID(),
 		SpecID:       name.SpecID(),
@@ -58,16 +59,10 @@ func NewArtifact(name names.Artifact, body *rpc.Artifact) (artifact *Artifact, e
 	}
 
 	if body.GetContents() != nil {
-		contents := body.GetContents()
-		// if contents are gzipped, uncompress before computing size and hash.
-		if strings.Contains(artifact.MimeType, "+gzip") && len(contents) > 0 {
-			contents, err = GUnzippedBytes(contents)
-			if err != nil {
-				return nil, status.Error(codes.InvalidArgument, err.Error())
-			}
+		artifact.SizeInBytes, artifact.Hash, err = sizeAndHash(artifact.MimeType, body.GetContents())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
-		artifact.SizeInBytes = int32(len(contents))
-		artifact.Hash = hashForBytes(contents)
 	}
 
 	return artifact, nil
@@ -78,19 +73,19 @@ func (artifact *Artifact) Name() string {
 	switch {
 	case artifact.SpecID != "":
 		return fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/specs/%s/artifacts/%s",
-			artifact.ProjectID, names.Location, artifact.ApiID, artifact.VersionID, artifact.SpecID, artifact.ArtifactID)
+			artifact.ProjectID, locationID(artifact.LocationID), artifact.ApiID, artifact.VersionID, artifact.SpecID, artifact.ArtifactID)
 	case artifact.VersionID != "":
 		return fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/artifacts/%s",
-			artifact.ProjectID, names.Location, artifact.ApiID, artifact.VersionID, artifact.ArtifactID)
+			artifact.ProjectID, locationID(artifact.LocationID), artifact.ApiID, artifact.VersionID, artifact.ArtifactID)
 	case artifact.DeploymentID != "":
 		return fmt.Sprintf("projects/%s/locations/%s/apis/%s/deployments/%s/artifacts/%s",
-			artifact.ProjectID, names.Location, artifact.ApiID, artifact.DeploymentID, artifact.ArtifactID)
+			artifact.ProjectID, locationID(artifact.LocationID), artifact.ApiID, artifact.DeploymentID, artifact.ArtifactID)
 	case artifact.ApiID != "":
 		return fmt.Sprintf("projects/%s/locations/%s/apis/%s/artifacts/%s",
-			artifact.ProjectID, names.Location, artifact.ApiID, artifact.ArtifactID)
+			artifact.ProjectID, locationID(artifact.LocationID), artifact.ApiID, artifact.ArtifactID)
 	case artifact.ProjectID != "":
 		return fmt.Sprintf("projects/%s/locations/%s/artifacts/%s",
-			artifact.ProjectID, names.Location, artifact.ArtifactID)
+			artifact.ProjectID, locationID(artifact.LocationID), artifact.ArtifactID)
 	default:
 		return "UNKNOWN"
 	}
@@ -0,0 +1,89 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AuditEvent is the storage-side representation of an audit event.
+// Audit events are not owned by the projects that they describe,
+// so they are kept when projects are deleted.
+type AuditEvent struct {
+	Key        string    `gorm:"primaryKey"` // A unique identifier of the event.
+	ProjectID  string    // Project of the changed resource.
+	Method     string    // Name of the method that made the change.
+	Resource   string    // Name of the changed resource.
+	Principal  string    // Identity of the caller.
+	RequestID  string    // Identifier of the request.
+	CreateTime time.Time // Time of the change.
+	Changes    []byte    // JSON-encoded list of field changes.
+}
+
+// FieldChange is the storage-side representation of the change of a single field.
+type FieldChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"old,omitempty"`
+	NewValue string `json:"new,omitempty"`
+}
+
+// NewAuditEvent initializes a new audit event.
+func NewAuditEvent(projectID, method, resource, principal, requestID string, changes []FieldChange) (*AuditEvent, error) {
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditEvent{
+		Key:        uuid.New().String(),
+		ProjectID:  projectID,
+		Method:     method,
+		Resource:   resource,
+		Principal:  principal,
+		RequestID:  requestID,
+		CreateTime: time.Now().Round(time.Microsecond),
+		Changes:    encoded,
+	}, nil
+}
+
+// Message returns a message representing an audit event.
+func (e *AuditEvent) Message() (*rpc.AuditEvent, error) {
+	var changes []FieldChange
+	if len(e.Changes) > 0 {
+		if err := json.Unmarshal(e.Changes, &changes); err != nil {
+			return nil, err
+		}
+	}
+	message := &rpc.AuditEvent{
+		Id:         e.Key,
+		Method:     e.Method,
+		Resource:   e.Resource,
+		Principal:  e.Principal,
+		RequestId:  e.RequestID,
+		CreateTime: timestamppb.New(e.CreateTime),
+	}
+	for _, c := range changes {
+		message.Changes = append(message.Changes, &rpc.AuditEvent_FieldChange{
+			Field:    c.Field,
+			OldValue: c.OldValue,
+			NewValue: c.NewValue,
+		})
+	}
+	return message, nil
+}
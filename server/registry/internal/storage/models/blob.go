@@ -20,6 +20,7 @@ import "time"
 type Blob struct {
 	Key          string    `gorm:"primaryKey"`
 	ProjectID    string    // Uniquely identifies a project.
+	LocationID   string    `gorm:"default:global"` // Identifies a location within a project.
 	ApiID        string    // Uniquely identifies an API within a project.
 	VersionID    string    // Uniquely identifies a version of an API.
 	SpecID       string    // Uniquely identifies a spec of a version.
@@ -38,6 +39,7 @@ func NewBlobForSpec(spec *Spec, contents []byte) *Blob {
 	now := time.Now().Round(time.Microsecond)
 	return &Blob{
 		ProjectID:   spec.ProjectID,
+		LocationID:  spec.LocationID,
 		ApiID:       spec.ApiID,
 		VersionID:   spec.VersionID,
 		SpecID:      spec.SpecID,
@@ -55,6 +57,7 @@ func NewBlobForArtifact(artifact *Artifact, contents []byte) *Blob {
 	now := time.Now().Round(time.Microsecond)
 	return &Blob{
 		ProjectID:    artifact.ProjectID,
+		LocationID:   artifact.LocationID,
 		ApiID:        artifact.ApiID,
 		VersionID:    artifact.VersionID,
 		SpecID:       artifact.SpecID,
@@ -28,6 +28,7 @@ import (
 type Deployment struct {
 	Key                string    `gorm:"primaryKey"`
 	ProjectID          string    // Uniquely identifies a project.
+	LocationID         string    `gorm:"default:global"` // Identifies a location within a project.
 	ApiID              string    // Uniquely identifies an api within a project.
 	DeploymentID       string    // Uniquely identifies a deployment within an api.
 	RevisionID         string    // Uniquely identifies a revision of a deployment.
@@ -50,6 +51,7 @@ func NewDeployment(name names.Deployment, body *rpc.ApiDeployment) (deployment *
 	now := time.Now().Round(time.Microsecond)
 	deployment = &Deployment{
 		ProjectID:          name.ProjectID,
+		LocationID:         locationID(name.LocationID),
 		ApiID:              name.ApiID,
 		DeploymentID:       name.DeploymentID,
 		RevisionID:         newRevisionID(),
@@ -83,6 +85,7 @@ func (s *Deployment) NewRevision() *Deployment {
 	now := time.Now().Round(time.Microsecond)
 	return &Deployment{
 		ProjectID:          s.ProjectID,
+		LocationID:         s.LocationID,
 		ApiID:              s.ApiID,
 		DeploymentID:       s.DeploymentID,
 		RevisionID:         newRevisionID(),
@@ -103,6 +106,7 @@ func (s *Deployment) NewRevision() *Deployment {
 func (s *Deployment) Name() string {
 	return names.Deployment{
 		ProjectID:    s.ProjectID,
+		LocationID:   s.LocationID,
 		ApiID:        s.ApiID,
 		DeploymentID: s.DeploymentID,
 	}.String()
@@ -111,7 +115,7 @@ func (s *Deployment) Name() string {
 // RevisionName generates the resource name of the deployment revision.
 func (s *Deployment) RevisionName() string {
 	return fmt.Sprintf("projects/%s/locations/%s/apis/%s/deployments/%s@%s",
-		s.ProjectID, names.Location, s.ApiID, s.DeploymentID, s.RevisionID)
+		s.ProjectID, locationID(s.LocationID), s.ApiID, s.DeploymentID, s.RevisionID)
 }
 
 // BasicMessage returns the basic view of the deployment resource as an RPC message.
@@ -201,6 +205,7 @@ func (s *Deployment) LabelsMap() (map[string]string, error) {
 type DeploymentRevisionTag struct {
 	Key          string    `gorm:"primaryKey"`
 	ProjectID    string    // Uniquely identifies a project.
+	LocationID   string    `gorm:"default:global"` // Identifies a location within a project.
 	ApiID        string    // Uniquely identifies an api within a project.
 	DeploymentID string    // Uniquely identifies a deployment within an api.
 	RevisionID   string    // Uniquely identifies a revision of a deployment.
@@ -214,6 +219,7 @@ func NewDeploymentRevisionTag(name names.DeploymentRevision, tag string) *Deploy
 	now := time.Now().Round(time.Microsecond)
 	return &DeploymentRevisionTag{
 		ProjectID:    name.ProjectID,
+		LocationID:   locationID(name.LocationID),
 		ApiID:        name.ApiID,
 		DeploymentID: name.DeploymentID,
 		RevisionID:   name.RevisionID,
@@ -225,5 +231,11 @@ func NewDeploymentRevisionTag(name names.DeploymentRevision, tag string) *Deploy
 
 func (t *DeploymentRevisionTag) String() string {
 	return fmt.Sprintf("projects/%s/locations/%s/apis/%s/deployments/%s@%s",
-		t.ProjectID, names.Location, t.ApiID, t.DeploymentID, t.Tag)
+		t.ProjectID, locationID(t.LocationID), t.ApiID, t.DeploymentID, t.Tag)
+}
+
+// RevisionName returns the name of the tagged revision.
+func (t *DeploymentRevisionTag) RevisionName() string {
+	return fmt.Sprintf("projects/%s/locations/%s/apis/%s/deployments/%s@%s",
+		t.ProjectID, locationID(t.LocationID), t.ApiID, t.DeploymentID, t.RevisionID)
 }
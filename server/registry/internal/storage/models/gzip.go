@@ -17,7 +17,10 @@ package models
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
 	"io"
+	"strings"
 )
 
 // GUnzippedBytes uncompresses a slice of bytes.
@@ -29,3 +32,27 @@ func GUnzippedBytes(input []byte) ([]byte, error) {
 	}
 	return io.ReadAll(zr)
 }
+
+// sizeAndHash computes the size and hash of contents, uncompressing them while
+// they are read if the MIME type indicates that they are gzipped.
+// Empty contents have an empty hash.
+func sizeAndHash(mimeType string, contents []byte) (int32, string, error) {
+	var r io.Reader = bytes.NewReader(contents)
+	if strings.Contains(mimeType, "+gzip") && len(contents) > 0 {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return 0, "", err
+		}
+		r = zr
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return 0, "", err
+	}
+	if n == 0 {
+		return 0, "", nil
+	}
+	return int32(n), fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Instance is the storage-side representation of a provisioned registry instance.
+// Instances are kept in the primary database and their resources are kept
+// in separate databases.
+type Instance struct {
+	Key          string    `gorm:"primaryKey"`
+	ProjectID    string    // Project that owns the instance.
+	LocationID   string    // Location of the instance.
+	InstanceID   string    // Uniquely identifies an instance within a location.
+	CreateTime   time.Time // Creation time.
+	UpdateTime   time.Time // Time of last change.
+	State        string    // Provisioning state.
+	StateMessage string    // Details of a failed state change.
+	CmekKeyName  string    // Requested encryption key (recorded but not used).
+	Database     string    // SQLite file or PostgreSQL schema of the instance.
+}
+
+// NewInstance initializes a new resource.
+func NewInstance(name names.Instance, body *rpc.Instance) *Instance {
+	now := time.Now().Round(time.Microsecond)
+	return &Instance{
+		ProjectID:   name.ProjectID,
+		LocationID:  locationID(name.LocationID),
+		InstanceID:  name.InstanceID,
+		CreateTime:  now,
+		UpdateTime:  now,
+		State:       rpc.Instance_CREATING.String(),
+		CmekKeyName: body.GetConfig().GetCmekKeyName(),
+	}
+}
+
+// Name returns the resource name of the instance.
+func (i *Instance) Name() string {
+	return names.Instance{
+		ProjectID:  i.ProjectID,
+		LocationID: i.LocationID,
+		InstanceID: i.InstanceID,
+	}.String()
+}
+
+// Message returns a message representing an instance.
+func (i *Instance) Message() *rpc.Instance {
+	return &rpc.Instance{
+		Name:         i.Name(),
+		CreateTime:   timestamppb.New(i.CreateTime),
+		UpdateTime:   timestamppb.New(i.UpdateTime),
+		State:        rpc.Instance_State(rpc.Instance_State_value[i.State]),
+		StateMessage: i.StateMessage,
+		Config: &rpc.Instance_Config{
+			Location:    i.LocationID,
+			CmekKeyName: i.CmekKeyName,
+		},
+	}
+}
+
+// SetState records a change of the state of an instance.
+func (i *Instance) SetState(state rpc.Instance_State, message string) {
+	i.UpdateTime = time.Now().Round(time.Microsecond)
+	i.State = state.String()
+	i.StateMessage = message
+}
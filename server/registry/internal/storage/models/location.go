@@ -0,0 +1,26 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "github.com/apigee/registry/server/registry/names"
+
+// locationID returns the stored form of a location ID from a resource name,
+// in which an empty location ID refers to the default location.
+func locationID(id string) string {
+	if id == "" {
+		return names.DefaultLocation
+	}
+	return id
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/proto"
+)
+
+// Operation is the storage-side representation of a long-running operation.
+// Operations are kept in the primary database, even when they act on an instance.
+type Operation struct {
+	Key        string    `gorm:"primaryKey"` // Resource name of the operation.
+	Parent     string    // Collection that contains the operation.
+	Method     string    // Name of the method that started the operation.
+	Done       bool      // Whether the operation has finished.
+	CreateTime time.Time // Time the operation was started.
+	UpdateTime time.Time // Time of last change.
+	ExpireTime time.Time // Time after which an unfinished operation is abandoned unless its lease is renewed.
+	Body       []byte    // Serialized google.longrunning.Operation.
+}
+
+// NewOperation initializes a new operation record.
+func NewOperation(parent, method string, op *longrunning.Operation) (*Operation, error) {
+	now := time.Now().Round(time.Microsecond)
+	v := &Operation{
+		Key:        op.GetName(),
+		Parent:     parent,
+		Method:     method,
+		CreateTime: now,
+	}
+	return v, v.Update(op)
+}
+
+// Update replaces the recorded state of the operation.
+func (o *Operation) Update(op *longrunning.Operation) error {
+	b, err := proto.Marshal(op)
+	if err != nil {
+		return err
+	}
+	o.Done = op.GetDone()
+	o.UpdateTime = time.Now().Round(time.Microsecond)
+	o.Body = b
+	return nil
+}
+
+// Abandoned returns true if the operation is unfinished and the server that
+// runs it stopped renewing its lease, for example because it crashed.
+func (o *Operation) Abandoned(now time.Time) bool {
+	return !o.Done && !now.Before(o.ExpireTime)
+}
+
+// Message returns the recorded state of the operation.
+func (o *Operation) Message() (*longrunning.Operation, error) {
+	op := &longrunning.Operation{}
+	if err := proto.Unmarshal(o.Body, op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
@@ -15,9 +15,7 @@
 package models
 
 import (
-	"crypto/sha256"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/apigee/registry/rpc"
@@ -33,6 +31,7 @@ import (
 type Spec struct {
 	Key                string    `gorm:"primaryKey"`
 	ProjectID          string    // Uniquely identifies a project.
+	LocationID         string    `gorm:"default:global"` // Identifies a location within a project.
 	ApiID              string    // Uniquely identifies an api within a project.
 	VersionID          string    // Uniquely identifies a version within a api.
 	SpecID             string    // Uniquely identifies a spec within a version.
@@ -55,6 +54,7 @@ func NewSpec(name names.Spec, body *rpc.ApiSpec) (spec *Spec, err error) {
 	now := time.Now().Round(time.Microsecond)
 	spec = &Spec{
 		ProjectID:          name.ProjectID,
+		LocationID:         locationID(name.LocationID),
 		ApiID:              name.ApiID,
 		VersionID:          name.VersionID,
 		SpecID:             name.SpecID,
@@ -79,16 +79,10 @@ func NewSpec(name names.Spec, body *rpc.ApiSpec) (spec *Spec, err error) {
 	}
 
 	if body.GetContents() != nil {
-		contents := body.GetContents()
-		// if contents are gzipped, uncompress before computing size and hash.
-		if strings.Contains(spec.MimeType, "+gzip") && len(contents) > 0 {
-			contents, err = GUnzippedBytes(contents)
-			if err != nil {
-				return nil, status.Error(codes.InvalidArgument, err.Error())
-			}
+		spec.SizeInBytes, spec.Hash, err = sizeAndHash(spec.MimeType, body.GetContents())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
-		spec.SizeInBytes = int32(len(contents))
-		spec.Hash = hashForBytes(contents)
 	}
 
 	return spec, nil
@@ -99,6 +93,7 @@ func (s *Spec) NewRevision() *Spec {
 	now := time.Now().Round(time.Microsecond)
 	return &Spec{
 		ProjectID:          s.ProjectID,
+		LocationID:         s.LocationID,
 		ApiID:              s.ApiID,
 		VersionID:          s.VersionID,
 		SpecID:             s.SpecID,
@@ -118,17 +113,18 @@ func (s *Spec) NewRevision() *Spec {
 // Name returns the resource name of the spec.
 func (s *Spec) Name() string {
 	return names.Spec{
-		ProjectID: s.ProjectID,
-		ApiID:     s.ApiID,
-		VersionID: s.VersionID,
-		SpecID:    s.SpecID,
+		ProjectID:  s.ProjectID,
+		LocationID: s.LocationID,
+		ApiID:      s.ApiID,
+		VersionID:  s.VersionID,
+		SpecID:     s.SpecID,
 	}.String()
 }
 
 // RevisionName generates the resource name of the spec revision.
 func (s *Spec) RevisionName() string {
 	return fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/specs/%s@%s",
-		s.ProjectID, names.Location, s.ApiID, s.VersionID, s.SpecID, s.RevisionID)
+		s.ProjectID, locationID(s.LocationID), s.ApiID, s.VersionID, s.SpecID, s.RevisionID)
 }
 
 // BasicMessage returns the basic view of the spec resource as an RPC message.
@@ -200,18 +196,15 @@ func (s *Spec) Update(message *rpc.ApiSpec, mask *fieldmaskpb.FieldMask) error {
 
 func (s *Spec) updateContents(contents []byte) error {
 	// Compute size and hash using uncompressed spec.
-	if strings.Contains(s.MimeType, "+gzip") && len(contents) > 0 {
-		var err error
-		contents, err = GUnzippedBytes(contents)
-		if err != nil {
-			return status.Error(codes.InvalidArgument, err.Error())
-		}
+	size, hash, err := sizeAndHash(s.MimeType, contents)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if hash := hashForBytes(contents); hash != s.Hash {
+	if hash != s.Hash {
 		s.Hash = hash
 		s.RevisionID = newRevisionID()
-		s.SizeInBytes = int32(len(contents))
+		s.SizeInBytes = size
 
 		now := time.Now().Round(time.Microsecond)
 		s.RevisionCreateTime = now
@@ -231,21 +224,11 @@ func newRevisionID() string {
 	return s[len(s)-8:]
 }
 
-func hashForBytes(b []byte) string {
-	if len(b) == 0 {
-		return ""
-	}
-
-	h := sha256.New()
-	_, _ = h.Write(b)
-	bs := h.Sum(nil)
-	return fmt.Sprintf("%x", bs)
-}
-
 // SpecRevisionTag is the storage-side representation of a spec revision tag.
 type SpecRevisionTag struct {
 	Key        string    `gorm:"primaryKey"`
 	ProjectID  string    // Uniquely identifies a project.
+	LocationID string    `gorm:"default:global"` // Identifies a location within a project.
 	ApiID      string    // Uniquely identifies an api within a project.
 	VersionID  string    // Uniquely identifies a version within a api.
 	SpecID     string    // Uniquely identifies a spec within a version.
@@ -260,6 +243,7 @@ func NewSpecRevisionTag(name names.SpecRevision, tag string) *SpecRevisionTag {
 	now := time.Now().Round(time.Microsecond)
 	return &SpecRevisionTag{
 		ProjectID:  name.ProjectID,
+		LocationID: locationID(name.LocationID),
 		ApiID:      name.ApiID,
 		VersionID:  name.VersionID,
 		SpecID:     name.SpecID,
@@ -272,5 +256,11 @@ func NewSpecRevisionTag(name names.SpecRevision, tag string) *SpecRevisionTag {
 
 func (t *SpecRevisionTag) String() string {
 	return fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/specs/%s@%s",
-		t.ProjectID, names.Location, t.ApiID, t.VersionID, t.SpecID, t.Tag)
+		t.ProjectID, locationID(t.LocationID), t.ApiID, t.VersionID, t.SpecID, t.Tag)
+}
+
+// RevisionName returns the name of the tagged revision.
+func (t *SpecRevisionTag) RevisionName() string {
+	return fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/specs/%s@%s",
+		t.ProjectID, locationID(t.LocationID), t.ApiID, t.VersionID, t.SpecID, t.RevisionID)
 }
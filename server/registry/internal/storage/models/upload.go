@@ -0,0 +1,29 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// UploadChunk is the storage-side representation of part of a pending upload.
+// Chunks are saved as they are received so that interrupted uploads can be
+// resumed, and are deleted when the upload is finished.
+type UploadChunk struct {
+	UploadID    string    `gorm:"primaryKey"` // Uniquely identifies an upload.
+	WriteOffset int64     `gorm:"primaryKey"` // Offset of the chunk in the upload.
+	Key         string    // Name of the uploaded resource.
+	ProjectID   string    // Project associated with the upload.
+	Data        []byte    // The contents of the chunk.
+	CreateTime  time.Time // Creation time.
+}
@@ -27,6 +27,7 @@ import (
 type Version struct {
 	Key         string    `gorm:"primaryKey"`
 	ProjectID   string    // Uniquely identifies a project.
+	LocationID  string    `gorm:"default:global"` // Identifies a location within a project.
 	ApiID       string    // Uniquely identifies an api within a project.
 	VersionID   string    // Uniquely identifies a version wihtin a api.
 	DisplayName string    // A human-friendly name.
@@ -43,6 +44,7 @@ func NewVersion(name names.Version, body *rpc.ApiVersion) (version *Version, err
 	now := time.Now().Round(time.Microsecond)
 	version = &Version{
 		ProjectID:   name.ProjectID,
+		LocationID:  locationID(name.LocationID),
 		ApiID:       name.ApiID,
 		VersionID:   name.VersionID,
 		Description: body.GetDescription(),
@@ -68,9 +70,10 @@ func NewVersion(name names.Version, body *rpc.ApiVersion) (version *Version, err
 // Name returns the resource name of the version.
 func (v *Version) Name() string {
 	return names.Version{
-		ProjectID: v.ProjectID,
-		ApiID:     v.ApiID,
-		VersionID: v.VersionID,
+		ProjectID:  v.ProjectID,
+		LocationID: v.LocationID,
+		ApiID:      v.ApiID,
+		VersionID:  v.VersionID,
 	}.String()
 }
 
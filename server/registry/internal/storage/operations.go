@@ -0,0 +1,179 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/apigee/registry/server/registry/internal/storage/filtering"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+var operationFields = map[string]filtering.FieldType{
+	"name":        filtering.String,
+	"method":      filtering.String,
+	"done":        filtering.Bool,
+	"create_time": filtering.Timestamp,
+	"update_time": filtering.Timestamp,
+}
+
+func (c *Client) CreateOperation(ctx context.Context, v *models.Operation) error {
+	return c.create(ctx, v)
+}
+
+func (c *Client) GetOperation(ctx context.Context, name string) (*models.Operation, error) {
+	v := new(models.Operation)
+	if err := c.db.WithContext(ctx).Take(v, "key = ?", name).Error; err == gorm.ErrRecordNotFound {
+		return nil, status.Errorf(codes.NotFound, "%q not found in database", name)
+	} else if err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+
+	return v, nil
+}
+
+func (c *Client) SaveOperation(ctx context.Context, v *models.Operation) error {
+	return c.save(ctx, v)
+}
+
+// RenewOperation extends the lease of an unfinished operation.
+func (c *Client) RenewOperation(ctx context.Context, name string, expireTime time.Time) error {
+	err := c.db.WithContext(ctx).Model(&models.Operation{}).
+		Where("key = ?", name).
+		Where("done = ?", false).
+		Update("expire_time", expireTime).Error
+	return grpcErrorForDBError(ctx, err)
+}
+
+// FinishAbandonedOperation saves the final state of an abandoned operation unless
+// the operation was finished or its lease was renewed. It returns true if it was saved.
+// Operations recorded before leases were added have no expiration time.
+func (c *Client) FinishAbandonedOperation(ctx context.Context, v *models.Operation, now time.Time) (bool, error) {
+	op := c.db.WithContext(ctx).Model(&models.Operation{}).
+		Where("key = ?", v.Key).
+		Where("done = ?", false).
+		Where("expire_time IS NULL OR expire_time <= ?", now).
+		Updates(map[string]interface{}{
+			"done":        v.Done,
+			"update_time": v.UpdateTime,
+			"body":        v.Body,
+		})
+	return op.RowsAffected > 0, grpcErrorForDBError(ctx, op.Error)
+}
+
+// ListAbandonedOperations returns the unfinished operations whose leases expired before a time.
+func (c *Client) ListAbandonedOperations(ctx context.Context, now time.Time) ([]models.Operation, error) {
+	var v []models.Operation
+	err := c.db.WithContext(ctx).
+		Where("done = ?", false).
+		Where("expire_time IS NULL OR expire_time <= ?", now).
+		Find(&v).Error
+	return v, grpcErrorForDBError(ctx, err)
+}
+
+func (c *Client) DeleteOperation(ctx context.Context, name string) error {
+	op := c.db.WithContext(ctx).Where("key = ?", name)
+	if err := op.Delete(models.Operation{}).Error; err != nil {
+		return grpcErrorForDBError(ctx, err)
+	} else if op.RowsAffected == 0 {
+		return status.Errorf(codes.NotFound, "%q not found in database", name)
+	}
+	return nil
+}
+
+// OperationList contains a page of operations.
+type OperationList struct {
+	Operations []models.Operation
+	Token      string
+}
+
+// ListOperations lists the operations in a collection, or all operations if parent is empty.
+func (c *Client) ListOperations(ctx context.Context, parent string, opts PageOptions) (OperationList, error) {
+	token, err := decodeToken(opts.Token)
+	if err != nil {
+		return OperationList{}, status.Errorf(codes.InvalidArgument, "invalid page token %q: %s", opts.Token, err.Error())
+	}
+
+	if err := token.ValidateFilter(opts.Filter); err != nil {
+		return OperationList{}, status.Errorf(codes.InvalidArgument, "invalid filter %q: %s", opts.Filter, err)
+	} else {
+		token.Filter = opts.Filter
+	}
+
+	op := c.db.WithContext(ctx).
+		Order("create_time,key").
+		Limit(limit(opts))
+
+	if parent != "" {
+		op = op.Where("parent = ?", parent)
+	}
+
+	filter, err := filtering.NewFilter(opts.Filter, operationFields)
+	if err != nil {
+		return OperationList{}, err
+	}
+
+	response := OperationList{
+		Operations: make([]models.Operation, 0, opts.Size),
+	}
+
+	for {
+		var page []models.Operation
+		err := op.Offset(token.Offset).Find(&page).Error
+
+		if err != nil {
+			return OperationList{}, grpcErrorForDBError(ctx, err)
+		} else if len(page) == 0 {
+			break
+		}
+
+		for _, v := range page {
+			match, err := filter.Matches(operationMap(v))
+			if err != nil {
+				return OperationList{}, err
+			} else if !match {
+				token.Offset++
+				continue
+			}
+
+			if len(response.Operations) == int(opts.Size) {
+				response.Token, err = encodeToken(token)
+				if err != nil {
+					return OperationList{}, status.Error(codes.Internal, err.Error())
+				}
+				return response, nil
+			}
+
+			token.Offset++
+			response.Operations = append(response.Operations, v)
+		}
+	}
+
+	return response, nil
+}
+
+func operationMap(o models.Operation) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        o.Key,
+		"method":      o.Method,
+		"done":        o.Done,
+		"create_time": o.CreateTime,
+		"update_time": o.UpdateTime,
+	}
+}
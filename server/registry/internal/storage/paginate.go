@@ -34,6 +34,9 @@ type PageOptions struct {
 	// If specified, listing will continue from the end of the previous page. Otherwise,
 	// the first page in a listing series will be returned.
 	Token string
+	// Fields optionally names the fields of the listed resources that are needed.
+	// If specified, only the columns needed to read these fields are loaded.
+	Fields []string
 }
 
 // token contains information to share between sequential page iterators.
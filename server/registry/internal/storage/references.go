@@ -0,0 +1,260 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Reference describes a field of one resource that refers to another resource.
+type Reference struct {
+	Name  string // The name of the referencing resource.
+	Field string // The name of the referencing field.
+	Value string // The value of the referencing field.
+}
+
+// referenceMatcher returns a prefix shared by all references to a resource
+// and a function that decides whether a (lowercased) reference refers to it.
+func (c *Client) referenceMatcher(ctx context.Context, name names.Name) (string, func(string) bool, error) {
+	switch n := name.(type) {
+	case names.SpecRevision:
+		n, err := c.unwrapSpecRevisionTag(ctx, n)
+		if err != nil {
+			return "", nil, err
+		}
+		values := map[string]bool{n.String(): true}
+		var tags []models.SpecRevisionTag
+		op := c.db.WithContext(ctx).
+			Where("project_id = ?", n.ProjectID).
+			Where("location_id = ?", locationID(n.LocationID)).
+			Where("api_id = ?", n.ApiID).
+			Where("version_id = ?", n.VersionID).
+			Where("spec_id = ?", n.SpecID).
+			Where("revision_id = ?", n.RevisionID)
+		if err := op.Find(&tags).Error; err != nil {
+			return "", nil, grpcErrorForDBError(ctx, err)
+		}
+		for _, t := range tags {
+			values[strings.ToLower(t.String())] = true
+		}
+		return n.Spec().String() + "@", func(v string) bool { return values[v] }, nil
+	case names.DeploymentRevision:
+		n, err := c.unwrapDeploymentRevisionTag(ctx, n)
+		if err != nil {
+			return "", nil, err
+		}
+		values := map[string]bool{n.String(): true}
+		var tags []models.DeploymentRevisionTag
+		op := c.db.WithContext(ctx).
+			Where("project_id = ?", n.ProjectID).
+			Where("location_id = ?", locationID(n.LocationID)).
+			Where("api_id = ?", n.ApiID).
+			Where("deployment_id = ?", n.DeploymentID).
+			Where("revision_id = ?", n.RevisionID)
+		if err := op.Find(&tags).Error; err != nil {
+			return "", nil, grpcErrorForDBError(ctx, err)
+		}
+		for _, t := range tags {
+			values[strings.ToLower(t.String())] = true
+		}
+		return n.Deployment().String() + "@", func(v string) bool { return values[v] }, nil
+	default:
+		// References to a resource or to any of its children or revisions.
+		target := strings.ToLower(name.String())
+		return target, func(v string) bool {
+			return v == target || strings.HasPrefix(v, target+"/") || strings.HasPrefix(v, target+"@")
+		}, nil
+	}
+}
+
+// referenceProject returns the ID of the project that contains a resource.
+func referenceProject(name names.Name) string {
+	switch n := name.(type) {
+	case names.Project:
+		return n.ProjectID
+	case names.SpecRevision:
+		return n.ProjectID
+	case names.DeploymentRevision:
+		return n.ProjectID
+	case interface{ Project() names.Project }:
+		return n.Project().ProjectID
+	}
+	return ""
+}
+
+// ListReferences returns the references to a resource or to any of its children.
+// Only references held by resources in the same project are considered, and
+// only the current revision of each deployment is considered to be a reference.
+func (c *Client) ListReferences(ctx context.Context, name names.Name) ([]Reference, error) {
+	prefix, match, err := c.referenceMatcher(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := c.listReferences(ctx, referenceProject(name), prefix)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]Reference, 0, len(refs))
+	for _, ref := range refs {
+		if match(strings.ToLower(ref.Value)) {
+			matched = append(matched, ref)
+		}
+	}
+	return matched, nil
+}
+
+// ListProjectReferences returns all of the references held by resources in a
+// project, including references to other projects. Only the current revision
+// of each deployment is considered to hold references.
+func (c *Client) ListProjectReferences(ctx context.Context, name names.Project) ([]Reference, error) {
+	refs, err := c.listReferences(ctx, name.ProjectID, "")
+	if err != nil {
+		return nil, err
+	}
+	held := make([]Reference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Value != "" {
+			held = append(held, ref)
+		}
+	}
+	return held, nil
+}
+
+// listReferences returns the reference fields of resources in a project whose
+// (lowercased) values begin with a prefix, ordered by name and field.
+func (c *Client) listReferences(ctx context.Context, project, prefix string) ([]Reference, error) {
+	refs := make([]Reference, 0)
+	var apis []models.Api
+	op := c.db.WithContext(ctx).
+		Where("project_id = ?", project).
+		Where("LOWER(recommended_version) LIKE ? OR LOWER(recommended_deployment) LIKE ?", prefix+"%", prefix+"%")
+	if err := op.Find(&apis).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	for _, api := range apis {
+		if v := api.RecommendedVersion; strings.HasPrefix(strings.ToLower(v), prefix) {
+			refs = append(refs, Reference{Name: api.Name(), Field: "recommended_version", Value: v})
+		}
+		if v := api.RecommendedDeployment; strings.HasPrefix(strings.ToLower(v), prefix) {
+			refs = append(refs, Reference{Name: api.Name(), Field: "recommended_deployment", Value: v})
+		}
+	}
+
+	// Earlier revisions are historical records, not live references,
+	// so only the current revision of each deployment is selected.
+	var deployments []models.Deployment
+	op = c.db.WithContext(ctx).Select("deployments.*").
+		Table("deployments").
+		Joins("JOIN (?) AS grp ON deployments.project_id = grp.project_id AND deployments.location_id = grp.location_id AND deployments.api_id = grp.api_id AND deployments.deployment_id = grp.deployment_id AND deployments.revision_create_time = grp.recent_create_time",
+			c.db.WithContext(ctx).Select("project_id, location_id, api_id, deployment_id, MAX(revision_create_time) AS recent_create_time").
+				Table("deployments").
+				Where("project_id = ?", project).
+				Group("project_id, location_id, api_id, deployment_id")).
+		Where("deployments.project_id = ?", project).
+		Where("LOWER(deployments.api_spec_revision) LIKE ?", prefix+"%")
+	if err := op.Find(&deployments).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	for _, d := range deployments {
+		refs = append(refs, Reference{Name: d.Name(), Field: "api_spec_revision", Value: d.ApiSpecRevision})
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].Field < refs[j].Field
+	})
+	return refs, nil
+}
+
+// ListExternalReferences returns the references to a resource or to any of its
+// children that would be left dangling if the resource were deleted.
+// References held by the resource itself or by its children are excluded.
+func (c *Client) ListExternalReferences(ctx context.Context, name names.Name) ([]Reference, error) {
+	refs, err := c.ListReferences(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := strings.ToLower(name.String())
+	switch name.(type) {
+	case names.SpecRevision, names.DeploymentRevision:
+		// Revisions don't own other resources.
+		owner = ""
+	}
+
+	external := make([]Reference, 0, len(refs))
+	for _, ref := range refs {
+		if owner != "" && (ref.Name == owner || strings.HasPrefix(ref.Name, owner+"/")) {
+			continue
+		}
+		external = append(external, ref)
+	}
+	return external, nil
+}
+
+// ClearReferences resets the referencing fields of a list of references.
+// Clearing the spec revision of a deployment creates a new deployment revision.
+func (c *Client) ClearReferences(ctx context.Context, refs []Reference) error {
+	for _, ref := range refs {
+		switch ref.Field {
+		case "recommended_version", "recommended_deployment":
+			name, err := names.ParseApi(ref.Name)
+			if err != nil {
+				return err
+			}
+			api, err := c.GetApi(ctx, name)
+			if err != nil {
+				return err
+			}
+			if ref.Field == "recommended_version" {
+				api.RecommendedVersion = ""
+			} else {
+				api.RecommendedDeployment = ""
+			}
+			api.UpdateTime = time.Now().Round(time.Microsecond)
+			mask := &fieldmaskpb.FieldMask{Paths: []string{ref.Field, "update_time"}}
+			if err := c.SaveApi(ctx, api, mask); err != nil {
+				return err
+			}
+		case "api_spec_revision":
+			name, err := names.ParseDeployment(ref.Name)
+			if err != nil {
+				return err
+			}
+			deployment, err := c.GetDeployment(ctx, name)
+			if err != nil {
+				return err
+			}
+			mask := &fieldmaskpb.FieldMask{Paths: []string{ref.Field}}
+			if err := deployment.Update(&rpc.ApiDeployment{}, mask); err != nil {
+				return err
+			}
+			if err := c.SaveDeploymentRevision(ctx, deployment); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
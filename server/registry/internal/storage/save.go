@@ -95,8 +95,15 @@ func (c *Client) save(ctx context.Context, v interface{}) error {
 }
 
 func (c *Client) saveWithMask(ctx context.Context, v interface{}, fieldMask *fieldmaskpb.FieldMask) error {
+	// Every update changes the update time, whether or not it is in the mask,
+	// and none change the create time, even with a "*" mask.
+	// The paths are copied so that the caller's mask is never modified.
+	paths := make([]string, 0, len(fieldMask.GetPaths())+1)
+	paths = append(paths, fieldMask.GetPaths()...)
+	paths = append(paths, "update_time")
 	op := c.db.WithContext(ctx).
-		Select(fieldMask.GetPaths()).
+		Select(paths).
+		Omit("create_time").
 		Clauses(clause.Returning{})
 	err := op.Save(v).Error
 	if err == nil && op.RowsAffected == 0 {
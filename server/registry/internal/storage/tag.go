@@ -16,6 +16,7 @@ package storage
 
 import (
 	"context"
+	"strings"
 
 	"github.com/apigee/registry/server/registry/internal/storage/models"
 	"github.com/apigee/registry/server/registry/names"
@@ -43,3 +44,59 @@ func (c *Client) unwrapDeploymentRevisionTag(ctx context.Context, name names.Dep
 
 	return name.Deployment().Revision(v.RevisionID), nil
 }
+
+// ListSpecRevisionTags returns the tags of the specified spec revisions.
+func (c *Client) ListSpecRevisionTags(ctx context.Context, revisions []models.Spec) ([]*models.SpecRevisionTag, error) {
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	// Revision IDs are only unique within a spec, so revisions are grouped by spec.
+	var specs []models.Spec
+	ids := make(map[string][]string)
+	for _, r := range revisions {
+		name := r.Name()
+		if _, ok := ids[name]; !ok {
+			specs = append(specs, r)
+		}
+		ids[name] = append(ids[name], r.RevisionID)
+	}
+	conditions := make([]string, 0, len(specs))
+	args := make([]interface{}, 0, 6*len(specs))
+	for _, s := range specs {
+		conditions = append(conditions, "(project_id = ? AND location_id = ? AND api_id = ? AND version_id = ? AND spec_id = ? AND revision_id IN ?)")
+		args = append(args, s.ProjectID, s.LocationID, s.ApiID, s.VersionID, s.SpecID, ids[s.Name()])
+	}
+	var v []*models.SpecRevisionTag
+	if err := c.db.WithContext(ctx).Order("key").Where(strings.Join(conditions, " OR "), args...).Find(&v).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	return v, nil
+}
+
+// ListDeploymentRevisionTags returns the tags of the specified deployment revisions.
+func (c *Client) ListDeploymentRevisionTags(ctx context.Context, revisions []models.Deployment) ([]*models.DeploymentRevisionTag, error) {
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	// Revision IDs are only unique within a deployment, so revisions are grouped by deployment.
+	var deployments []models.Deployment
+	ids := make(map[string][]string)
+	for _, r := range revisions {
+		name := r.Name()
+		if _, ok := ids[name]; !ok {
+			deployments = append(deployments, r)
+		}
+		ids[name] = append(ids[name], r.RevisionID)
+	}
+	conditions := make([]string, 0, len(deployments))
+	args := make([]interface{}, 0, 5*len(deployments))
+	for _, d := range deployments {
+		conditions = append(conditions, "(project_id = ? AND location_id = ? AND api_id = ? AND deployment_id = ? AND revision_id IN ?)")
+		args = append(args, d.ProjectID, d.LocationID, d.ApiID, d.DeploymentID, ids[d.Name()])
+	}
+	var v []*models.DeploymentRevisionTag
+	if err := c.db.WithContext(ctx).Order("key").Where(strings.Join(conditions, " OR "), args...).Find(&v).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// SaveUploadChunk saves part of a pending upload.
+func (c *Client) SaveUploadChunk(ctx context.Context, v *models.UploadChunk) error {
+	return c.save(ctx, v)
+}
+
+// GetUploadSize returns the number of bytes that have been saved for a pending upload.
+func (c *Client) GetUploadSize(ctx context.Context, uploadID string) (int64, error) {
+	var size int64
+	err := c.db.WithContext(ctx).Model(&models.UploadChunk{}).
+		Where("upload_id = ?", uploadID).
+		Select("COALESCE(SUM(LENGTH(data)), 0)").
+		Scan(&size).Error
+	if err != nil {
+		return 0, grpcErrorForDBError(ctx, err)
+	}
+	return size, nil
+}
+
+// GetUploadContents returns the contents of a pending upload.
+// Chunks are read one at a time into a buffer of the size of the upload,
+// so that the contents are only held in memory once.
+func (c *Client) GetUploadContents(ctx context.Context, uploadID string) ([]byte, error) {
+	size, err := c.GetUploadSize(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.db.WithContext(ctx).Model(&models.UploadChunk{}).
+		Select("data").
+		Where("upload_id = ?", uploadID).
+		Order("write_offset").
+		Rows()
+	if err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	defer rows.Close()
+
+	contents := make([]byte, 0, size)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, grpcErrorForDBError(ctx, err)
+		}
+		contents = append(contents, data...)
+	}
+	return contents, grpcErrorForDBError(ctx, rows.Err())
+}
+
+// GetUploadChunk returns the saved chunk of a pending upload that contains the specified offset.
+func (c *Client) GetUploadChunk(ctx context.Context, uploadID string, offset int64) (*models.UploadChunk, error) {
+	v := new(models.UploadChunk)
+	err := c.db.WithContext(ctx).
+		Where("upload_id = ?", uploadID).
+		Where("write_offset <= ?", offset).
+		Order("write_offset DESC").
+		Take(v).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, status.Errorf(codes.NotFound, "no chunk of upload %q contains offset %d", uploadID, offset)
+	} else if err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	return v, nil
+}
+
+// DeleteUpload deletes all saved parts of a pending upload.
+func (c *Client) DeleteUpload(ctx context.Context, uploadID string) error {
+	err := c.db.WithContext(ctx).
+		Where("upload_id = ?", uploadID).
+		Delete(&models.UploadChunk{}).Error
+	return grpcErrorForDBError(ctx, err)
+}
+
+// DeleteExpiredUploads deletes pending uploads that haven't received a chunk
+// since the specified time and returns the number of deleted chunks.
+func (c *Client) DeleteExpiredUploads(ctx context.Context, before time.Time) (int64, error) {
+	expired := c.db.Model(&models.UploadChunk{}).
+		Select("upload_id").
+		Group("upload_id").
+		Having("MAX(create_time) < ?", before)
+	op := c.db.WithContext(ctx).
+		Where("upload_id IN (?)", expired).
+		Delete(&models.UploadChunk{})
+	return op.RowsAffected, grpcErrorForDBError(ctx, op.Error)
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+)
+
+// ProjectUsage describes the resources used by a project.
+type ProjectUsage struct {
+	ProjectID            string
+	ApiCount             int64 // The number of APIs.
+	MaxSpecRevisionCount int64 // The largest number of revisions of any spec.
+	BlobBytes            int64 // The total size of all blobs and pending uploads.
+}
+
+// CountApis returns the number of APIs in a project.
+func (c *Client) CountApis(ctx context.Context, projectID string) (int64, error) {
+	var count int64
+	err := c.db.WithContext(ctx).Model(&models.Api{}).
+		Where("project_id = ?", projectID).
+		Count(&count).Error
+	return count, grpcErrorForDBError(ctx, err)
+}
+
+// CountSpecRevisions returns the number of revisions of a spec.
+func (c *Client) CountSpecRevisions(ctx context.Context, name names.Spec) (int64, error) {
+	var count int64
+	err := c.db.WithContext(ctx).Model(&models.Spec{}).
+		Where("project_id = ?", name.ProjectID).
+		Where("location_id = ?", locationID(name.LocationID)).
+		Where("api_id = ?", name.ApiID).
+		Where("version_id = ?", name.VersionID).
+		Where("spec_id = ?", name.SpecID).
+		Count(&count).Error
+	return count, grpcErrorForDBError(ctx, err)
+}
+
+// MaxSpecRevisionCount returns the largest number of revisions of any spec in a project.
+func (c *Client) MaxSpecRevisionCount(ctx context.Context, projectID string) (int64, error) {
+	var count int64
+	perSpec := c.db.Model(&models.Spec{}).
+		Select("COUNT(*) AS value").
+		Where("project_id = ?", projectID).
+		Group("location_id, api_id, version_id, spec_id")
+	err := c.db.WithContext(ctx).Table("(?) AS spec_counts", perSpec).
+		Select("COALESCE(MAX(value), 0)").
+		Scan(&count).Error
+	return count, grpcErrorForDBError(ctx, err)
+}
+
+// ProjectBlobBytes returns the total size of the blobs and pending uploads of a project,
+// excluding the blob with the specified key, which is about to be replaced.
+func (c *Client) ProjectBlobBytes(ctx context.Context, projectID, replacedKey string) (int64, error) {
+	var blobs int64
+	err := c.db.WithContext(ctx).Model(&models.Blob{}).
+		Select("COALESCE(SUM(size_in_bytes), 0)").
+		Where("project_id = ?", projectID).
+		Where("key <> ?", replacedKey).
+		Scan(&blobs).Error
+	if err != nil {
+		return 0, grpcErrorForDBError(ctx, err)
+	}
+	var uploads int64
+	err = c.db.WithContext(ctx).Model(&models.UploadChunk{}).
+		Select("COALESCE(SUM(LENGTH(data)), 0)").
+		Where("project_id = ?", projectID).
+		Scan(&uploads).Error
+	return blobs + uploads, grpcErrorForDBError(ctx, err)
+}
+
+// ListProjectUsage returns the usage of every project, ordered by project ID.
+func (c *Client) ListProjectUsage(ctx context.Context) ([]ProjectUsage, error) {
+	var projects []string
+	if err := c.db.WithContext(ctx).Model(&models.Project{}).Order("project_id").Pluck("project_id", &projects).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+
+	type count struct {
+		ProjectID string
+		Value     int64
+	}
+	apis := make([]count, 0)
+	if err := c.db.WithContext(ctx).Model(&models.Api{}).
+		Select("project_id, COUNT(*) AS value").
+		Group("project_id").
+		Scan(&apis).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	revisions := make([]count, 0)
+	perSpec := c.db.Model(&models.Spec{}).
+		Select("project_id, COUNT(*) AS value").
+		Group("project_id, location_id, api_id, version_id, spec_id")
+	if err := c.db.WithContext(ctx).Table("(?) AS spec_counts", perSpec).
+		Select("project_id, MAX(value) AS value").
+		Group("project_id").
+		Scan(&revisions).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	blobs := make([]count, 0)
+	if err := c.db.WithContext(ctx).Model(&models.Blob{}).
+		Select("project_id, SUM(size_in_bytes) AS value").
+		Group("project_id").
+		Scan(&blobs).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+	uploads := make([]count, 0)
+	if err := c.db.WithContext(ctx).Model(&models.UploadChunk{}).
+		Select("project_id, SUM(LENGTH(data)) AS value").
+		Group("project_id").
+		Scan(&uploads).Error; err != nil {
+		return nil, grpcErrorForDBError(ctx, err)
+	}
+
+	usage := make([]ProjectUsage, len(projects))
+	index := make(map[string]*ProjectUsage, len(projects))
+	for i, id := range projects {
+		usage[i].ProjectID = id
+		index[id] = &usage[i]
+	}
+	for _, c := range apis {
+		if u, ok := index[c.ProjectID]; ok {
+			u.ApiCount = c.Value
+		}
+	}
+	for _, c := range revisions {
+		if u, ok := index[c.ProjectID]; ok {
+			u.MaxSpecRevisionCount = c.Value
+		}
+	}
+	for _, c := range blobs {
+		if u, ok := index[c.ProjectID]; ok {
+			u.BlobBytes = c.Value
+		}
+	}
+	for _, c := range uploads {
+		if u, ok := index[c.ProjectID]; ok {
+			u.BlobBytes += c.Value
+		}
+	}
+	return usage, nil
+}
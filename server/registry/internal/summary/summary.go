@@ -0,0 +1,272 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package summary extracts standard metadata from spec contents.
+package summary
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	discovery "github.com/google/gnostic/discovery"
+	oas2 "github.com/google/gnostic/openapiv2"
+	oas3 "github.com/google/gnostic/openapiv3"
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// Supported returns true if specs with a MIME type can be summarized.
+func Supported(mimeType string) bool {
+	return isOpenAPIv2(mimeType) || isOpenAPIv3(mimeType) || isDiscovery(mimeType) || isProto(mimeType)
+}
+
+// Summarize extracts metadata from spec contents according to their MIME type.
+// It returns nil if the MIME type is unsupported and an error if the contents
+// can't be parsed.
+func Summarize(mimeType string, contents []byte) (*rpc.SpecSummary, error) {
+	if !Supported(mimeType) {
+		return nil, nil
+	}
+
+	if strings.Contains(mimeType, "+gzip") {
+		var err error
+		contents, err = models.GUnzippedBytes(contents)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	summary := &rpc.SpecSummary{MimeType: mimeType}
+	if !strings.Contains(mimeType, "+zip") {
+		if err := summarizeFile(summary, mimeType, contents); err != nil {
+			return nil, err
+		}
+		return summary, nil
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || !isSpecFile(mimeType, f.Name) {
+			continue
+		}
+		b, err := readFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if err := summarizeFile(summary, mimeType, b); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(summary.Packages)
+	sort.Strings(summary.Services)
+	return summary, nil
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// isSpecFile returns true if a file in an archive should be summarized.
+// Only protobuf archives are expected to contain multiple files, so other
+// archives are summarized from the first file that matches their type.
+func isSpecFile(mimeType, name string) bool {
+	switch ext := strings.ToLower(filepath.Ext(name)); {
+	case isProto(mimeType):
+		return ext == ".proto"
+	case isDiscovery(mimeType):
+		return ext == ".json"
+	default:
+		return ext == ".yaml" || ext == ".yml" || ext == ".json"
+	}
+}
+
+func summarizeFile(summary *rpc.SpecSummary, mimeType string, b []byte) error {
+	switch {
+	case isProto(mimeType):
+		return summarizeProto(summary, b)
+	case summary.GetTitle() != "" || summary.GetOperationCount() > 0:
+		// Non-protobuf archives are summarized from their first document.
+		return nil
+	case isOpenAPIv2(mimeType):
+		return summarizeOpenAPIv2(summary, b)
+	case isOpenAPIv3(mimeType):
+		return summarizeOpenAPIv3(summary, b)
+	case isDiscovery(mimeType):
+		return summarizeDiscovery(summary, b)
+	}
+	return nil
+}
+
+func summarizeOpenAPIv2(summary *rpc.SpecSummary, b []byte) error {
+	doc, err := oas2.ParseDocument(b)
+	if err != nil {
+		return err
+	}
+	if info := doc.GetInfo(); info != nil {
+		summary.Title = info.GetTitle()
+		summary.Description = info.GetDescription()
+		summary.Version = info.GetVersion()
+	}
+	if host := doc.GetHost(); host != "" {
+		schemes := doc.GetSchemes()
+		if len(schemes) == 0 {
+			schemes = []string{"https"}
+		}
+		for _, scheme := range schemes {
+			summary.Servers = append(summary.Servers, scheme+"://"+host+doc.GetBasePath())
+		}
+	}
+	for _, tag := range doc.GetTags() {
+		summary.Tags = append(summary.Tags, tag.GetName())
+	}
+	for _, path := range doc.GetPaths().GetPath() {
+		item := path.GetValue()
+		summary.OperationCount += count(item.GetGet(), item.GetPut(), item.GetPost(), item.GetDelete(),
+			item.GetOptions(), item.GetHead(), item.GetPatch())
+	}
+	return nil
+}
+
+func summarizeOpenAPIv3(summary *rpc.SpecSummary, b []byte) error {
+	doc, err := oas3.ParseDocument(b)
+	if err != nil {
+		return err
+	}
+	if info := doc.GetInfo(); info != nil {
+		summary.Title = info.GetTitle()
+		summary.Description = info.GetDescription()
+		summary.Version = info.GetVersion()
+	}
+	for _, server := range doc.GetServers() {
+		summary.Servers = append(summary.Servers, server.GetUrl())
+	}
+	for _, tag := range doc.GetTags() {
+		summary.Tags = append(summary.Tags, tag.GetName())
+	}
+	for _, path := range doc.GetPaths().GetPath() {
+		item := path.GetValue()
+		summary.OperationCount += count(item.GetGet(), item.GetPut(), item.GetPost(), item.GetDelete(),
+			item.GetOptions(), item.GetHead(), item.GetPatch(), item.GetTrace())
+	}
+	return nil
+}
+
+// count returns the number of non-nil operations.
+func count[T any](operations ...*T) int32 {
+	var n int32
+	for _, op := range operations {
+		if op != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func summarizeDiscovery(summary *rpc.SpecSummary, b []byte) error {
+	doc, err := discovery.ParseDocument(b)
+	if err != nil {
+		return err
+	}
+	summary.Name = doc.GetName()
+	summary.Title = doc.GetTitle()
+	summary.Description = doc.GetDescription()
+	summary.Version = doc.GetVersion()
+	if root := doc.GetRootUrl(); root != "" {
+		summary.Servers = append(summary.Servers, root)
+	}
+	summary.OperationCount = int32(len(doc.GetMethods().GetAdditionalProperties())) +
+		countDiscoveryMethods(doc.GetResources())
+	return nil
+}
+
+func countDiscoveryMethods(resources *discovery.Resources) int32 {
+	var n int32
+	for _, r := range resources.GetAdditionalProperties() {
+		n += int32(len(r.GetValue().GetMethods().GetAdditionalProperties()))
+		n += countDiscoveryMethods(r.GetValue().GetResources())
+	}
+	return n
+}
+
+func summarizeProto(summary *rpc.SpecSummary, b []byte) error {
+	p, err := protoparser.Parse(bytes.NewReader(b), protoparser.WithPermissive(true))
+	if err != nil {
+		return err
+	}
+	pkg := ""
+	for _, v := range p.ProtoBody {
+		if x, ok := v.(*parser.Package); ok {
+			pkg = x.Name
+			if !contains(summary.Packages, pkg) {
+				summary.Packages = append(summary.Packages, pkg)
+			}
+		}
+	}
+	for _, v := range p.ProtoBody {
+		x, ok := v.(*parser.Service)
+		if !ok {
+			continue
+		}
+		service := x.ServiceName
+		if pkg != "" {
+			service = pkg + "." + service
+		}
+		summary.Services = append(summary.Services, service)
+		for _, e := range x.ServiceBody {
+			if _, ok := e.(*parser.RPC); ok {
+				summary.OperationCount++
+			}
+		}
+	}
+	return nil
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isOpenAPIv2(mimeType string) bool {
+	return strings.Contains(mimeType, "openapi") && strings.Contains(mimeType, "version=2")
+}
+
+func isOpenAPIv3(mimeType string) bool {
+	return strings.Contains(mimeType, "openapi") && strings.Contains(mimeType, "version=3")
+}
+
+func isDiscovery(mimeType string) bool {
+	return strings.Contains(mimeType, "discovery")
+}
+
+func isProto(mimeType string) bool {
+	return strings.Contains(mimeType, "protobuf")
+}
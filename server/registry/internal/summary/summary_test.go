@@ -0,0 +1,266 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package summary
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+const (
+	openapiv3 = `openapi: 3.0.0
+info:
+  title: Sample
+  description: A sample API.
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+tags:
+  - name: items
+paths:
+  /items:
+    get:
+      responses:
+        "200":
+          description: OK
+    post:
+      responses:
+        "200":
+          description: OK
+  /items/{id}:
+    delete:
+      responses:
+        "200":
+          description: OK
+`
+	openapiv2 = `swagger: "2.0"
+info:
+  title: Sample
+  version: 1.0.0
+host: api.example.com
+basePath: /v1
+schemes: [https]
+tags:
+  - name: items
+paths:
+  /items:
+    get:
+      responses:
+        "200":
+          description: OK
+`
+	discoveryDoc = `{
+  "kind": "discovery#restDescription",
+  "discoveryVersion": "v1",
+  "name": "sample",
+  "title": "Sample API",
+  "version": "v1",
+  "rootUrl": "https://sample.googleapis.com/",
+  "methods": {
+    "ping": {"id": "sample.ping"}
+  },
+  "resources": {
+    "items": {
+      "methods": {
+        "get": {"id": "sample.items.get"},
+        "list": {"id": "sample.items.list"}
+      },
+      "resources": {
+        "parts": {
+          "methods": {
+            "get": {"id": "sample.items.parts.get"}
+          }
+        }
+      }
+    }
+  }
+}`
+	itemProto = `syntax = "proto3";
+
+package sample.v1;
+
+service Items {
+  rpc GetItem(Item) returns (Item);
+  rpc ListItems(Item) returns (Item);
+}
+
+message Item {
+  string name = 1;
+}
+`
+	adminProto = `syntax = "proto3";
+
+package sample.v1;
+
+service Admin {
+  rpc GetStatus(Status) returns (Status);
+}
+
+message Status {}
+`
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		desc     string
+		mimeType string
+		contents []byte
+		want     *rpc.SpecSummary
+	}{
+		{
+			desc:     "openapi v3",
+			mimeType: "application/x.openapi;version=3",
+			contents: []byte(openapiv3),
+			want: &rpc.SpecSummary{
+				MimeType:       "application/x.openapi;version=3",
+				Title:          "Sample",
+				Description:    "A sample API.",
+				Version:        "1.0.0",
+				Servers:        []string{"https://api.example.com/v1"},
+				Tags:           []string{"items"},
+				OperationCount: 3,
+			},
+		},
+		{
+			desc:     "gzipped openapi v2",
+			mimeType: "application/x.openapi+gzip;version=2",
+			contents: gzipped(t, openapiv2),
+			want: &rpc.SpecSummary{
+				MimeType:       "application/x.openapi+gzip;version=2",
+				Title:          "Sample",
+				Version:        "1.0.0",
+				Servers:        []string{"https://api.example.com/v1"},
+				Tags:           []string{"items"},
+				OperationCount: 1,
+			},
+		},
+		{
+			desc:     "discovery",
+			mimeType: "application/x.discovery",
+			contents: []byte(discoveryDoc),
+			want: &rpc.SpecSummary{
+				MimeType:       "application/x.discovery",
+				Name:           "sample",
+				Title:          "Sample API",
+				Version:        "v1",
+				Servers:        []string{"https://sample.googleapis.com/"},
+				OperationCount: 4,
+			},
+		},
+		{
+			desc:     "protos",
+			mimeType: "application/x.protobuf+zip",
+			contents: zipped(t, map[string]string{
+				"sample/v1/item.proto":  itemProto,
+				"sample/v1/admin.proto": adminProto,
+				"README.md":             "# Sample",
+			}),
+			want: &rpc.SpecSummary{
+				MimeType:       "application/x.protobuf+zip",
+				Packages:       []string{"sample.v1"},
+				Services:       []string{"sample.v1.Admin", "sample.v1.Items"},
+				OperationCount: 3,
+			},
+		},
+		{
+			desc:     "unsupported",
+			mimeType: "text/plain",
+			contents: []byte(openapiv3),
+			want:     nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := Summarize(test.mimeType, test.contents)
+			if err != nil {
+				t.Fatalf("Summarize(%q) returned error: %s", test.mimeType, err)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Summarize(%q) returned unexpected diff (-want +got):\n%s", test.mimeType, diff)
+			}
+		})
+	}
+}
+
+func TestSummarizeErrors(t *testing.T) {
+	tests := []struct {
+		desc     string
+		mimeType string
+		contents []byte
+	}{
+		{
+			desc:     "invalid gzip",
+			mimeType: "application/x.openapi+gzip;version=3",
+			contents: []byte(openapiv3),
+		},
+		{
+			desc:     "invalid zip",
+			mimeType: "application/x.protobuf+zip",
+			contents: []byte(itemProto),
+		},
+		{
+			desc:     "invalid openapi",
+			mimeType: "application/x.openapi;version=3",
+			contents: []byte("openapi: 3.0.0\ninfo:\n\ttitle: Sample\n"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, err := Summarize(test.mimeType, test.contents); err == nil {
+				t.Errorf("Summarize(%q) returned %+v, want error", test.mimeType, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validation checks that spec contents can be parsed according to
+// their MIME types.
+package validation
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/gnostic/compiler"
+	discovery "github.com/google/gnostic/discovery"
+	oas2 "github.com/google/gnostic/openapiv2"
+	oas3 "github.com/google/gnostic/openapiv3"
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// Problem describes a reason that a spec is invalid.
+type Problem struct {
+	File    string // For archives, the name of the file containing the problem.
+	Line    int    // The line of the problem, starting at 1, or 0 if unknown.
+	Column  int    // The column of the problem, starting at 1, or 0 if unknown.
+	Message string // A description of the problem.
+}
+
+func (p Problem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location += fmt.Sprintf(":%d:%d", p.Line, p.Column)
+	}
+	if location == "" {
+		return p.Message
+	}
+	return strings.TrimPrefix(location, ":") + ": " + p.Message
+}
+
+// Error is returned for specs that fail validation.
+type Error struct {
+	Problems []Problem
+}
+
+func (e *Error) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Supported returns true if specs with a MIME type can be validated.
+func Supported(mimeType string) bool {
+	return isOpenAPIv2(mimeType) || isOpenAPIv3(mimeType) || isDiscovery(mimeType) || isProto(mimeType)
+}
+
+// ValidateSpec parses spec contents according to their MIME type.
+// It returns an *Error if the contents are invalid. Contents with
+// unsupported MIME types are not checked. If limit is positive, compressed
+// contents are invalid if they uncompress to more than limit bytes.
+func ValidateSpec(mimeType string, contents []byte, limit int64) error {
+	if !Supported(mimeType) {
+		return nil
+	}
+
+	if strings.Contains(mimeType, "+gzip") {
+		zr, err := gzip.NewReader(bytes.NewReader(contents))
+		if err != nil {
+			return &Error{Problems: []Problem{{Message: fmt.Sprintf("invalid gzip contents: %s", err)}}}
+		}
+		if contents, err = readLimited(zr, limit); err != nil {
+			return &Error{Problems: []Problem{{Message: fmt.Sprintf("invalid gzip contents: %s", err)}}}
+		}
+	}
+
+	if !strings.Contains(mimeType, "+zip") {
+		if problems := validateFile(mimeType, "", contents); len(problems) > 0 {
+			return &Error{Problems: problems}
+		}
+		return nil
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+	if err != nil {
+		return &Error{Problems: []Problem{{Message: fmt.Sprintf("invalid zip archive: %s", err)}}}
+	}
+	// The limit applies to the total size of the files that are read.
+	remaining := limit
+	var problems []Problem
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || !isSpecFile(mimeType, f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			problems = append(problems, Problem{File: f.Name, Message: err.Error()})
+			continue
+		}
+		b, err := readLimited(rc, remaining)
+		rc.Close()
+		if err != nil {
+			return &Error{Problems: append(problems, Problem{File: f.Name, Message: err.Error()})}
+		}
+		if limit > 0 {
+			remaining -= int64(len(b))
+		}
+		problems = append(problems, validateFile(mimeType, f.Name, b)...)
+	}
+	if len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	return nil
+}
+
+// readLimited reads all of r, returning an error if limit is positive and
+// there are more than limit bytes to read.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("uncompressed contents exceed the limit of %d bytes", limit)
+	}
+	return b, nil
+}
+
+// isSpecFile returns true if a file in an archive should be validated.
+func isSpecFile(mimeType, name string) bool {
+	switch ext := strings.ToLower(filepath.Ext(name)); {
+	case isProto(mimeType):
+		return ext == ".proto"
+	case isDiscovery(mimeType):
+		return ext == ".json"
+	default:
+		return ext == ".yaml" || ext == ".yml" || ext == ".json"
+	}
+}
+
+func validateFile(mimeType, name string, b []byte) []Problem {
+	var err error
+	switch {
+	case isOpenAPIv2(mimeType):
+		_, err = oas2.ParseDocument(b)
+	case isOpenAPIv3(mimeType):
+		_, err = oas3.ParseDocument(b)
+	case isDiscovery(mimeType):
+		_, err = discovery.ParseDocument(b)
+	case isProto(mimeType):
+		_, err = protoparser.Parse(bytes.NewReader(b),
+			protoparser.WithPermissive(true),
+			protoparser.WithFilename(filepath.Base(name)))
+		if err != nil {
+			return []Problem{protoProblem(name, err)}
+		}
+	}
+	if err != nil {
+		return documentProblems(name, err)
+	}
+	return nil
+}
+
+// yamlLine matches the line numbers in errors returned by the YAML parser.
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// documentProblems converts errors returned by gnostic parsers.
+func documentProblems(name string, err error) []Problem {
+	var group *compiler.ErrorGroup
+	if errors.As(err, &group) {
+		var problems []Problem
+		for _, e := range group.Errors {
+			problems = append(problems, documentProblems(name, e)...)
+		}
+		return problems
+	}
+
+	var cerr *compiler.Error
+	if errors.As(err, &cerr) {
+		p := Problem{File: name, Message: cerr.Message}
+		if cerr.Context != nil {
+			p.Message = cerr.Context.Description() + " " + cerr.Message
+			if cerr.Context.Node != nil {
+				p.Line, p.Column = cerr.Context.Node.Line, cerr.Context.Node.Column
+			}
+		}
+		return []Problem{p}
+	}
+
+	if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return []Problem{{File: name, Line: line, Column: 1, Message: m[2]}}
+	}
+	return []Problem{{File: name, Message: err.Error()}}
+}
+
+// protoMessage matches the first error in messages returned by the protobuf
+// parser and the position of the unexpected token in it.
+var protoMessage = regexp.MustCompile(`found "((?:[^"\\]|\\.)*)" but expected \[([^\]]*)\]`)
+var protoPosition = regexp.MustCompile(`Pos=[^)]*:(\d+):(\d+)\)$`)
+
+// protoProblem converts errors returned by the protobuf parser.
+// Most errors are *meta.Error values, but errors in some statements are
+// combined by unexported types that can't be unwrapped, so the first error
+// in their messages is used.
+func protoProblem(name string, err error) Problem {
+	var merr *meta.Error
+	if errors.As(err, &merr) {
+		return Problem{
+			File:    name,
+			Line:    merr.Pos.Line,
+			Column:  merr.Pos.Column,
+			Message: fmt.Sprintf("found %q but expected [%s]", merr.Found, merr.Expected),
+		}
+	}
+	p := Problem{File: name, Message: err.Error()}
+	m := protoMessage.FindStringSubmatch(err.Error())
+	if m == nil {
+		return p
+	}
+	found, uerr := strconv.Unquote(`"` + m[1] + `"`)
+	if uerr != nil {
+		return p
+	}
+	p.Message = fmt.Sprintf("found %q but expected [%s]", found, m[2])
+	if pos := protoPosition.FindStringSubmatch(found); pos != nil {
+		p.Line, _ = strconv.Atoi(pos[1])
+		p.Column, _ = strconv.Atoi(pos[2])
+	}
+	return p
+}
+
+func isOpenAPIv2(mimeType string) bool {
+	return strings.Contains(mimeType, "openapi") && strings.Contains(mimeType, "version=2")
+}
+
+func isOpenAPIv3(mimeType string) bool {
+	return strings.Contains(mimeType, "openapi") && strings.Contains(mimeType, "version=3")
+}
+
+func isDiscovery(mimeType string) bool {
+	return strings.Contains(mimeType, "discovery")
+}
+
+func isProto(mimeType string) bool {
+	return strings.Contains(mimeType, "protobuf")
+}
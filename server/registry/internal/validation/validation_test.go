@@ -0,0 +1,234 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const (
+	openapiv3 = `openapi: 3.0.0
+info:
+  title: Sample
+  version: 1.0.0
+paths: {}
+`
+	invalidOpenapiv3 = `openapi: 3.0.0
+info:
+  title: Sample
+  version: 1.0.0
+paths:
+  /items:
+    get: 7
+`
+	invalidYAML = "openapi: 3.0.0\ninfo:\n\ttitle: Sample\n"
+	openapiv2   = `swagger: "2.0"
+info:
+  title: Sample
+  version: 1.0.0
+paths: {}
+`
+	discoveryDoc = `{
+  "kind": "discovery#restDescription",
+  "discoveryVersion": "v1",
+  "name": "sample",
+  "version": "v1"
+}`
+	protoFile = `syntax = "proto3";
+
+package sample.v1;
+
+message Item {
+  string name = 1;
+}
+`
+	invalidProtoFile = `syntax = "proto3";
+
+package sample.v1;
+
+message Item {
+  string name = 1
+}
+`
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	// Files are written in order, so problems are reported in order.
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidateSpec(t *testing.T) {
+	tests := []struct {
+		desc     string
+		mimeType string
+		contents []byte
+		limit    int64
+		want     []Problem
+	}{
+		{
+			desc:     "valid openapi v3",
+			mimeType: "application/x.openapi;version=3",
+			contents: []byte(openapiv3),
+		},
+		{
+			desc:     "valid gzipped openapi v3",
+			mimeType: "application/x.openapi+gzip;version=3",
+			contents: gzipped(t, openapiv3),
+		},
+		{
+			desc:     "valid openapi v2",
+			mimeType: "application/x.openapi;version=2",
+			contents: []byte(openapiv2),
+		},
+		{
+			desc:     "valid discovery",
+			mimeType: "application/x.discovery",
+			contents: []byte(discoveryDoc),
+		},
+		{
+			desc:     "valid protos",
+			mimeType: "application/x.protobuf+zip",
+			contents: zipped(t, map[string]string{"sample/v1/item.proto": protoFile, "README.md": "# Sample"}),
+		},
+		{
+			desc:     "unsupported type",
+			mimeType: "text/plain",
+			contents: []byte(invalidYAML),
+		},
+		{
+			desc:     "invalid yaml",
+			mimeType: "application/x.openapi;version=3",
+			contents: []byte(invalidYAML),
+			want:     []Problem{{Line: 3, Column: 1}},
+		},
+		{
+			desc:     "invalid openapi v3",
+			mimeType: "application/x.openapi;version=3",
+			contents: []byte(invalidOpenapiv3),
+			want:     []Problem{{Line: 7, Column: 10}},
+		},
+		{
+			desc:     "invalid gzip",
+			mimeType: "application/x.openapi+gzip;version=3",
+			contents: []byte(openapiv3),
+			want:     []Problem{{}},
+		},
+		{
+			desc:     "invalid zip",
+			mimeType: "application/x.protobuf+zip",
+			contents: []byte(protoFile),
+			want:     []Problem{{}},
+		},
+		{
+			desc:     "invalid protos",
+			mimeType: "application/x.protobuf+zip",
+			contents: zipped(t, map[string]string{"sample/v1/item.proto": invalidProtoFile}),
+			want:     []Problem{{File: "sample/v1/item.proto", Line: 7, Column: 1}},
+		},
+		{
+			desc:     "invalid proto statement",
+			mimeType: "application/x.protobuf+zip",
+			contents: zipped(t, map[string]string{"sample/v1/item.proto": "syntax = \"proto3\";\nmessage Item {\n  reserved x;\n}\n"}),
+			want:     []Problem{{File: "sample/v1/item.proto", Line: 3, Column: 12}},
+		},
+		{
+			desc:     "gzipped contents within limit",
+			mimeType: "application/x.openapi+gzip;version=3",
+			contents: gzipped(t, openapiv3),
+			limit:    int64(len(openapiv3)),
+		},
+		{
+			desc:     "gzipped contents over limit",
+			mimeType: "application/x.openapi+gzip;version=3",
+			contents: gzipped(t, openapiv3),
+			limit:    int64(len(openapiv3)) - 1,
+			want:     []Problem{{}},
+		},
+		{
+			desc:     "zipped contents over limit",
+			mimeType: "application/x.protobuf+zip",
+			contents: zipped(t, map[string]string{"a.proto": protoFile, "b.proto": protoFile}),
+			limit:    int64(len(protoFile)) + 1,
+			want:     []Problem{{File: "b.proto"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := ValidateSpec(test.mimeType, test.contents, test.limit)
+			if test.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateSpec(%q) returned error: %s", test.mimeType, err)
+				}
+				return
+			}
+
+			var verr *Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateSpec(%q) returned %v, want validation error", test.mimeType, err)
+			}
+			opts := cmpopts.IgnoreFields(Problem{}, "Message")
+			if !cmp.Equal(test.want, verr.Problems, opts) {
+				t.Errorf("ValidateSpec(%q) returned unexpected diff (-want +got):\n%s", test.mimeType, cmp.Diff(test.want, verr.Problems, opts))
+			}
+			for _, p := range verr.Problems {
+				if p.Message == "" {
+					t.Errorf("ValidateSpec(%q) returned problem without a message: %+v", test.mimeType, p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLocations(t *testing.T) {
+	ctx := context.Background()
+	server := defaultTestServer(t)
+	if err := seeder.SeedRegistry(ctx, server,
+		&rpc.ApiSpec{Name: "projects/my-project/locations/us/apis/a/versions/v1/specs/s", Contents: []byte("us")},
+		&rpc.ApiSpec{Name: "projects/my-project/locations/eu/apis/a/versions/v1/specs/s", Contents: []byte("eu")},
+		&rpc.Artifact{Name: "projects/my-project/locations/eu/artifacts/x"},
+	); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+
+	listApis := func(parent string) []string {
+		t.Helper()
+		resp, err := server.ListApis(ctx, &rpc.ListApisRequest{Parent: parent})
+		if err != nil {
+			t.Fatalf("ListApis(%q) returned error: %s", parent, err)
+		}
+		var names []string
+		for _, api := range resp.GetApis() {
+			names = append(names, api.GetName())
+		}
+		return names
+	}
+	tests := []struct {
+		parent string
+		want   []string
+	}{
+		{"projects/my-project/locations/us", []string{"projects/my-project/locations/us/apis/a"}},
+		{"projects/my-project/locations/eu", []string{"projects/my-project/locations/eu/apis/a"}},
+		{"projects/my-project/locations/global", nil},
+		{"projects/my-project/locations/-", []string{
+			"projects/my-project/locations/eu/apis/a",
+			"projects/my-project/locations/us/apis/a",
+		}},
+	}
+	for _, test := range tests {
+		if diff := cmp.Diff(test.want, listApis(test.parent)); diff != "" {
+			t.Errorf("ListApis(%q) returned unexpected names (-want +got):\n%s", test.parent, diff)
+		}
+	}
+
+	spec, err := server.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{
+		Name: "projects/my-project/locations/eu/apis/a/versions/v1/specs/s",
+	})
+	if err != nil {
+		t.Fatalf("GetApiSpecContents() returned error: %s", err)
+	}
+	if got := string(spec.GetData()); got != "eu" {
+		t.Errorf("GetApiSpecContents() returned %q, want %q", got, "eu")
+	}
+	specs, err := server.ListApiSpecs(ctx, &rpc.ListApiSpecsRequest{
+		Parent: "projects/my-project/locations/-/apis/a/versions/v1",
+	})
+	if err != nil {
+		t.Fatalf("ListApiSpecs() returned error: %s", err)
+	}
+	if got := len(specs.GetApiSpecs()); got != 2 {
+		t.Errorf("ListApiSpecs() in all locations returned %d specs, want 2", got)
+	}
+	for parent, want := range map[string]int{
+		"projects/my-project/locations/us": 0,
+		"projects/my-project/locations/eu": 1,
+		"projects/my-project/locations/-":  1,
+	} {
+		artifacts, err := server.ListArtifacts(ctx, &rpc.ListArtifactsRequest{Parent: parent})
+		if err != nil {
+			t.Fatalf("ListArtifacts(%q) returned error: %s", parent, err)
+		}
+		if got := len(artifacts.GetArtifacts()); got != want {
+			t.Errorf("ListArtifacts(%q) returned %d artifacts, want %d", parent, got, want)
+		}
+	}
+
+	if _, err := server.DeleteApi(ctx, &rpc.DeleteApiRequest{
+		Name:  "projects/my-project/locations/us/apis/a",
+		Force: true,
+	}); err != nil {
+		t.Fatalf("DeleteApi() returned error: %s", err)
+	}
+	if _, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{
+		Name: "projects/my-project/locations/eu/apis/a/versions/v1/specs/s",
+	}); err != nil {
+		t.Errorf("GetApiSpec() in another location returned error after DeleteApi(): %s", err)
+	}
+
+	_, err = server.CreateApi(ctx, &rpc.CreateApiRequest{
+		Parent: "projects/my-project/locations/-",
+		ApiId:  "b",
+		Api:    &rpc.Api{},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateApi() in all locations returned status code %q, want %q: %v", status.Code(err), codes.InvalidArgument, err)
+	}
+}
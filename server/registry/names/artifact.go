@@ -17,20 +17,21 @@ package names
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 var (
-	projectArtifactCollectionRegexp    = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/artifacts$", identifier, Location))
-	apiArtifactCollectionRegexp        = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/artifacts$", identifier, Location, identifier))
-	versionArtifactCollectionRegexp    = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/artifacts$", identifier, Location, identifier, identifier))
-	specArtifactCollectionRegexp       = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/specs/%s/artifacts$", identifier, Location, identifier, identifier, identifier))
-	deploymentArtifactCollectionRegexp = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/deployments/%s/artifacts$", identifier, Location, identifier, identifier))
-
-	projectArtifactRegexp    = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/artifacts/%s$", identifier, Location, identifier))
-	apiArtifactRegexp        = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/artifacts/%s$", identifier, Location, identifier, identifier))
-	versionArtifactRegexp    = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/artifacts/%s$", identifier, Location, identifier, identifier, identifier))
-	specArtifactRegexp       = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/specs/%s/artifacts/%s$", identifier, Location, identifier, identifier, identifier, identifier))
-	deploymentArtifactRegexp = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/deployments/%s/artifacts/%s$", identifier, Location, identifier, identifier, identifier))
+	projectArtifactCollectionRegexp    = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/artifacts$", identifier, identifier))
+	apiArtifactCollectionRegexp        = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/artifacts$", identifier, identifier, identifier))
+	versionArtifactCollectionRegexp    = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/artifacts$", identifier, identifier, identifier, identifier))
+	specArtifactCollectionRegexp       = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/specs/%s/artifacts$", identifier, identifier, identifier, identifier, identifier))
+	deploymentArtifactCollectionRegexp = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/deployments/%s/artifacts$", identifier, identifier, identifier, identifier))
+
+	projectArtifactRegexp    = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/artifacts/%s$", identifier, identifier, identifier))
+	apiArtifactRegexp        = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/artifacts/%s$", identifier, identifier, identifier, identifier))
+	versionArtifactRegexp    = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/artifacts/%s$", identifier, identifier, identifier, identifier, identifier))
+	specArtifactRegexp       = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/specs/%s/artifacts/%s$", identifier, identifier, identifier, identifier, identifier, identifier))
+	deploymentArtifactRegexp = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/deployments/%s/artifacts/%s$", identifier, identifier, identifier, identifier, identifier))
 )
 
 // Artifact represents a resource name for an artifact.
@@ -59,6 +60,24 @@ func (a Artifact) ProjectID() string {
 	}
 }
 
+// LocationID returns the artifact's location ID, or empty string if it doesn't have one.
+func (a Artifact) LocationID() string {
+	switch name := a.name.(type) {
+	case projectArtifact:
+		return name.LocationID
+	case apiArtifact:
+		return name.LocationID
+	case versionArtifact:
+		return name.LocationID
+	case specArtifact:
+		return name.LocationID
+	case deploymentArtifact:
+		return name.LocationID
+	default:
+		return ""
+	}
+}
+
 // ApiID returns the artifact's API ID, or empty string if it doesn't have one.
 func (a Artifact) ApiID() string {
 	switch name := a.name.(type) {
@@ -125,6 +144,13 @@ func (a Artifact) ArtifactID() string {
 	}
 }
 
+// Reserved returns true if the artifact's ID is reserved for artifacts that are
+// maintained by the server. Reserved IDs begin with a ".", so they can't be
+// created by users.
+func (a Artifact) Reserved() bool {
+	return strings.HasPrefix(a.ArtifactID(), ".")
+}
+
 // Validate returns an error if the resource name is invalid.
 // For backward compatibility, names should only be validated at creation time.
 func (a Artifact) Validate() error {
@@ -201,6 +227,7 @@ func ParseArtifactCollection(name string) (Artifact, error) {
 
 type projectArtifact struct {
 	ProjectID  string
+	LocationID string
 	ArtifactID string
 }
 
@@ -209,16 +236,20 @@ func (a projectArtifact) Validate() error {
 		return fmt.Errorf("invalid project artifact name %q: must match %q", name, projectArtifactRegexp)
 	}
 
+	if err := validateID(location(a.LocationID)); err != nil {
+		return err
+	}
+
 	return validateID(a.ArtifactID)
 }
 
 func (a projectArtifact) Parent() string {
-	return fmt.Sprintf("projects/%s/locations/%s", a.ProjectID, Location)
+	return fmt.Sprintf("projects/%s/locations/%s", a.ProjectID, location(a.LocationID))
 }
 
 func (a projectArtifact) String() string {
 	return normalize(fmt.Sprintf("projects/%s/locations/%s/artifacts/%s",
-		a.ProjectID, Location, a.ArtifactID))
+		a.ProjectID, location(a.LocationID), a.ArtifactID))
 }
 
 func parseProjectArtifact(name string) (projectArtifact, error) {
@@ -229,7 +260,8 @@ func parseProjectArtifact(name string) (projectArtifact, error) {
 	m := projectArtifactRegexp.FindStringSubmatch(name)
 	artifact := projectArtifact{
 		ProjectID:  m[1],
-		ArtifactID: m[2],
+		LocationID: m[2],
+		ArtifactID: m[3],
 	}
 
 	return artifact, nil
@@ -243,6 +275,7 @@ func parseProjectArtifactCollection(name string) (projectArtifact, error) {
 	m := projectArtifactCollectionRegexp.FindStringSubmatch(name)
 	artifact := projectArtifact{
 		ProjectID:  m[1],
+		LocationID: m[2],
 		ArtifactID: "",
 	}
 
@@ -251,6 +284,7 @@ func parseProjectArtifactCollection(name string) (projectArtifact, error) {
 
 type apiArtifact struct {
 	ProjectID  string
+	LocationID string
 	ApiID      string
 	ArtifactID string
 }
@@ -265,14 +299,15 @@ func (a apiArtifact) Validate() error {
 
 func (a apiArtifact) Parent() string {
 	return Api{
-		ProjectID: a.ProjectID,
-		ApiID:     a.ApiID,
+		ProjectID:  a.ProjectID,
+		LocationID: a.LocationID,
+		ApiID:      a.ApiID,
 	}.String()
 }
 
 func (a apiArtifact) String() string {
 	return normalize(fmt.Sprintf("projects/%s/locations/%s/apis/%s/artifacts/%s",
-		a.ProjectID, Location, a.ApiID, a.ArtifactID))
+		a.ProjectID, location(a.LocationID), a.ApiID, a.ArtifactID))
 }
 
 func parseApiArtifact(name string) (apiArtifact, error) {
@@ -283,8 +318,9 @@ func parseApiArtifact(name string) (apiArtifact, error) {
 	m := apiArtifactRegexp.FindStringSubmatch(name)
 	artifact := apiArtifact{
 		ProjectID:  m[1],
-		ApiID:      m[2],
-		ArtifactID: m[3],
+		LocationID: m[2],
+		ApiID:      m[3],
+		ArtifactID: m[4],
 	}
 
 	return artifact, nil
@@ -298,7 +334,8 @@ func parseApiArtifactCollection(name string) (apiArtifact, error) {
 	m := apiArtifactCollectionRegexp.FindStringSubmatch(name)
 	artifact := apiArtifact{
 		ProjectID:  m[1],
-		ApiID:      m[2],
+		LocationID: m[2],
+		ApiID:      m[3],
 		ArtifactID: "",
 	}
 
@@ -307,6 +344,7 @@ func parseApiArtifactCollection(name string) (apiArtifact, error) {
 
 type versionArtifact struct {
 	ProjectID  string
+	LocationID string
 	ApiID      string
 	VersionID  string
 	ArtifactID string
@@ -322,15 +360,16 @@ func (a versionArtifact) Validate() error {
 
 func (a versionArtifact) Parent() string {
 	return Version{
-		ProjectID: a.ProjectID,
-		ApiID:     a.ApiID,
-		VersionID: a.VersionID,
+		ProjectID:  a.ProjectID,
+		LocationID: a.LocationID,
+		ApiID:      a.ApiID,
+		VersionID:  a.VersionID,
 	}.String()
 }
 
 func (a versionArtifact) String() string {
 	return normalize(fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/artifacts/%s",
-		a.ProjectID, Location, a.ApiID, a.VersionID, a.ArtifactID))
+		a.ProjectID, location(a.LocationID), a.ApiID, a.VersionID, a.ArtifactID))
 }
 
 func parseVersionArtifact(name string) (versionArtifact, error) {
@@ -341,9 +380,10 @@ func parseVersionArtifact(name string) (versionArtifact, error) {
 	m := versionArtifactRegexp.FindStringSubmatch(name)
 	artifact := versionArtifact{
 		ProjectID:  m[1],
-		ApiID:      m[2],
-		VersionID:  m[3],
-		ArtifactID: m[4],
+		LocationID: m[2],
+		ApiID:      m[3],
+		VersionID:  m[4],
+		ArtifactID: m[5],
 	}
 
 	return artifact, nil
@@ -357,8 +397,9 @@ func parseVersionArtifactCollection(name string) (versionArtifact, error) {
 	m := versionArtifactCollectionRegexp.FindStringSubmatch(name)
 	artifact := versionArtifact{
 		ProjectID:  m[1],
-		ApiID:      m[2],
-		VersionID:  m[3],
+		LocationID: m[2],
+		ApiID:      m[3],
+		VersionID:  m[4],
 		ArtifactID: "",
 	}
 
@@ -367,6 +408,7 @@ func parseVersionArtifactCollection(name string) (versionArtifact, error) {
 
 type specArtifact struct {
 	ProjectID  string
+	LocationID string
 	ApiID      string
 	VersionID  string
 	SpecID     string
@@ -383,16 +425,17 @@ func (a specArtifact) Validate() error {
 
 func (a specArtifact) Parent() string {
 	return Spec{
-		ProjectID: a.ProjectID,
-		ApiID:     a.ApiID,
-		VersionID: a.VersionID,
-		SpecID:    a.SpecID,
+		ProjectID:  a.ProjectID,
+		LocationID: a.LocationID,
+		ApiID:      a.ApiID,
+		VersionID:  a.VersionID,
+		SpecID:     a.SpecID,
 	}.String()
 }
 
 func (a specArtifact) String() string {
 	return normalize(fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/specs/%s/artifacts/%s",
-		a.ProjectID, Location, a.ApiID, a.VersionID, a.SpecID, a.ArtifactID))
+		a.ProjectID, location(a.LocationID), a.ApiID, a.VersionID, a.SpecID, a.ArtifactID))
 }
 
 func parseSpecArtifact(name string) (specArtifact, error) {
@@ -403,10 +446,11 @@ func parseSpecArtifact(name string) (specArtifact, error) {
 	m := specArtifactRegexp.FindStringSubmatch(name)
 	artifact := specArtifact{
 		ProjectID:  m[1],
-		ApiID:      m[2],
-		VersionID:  m[3],
-		SpecID:     m[4],
-		ArtifactID: m[5],
+		LocationID: m[2],
+		ApiID:      m[3],
+		VersionID:  m[4],
+		SpecID:     m[5],
+		ArtifactID: m[6],
 	}
 
 	return artifact, nil
@@ -420,9 +464,10 @@ func parseSpecArtifactCollection(name string) (specArtifact, error) {
 	m := specArtifactCollectionRegexp.FindStringSubmatch(name)
 	artifact := specArtifact{
 		ProjectID:  m[1],
-		ApiID:      m[2],
-		VersionID:  m[3],
-		SpecID:     m[4],
+		LocationID: m[2],
+		ApiID:      m[3],
+		VersionID:  m[4],
+		SpecID:     m[5],
 		ArtifactID: "",
 	}
 
@@ -431,6 +476,7 @@ func parseSpecArtifactCollection(name string) (specArtifact, error) {
 
 type deploymentArtifact struct {
 	ProjectID    string
+	LocationID   string
 	ApiID        string
 	DeploymentID string
 	ArtifactID   string
@@ -447,6 +493,7 @@ func (a deploymentArtifact) Validate() error {
 func (a deploymentArtifact) Parent() string {
 	return Deployment{
 		ProjectID:    a.ProjectID,
+		LocationID:   a.LocationID,
 		ApiID:        a.ApiID,
 		DeploymentID: a.DeploymentID,
 	}.String()
@@ -454,7 +501,7 @@ func (a deploymentArtifact) Parent() string {
 
 func (a deploymentArtifact) String() string {
 	return normalize(fmt.Sprintf("projects/%s/locations/%s/apis/%s/deployments/%s/artifacts/%s",
-		a.ProjectID, Location, a.ApiID, a.DeploymentID, a.ArtifactID))
+		a.ProjectID, location(a.LocationID), a.ApiID, a.DeploymentID, a.ArtifactID))
 }
 
 func parseDeploymentArtifact(name string) (deploymentArtifact, error) {
@@ -465,9 +512,10 @@ func parseDeploymentArtifact(name string) (deploymentArtifact, error) {
 	m := deploymentArtifactRegexp.FindStringSubmatch(name)
 	artifact := deploymentArtifact{
 		ProjectID:    m[1],
-		ApiID:        m[2],
-		DeploymentID: m[3],
-		ArtifactID:   m[4],
+		LocationID:   m[2],
+		ApiID:        m[3],
+		DeploymentID: m[4],
+		ArtifactID:   m[5],
 	}
 
 	return artifact, nil
@@ -481,8 +529,9 @@ func parseDeploymentArtifactCollection(name string) (deploymentArtifact, error)
 	m := deploymentArtifactCollectionRegexp.FindStringSubmatch(name)
 	artifact := deploymentArtifact{
 		ProjectID:    m[1],
-		ApiID:        m[2],
-		DeploymentID: m[3],
+		LocationID:   m[2],
+		ApiID:        m[3],
+		DeploymentID: m[4],
 		ArtifactID:   "",
 	}
 
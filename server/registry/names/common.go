@@ -64,8 +64,21 @@ func normalize(identifier string) string {
 	return strings.ToLower(identifier)
 }
 
-// Location is included in resource names immediately following the project_id.
-const Location = "global"
+// DefaultLocation is the location of resources whose names don't specify one.
+const DefaultLocation = "global"
+
+// Location is the default location.
+//
+// Deprecated: Use DefaultLocation, or the LocationID of a name.
+const Location = DefaultLocation
+
+// location returns the provided location ID, or the default location if it is empty.
+func location(id string) string {
+	if id == "" {
+		return DefaultLocation
+	}
+	return id
+}
 
 // Name is an interface that represents resource names.
 type Name interface {
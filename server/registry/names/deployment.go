@@ -22,6 +22,7 @@ import (
 // Deployment represents a resource name for an API deployment.
 type Deployment struct {
 	ProjectID    string
+	LocationID   string
 	ApiID        string
 	DeploymentID string
 }
@@ -45,8 +46,9 @@ func (d Deployment) Project() Project {
 // Api returns the parent API for this resource.
 func (d Deployment) Api() Api {
 	return Api{
-		ProjectID: d.ProjectID,
-		ApiID:     d.ApiID,
+		ProjectID:  d.ProjectID,
+		LocationID: d.LocationID,
+		ApiID:      d.ApiID,
 	}
 }
 
@@ -54,6 +56,7 @@ func (d Deployment) Api() Api {
 func (d Deployment) Revision(id string) DeploymentRevision {
 	return DeploymentRevision{
 		ProjectID:    d.ProjectID,
+		LocationID:   d.LocationID,
 		ApiID:        d.ApiID,
 		DeploymentID: d.DeploymentID,
 		RevisionID:   id,
@@ -65,6 +68,7 @@ func (d Deployment) Artifact(id string) Artifact {
 	return Artifact{
 		name: deploymentArtifact{
 			ProjectID:    d.ProjectID,
+			LocationID:   d.LocationID,
 			ApiID:        d.ApiID,
 			DeploymentID: d.DeploymentID,
 			ArtifactID:   id,
@@ -76,6 +80,7 @@ func (d Deployment) Artifact(id string) Artifact {
 func (d Deployment) Normal() Deployment {
 	return Deployment{
 		ProjectID:    normalize(d.ProjectID),
+		LocationID:   normalize(d.LocationID),
 		ApiID:        normalize(d.ApiID),
 		DeploymentID: normalize(d.DeploymentID),
 	}
@@ -88,19 +93,19 @@ func (d Deployment) Parent() string {
 
 func (d Deployment) String() string {
 	return normalize(fmt.Sprintf("projects/%s/locations/%s/apis/%s/deployments/%s",
-		d.ProjectID, Location, d.ApiID, d.DeploymentID))
+		d.ProjectID, location(d.LocationID), d.ApiID, d.DeploymentID))
 }
 
 // deploymentCollectionRegexp returns a regular expression that matches a collection of deployments.
 func deploymentCollectionRegexp() *regexp.Regexp {
 	return regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/deployments$",
-		identifier, Location, identifier))
+		identifier, identifier, identifier))
 }
 
 // deploymentRegexp returns a regular expression that matches a deployment resource name.
 func deploymentRegexp() *regexp.Regexp {
 	return regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/deployments/%s$",
-		identifier, Location, identifier, identifier))
+		identifier, identifier, identifier, identifier))
 }
 
 // ParseDeployment parses the name of a deployment.
@@ -113,8 +118,9 @@ func ParseDeployment(name string) (Deployment, error) {
 	m := r.FindStringSubmatch(name)
 	return Deployment{
 		ProjectID:    m[1],
-		ApiID:        m[2],
-		DeploymentID: m[3],
+		LocationID:   m[2],
+		ApiID:        m[3],
+		DeploymentID: m[4],
 	}, nil
 }
 
@@ -127,7 +133,8 @@ func ParseDeploymentCollection(name string) (Deployment, error) {
 	m := r.FindStringSubmatch(name)
 	return Deployment{
 		ProjectID:    m[1],
-		ApiID:        m[2],
+		LocationID:   m[2],
+		ApiID:        m[3],
 		DeploymentID: "",
 	}, nil
 }
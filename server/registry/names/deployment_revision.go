@@ -19,11 +19,12 @@ import (
 	"regexp"
 )
 
-var deploymentRevisionRegexp = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/deployments/%s@%s$", identifier, Location, identifier, identifier, revisionTag))
+var deploymentRevisionRegexp = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/deployments/%s@%s$", identifier, identifier, identifier, identifier, revisionTag))
 
 // DeploymentRevision represents a resource name for an API deployment revision.
 type DeploymentRevision struct {
 	ProjectID    string
+	LocationID   string
 	ApiID        string
 	DeploymentID string
 	RevisionID   string
@@ -33,6 +34,7 @@ type DeploymentRevision struct {
 func (s DeploymentRevision) Deployment() Deployment {
 	return Deployment{
 		ProjectID:    s.ProjectID,
+		LocationID:   s.LocationID,
 		ApiID:        s.ApiID,
 		DeploymentID: s.DeploymentID,
 	}
@@ -40,7 +42,7 @@ func (s DeploymentRevision) Deployment() Deployment {
 
 func (s DeploymentRevision) String() string {
 	return normalize(fmt.Sprintf("projects/%s/locations/%s/apis/%s/deployments/%s@%s",
-		s.ProjectID, Location, s.ApiID, s.DeploymentID, s.RevisionID))
+		s.ProjectID, location(s.LocationID), s.ApiID, s.DeploymentID, s.RevisionID))
 }
 
 // ParseDeploymentRevision parses the name of a deployment.
@@ -52,9 +54,10 @@ func ParseDeploymentRevision(name string) (DeploymentRevision, error) {
 	m := deploymentRevisionRegexp.FindStringSubmatch(name)
 	revision := DeploymentRevision{
 		ProjectID:    m[1],
-		ApiID:        m[2],
-		DeploymentID: m[3],
-		RevisionID:   m[4],
+		LocationID:   m[2],
+		ApiID:        m[3],
+		DeploymentID: m[4],
+		RevisionID:   m[5],
 	}
 
 	return revision, nil
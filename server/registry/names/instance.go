@@ -0,0 +1,73 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package names
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Instance represents a resource name for a provisioned registry instance.
+type Instance struct {
+	ProjectID  string
+	LocationID string
+	InstanceID string
+}
+
+// Validate returns an error if the resource name is invalid.
+func (i Instance) Validate() error {
+	r := instanceRegexp()
+	if name := i.String(); !r.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q: must match %q", name, r)
+	}
+
+	if err := validateID(location(i.LocationID)); err != nil {
+		return err
+	}
+
+	return validateID(i.InstanceID)
+}
+
+// Location returns the name of this resource's parent location.
+func (i Instance) Location() ProjectLocation {
+	return ProjectLocation{
+		ProjectID:  i.ProjectID,
+		LocationID: i.LocationID,
+	}
+}
+
+func (i Instance) String() string {
+	return normalize(fmt.Sprintf("projects/%s/locations/%s/instances/%s", i.ProjectID, location(i.LocationID), i.InstanceID))
+}
+
+// instanceRegexp returns a regular expression that matches an instance resource name.
+func instanceRegexp() *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/instances/%s$", identifier, identifier, identifier))
+}
+
+// ParseInstance parses the name of an instance.
+func ParseInstance(name string) (Instance, error) {
+	r := instanceRegexp()
+	if !r.MatchString(name) {
+		return Instance{}, fmt.Errorf("invalid instance name %q: must match %q", name, r)
+	}
+
+	m := r.FindStringSubmatch(name)
+	return Instance{
+		ProjectID:  m[1],
+		LocationID: m[2],
+		InstanceID: m[3],
+	}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package names
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ProjectLocation represents a resource name for a location within a project.
+// Locations partition the APIs and artifacts of a project, for example by
+// region or environment. Locations are not stored; they exist implicitly
+// when they contain resources.
+type ProjectLocation struct {
+	ProjectID  string
+	LocationID string
+}
+
+// Validate returns an error if the resource name is invalid.
+func (l ProjectLocation) Validate() error {
+	r := locationRegexp()
+	if name := l.String(); !r.MatchString(name) {
+		return fmt.Errorf("invalid location name %q: must match %q", name, r)
+	}
+
+	return validateID(location(l.LocationID))
+}
+
+// Project returns the name of this resource's parent project.
+func (l ProjectLocation) Project() Project {
+	return Project{
+		ProjectID: l.ProjectID,
+	}
+}
+
+// Api returns an API with the provided ID and this resource as its parent.
+func (l ProjectLocation) Api(id string) Api {
+	return Api{
+		ProjectID:  l.ProjectID,
+		LocationID: l.LocationID,
+		ApiID:      id,
+	}
+}
+
+// Artifact returns an artifact with the provided ID and this resource as its parent.
+func (l ProjectLocation) Artifact(id string) Artifact {
+	return Artifact{
+		name: projectArtifact{
+			ProjectID:  l.ProjectID,
+			LocationID: l.LocationID,
+			ArtifactID: id,
+		},
+	}
+}
+
+func (l ProjectLocation) String() string {
+	return normalize(fmt.Sprintf("projects/%s/locations/%s", l.ProjectID, location(l.LocationID)))
+}
+
+// locationRegexp returns a regular expression that matches a location resource name.
+func locationRegexp() *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s$", identifier, identifier))
+}
+
+// ParseLocation parses the name of a location.
+// The location ID may be "-" to refer to all locations of a project.
+func ParseLocation(name string) (ProjectLocation, error) {
+	r := locationRegexp()
+	if !r.MatchString(name) {
+		return ProjectLocation{}, fmt.Errorf("invalid location name %q: must match %q", name, r)
+	}
+
+	m := r.FindStringSubmatch(name)
+	return ProjectLocation{
+		ProjectID:  m[1],
+		LocationID: m[2],
+	}, nil
+}
@@ -55,6 +55,62 @@ func TestResourceNames(t *testing.T) {
 				"-",
 			},
 		},
+		{
+			name: "location",
+			check: func(name string) bool {
+				_, err := ParseLocation(name)
+				return err == nil
+			},
+			pass: []string{
+				"projects/google/locations/global",
+				"projects/google/locations/us-central1",
+				"projects/google/locations/-",
+				"projects/-/locations/-",
+			},
+			fail: []string{
+				"-",
+				"projects/google",
+				"projects/google/locations",
+				"projects/google/locations/",
+				"projects/google/locations/us/apis",
+			},
+		},
+		{
+			name: "instance",
+			check: func(name string) bool {
+				_, err := ParseInstance(name)
+				return err == nil
+			},
+			pass: []string{
+				"projects/google/locations/global/instances/tenant",
+				"projects/google/locations/us/instances/tenant-1",
+			},
+			fail: []string{
+				"-",
+				"projects/google/locations/global",
+				"projects/google/locations/global/instances",
+				"projects/google/locations/global/instances/",
+				"projects/google/locations/global/apis/a",
+			},
+		},
+		{
+			name: "archive",
+			check: func(name string) bool {
+				_, err := ParseArchive(name)
+				return err == nil
+			},
+			pass: []string{
+				"archives/0b9a6a4e-6b4a-4c6b-9f5e-0f2a8f1e2d3c",
+				"archives/my-export",
+			},
+			fail: []string{
+				"-",
+				"archives",
+				"archives/",
+				"archives/a/b",
+				"projects/google/archives/a",
+			},
+		},
 		{
 			name: "api collections",
 			check: func(name string) bool {
@@ -64,6 +120,8 @@ func TestResourceNames(t *testing.T) {
 			pass: []string{
 				"projects/google/locations/global/apis",
 				"projects/-/locations/global/apis",
+				"projects/google/locations/eu/apis",
+				"projects/google/locations/-/apis",
 			},
 			fail: []string{
 				"-",
@@ -80,6 +138,7 @@ func TestResourceNames(t *testing.T) {
 				"projects/-/locations/global/apis/-",
 				"projects/123/locations/global/apis/abc",
 				"projects/1-2-3/locations/global/apis/abc",
+				"projects/google/locations/us/apis/sample",
 			},
 			fail: []string{
 				"-",
@@ -268,6 +327,8 @@ func TestResourceNames(t *testing.T) {
 				"projects/google/locations/global/apis/sample/versions/v1/artifacts/test-artifact",
 				"projects/google/locations/global/apis/sample/versions/v1/specs/openapi.yaml/artifacts/test-artifact",
 				"projects/google/locations/global/apis/sample/deployments/prod/artifacts/test-artifact",
+				"projects/google/locations/us/artifacts/test-artifact",
+				"projects/google/locations/us/apis/sample/versions/v1/specs/openapi.yaml/artifacts/test-artifact",
 			},
 			fail: []string{
 				"-",
@@ -287,3 +348,61 @@ func TestResourceNames(t *testing.T) {
 		}
 	}
 }
+
+func TestLocations(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func(string) (Name, error)
+		location string
+	}{
+		{"projects/p/locations/us/apis/a", func(s string) (Name, error) { return ParseApi(s) }, "us"},
+		{"projects/p/locations/eu/apis/a/versions/v", func(s string) (Name, error) { return ParseVersion(s) }, "eu"},
+		{"projects/p/locations/us/apis/a/versions/v/specs/s", func(s string) (Name, error) { return ParseSpec(s) }, "us"},
+		{"projects/p/locations/us/apis/a/versions/v/specs/s@r", func(s string) (Name, error) { return ParseSpecRevision(s) }, "us"},
+		{"projects/p/locations/us/apis/a/deployments/d", func(s string) (Name, error) { return ParseDeployment(s) }, "us"},
+		{"projects/p/locations/us/apis/a/deployments/d@r", func(s string) (Name, error) { return ParseDeploymentRevision(s) }, "us"},
+		{"projects/p/locations/us/artifacts/x", func(s string) (Name, error) { return ParseArtifact(s) }, "us"},
+		{"projects/p/locations/eu/apis/a/versions/v/specs/s/artifacts/x", func(s string) (Name, error) { return ParseArtifact(s) }, "eu"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, err := test.parse(test.name)
+			if err != nil {
+				t.Fatalf("parse returned error: %s", err)
+			}
+			if got := name.String(); got != test.name {
+				t.Errorf("String() returned %q, want %q", got, test.name)
+			}
+		})
+	}
+
+	api, err := ParseApi("projects/p/locations/us/apis/a")
+	if err != nil {
+		t.Fatalf("ParseApi() returned error: %s", err)
+	}
+	if got, want := api.Version("v").Spec("s").Artifact("x").LocationID(), "us"; got != want {
+		t.Errorf("child artifact has location %q, want %q", got, want)
+	}
+	if got, want := api.Parent(), "projects/p/locations/us"; got != want {
+		t.Errorf("Parent() returned %q, want %q", got, want)
+	}
+	if got, want := (Api{ProjectID: "p", ApiID: "a"}).String(), "projects/p/locations/global/apis/a"; got != want {
+		t.Errorf("name without location is %q, want %q", got, want)
+	}
+	if err := (ProjectLocation{ProjectID: "p", LocationID: "-"}).Api("a").Validate(); err == nil {
+		t.Errorf("Validate() accepted an API in location %q", "-")
+	}
+}
+
+func TestParseProjectWithLocation(t *testing.T) {
+	p, err := ParseProjectWithLocation("projects/p/locations/global")
+	if err != nil {
+		t.Fatalf("ParseProjectWithLocation() returned error: %s", err)
+	}
+	if got, want := p.String(), "projects/p"; got != want {
+		t.Errorf("ParseProjectWithLocation() returned %q, want %q", got, want)
+	}
+	if _, err := ParseProjectWithLocation("projects/p/locations/us"); err == nil {
+		t.Errorf("ParseProjectWithLocation() accepted a location other than %q", DefaultLocation)
+	}
+}
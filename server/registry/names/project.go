@@ -35,22 +35,22 @@ func (p Project) Validate() error {
 	return validateID(p.ProjectID)
 }
 
-// Api returns an API with the provided ID and this resource as its parent.
-func (p Project) Api(id string) Api {
-	return Api{
-		ProjectID: p.ProjectID,
-		ApiID:     id,
+// Location returns a location with the provided ID and this resource as its parent.
+func (p Project) Location(id string) ProjectLocation {
+	return ProjectLocation{
+		ProjectID:  p.ProjectID,
+		LocationID: id,
 	}
 }
 
-// Artifact returns an artifact with the provided ID and this resource as its parent.
+// Api returns an API with the provided ID in the default location of this project.
+func (p Project) Api(id string) Api {
+	return p.Location(DefaultLocation).Api(id)
+}
+
+// Artifact returns an artifact with the provided ID in the default location of this project.
 func (p Project) Artifact(id string) Artifact {
-	return Artifact{
-		name: projectArtifact{
-			ProjectID:  p.ProjectID,
-			ArtifactID: id,
-		},
-	}
+	return p.Location(DefaultLocation).Artifact(id)
 }
 
 func (p Project) String() string {
@@ -67,11 +67,6 @@ func projectRegexp() *regexp.Regexp {
 	return regexp.MustCompile(fmt.Sprintf("^projects/%s$", identifier))
 }
 
-// projectWithLocationRegexp returns a regular expression that matches a project resource name followed by a location.
-func projectWithLocationRegexp() *regexp.Regexp {
-	return regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s$", identifier, Location))
-}
-
 // ParseProject parses the name of a project.
 func ParseProject(name string) (Project, error) {
 	r := projectRegexp()
@@ -97,15 +92,16 @@ func ParseProjectCollection(name string) (Project, error) {
 	}, nil
 }
 
-// ParseProjectWithLocation parses the name of a project.
+// ParseProjectWithLocation parses the name of a project followed by the default location.
+//
+// Deprecated: Use ParseLocation, which accepts any location and keeps its ID.
 func ParseProjectWithLocation(name string) (Project, error) {
-	r := projectWithLocationRegexp()
-	if !r.MatchString(name) {
-		return Project{}, fmt.Errorf("invalid project name %q: must match %q", name, r)
+	l, err := ParseLocation(name)
+	if err != nil {
+		return Project{}, err
 	}
-
-	m := r.FindStringSubmatch(name)
-	return Project{
-		ProjectID: m[1],
-	}, nil
+	if l.LocationID != DefaultLocation {
+		return Project{}, fmt.Errorf("invalid project name %q: location must be %q", name, DefaultLocation)
+	}
+	return l.Project(), nil
 }
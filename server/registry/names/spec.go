@@ -22,14 +22,15 @@ import (
 // simpleSpecRegexp is the regex pattern for spec resource names.
 // Notably, this differs from SpecRegexp() by not accepting spec revision IDs in the resource name.
 var simpleSpecRegexp = regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/specs/%s$",
-	identifier, Location, identifier, identifier, identifier))
+	identifier, identifier, identifier, identifier, identifier))
 
 // Spec represents a resource name for an API spec.
 type Spec struct {
-	ProjectID string
-	ApiID     string
-	VersionID string
-	SpecID    string
+	ProjectID  string
+	LocationID string
+	ApiID      string
+	VersionID  string
+	SpecID     string
 }
 
 // Validate returns an error if the resource name is invalid.
@@ -53,17 +54,19 @@ func (s Spec) Project() Project {
 // Api returns the parent API for this resource.
 func (s Spec) Api() Api {
 	return Api{
-		ProjectID: s.ProjectID,
-		ApiID:     s.ApiID,
+		ProjectID:  s.ProjectID,
+		LocationID: s.LocationID,
+		ApiID:      s.ApiID,
 	}
 }
 
 // Version returns the parent API version for this resource.
 func (s Spec) Version() Version {
 	return Version{
-		ProjectID: s.ProjectID,
-		ApiID:     s.ApiID,
-		VersionID: s.VersionID,
+		ProjectID:  s.ProjectID,
+		LocationID: s.LocationID,
+		ApiID:      s.ApiID,
+		VersionID:  s.VersionID,
 	}
 }
 
@@ -71,6 +74,7 @@ func (s Spec) Version() Version {
 func (s Spec) Revision(id string) SpecRevision {
 	return SpecRevision{
 		ProjectID:  s.ProjectID,
+		LocationID: s.LocationID,
 		ApiID:      s.ApiID,
 		VersionID:  s.VersionID,
 		SpecID:     s.SpecID,
@@ -83,6 +87,7 @@ func (s Spec) Artifact(id string) Artifact {
 	return Artifact{
 		name: specArtifact{
 			ProjectID:  s.ProjectID,
+			LocationID: s.LocationID,
 			ApiID:      s.ApiID,
 			VersionID:  s.VersionID,
 			SpecID:     s.SpecID,
@@ -94,10 +99,11 @@ func (s Spec) Artifact(id string) Artifact {
 // Normal returns the resource name with normalized identifiers.
 func (s Spec) Normal() Spec {
 	return Spec{
-		ProjectID: normalize(s.ProjectID),
-		ApiID:     normalize(s.ApiID),
-		VersionID: normalize(s.VersionID),
-		SpecID:    normalize(s.SpecID),
+		ProjectID:  normalize(s.ProjectID),
+		LocationID: normalize(s.LocationID),
+		ApiID:      normalize(s.ApiID),
+		VersionID:  normalize(s.VersionID),
+		SpecID:     normalize(s.SpecID),
 	}
 }
 
@@ -108,19 +114,19 @@ func (s Spec) Parent() string {
 
 func (s Spec) String() string {
 	return normalize(fmt.Sprintf("projects/%s/locations/%s/apis/%s/versions/%s/specs/%s",
-		s.ProjectID, Location, s.ApiID, s.VersionID, s.SpecID))
+		s.ProjectID, location(s.LocationID), s.ApiID, s.VersionID, s.SpecID))
 }
 
 // specCollectionRegexp returns a regular expression that matches a collection of specs.
 func specCollectionRegexp() *regexp.Regexp {
 	return regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/specs$",
-		identifier, Location, identifier, identifier))
+		identifier, identifier, identifier, identifier))
 }
 
 // specRegexp returns a regular expression that matches a spec resource name with an optional revision identifier.
 func specRegexp() *regexp.Regexp {
 	return regexp.MustCompile(fmt.Sprintf("^projects/%s/locations/%s/apis/%s/versions/%s/specs/%s(@%s)?$",
-		identifier, Location, identifier, identifier, identifier, revisionTag))
+		identifier, identifier, identifier, identifier, identifier, revisionTag))
 }
 
 // ParseSpec parses the name of a spec.
@@ -131,10 +137,11 @@ func ParseSpec(name string) (Spec, error) {
 
 	m := simpleSpecRegexp.FindStringSubmatch(name)
 	spec := Spec{
-		ProjectID: m[1],
-		ApiID:     m[2],
-		VersionID: m[3],
-		SpecID:    m[4],
+		ProjectID:  m[1],
+		LocationID: m[2],
+		ApiID:      m[3],
+		VersionID:  m[4],
+		SpecID:     m[5],
 	}
 
 	return spec, nil
@@ -149,10 +156,11 @@ func ParseSpecCollection(name string) (Spec, error) {
 
 	m := r.FindStringSubmatch(name)
 	spec := Spec{
-		ProjectID: m[1],
-		ApiID:     m[2],
-		VersionID: m[3],
-		SpecID:    "",
+		ProjectID:  m[1],
+		LocationID: m[2],
+		ApiID:      m[3],
+		VersionID:  m[4],
+		SpecID:     "",
 	}
 
 	return spec, nil
@@ -0,0 +1,448 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultWaitTimeout is the longest time that WaitOperation waits when no timeout is requested.
+const defaultWaitTimeout = time.Minute
+
+// operationLease is the time that an operation is considered to be running
+// without its lease being renewed. The server that runs an operation renews
+// its lease until it finishes, so the operations of a server that stopped
+// without finishing them are abandoned when their leases expire.
+const operationLease = time.Minute
+
+// operations tracks the long-running operations that are running in this server.
+// Every operation is recorded in the primary database when it starts and when
+// it finishes, so it can be read from any server that shares the database.
+// Abandoned operations are recorded as failed when they are read.
+// Progress is kept in memory while an operation runs, because operations may
+// report it from within transactions, and only the server that is running an
+// operation can report its progress and cancel it.
+type operations struct {
+	mu      sync.Mutex
+	running map[string]*runningOperation
+	wg      sync.WaitGroup
+}
+
+// runningOperation is an operation that is running in this server.
+type runningOperation struct {
+	cancel context.CancelFunc
+	latest *longrunning.Operation
+}
+
+func newOperations() *operations {
+	return &operations{running: make(map[string]*runningOperation)}
+}
+
+// get returns a copy of the latest state of an operation if it is running in this server.
+func (o *operations) get(name string) (*longrunning.Operation, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	r, ok := o.running[name]
+	if !ok {
+		return nil, false
+	}
+	return proto.Clone(r.latest).(*longrunning.Operation), true
+}
+
+// cancel cancels an operation and records the request in its latest state.
+// It returns false if the operation isn't running in this server.
+func (o *operations) cancel(name string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	r, ok := o.running[name]
+	if !ok {
+		return false
+	}
+	r.cancel()
+	// The operation records the request when it next publishes its state,
+	// but callers should see it immediately.
+	if metadata, err := r.latest.GetMetadata().UnmarshalNew(); err == nil {
+		setMetadataField(metadata, "cancellation_requested", protoreflect.ValueOfBool(true))
+		latest := proto.Clone(r.latest).(*longrunning.Operation)
+		if setOperationMetadata(latest, metadata) == nil {
+			r.latest = latest
+		}
+	}
+	return true
+}
+
+// cancelAll cancels all running operations and waits for them to finish.
+func (o *operations) cancelAll() {
+	o.mu.Lock()
+	for _, r := range o.running {
+		r.cancel()
+	}
+	o.mu.Unlock()
+	o.wg.Wait()
+}
+
+// operationFunc does the work of a long-running operation and returns its response.
+// It may update the operation's metadata and call report to publish its progress.
+// Its context is cancelled when cancellation of the operation is requested.
+type operationFunc func(ctx context.Context, report func()) (proto.Message, error)
+
+// startOperation records a new operation on a resource in the parent collection
+// and runs it in the background. The metadata should have create_time, end_time,
+// and cancellation_requested fields, which are set as the operation runs.
+// Operations that don't belong to a collection have an empty parent.
+func (s *RegistryServer) startOperation(ctx context.Context, parent, method string, metadata proto.Message, fn operationFunc) (*longrunning.Operation, error) {
+	name := fmt.Sprintf("operations/%s", uuid.New())
+	if parent != "" {
+		name = parent + "/" + name
+	}
+
+	setMetadataField(metadata, "create_time", protoreflect.ValueOfMessage(timestamppb.Now().ProtoReflect()))
+	op := &longrunning.Operation{Name: name}
+	if err := setOperationMetadata(op, metadata); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	record, err := models.NewOperation(parent, method, op)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	record.ExpireTime = time.Now().Add(operationLease).Round(time.Microsecond)
+	if err := s.storageClient.CreateOperation(ctx, record); err != nil {
+		return nil, err
+	}
+
+	// The operation keeps the values of the request's context, such as the
+	// instance that it acts on, but outlives the request.
+	ctx, cancel := context.WithCancel(detachedContext{ctx})
+	running := &runningOperation{cancel: cancel, latest: proto.Clone(op).(*longrunning.Operation)}
+	s.operations.mu.Lock()
+	s.operations.running[name] = running
+	s.operations.wg.Add(2)
+	s.operations.mu.Unlock()
+	finished := make(chan struct{})
+	go s.renewLease(name, finished)
+
+	// update publishes the state of the operation. The metadata is only
+	// changed by the operation, so reading it here doesn't need a lock.
+	update := func(op *longrunning.Operation) {
+		if ctx.Err() != nil {
+			setMetadataField(metadata, "cancellation_requested", protoreflect.ValueOfBool(true))
+		}
+		if err := setOperationMetadata(op, metadata); err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("Failed to encode metadata of operation %s", name)
+		}
+		s.operations.mu.Lock()
+		running.latest = op
+		s.operations.mu.Unlock()
+	}
+
+	go func() {
+		defer s.operations.wg.Done()
+		defer cancel()
+		defer close(finished)
+
+		response, err := fn(ctx, func() { update(&longrunning.Operation{Name: name}) })
+		if err != nil && ctx.Err() == context.Canceled {
+			err = status.Error(codes.Canceled, "operation was cancelled")
+		}
+
+		setMetadataField(metadata, "end_time", protoreflect.ValueOfMessage(timestamppb.Now().ProtoReflect()))
+		done := &longrunning.Operation{Name: name, Done: true}
+		if err == nil {
+			var r *anypb.Any
+			if r, err = anypb.New(response); err == nil {
+				done.Result = &longrunning.Operation_Response{Response: r}
+			}
+		}
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("Operation %s failed", name)
+			done.Result = &longrunning.Operation_Error{Error: status.Convert(err).Proto()}
+		}
+		update(done)
+
+		// The result is saved with a context that isn't cancelled.
+		if err := record.Update(done); err == nil {
+			err = s.storageClient.SaveOperation(context.Background(), record)
+		}
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("Failed to save operation %s", name)
+		}
+		s.operations.mu.Lock()
+		delete(s.operations.running, name)
+		s.operations.mu.Unlock()
+	}()
+
+	return op, nil
+}
+
+// renewLease periodically extends the lease of a running operation until it is finished.
+func (s *RegistryServer) renewLease(name string, finished <-chan struct{}) {
+	defer s.operations.wg.Done()
+	ticker := time.NewTicker(operationLease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			expireTime := time.Now().Add(operationLease).Round(time.Microsecond)
+			if err := s.storageClient.RenewOperation(context.Background(), name, expireTime); err != nil {
+				log.FromContext(context.Background()).WithError(err).Errorf("Failed to renew the lease of operation %s", name)
+			}
+		case <-finished:
+			return
+		}
+	}
+}
+
+// finishAbandoned records an abandoned operation as failed, so that it can be
+// deleted and isn't reported as running forever. Other records are returned unchanged.
+func (s *RegistryServer) finishAbandoned(ctx context.Context, record *models.Operation) (*models.Operation, error) {
+	now := time.Now()
+	if !record.Abandoned(now) {
+		return record, nil
+	}
+	if _, ok := s.operations.get(record.Key); ok {
+		return record, nil
+	}
+	op, err := record.Message()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if metadata, err := op.GetMetadata().UnmarshalNew(); err == nil {
+		setMetadataField(metadata, "end_time", protoreflect.ValueOfMessage(timestamppb.New(now).ProtoReflect()))
+		if err := setOperationMetadata(op, metadata); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	op.Done = true
+	op.Result = &longrunning.Operation_Error{
+		Error: status.New(codes.Aborted, "operation was abandoned by the server that ran it").Proto(),
+	}
+	abandoned := *record
+	if err := abandoned.Update(op); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if saved, err := s.storageClient.FinishAbandonedOperation(ctx, &abandoned, now); err != nil {
+		return nil, err
+	} else if !saved {
+		// The operation finished or its lease was renewed since it was read.
+		return s.storageClient.GetOperation(ctx, record.Key)
+	}
+	log.FromContext(ctx).Warnf("Operation %s was abandoned", record.Key)
+	return &abandoned, nil
+}
+
+// finishAbandonedOperations records all abandoned operations as failed.
+func (s *RegistryServer) finishAbandonedOperations(ctx context.Context) error {
+	records, err := s.storageClient.ListAbandonedOperations(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		if _, err := s.finishAbandoned(ctx, &records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setOperationMetadata(op *longrunning.Operation, metadata proto.Message) error {
+	m, err := anypb.New(metadata)
+	if err != nil {
+		return err
+	}
+	op.Metadata = m
+	return nil
+}
+
+// setMetadataField sets a field of an operation's metadata, if the metadata has that field.
+func setMetadataField(metadata proto.Message, name string, v protoreflect.Value) {
+	m := metadata.ProtoReflect()
+	if f := m.Descriptor().Fields().ByName(protoreflect.Name(name)); f != nil {
+		m.Set(f, v)
+	}
+}
+
+// progressPercent returns the percentage of work that is done.
+// Estimates of the total may be low, so it is never more than 100.
+func progressPercent(done, total int) int32 {
+	if total <= 0 || done >= total {
+		return 100
+	}
+	return int32(100 * done / total)
+}
+
+// detachedContext is a context with the values of another context that is never cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (s *RegistryServer) getOperation(ctx context.Context, name string) (*longrunning.Operation, error) {
+	if op, ok := s.operations.get(name); ok {
+		return op, nil
+	}
+	record, err := s.storageClient.GetOperation(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if record, err = s.finishAbandoned(ctx, record); err != nil {
+		return nil, err
+	}
+	op, err := record.Message()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return op, nil
+}
+
+// GetOperation handles the corresponding API request.
+func (s *RegistryServer) GetOperation(ctx context.Context, req *longrunning.GetOperationRequest) (*longrunning.Operation, error) {
+	return s.getOperation(ctx, req.GetName())
+}
+
+// ListOperations handles the corresponding API request.
+// The name of the request is the collection that contains the operations,
+// which is a location for operations of the Provisioning service. Operations
+// of the Admin service are not in a collection and are listed when it is empty,
+// along with all other operations.
+func (s *RegistryServer) ListOperations(ctx context.Context, req *longrunning.ListOperationsRequest) (*longrunning.ListOperationsResponse, error) {
+	if req.GetPageSize() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_size %d: must not be negative", req.GetPageSize())
+	} else if req.GetPageSize() > 1000 {
+		req.PageSize = 1000
+	} else if req.GetPageSize() == 0 {
+		req.PageSize = 50
+	}
+
+	listing, err := s.storageClient.ListOperations(ctx, req.GetName(), storage.PageOptions{
+		Size:   req.GetPageSize(),
+		Filter: req.GetFilter(),
+		Token:  req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &longrunning.ListOperationsResponse{
+		Operations:    make([]*longrunning.Operation, len(listing.Operations)),
+		NextPageToken: listing.Token,
+	}
+
+	for i, record := range listing.Operations {
+		if op, ok := s.operations.get(record.Key); ok {
+			response.Operations[i] = op
+			continue
+		}
+		finished, err := s.finishAbandoned(ctx, &record)
+		if err != nil {
+			return nil, err
+		}
+		response.Operations[i], err = finished.Message()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return response, nil
+}
+
+// DeleteOperation handles the corresponding API request.
+// Running operations can't be deleted, but abandoned operations can.
+func (s *RegistryServer) DeleteOperation(ctx context.Context, req *longrunning.DeleteOperationRequest) (*emptypb.Empty, error) {
+	record, err := s.storageClient.GetOperation(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if record, err = s.finishAbandoned(ctx, record); err != nil {
+		return nil, err
+	}
+	if !record.Done {
+		return nil, status.Errorf(codes.FailedPrecondition, "operation %q is running", req.GetName())
+	}
+	if err := s.storageClient.DeleteOperation(ctx, req.GetName()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// CancelOperation handles the corresponding API request.
+// Cancellation is asynchronous: the operation finishes with a CANCELLED error
+// unless it completes before it notices the request.
+func (s *RegistryServer) CancelOperation(ctx context.Context, req *longrunning.CancelOperationRequest) (*emptypb.Empty, error) {
+	if s.operations.cancel(req.GetName()) {
+		return &emptypb.Empty{}, nil
+	}
+
+	record, err := s.storageClient.GetOperation(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	// Abandoned operations are already stopped and are finished with an ABORTED error.
+	if record, err = s.finishAbandoned(ctx, record); err != nil {
+		return nil, err
+	}
+	if !record.Done {
+		return nil, status.Errorf(codes.FailedPrecondition, "operation %q is not running on this server", req.GetName())
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WaitOperation handles the corresponding API request.
+// It returns the latest state of the operation when it is done or the timeout expires.
+func (s *RegistryServer) WaitOperation(ctx context.Context, req *longrunning.WaitOperationRequest) (*longrunning.Operation, error) {
+	timeout := defaultWaitTimeout
+	if t := req.GetTimeout(); t != nil {
+		if err := t.CheckValid(); err != nil || t.AsDuration() < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid timeout %v", t)
+		}
+		timeout = t.AsDuration()
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		op, err := s.getOperation(ctx, req.GetName())
+		if err != nil || op.GetDone() {
+			return op, err
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return op, nil
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
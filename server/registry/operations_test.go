@@ -0,0 +1,272 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// waitForOperation waits for an operation to finish and returns its final state.
+// Operation errors are returned as errors.
+func waitForOperation(ctx context.Context, t *testing.T, server TestServer, op *longrunning.Operation) (*longrunning.Operation, error) {
+	t.Helper()
+	op, err := server.WaitOperation(ctx, &longrunning.WaitOperationRequest{
+		Name:    op.GetName(),
+		Timeout: durationpb.New(10 * time.Second),
+	})
+	if err != nil {
+		t.Fatalf("WaitOperation(%s) returned error: %s", op.GetName(), err)
+	}
+	if !op.GetDone() {
+		t.Fatalf("WaitOperation(%s) timed out", op.GetName())
+	}
+	if op.GetError() != nil {
+		return op, status.ErrorProto(op.GetError())
+	}
+	return op, nil
+}
+
+func TestOperations(t *testing.T) {
+	ctx := context.Background()
+	server, err := serverWithSQLite(t)
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+
+	// This operation runs until it is cancelled.
+	started := make(chan struct{})
+	blocked, err := server.startOperation(ctx, "", "Test", &rpc.MigrateDatabaseMetadata{}, func(ctx context.Context, report func()) (proto.Message, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("startOperation() returned error: %s", err)
+	}
+	<-started
+	if !strings.HasPrefix(blocked.GetName(), "operations/") {
+		t.Errorf("startOperation() returned operation named %q, want prefix %q", blocked.GetName(), "operations/")
+	}
+
+	migrate, err := server.MigrateDatabase(ctx, &rpc.MigrateDatabaseRequest{})
+	if err != nil {
+		t.Fatalf("MigrateDatabase() returned error: %s", err)
+	}
+	migrate, err = waitForOperation(ctx, t, server, migrate)
+	if err != nil {
+		t.Fatalf("MigrateDatabase() operation returned error: %s", err)
+	}
+	metadata := &rpc.MigrateDatabaseMetadata{}
+	if err := migrate.GetMetadata().UnmarshalTo(metadata); err != nil {
+		t.Fatalf("MigrateDatabase() operation returned unexpected metadata: %s", err)
+	}
+	if metadata.GetProgressPercent() != 100 || metadata.GetCreateTime() == nil || metadata.GetEndTime() == nil {
+		t.Errorf("MigrateDatabase() operation returned incomplete metadata %v", metadata)
+	}
+
+	t.Run("list", func(t *testing.T) {
+		got, err := server.ListOperations(ctx, &longrunning.ListOperationsRequest{})
+		if err != nil {
+			t.Fatalf("ListOperations() returned error: %s", err)
+		}
+		if len(got.GetOperations()) != 2 {
+			t.Errorf("ListOperations() returned %d operations, want 2", len(got.GetOperations()))
+		}
+		got, err = server.ListOperations(ctx, &longrunning.ListOperationsRequest{Filter: "done"})
+		if err != nil {
+			t.Fatalf("ListOperations() returned error: %s", err)
+		}
+		if len(got.GetOperations()) != 1 || got.GetOperations()[0].GetName() != migrate.GetName() {
+			t.Errorf("ListOperations(done) returned %v, want only %q", got.GetOperations(), migrate.GetName())
+		}
+		got, err = server.ListOperations(ctx, &longrunning.ListOperationsRequest{PageSize: 1})
+		if err != nil {
+			t.Fatalf("ListOperations() returned error: %s", err)
+		}
+		if len(got.GetOperations()) != 1 || got.GetNextPageToken() == "" {
+			t.Errorf("ListOperations(page_size=1) returned %d operations and token %q, want 1 and a token", len(got.GetOperations()), got.GetNextPageToken())
+		}
+	})
+
+	t.Run("wait with timeout", func(t *testing.T) {
+		got, err := server.WaitOperation(ctx, &longrunning.WaitOperationRequest{
+			Name:    blocked.GetName(),
+			Timeout: durationpb.New(10 * time.Millisecond),
+		})
+		if err != nil {
+			t.Fatalf("WaitOperation() returned error: %s", err)
+		}
+		if got.GetDone() {
+			t.Errorf("WaitOperation() returned done operation, want running")
+		}
+	})
+
+	t.Run("delete running", func(t *testing.T) {
+		_, err := server.DeleteOperation(ctx, &longrunning.DeleteOperationRequest{Name: blocked.GetName()})
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("DeleteOperation() of running operation returned status code %q, want %q: %v", status.Code(err), codes.FailedPrecondition, err)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		if _, err := server.CancelOperation(ctx, &longrunning.CancelOperationRequest{Name: blocked.GetName()}); err != nil {
+			t.Fatalf("CancelOperation() returned error: %s", err)
+		}
+		got, err := waitForOperation(ctx, t, server, blocked)
+		if status.Code(err) != codes.Canceled {
+			t.Errorf("Cancelled operation returned status code %q, want %q: %v", status.Code(err), codes.Canceled, err)
+		}
+		metadata := &rpc.MigrateDatabaseMetadata{}
+		if err := got.GetMetadata().UnmarshalTo(metadata); err != nil {
+			t.Fatalf("Cancelled operation returned unexpected metadata: %s", err)
+		}
+		if !metadata.GetCancellationRequested() {
+			t.Errorf("Cancelled operation returned metadata %v, want cancellation_requested", metadata)
+		}
+		// Cancelling a finished operation has no effect.
+		if _, err := server.CancelOperation(ctx, &longrunning.CancelOperationRequest{Name: migrate.GetName()}); err != nil {
+			t.Errorf("CancelOperation() of finished operation returned error: %s", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if _, err := server.DeleteOperation(ctx, &longrunning.DeleteOperationRequest{Name: migrate.GetName()}); err != nil {
+			t.Fatalf("DeleteOperation() returned error: %s", err)
+		}
+		if _, err := server.GetOperation(ctx, &longrunning.GetOperationRequest{Name: migrate.GetName()}); status.Code(err) != codes.NotFound {
+			t.Errorf("GetOperation() of deleted operation returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+		}
+	})
+
+	if _, err := server.CancelOperation(ctx, &longrunning.CancelOperationRequest{Name: "operations/missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("CancelOperation() of missing operation returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+}
+
+func TestOperationOutlivesRequest(t *testing.T) {
+	server, err := serverWithSQLite(t)
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	op, err := server.startOperation(ctx, "", "Test", &rpc.MigrateDatabaseMetadata{}, func(ctx context.Context, report func()) (proto.Message, error) {
+		time.Sleep(10 * time.Millisecond)
+		return &emptypb.Empty{}, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("startOperation() returned error: %s", err)
+	}
+	cancel()
+	if _, err := waitForOperation(context.Background(), t, server, op); err != nil {
+		t.Errorf("Operation returned error after its request ended: %s", err)
+	}
+}
+
+// abandonOperation records an unfinished operation whose lease has expired,
+// like the operations of a server that crashed while running them.
+func abandonOperation(ctx context.Context, t *testing.T, server *RegistryServer, name string) {
+	t.Helper()
+	op := &longrunning.Operation{Name: name}
+	if err := setOperationMetadata(op, &rpc.ExportProjectMetadata{}); err != nil {
+		t.Fatalf("Setup: failed to set metadata: %s", err)
+	}
+	record, err := models.NewOperation("", "ExportProject", op)
+	if err != nil {
+		t.Fatalf("Setup: NewOperation() returned error: %s", err)
+	}
+	record.ExpireTime = time.Now().Add(-time.Second)
+	if err := server.storageClient.CreateOperation(ctx, record); err != nil {
+		t.Fatalf("Setup: CreateOperation() returned error: %s", err)
+	}
+}
+
+func TestAbandonedOperations(t *testing.T) {
+	ctx := context.Background()
+	dbConfig := fmt.Sprintf("%s/registry.db", t.TempDir())
+	server, err := New(Config{Database: "sqlite3", DBConfig: dbConfig})
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	t.Cleanup(server.Close)
+
+	// Running operations hold a lease.
+	release := make(chan struct{})
+	running, err := server.startOperation(ctx, "", "Test", &rpc.MigrateDatabaseMetadata{}, func(ctx context.Context, report func()) (proto.Message, error) {
+		<-release
+		return &emptypb.Empty{}, nil
+	})
+	if err != nil {
+		t.Fatalf("startOperation() returned error: %s", err)
+	}
+	record, err := server.storageClient.GetOperation(ctx, running.GetName())
+	if err != nil {
+		t.Fatalf("GetOperation() returned error: %s", err)
+	}
+	if record.Abandoned(time.Now()) {
+		t.Errorf("Running operation has a lease that expired at %s", record.ExpireTime)
+	}
+	close(release)
+	if _, err := waitForOperation(ctx, t, server, running); err != nil {
+		t.Fatalf("Operation returned error: %s", err)
+	}
+
+	abandonOperation(ctx, t, server, "operations/get")
+	op, err := server.GetOperation(ctx, &longrunning.GetOperationRequest{Name: "operations/get"})
+	if err != nil {
+		t.Fatalf("GetOperation() returned error: %s", err)
+	}
+	if !op.GetDone() || codes.Code(op.GetError().GetCode()) != codes.Aborted {
+		t.Errorf("GetOperation() of abandoned operation returned %v, want it done with an ABORTED error", op)
+	}
+	metadata := &rpc.ExportProjectMetadata{}
+	if err := op.GetMetadata().UnmarshalTo(metadata); err != nil || metadata.GetEndTime() == nil {
+		t.Errorf("GetOperation() of abandoned operation returned metadata without an end time: %v", op.GetMetadata())
+	}
+
+	abandonOperation(ctx, t, server, "operations/cancel")
+	if _, err := server.CancelOperation(ctx, &longrunning.CancelOperationRequest{Name: "operations/cancel"}); err != nil {
+		t.Errorf("CancelOperation() of abandoned operation returned error: %s", err)
+	}
+	abandonOperation(ctx, t, server, "operations/delete")
+	if _, err := server.DeleteOperation(ctx, &longrunning.DeleteOperationRequest{Name: "operations/delete"}); err != nil {
+		t.Errorf("DeleteOperation() of abandoned operation returned error: %s", err)
+	}
+
+	// Abandoned operations are finished when a server starts.
+	abandonOperation(ctx, t, server, "operations/startup")
+	restarted, err := New(Config{Database: "sqlite3", DBConfig: dbConfig})
+	if err != nil {
+		t.Fatalf("Setup: failed to restart server: %s", err)
+	}
+	t.Cleanup(restarted.Close)
+	if record, err := restarted.storageClient.GetOperation(ctx, "operations/startup"); err != nil {
+		t.Fatalf("GetOperation() returned error: %s", err)
+	} else if !record.Done {
+		t.Errorf("Abandoned operation was not finished when the server started")
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QuotaLimits limit the resources of a project. Zero values are unlimited.
+type QuotaLimits struct {
+	MaxApis             int64 // The maximum number of APIs.
+	MaxRevisionsPerSpec int64 // The maximum number of revisions of each spec.
+	MaxBlobBytes        int64 // The maximum total size of the contents of specs and artifacts.
+}
+
+// QuotaConfig configures the quotas of projects.
+type QuotaConfig struct {
+	// Default limits apply to projects that aren't listed in Projects.
+	Default QuotaLimits
+	// Projects replaces the default limits of specific projects, keyed by project ID.
+	Projects map[string]QuotaLimits
+}
+
+// limits returns the quota limits of a project.
+func (c QuotaConfig) limits(projectID string) QuotaLimits {
+	if l, ok := c.Projects[projectID]; ok {
+		return l
+	}
+	return c.Default
+}
+
+// quotaExceeded returns a ResourceExhausted error describing a quota violation.
+func quotaExceeded(subject, description string) error {
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("quota exceeded for %s: %s", subject, description))
+	if detailed, err := st.WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{Subject: subject, Description: description}},
+	}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// checkApiQuota returns an error if a project can't have another API.
+func (s *RegistryServer) checkApiQuota(ctx context.Context, db *storage.Client, projectID string) error {
+	limit := s.quotas.limits(projectID).MaxApis
+	if limit <= 0 {
+		return nil
+	}
+	count, err := db.CountApis(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if count >= limit {
+		return quotaExceeded(names.Project{ProjectID: projectID}.String(),
+			fmt.Sprintf("project has %d APIs, the limit is %d", count, limit))
+	}
+	return nil
+}
+
+// checkRevisionQuota returns an error if a spec can't have another revision.
+func (s *RegistryServer) checkRevisionQuota(ctx context.Context, db *storage.Client, name names.Spec) error {
+	limit := s.quotas.limits(name.ProjectID).MaxRevisionsPerSpec
+	if limit <= 0 {
+		return nil
+	}
+	count, err := db.CountSpecRevisions(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count >= limit {
+		return quotaExceeded(name.String(),
+			fmt.Sprintf("spec has %d revisions, the limit is %d", count, limit))
+	}
+	return nil
+}
+
+// checkBlobQuota returns an error if saving contents of the specified size
+// would exceed the total size of contents allowed in a project. The key
+// identifies the blob that is saved, which is replaced if it already exists.
+func (s *RegistryServer) checkBlobQuota(ctx context.Context, db *storage.Client, projectID, key string, size int) error {
+	limit := s.quotas.limits(projectID).MaxBlobBytes
+	if limit <= 0 {
+		return nil
+	}
+	used, err := db.ProjectBlobBytes(ctx, projectID, key)
+	if err != nil {
+		return err
+	}
+	if used+int64(size) > limit {
+		return quotaExceeded(names.Project{ProjectID: projectID}.String(),
+			fmt.Sprintf("saving %d bytes would use %d bytes of contents, the limit is %d", size, used+int64(size), limit))
+	}
+	return nil
+}
+
+// checkProjectQuotas returns an error if a project has more resources than its
+// quotas allow. It checks projects after records are written in bulk, as they
+// are by ImportProject, rather than before each resource is created.
+func (s *RegistryServer) checkProjectQuotas(ctx context.Context, db *storage.Client, projectID string) error {
+	limits := s.quotas.limits(projectID)
+	subject := names.Project{ProjectID: projectID}.String()
+	if limit := limits.MaxApis; limit > 0 {
+		count, err := db.CountApis(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		if count > limit {
+			return quotaExceeded(subject, fmt.Sprintf("project has %d APIs, the limit is %d", count, limit))
+		}
+	}
+	if limit := limits.MaxRevisionsPerSpec; limit > 0 {
+		count, err := db.MaxSpecRevisionCount(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		if count > limit {
+			return quotaExceeded(subject, fmt.Sprintf("a spec has %d revisions, the limit is %d", count, limit))
+		}
+	}
+	if limit := limits.MaxBlobBytes; limit > 0 {
+		used, err := db.ProjectBlobBytes(ctx, projectID, "")
+		if err != nil {
+			return err
+		}
+		if used > limit {
+			return quotaExceeded(subject, fmt.Sprintf("project uses %d bytes of contents, the limit is %d", used, limit))
+		}
+	}
+	return nil
+}
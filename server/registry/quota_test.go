@@ -0,0 +1,256 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func serverWithQuotas(t *testing.T, quotas QuotaConfig) *RegistryServer {
+	t.Helper()
+	server, err := New(Config{
+		Database: "sqlite3",
+		DBConfig: fmt.Sprintf("%s/registry.db", t.TempDir()),
+		Quotas:   quotas,
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	return server
+}
+
+func checkQuotaExceeded(t *testing.T, method string, err error) {
+	t.Helper()
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("%s returned status code %q, want %q: %v", method, status.Code(err), codes.ResourceExhausted, err)
+	}
+	for _, d := range status.Convert(err).Details() {
+		if _, ok := d.(*errdetails.QuotaFailure); ok {
+			return
+		}
+	}
+	t.Errorf("%s returned %v, want QuotaFailure details", method, err)
+}
+
+func TestQuotas(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithQuotas(t, QuotaConfig{
+		Default: QuotaLimits{MaxApis: 2, MaxRevisionsPerSpec: 2, MaxBlobBytes: 10},
+		Projects: map[string]QuotaLimits{
+			"unlimited": {},
+		},
+	})
+	for _, id := range []string{"limited", "unlimited"} {
+		if _, err := server.CreateProject(ctx, &rpc.CreateProjectRequest{ProjectId: id, Project: &rpc.Project{}}); err != nil {
+			t.Fatalf("Setup: CreateProject() returned error: %s", err)
+		}
+	}
+
+	createApi := func(project, id string) error {
+		_, err := server.CreateApi(ctx, &rpc.CreateApiRequest{
+			Parent: "projects/" + project + "/locations/global",
+			ApiId:  id,
+			Api:    &rpc.Api{},
+		})
+		return err
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := createApi("limited", id); err != nil {
+			t.Fatalf("CreateApi() returned error: %s", err)
+		}
+	}
+	checkQuotaExceeded(t, "CreateApi()", createApi("limited", "c"))
+	_, err := server.UpdateApi(ctx, &rpc.UpdateApiRequest{
+		Api:          &rpc.Api{Name: "projects/limited/locations/global/apis/c"},
+		AllowMissing: true,
+	})
+	checkQuotaExceeded(t, "UpdateApi() with allow_missing", err)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := createApi("unlimited", id); err != nil {
+			t.Errorf("CreateApi() in project with replaced limits returned error: %s", err)
+		}
+	}
+
+	if _, err := server.CreateApiVersion(ctx, &rpc.CreateApiVersionRequest{
+		Parent:       "projects/limited/locations/global/apis/a",
+		ApiVersionId: "v1",
+		ApiVersion:   &rpc.ApiVersion{},
+	}); err != nil {
+		t.Fatalf("Setup: CreateApiVersion() returned error: %s", err)
+	}
+	specName := "projects/limited/locations/global/apis/a/versions/v1/specs/s"
+	if _, err := server.CreateApiSpec(ctx, &rpc.CreateApiSpecRequest{
+		Parent:    "projects/limited/locations/global/apis/a/versions/v1",
+		ApiSpecId: "s",
+		ApiSpec:   &rpc.ApiSpec{Contents: []byte("1234")},
+	}); err != nil {
+		t.Fatalf("CreateApiSpec() returned error: %s", err)
+	}
+	updateContents := func(contents string) error {
+		_, err := server.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+			ApiSpec:    &rpc.ApiSpec{Name: specName, Contents: []byte(contents)},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"contents"}},
+		})
+		return err
+	}
+	// The second revision uses 8 of the 10 bytes allowed.
+	if err := updateContents("5678"); err != nil {
+		t.Fatalf("UpdateApiSpec() returned error: %s", err)
+	}
+	// Updates that don't create revisions are allowed.
+	if _, err := server.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+		ApiSpec:    &rpc.ApiSpec{Name: specName, Description: "updated"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"description"}},
+	}); err != nil {
+		t.Errorf("UpdateApiSpec() without a new revision returned error: %s", err)
+	}
+	checkQuotaExceeded(t, "UpdateApiSpec()", updateContents("9"))
+	spec, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: specName})
+	if err != nil {
+		t.Fatalf("GetApiSpec() returned error: %s", err)
+	}
+	_, err = server.RollbackApiSpec(ctx, &rpc.RollbackApiSpecRequest{Name: specName, RevisionId: spec.GetRevisionId()})
+	checkQuotaExceeded(t, "RollbackApiSpec()", err)
+
+	// Artifacts share the limit on contents with specs.
+	createArtifact := func(id, contents string) error {
+		_, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+			Parent:     "projects/limited/locations/global",
+			ArtifactId: id,
+			Artifact:   &rpc.Artifact{Contents: []byte(contents)},
+		})
+		return err
+	}
+	if err := createArtifact("x", "12"); err != nil {
+		t.Fatalf("CreateArtifact() returned error: %s", err)
+	}
+	checkQuotaExceeded(t, "CreateArtifact()", createArtifact("y", "1"))
+	// Replacements are measured without the contents that they replace.
+	if _, err := server.ReplaceArtifact(ctx, &rpc.ReplaceArtifactRequest{
+		Artifact: &rpc.Artifact{Name: "projects/limited/locations/global/artifacts/x", Contents: []byte("ab")},
+	}); err != nil {
+		t.Errorf("ReplaceArtifact() with contents of the same size returned error: %s", err)
+	}
+	_, err = server.ReplaceArtifact(ctx, &rpc.ReplaceArtifactRequest{
+		Artifact: &rpc.Artifact{Name: "projects/limited/locations/global/artifacts/x", Contents: []byte("abc")},
+	})
+	checkQuotaExceeded(t, "ReplaceArtifact()", err)
+
+	storage, err := server.GetStorage(ctx, &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("GetStorage() returned error: %s", err)
+	}
+	want := []*rpc.Storage_ProjectUsage{
+		{
+			Project:              "projects/limited",
+			ApiCount:             2,
+			ApiLimit:             2,
+			MaxSpecRevisionCount: 2,
+			SpecRevisionLimit:    2,
+			BlobBytes:            10,
+			BlobBytesLimit:       10,
+		},
+		{
+			Project:  "projects/unlimited",
+			ApiCount: 3,
+		},
+	}
+	if diff := cmp.Diff(want, storage.GetProjectUsage(), protocmp.Transform()); diff != "" {
+		t.Errorf("GetStorage() returned unexpected project usage (-want +got):\n%s", diff)
+	}
+}
+
+func TestUploadQuotas(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithQuotas(t, QuotaConfig{Default: QuotaLimits{MaxBlobBytes: 10}})
+	if _, err := server.CreateProject(ctx, &rpc.CreateProjectRequest{ProjectId: "limited", Project: &rpc.Project{}}); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+	listener, grpcServer, err := server.ServeGRPC(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Setup: failed to serve: %s", err)
+	}
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Setup: failed to dial server: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := rpc.NewRegistryClient(conn)
+
+	// Each artifact is uploaded by a single upload that is resumed by its ID.
+	uploads := make(map[string]string)
+	upload := func(id string, req *rpc.UploadArtifactContentsRequest) error {
+		stream, err := client.UploadArtifactContents(ctx)
+		if err != nil {
+			return err
+		}
+		req.Artifact = &rpc.Artifact{Name: "projects/limited/locations/global/artifacts/" + id}
+		req.UploadId = uploads[id]
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+		resp, err := stream.CloseAndRecv()
+		uploads[id] = resp.GetUploadId()
+		return err
+	}
+	blobBytes := func() int64 {
+		t.Helper()
+		storage, err := server.GetStorage(ctx, &emptypb.Empty{})
+		if err != nil {
+			t.Fatalf("GetStorage() returned error: %s", err)
+		}
+		return storage.GetProjectUsage()[0].GetBlobBytes()
+	}
+
+	// Pending uploads count towards the limit on contents.
+	if err := upload("a", &rpc.UploadArtifactContentsRequest{Data: []byte("123456")}); err != nil {
+		t.Fatalf("UploadArtifactContents() returned error: %s", err)
+	}
+	if got := blobBytes(); got != 6 {
+		t.Errorf("GetStorage() returned %d blob bytes with a pending upload, want 6", got)
+	}
+	_, err = server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     "projects/limited/locations/global",
+		ArtifactId: "b",
+		Artifact:   &rpc.Artifact{Contents: []byte("12345")},
+	})
+	checkQuotaExceeded(t, "CreateArtifact()", err)
+	checkQuotaExceeded(t, "UploadArtifactContents()", upload("c", &rpc.UploadArtifactContentsRequest{Data: []byte("12345")}))
+
+	// Finished uploads are only counted once.
+	if err := upload("a", &rpc.UploadArtifactContentsRequest{WriteOffset: 6, Data: []byte("78"), FinishWrite: true}); err != nil {
+		t.Fatalf("UploadArtifactContents() returned error: %s", err)
+	}
+	if got := blobBytes(); got != 8 {
+		t.Errorf("GetStorage() returned %d blob bytes after finishing an upload, want 8", got)
+	}
+}
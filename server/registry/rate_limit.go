@@ -0,0 +1,200 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimitConfig configures the rate limiting of calls.
+// Calls are limited separately for each caller in each project. Callers are
+// identified as in audit events, or by their addresses if they aren't identified.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate of calls that are allowed.
+	// If zero, calls are not limited.
+	RequestsPerSecond float64
+	// Burst is the number of calls that are allowed at once.
+	// If zero, it is the number of requests allowed per second (rounded up).
+	Burst int
+}
+
+// maxRateLimiters is the number of limiters above which idle limiters are discarded.
+const maxRateLimiters = 10000
+
+// rateLimiter keeps a token bucket for each caller of each project.
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+	// principalHeaders identify callers, see principal.
+	principalHeaders []string
+
+	mu       sync.Mutex
+	limiters map[string]*keyLimiter
+}
+
+type keyLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+func newRateLimiter(config RateLimitConfig, principalHeaders []string) *rateLimiter {
+	if config.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := config.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(config.RequestsPerSecond))
+	}
+	return &rateLimiter{
+		limit:            rate.Limit(config.RequestsPerSecond),
+		burst:            burst,
+		principalHeaders: principalHeaders,
+		limiters:         make(map[string]*keyLimiter),
+	}
+}
+
+// delay takes a token for a key. If no token is available, it returns the
+// time until one will be.
+func (r *rateLimiter) delay(key string, now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.limiters[key]
+	if !ok {
+		if len(r.limiters) >= maxRateLimiters {
+			r.discardIdle(now)
+		}
+		l = &keyLimiter{limiter: rate.NewLimiter(r.limit, r.burst)}
+		r.limiters[key] = l
+	}
+	l.lastUsed = now
+	reservation := l.limiter.ReserveN(now, 1)
+	if d := reservation.DelayFrom(now); d > 0 {
+		reservation.CancelAt(now)
+		return d
+	}
+	return 0
+}
+
+// discardIdle removes limiters that have been unused long enough to refill
+// their buckets, which are equivalent to new limiters.
+func (r *rateLimiter) discardIdle(now time.Time) {
+	refill := time.Duration(float64(r.burst) / float64(r.limit) * float64(time.Second))
+	for key, l := range r.limiters {
+		if now.Sub(l.lastUsed) > refill {
+			delete(r.limiters, key)
+		}
+	}
+}
+
+// check returns a ResourceExhausted error with retry information if
+// a request exceeds its rate limit.
+func (r *rateLimiter) check(ctx context.Context, req proto.Message) error {
+	project := projectOf(requestedProject(req))
+	caller := callerKey(ctx, r.principalHeaders)
+	delay := r.delay(project+"\x00"+caller, time.Now())
+	if delay == 0 {
+		return nil
+	}
+	if caller == "" {
+		caller = "anonymous callers"
+	}
+	if project == "" {
+		project = "-"
+	}
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded for %s in project %s, retry in %s", caller, project, delay.Round(time.Millisecond)))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// callerKey identifies the caller of a request for rate limiting. Callers
+// without a verified identity are identified by their network address,
+// without the port, so that new connections don't get new limits.
+func callerKey(ctx context.Context, principalHeaders []string) string {
+	if caller := principal(ctx, principalHeaders); caller != "" {
+		return caller
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "address " + addr
+}
+
+// requestedProject returns the name of the resource or collection identified by a request,
+// which includes the name of its project.
+func requestedProject(req proto.Message) string {
+	if name := requestedName(req); name != "" {
+		return name
+	}
+	m := req.ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName("parent"); fd != nil && fd.Kind() == protoreflect.StringKind {
+		return m.Get(fd).String()
+	}
+	return ""
+}
+
+// rateLimitUnary limits the rate of calls of unary methods.
+func (r *rateLimiter) rateLimitUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if m, ok := req.(proto.Message); ok {
+		if err := r.check(ctx, m); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// rateLimitStream limits the rate of calls of streaming methods,
+// which are identified by the first request of each stream.
+func (r *rateLimiter) rateLimitStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &rateLimitedStream{ServerStream: ss, limiter: r})
+}
+
+type rateLimitedStream struct {
+	grpc.ServerStream
+	limiter  *rateLimiter
+	received bool
+}
+
+func (s *rateLimitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok && !s.received {
+		s.received = true
+		return s.limiter.check(s.Context(), msg)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apigee/registry/rpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimiterDelay(t *testing.T) {
+	if newRateLimiter(RateLimitConfig{}, nil) != nil {
+		t.Errorf("newRateLimiter() returned a limiter for a config without a rate")
+	}
+	r := newRateLimiter(RateLimitConfig{RequestsPerSecond: 2, Burst: 2}, nil)
+	now := time.Unix(1000, 0)
+	for i := 0; i < 2; i++ {
+		if d := r.delay("a", now); d != 0 {
+			t.Errorf("delay() of call %d in burst returned %s, want 0", i+1, d)
+		}
+	}
+	if d := r.delay("a", now); d != 500*time.Millisecond {
+		t.Errorf("delay() above burst returned %s, want 500ms", d)
+	}
+	// Rejected calls don't use tokens.
+	if d := r.delay("a", now.Add(500*time.Millisecond)); d != 0 {
+		t.Errorf("delay() after refill returned %s, want 0", d)
+	}
+	if d := r.delay("b", now); d != 0 {
+		t.Errorf("delay() for another key returned %s, want 0", d)
+	}
+}
+
+func TestRateLimiterDiscardIdle(t *testing.T) {
+	r := newRateLimiter(RateLimitConfig{RequestsPerSecond: 1, Burst: 1}, nil)
+	now := time.Unix(1000, 0)
+	r.delay("idle", now)
+	r.delay("active", now.Add(2*time.Second))
+	r.discardIdle(now.Add(2 * time.Second))
+	if _, ok := r.limiters["idle"]; ok {
+		t.Errorf("discardIdle() kept an idle limiter")
+	}
+	if _, ok := r.limiters["active"]; !ok {
+		t.Errorf("discardIdle() discarded an active limiter")
+	}
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	r := newRateLimiter(RateLimitConfig{RequestsPerSecond: 0.001, Burst: 1}, []string{"x-forwarded-email"})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	call := func(caller, project string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-email", caller))
+		req := &rpc.ListApisRequest{Parent: "projects/" + project + "/locations/global"}
+		_, err := r.rateLimitUnary(ctx, req, &grpc.UnaryServerInfo{}, handler)
+		return err
+	}
+
+	if err := call("alice@example.com", "p1"); err != nil {
+		t.Fatalf("first call returned error: %s", err)
+	}
+	err := call("alice@example.com", "p1")
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("call above limit returned status code %q, want %q: %v", status.Code(err), codes.ResourceExhausted, err)
+	}
+	var retry *errdetails.RetryInfo
+	for _, d := range status.Convert(err).Details() {
+		if d, ok := d.(*errdetails.RetryInfo); ok {
+			retry = d
+		}
+	}
+	if retry == nil || retry.GetRetryDelay().AsDuration() <= 0 {
+		t.Errorf("call above limit returned %v, want RetryInfo with a positive delay", err)
+	}
+
+	if err := call("bob@example.com", "p1"); err != nil {
+		t.Errorf("call by another caller returned error: %s", err)
+	}
+	if err := call("alice@example.com", "p2"); err != nil {
+		t.Errorf("call to another project returned error: %s", err)
+	}
+}
+
+func TestRateLimitCallerKey(t *testing.T) {
+	r := newRateLimiter(RateLimitConfig{RequestsPerSecond: 0.001, Burst: 1}, nil)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	call := func(caller string, port int) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-email", caller))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: port}})
+		req := &rpc.ListApisRequest{Parent: "projects/p/locations/global"}
+		_, err := r.rateLimitUnary(ctx, req, &grpc.UnaryServerInfo{}, handler)
+		return err
+	}
+
+	if err := call("alice@example.com", 1000); err != nil {
+		t.Fatalf("first call returned error: %s", err)
+	}
+	// Untrusted headers and new connections don't get new limits.
+	if err := call("bob@example.com", 1001); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("call from the same address returned status code %q, want %q: %v", status.Code(err), codes.ResourceExhausted, err)
+	}
+}
+
+func TestRateLimitServer(t *testing.T) {
+	server, err := New(Config{
+		Database:  "sqlite3",
+		DBConfig:  t.TempDir() + "/registry.db",
+		RateLimit: RateLimitConfig{RequestsPerSecond: 0.001, Burst: 1},
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	listener, grpcServer, err := server.ServeGRPC(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Setup: failed to serve: %s", err)
+	}
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Setup: failed to dial server: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := rpc.NewRegistryClient(conn)
+
+	ctx := context.Background()
+	req := &rpc.GetApiRequest{Name: "projects/missing/locations/global/apis/a"}
+	if _, err := client.GetApi(ctx, req); status.Code(err) != codes.NotFound {
+		t.Errorf("GetApi() returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+	if _, err := client.GetApi(ctx, req); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("GetApi() above limit returned status code %q, want %q: %v", status.Code(err), codes.ResourceExhausted, err)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"strings"
+
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// readsAllFields returns true if a read mask includes every field of a resource.
+func readsAllFields(mask *fieldmaskpb.FieldMask) bool {
+	paths := mask.GetPaths()
+	return len(paths) == 0 || (len(paths) == 1 && paths[0] == "*")
+}
+
+// validateReadMask returns an error if a read mask names fields that a resource doesn't have.
+func validateReadMask(m proto.Message, mask *fieldmaskpb.FieldMask) error {
+	if readsAllFields(mask) {
+		return nil
+	}
+	if err := models.ValidateMask(m, mask); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid read_mask %v: %s", mask.GetPaths(), err)
+	}
+	return nil
+}
+
+// readMaskFields returns the top-level fields of a resource that are named in a read mask,
+// or nil if the mask includes every field.
+func readMaskFields(mask *fieldmaskpb.FieldMask) []string {
+	if readsAllFields(mask) {
+		return nil
+	}
+	fields := make([]string, 0, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		field, _, _ := strings.Cut(path, ".")
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// applyReadMask clears the fields of a resource that are not included in a valid read mask.
+func applyReadMask(m proto.Message, mask *fieldmaskpb.FieldMask) {
+	if readsAllFields(mask) {
+		return
+	}
+	prune(m.ProtoReflect(), mask.GetPaths())
+}
+
+func prune(m protoreflect.Message, paths []string) {
+	// Fields map to the subpaths that are kept, or to nil if the entire field is kept.
+	kept := make(map[protoreflect.Name][]string)
+	for _, path := range paths {
+		field, subpath, nested := strings.Cut(path, ".")
+		subpaths, found := kept[protoreflect.Name(field)]
+		if found && subpaths == nil {
+			continue
+		} else if !nested {
+			kept[protoreflect.Name(field)] = nil
+		} else {
+			kept[protoreflect.Name(field)] = append(subpaths, subpath)
+		}
+	}
+
+	cleared := make([]protoreflect.FieldDescriptor, 0)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		subpaths, found := kept[fd.Name()]
+		if !found {
+			cleared = append(cleared, fd)
+		} else if subpaths != nil && fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+			prune(v.Message(), subpaths)
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
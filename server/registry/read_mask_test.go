@@ -0,0 +1,190 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestReadMasks(t *testing.T) {
+	ctx := context.Background()
+	server := defaultTestServer(t)
+	if err := seeder.SeedRegistry(ctx, server,
+		&rpc.Api{
+			Name:        "projects/my-project/locations/global/apis/a",
+			DisplayName: "A",
+			Description: "An API",
+			Labels:      map[string]string{"team": "blue"},
+		},
+		&rpc.ApiSpec{
+			Name:     "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+			Filename: "openapi.yaml",
+			MimeType: "application/x.openapi",
+			Contents: []byte("openapi: 3.0.0"),
+		},
+		&rpc.Artifact{
+			Name:     "projects/my-project/locations/global/artifacts/x",
+			MimeType: "text/plain",
+			Contents: []byte("x"),
+		},
+	); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+
+	mask := func(paths ...string) *fieldmaskpb.FieldMask {
+		return &fieldmaskpb.FieldMask{Paths: paths}
+	}
+
+	t.Run("GetApi", func(t *testing.T) {
+		got, err := server.GetApi(ctx, &rpc.GetApiRequest{
+			Name:     "projects/my-project/locations/global/apis/a",
+			ReadMask: mask("name", "labels"),
+		})
+		if err != nil {
+			t.Fatalf("GetApi() returned error: %s", err)
+		}
+		want := &rpc.Api{
+			Name:   "projects/my-project/locations/global/apis/a",
+			Labels: map[string]string{"team": "blue"},
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("GetApi() returned unexpected response (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("GetApiSpec nested field", func(t *testing.T) {
+		got, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{
+			Name:     "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+			ReadMask: mask("create_time.seconds"),
+		})
+		if err != nil {
+			t.Fatalf("GetApiSpec() returned error: %s", err)
+		}
+		if got.GetName() != "" || got.GetFilename() != "" {
+			t.Errorf("GetApiSpec() returned unmasked fields: %v", got)
+		}
+		if got.GetCreateTime().GetSeconds() == 0 || got.GetCreateTime().GetNanos() != 0 {
+			t.Errorf("GetApiSpec() returned create_time %v, want only seconds", got.GetCreateTime())
+		}
+	})
+
+	t.Run("ListApis", func(t *testing.T) {
+		got, err := server.ListApis(ctx, &rpc.ListApisRequest{
+			Parent:   "projects/my-project/locations/global",
+			ReadMask: mask("name"),
+		})
+		if err != nil {
+			t.Fatalf("ListApis() returned error: %s", err)
+		}
+		want := &rpc.ListApisResponse{
+			Apis: []*rpc.Api{{Name: "projects/my-project/locations/global/apis/a"}},
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("ListApis() returned unexpected response (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("ListApis with filter on unread field", func(t *testing.T) {
+		got, err := server.ListApis(ctx, &rpc.ListApisRequest{
+			Parent:   "projects/my-project/locations/global",
+			Filter:   "labels.team == 'blue'",
+			ReadMask: mask("display_name"),
+		})
+		if err != nil {
+			t.Fatalf("ListApis() returned error: %s", err)
+		}
+		want := &rpc.ListApisResponse{
+			Apis: []*rpc.Api{{DisplayName: "A"}},
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("ListApis() returned unexpected response (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("ListApiSpecs", func(t *testing.T) {
+		got, err := server.ListApiSpecs(ctx, &rpc.ListApiSpecsRequest{
+			Parent:   "projects/my-project/locations/global/apis/a/versions/v1",
+			ReadMask: mask("name", "filename", "size_bytes"),
+		})
+		if err != nil {
+			t.Fatalf("ListApiSpecs() returned error: %s", err)
+		}
+		want := &rpc.ListApiSpecsResponse{
+			ApiSpecs: []*rpc.ApiSpec{{
+				Name:      "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+				Filename:  "openapi.yaml",
+				SizeBytes: int32(len("openapi: 3.0.0")),
+			}},
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("ListApiSpecs() returned unexpected response (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("ListArtifacts", func(t *testing.T) {
+		got, err := server.ListArtifacts(ctx, &rpc.ListArtifactsRequest{
+			Parent:   "projects/my-project/locations/global",
+			ReadMask: mask("name", "mime_type"),
+		})
+		if err != nil {
+			t.Fatalf("ListArtifacts() returned error: %s", err)
+		}
+		want := &rpc.ListArtifactsResponse{
+			Artifacts: []*rpc.Artifact{{
+				Name:     "projects/my-project/locations/global/artifacts/x",
+				MimeType: "text/plain",
+			}},
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("ListArtifacts() returned unexpected response (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		got, err := server.GetProject(ctx, &rpc.GetProjectRequest{
+			Name:     "projects/my-project",
+			ReadMask: mask("*"),
+		})
+		if err != nil {
+			t.Fatalf("GetProject() returned error: %s", err)
+		}
+		if got.GetName() == "" || got.GetCreateTime() == nil {
+			t.Errorf("GetProject() returned incomplete project: %v", got)
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := server.ListProjects(ctx, &rpc.ListProjectsRequest{ReadMask: mask("name", "color")})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ListProjects() returned status code %q, want %q: %v", status.Code(err), codes.InvalidArgument, err)
+		}
+		_, err = server.GetApiDeployment(ctx, &rpc.GetApiDeploymentRequest{
+			Name:     "projects/my-project/locations/global/apis/a/deployments/d",
+			ReadMask: mask("color"),
+		})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GetApiDeployment() returned status code %q, want %q: %v", status.Code(err), codes.InvalidArgument, err)
+		}
+	})
+}
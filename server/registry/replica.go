@@ -0,0 +1,60 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"google.golang.org/grpc/metadata"
+)
+
+// ReadPrimaryHeader is a request header that, when set to "true", directs
+// the reads of a call to the primary database so that callers can read
+// their own writes when a read replica is configured.
+const ReadPrimaryHeader = "x-registry-read-primary"
+
+// getReadClient returns the storage client to use for non-transactional reads.
+// Reads use the read replica, if there is one, unless the primary is requested.
+func (s *RegistryServer) getReadClient(ctx context.Context) (*storage.Client, error) {
+	db, err := s.getStorageClient(ctx)
+	if err != nil || readsPrimary(ctx) {
+		return db, err
+	}
+	return db.Reader(), nil
+}
+
+type primaryReadsKey struct{}
+
+// withPrimaryReads returns a context in which reads use the primary database.
+// It is used when reads must observe writes made earlier in the same call.
+func withPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// readsPrimary returns true if reads in a context must use the primary database.
+func readsPrimary(ctx context.Context) bool {
+	if primary, _ := ctx.Value(primaryReadsKey{}).(bool); primary {
+		return true
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(ReadPrimaryHeader) {
+		if primary, _ := strconv.ParseBool(v); primary {
+			return true
+		}
+	}
+	return false
+}
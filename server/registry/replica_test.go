@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadReplica(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	primary := fmt.Sprintf("%s/primary.db", dir)
+	replica := fmt.Sprintf("%s/replica.db", dir)
+
+	// Stand in for replication lag with a replica that has diverged from the primary.
+	setup, err := New(Config{Database: "sqlite3", DBConfig: replica})
+	if err != nil {
+		t.Fatalf("Setup: failed to get replica server: %s", err)
+	}
+	if _, err := setup.CreateProject(ctx, &rpc.CreateProjectRequest{ProjectId: "replica-only", Project: &rpc.Project{}}); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+	setup.Close()
+
+	server, err := New(Config{Database: "sqlite3", DBConfig: primary, DBReplicaConfig: replica})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	if _, err := server.CreateProject(ctx, &rpc.CreateProjectRequest{ProjectId: "primary-only", Project: &rpc.Project{}}); err != nil {
+		t.Fatalf("Setup: CreateProject() returned error: %s", err)
+	}
+
+	readPrimary := metadata.NewIncomingContext(ctx, metadata.Pairs(ReadPrimaryHeader, "true"))
+	tests := []struct {
+		desc    string
+		ctx     context.Context
+		project string
+		want    codes.Code
+	}{
+		{"replica read of replica project", ctx, "replica-only", codes.OK},
+		{"replica read of primary project", ctx, "primary-only", codes.NotFound},
+		{"primary read of replica project", readPrimary, "replica-only", codes.NotFound},
+		{"primary read of primary project", readPrimary, "primary-only", codes.OK},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := server.GetProject(test.ctx, &rpc.GetProjectRequest{Name: "projects/" + test.project})
+			if status.Code(err) != test.want {
+				t.Errorf("GetProject(%q) returned status code %q, want %q: %v", test.project, status.Code(err), test.want, err)
+			}
+
+			list, err := server.ListProjects(test.ctx, &rpc.ListProjectsRequest{})
+			if err != nil {
+				t.Fatalf("ListProjects() returned error: %s", err)
+			}
+			found := false
+			for _, p := range list.GetProjects() {
+				found = found || p.GetName() == "projects/"+test.project
+			}
+			if want := test.want == codes.OK; found != want {
+				t.Errorf("ListProjects() included %q: %t, want %t", test.project, found, want)
+			}
+		})
+	}
+}
@@ -17,12 +17,17 @@ package registry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/apigee/registry/rpc"
 	"github.com/apigee/registry/server/registry/internal/storage"
+	"google.golang.org/genproto/googleapis/longrunning"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
@@ -37,33 +42,114 @@ type Config struct {
 	LogFormat string
 	Notify    bool
 	ProjectID string
+
+	// ValidateReferences requires references between resources to be valid
+	// when they are created or updated.
+	ValidateReferences bool
+	// ReferenceDeletion controls the deletion of referenced resources.
+	// Options are "ignore" (the default), "reject", and "clear".
+	ReferenceDeletion string
+	// Webhooks are called to approve mutations before they are committed.
+	Webhooks []WebhookConfig
+	// SummarizeSpecs enables the extraction of metadata from spec contents
+	// into a summary artifact that is attached to each spec.
+	SummarizeSpecs bool
+	// Audit enables the recording of audit events for calls of methods
+	// that change resources.
+	Audit bool
+	// PrincipalHeaders are the metadata keys that identify callers in audit
+	// events and rate limits. They must only be set when the server is behind
+	// an authenticating proxy that sets them and removes them from client
+	// requests, e.g. "x-goog-authenticated-user-email" for Identity-Aware Proxy.
+	// Otherwise, callers are only identified by verified client certificates.
+	PrincipalHeaders []string
+	// Quotas limit the resources of projects.
+	Quotas QuotaConfig
+	// RateLimit limits the rate of calls by each caller to each project.
+	RateLimit RateLimitConfig
+	// DBReplicaConfig is the data source name of a read-only replica of the
+	// database, which must use the same driver. When set, Get and List calls
+	// read from the replica unless they set the ReadPrimaryHeader.
+	DBReplicaConfig string
+	// UploadExpiration is the time after which unfinished uploads and project
+	// archives that haven't received any data are deleted. It defaults to
+	// DefaultUploadExpiration.
+	UploadExpiration time.Duration
+	// InstanceDir is the directory of the SQLite databases of instances that are
+	// created with the Provisioning service. It defaults to the directory of the
+	// server's database. With PostgreSQL, instances are kept in separate schemas.
+	InstanceDir string
 }
 
 // RegistryServer implements a Registry server.
 type RegistryServer struct {
-	database      string
-	dbConfig      string
-	notifyEnabled bool
-	projectID     string
-	storageClient *storage.Client
-	pubSubClient  *pubsub.Client
+	database         string
+	dbConfig         string
+	replicaConfig    string
+	notifyEnabled    bool
+	projectID        string
+	validateRefs     bool
+	refDeletion      string
+	webhooks         []WebhookConfig
+	summarizeSpecs   bool
+	auditEnabled     bool
+	principalHeaders []string
+	quotas           QuotaConfig
+	rateLimiter      *rateLimiter
+	instanceDir      string
+	instances        *instances
+	operations       *operations
+	uploadExpiration time.Duration
+	stopSweeping     chan struct{}
+	sweeping         sync.WaitGroup
+	storageClient    *storage.Client
+	pubSubClient     *pubsub.Client
 
 	rpc.UnimplementedRegistryServer
 	rpc.UnimplementedAdminServer
+	rpc.UnimplementedProvisioningServer
+	longrunning.UnimplementedOperationsServer
 }
 
 func New(config Config) (*RegistryServer, error) {
 	s := &RegistryServer{
-		database:      config.Database,
-		dbConfig:      config.DBConfig,
-		notifyEnabled: config.Notify,
-		projectID:     config.ProjectID,
+		database:         config.Database,
+		dbConfig:         config.DBConfig,
+		replicaConfig:    config.DBReplicaConfig,
+		notifyEnabled:    config.Notify,
+		projectID:        config.ProjectID,
+		validateRefs:     config.ValidateReferences,
+		refDeletion:      config.ReferenceDeletion,
+		webhooks:         config.Webhooks,
+		summarizeSpecs:   config.SummarizeSpecs,
+		auditEnabled:     config.Audit,
+		principalHeaders: config.PrincipalHeaders,
+		quotas:           config.Quotas,
+		rateLimiter:      newRateLimiter(config.RateLimit, config.PrincipalHeaders),
+		instanceDir:      config.InstanceDir,
+		instances:        newInstances(),
+		operations:       newOperations(),
+		uploadExpiration: config.UploadExpiration,
+		stopSweeping:     make(chan struct{}),
+	}
+	if s.uploadExpiration <= 0 {
+		s.uploadExpiration = DefaultUploadExpiration
 	}
 
 	if s.database == "" {
 		s.database = "sqlite3"
 		s.dbConfig = "/tmp/registry.db"
 	}
+	if s.instanceDir == "" && s.database == "sqlite3" {
+		s.instanceDir = filepath.Join(filepath.Dir(s.dbConfig), "instances")
+	}
+
+	switch s.refDeletion {
+	case "", ReferenceDeletionIgnore, ReferenceDeletionReject, ReferenceDeletionClear:
+	default:
+		return nil, fmt.Errorf("invalid reference deletion %q: must be one of %q, %q, or %q",
+			s.refDeletion, ReferenceDeletionIgnore, ReferenceDeletionReject, ReferenceDeletionClear)
+	}
 
 	var err error
 	ctx := context.Background()
@@ -74,6 +160,14 @@ func New(config Config) (*RegistryServer, error) {
 	if err := s.storageClient.EnsureTables(ctx); err != nil {
 		return nil, err
 	}
+	if err := s.finishAbandonedOperations(ctx); err != nil {
+		return nil, err
+	}
+	if s.replicaConfig != "" {
+		if err := s.storageClient.OpenReplica(ctx, s.database, s.replicaConfig); err != nil {
+			return nil, err
+		}
+	}
 
 	if s.notifyEnabled {
 		s.pubSubClient, err = pubsub.NewClient(ctx, s.projectID)
@@ -85,16 +179,33 @@ func New(config Config) (*RegistryServer, error) {
 		}
 	}
 
+	s.sweeping.Add(1)
+	go s.sweepUploads()
+
 	return s, nil
 }
 
 func (s *RegistryServer) getStorageClient(ctx context.Context) (*storage.Client, error) {
+	if c, ok := ctx.Value(transactionKey{}).(*storage.Client); ok && c != nil {
+		return c, nil
+	}
+	if c, ok := ctx.Value(instanceClientKey{}).(*storage.Client); ok {
+		return c, nil
+	}
 	if s.storageClient == nil {
 		return nil, errors.New("no storageClient")
 	}
 	return s.storageClient, nil
 }
 
+type transactionKey struct{}
+
+// withTransaction returns a context in which storage is read from and written to
+// a transaction, so that changes made by nested calls are committed together.
+func withTransaction(ctx context.Context, db *storage.Client) context.Context {
+	return context.WithValue(ctx, transactionKey{}, db)
+}
+
 func (s *RegistryServer) runInTransaction(ctx context.Context, fn func(ctx context.Context, db *storage.Client) error) error {
 	db, err := s.getStorageClient(ctx)
 	if err != nil {
@@ -111,6 +222,10 @@ func (s *RegistryServer) getPubSubClient(ctx context.Context) (*pubsub.Client, e
 }
 
 func (s *RegistryServer) Close() {
+	close(s.stopSweeping)
+	s.sweeping.Wait()
+	s.operations.cancelAll()
+	s.instances.closeAll()
 	s.storageClient.Close()
 	if s.pubSubClient != nil {
 		s.pubSubClient.Topic(TopicName).Flush()
@@ -129,10 +244,19 @@ func (rs *RegistryServer) ServeGRPC(addr *net.TCPAddr, opt ...grpc.ServerOption)
 		return nil, nil, err
 	}
 
+	opt = append(opt, grpc.ChainUnaryInterceptor(rs.instanceUnary), grpc.ChainStreamInterceptor(rs.instanceStream))
+	if rs.rateLimiter != nil {
+		opt = append(opt, grpc.ChainUnaryInterceptor(rs.rateLimiter.rateLimitUnary), grpc.ChainStreamInterceptor(rs.rateLimiter.rateLimitStream))
+	}
+	if rs.auditEnabled {
+		opt = append(opt, grpc.ChainUnaryInterceptor(rs.auditUnary))
+	}
 	s := grpc.NewServer(opt...)
 	reflection.Register(s)
 	rpc.RegisterRegistryServer(s, rs)
 	rpc.RegisterAdminServer(s, rs)
+	rpc.RegisterProvisioningServer(s, rs)
+	longrunning.RegisterOperationsServer(s, rs)
 
 	go func() {
 		if err := s.Serve(l); err != nil {
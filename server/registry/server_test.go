@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/apigee/registry/rpc"
+	"google.golang.org/genproto/googleapis/longrunning"
 	"github.com/apigee/registry/server/registry/test/remote"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -54,6 +55,7 @@ func init() {
 type TestServer interface {
 	rpc.AdminServer
 	rpc.RegistryServer
+	longrunning.OperationsServer
 }
 
 // defaultTestServer will call server.Close() when test completes
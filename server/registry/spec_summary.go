@@ -0,0 +1,108 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	"github.com/apigee/registry/log"
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/internal/summary"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// SpecSummaryArtifactID is the ID of the spec artifact that contains
+	// metadata extracted from the current revision of the spec. It begins
+	// with a ".", which user-provided IDs can't, so it is reserved for summaries.
+	SpecSummaryArtifactID = ".summary"
+	// SpecSummaryMimeType is the MIME type of spec summary artifacts.
+	SpecSummaryMimeType = "application/octet-stream;type=google.cloud.apigeeregistry.v1.apihub.SpecSummary"
+)
+
+// updateSpecSummary replaces the summary artifact of a spec with one describing
+// the spec's current revision. Summaries of specs that can't be summarized are
+// deleted so that they never describe an older revision. Artifacts with the
+// summary's ID that aren't summaries are neither replaced nor deleted.
+func (s *RegistryServer) updateSpecSummary(ctx context.Context, db *storage.Client, name names.Spec) error {
+	if !s.summarizeSpecs {
+		return nil
+	}
+
+	artifactName := name.Artifact(SpecSummaryArtifactID)
+	existing, err := db.GetArtifact(ctx, artifactName)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if existing != nil && existing.MimeType != SpecSummaryMimeType {
+		log.FromContext(ctx).Warnf("Not updating %s: it has MIME type %q, not %q", artifactName, existing.MimeType, SpecSummaryMimeType)
+		return nil
+	}
+
+	spec, err := db.GetSpec(ctx, name)
+	if isNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	blob, err := db.GetSpecRevisionContents(ctx, name.Revision(spec.RevisionID))
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	var specSummary *rpc.SpecSummary
+	if blob != nil && len(blob.Contents) > 0 {
+		specSummary, err = summary.Summarize(spec.MimeType, blob.Contents)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Debugf("Failed to summarize %s", spec.RevisionName())
+		}
+	}
+	if specSummary == nil {
+		if existing == nil {
+			return nil
+		}
+		if err := db.DeleteArtifact(ctx, artifactName); err != nil && !isNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	specSummary.Id = SpecSummaryArtifactID
+	specSummary.Kind = "SpecSummary"
+	specSummary.RevisionId = spec.RevisionID
+	contents, err := proto.Marshal(specSummary)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	artifact, err := models.NewArtifact(artifactName, &rpc.Artifact{
+		MimeType: SpecSummaryMimeType,
+		Contents: contents,
+	})
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		artifact.CreateTime = existing.CreateTime
+	}
+	if err := db.SaveArtifact(ctx, artifact); err != nil {
+		return err
+	}
+	return db.SaveArtifactContents(ctx, artifact, contents)
+}
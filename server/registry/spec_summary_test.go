@@ -0,0 +1,209 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/internal/storage/models"
+	"github.com/apigee/registry/server/registry/names"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// serverWithSpecSummaries will call server.Close() when test completes
+func serverWithSpecSummaries(t *testing.T) *RegistryServer {
+	t.Helper()
+	server, err := New(Config{
+		Database:       "sqlite3",
+		DBConfig:       fmt.Sprintf("%s/registry.db", t.TempDir()),
+		SummarizeSpecs: true,
+	})
+	if server != nil {
+		t.Cleanup(server.Close)
+	}
+	if err != nil {
+		t.Fatalf("Setup: failed to get server: %s", err)
+	}
+	return server
+}
+
+func getSpecSummary(ctx context.Context, t *testing.T, server *RegistryServer, spec string) (*rpc.SpecSummary, error) {
+	t.Helper()
+	contents, err := server.GetArtifactContents(ctx, &rpc.GetArtifactContentsRequest{
+		Name: spec + "/artifacts/" + SpecSummaryArtifactID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if contents.GetContentType() != SpecSummaryMimeType {
+		t.Errorf("GetArtifactContents() returned content type %q, want %q", contents.GetContentType(), SpecSummaryMimeType)
+	}
+	summary := new(rpc.SpecSummary)
+	if err := proto.Unmarshal(contents.GetData(), summary); err != nil {
+		t.Fatalf("Failed to unmarshal summary: %s", err)
+	}
+	return summary, nil
+}
+
+func TestSpecSummaries(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithSpecSummaries(t)
+	const name = "projects/my-project/locations/global/apis/a/versions/v1/specs/s"
+	first := &rpc.ApiSpec{
+		Name:     name,
+		MimeType: "application/x.openapi;version=3",
+		Contents: []byte("openapi: 3.0.0\ninfo:\n  title: First\n  version: 1.0.0\npaths: {}\n"),
+	}
+	if err := seeder.SeedSpecs(ctx, server, first); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+
+	created, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: name})
+	if err != nil {
+		t.Fatalf("GetApiSpec() returned error: %s", err)
+	}
+	got, err := getSpecSummary(ctx, t, server, name)
+	if err != nil {
+		t.Fatalf("Summary of created spec: %s", err)
+	}
+	want := &rpc.SpecSummary{
+		Id:         SpecSummaryArtifactID,
+		Kind:       "SpecSummary",
+		RevisionId: created.GetRevisionId(),
+		MimeType:   "application/x.openapi;version=3",
+		Title:      "First",
+		Version:    "1.0.0",
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Summary of created spec returned unexpected diff (-want +got):\n%s", diff)
+	}
+
+	updated, err := server.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+		ApiSpec: &rpc.ApiSpec{
+			Name:     name,
+			Contents: []byte("openapi: 3.0.0\ninfo:\n  title: Second\n  version: 2.0.0\npaths: {}\n"),
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"contents"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateApiSpec() returned error: %s", err)
+	}
+	got, err = getSpecSummary(ctx, t, server, name)
+	if err != nil {
+		t.Fatalf("Summary of updated spec: %s", err)
+	}
+	if got.GetTitle() != "Second" || got.GetRevisionId() != updated.GetRevisionId() {
+		t.Errorf("Summary of updated spec has title %q and revision %q, want %q and %q", got.GetTitle(), got.GetRevisionId(), "Second", updated.GetRevisionId())
+	}
+
+	if _, err := server.DeleteApiSpecRevision(ctx, &rpc.DeleteApiSpecRevisionRequest{
+		Name: name + "@" + updated.GetRevisionId(),
+	}); err != nil {
+		t.Fatalf("DeleteApiSpecRevision() returned error: %s", err)
+	}
+	got, err = getSpecSummary(ctx, t, server, name)
+	if err != nil {
+		t.Fatalf("Summary after revision deletion: %s", err)
+	}
+	if got.GetTitle() != "First" || got.GetRevisionId() != created.GetRevisionId() {
+		t.Errorf("Summary after revision deletion has title %q and revision %q, want %q and %q", got.GetTitle(), got.GetRevisionId(), "First", created.GetRevisionId())
+	}
+
+	if _, err := server.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+		ApiSpec:    &rpc.ApiSpec{Name: name, MimeType: "text/plain"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"mime_type"}},
+	}); err != nil {
+		t.Fatalf("UpdateApiSpec() returned error: %s", err)
+	}
+	if _, err := getSpecSummary(ctx, t, server, name); status.Code(err) != codes.NotFound {
+		t.Errorf("Summary of unsupported spec returned status code %q, want %q: %v", status.Code(err), codes.NotFound, err)
+	}
+}
+
+func TestSpecSummariesDisabled(t *testing.T) {
+	ctx := context.Background()
+	server := defaultTestServer(t)
+	const name = "projects/my-project/locations/global/apis/a/versions/v1/specs/s"
+	if err := seeder.SeedSpecs(ctx, server, &rpc.ApiSpec{
+		Name:     name,
+		MimeType: "application/x.openapi;version=3",
+		Contents: []byte("openapi: 3.0.0\ninfo:\n  title: First\n  version: 1.0.0\npaths: {}\n"),
+	}); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	req := &rpc.GetArtifactRequest{Name: name + "/artifacts/" + SpecSummaryArtifactID}
+	if _, err := server.GetArtifact(ctx, req); status.Code(err) != codes.NotFound {
+		t.Errorf("GetArtifact(%+v) returned status code %q, want %q: %v", req, status.Code(err), codes.NotFound, err)
+	}
+}
+
+func TestSpecSummaryArtifactIsReserved(t *testing.T) {
+	ctx := context.Background()
+	server := serverWithSpecSummaries(t)
+	const name = "projects/my-project/locations/global/apis/a/versions/v1/specs/s"
+	if err := seeder.SeedSpecs(ctx, server, &rpc.ApiSpec{Name: name, MimeType: "text/plain"}); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+
+	// Users can't create artifacts with the summary's ID.
+	if _, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     name,
+		ArtifactId: SpecSummaryArtifactID,
+		Artifact:   &rpc.Artifact{MimeType: "text/plain"},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateArtifact() with ID %q returned status code %q, want %q: %v", SpecSummaryArtifactID, status.Code(err), codes.InvalidArgument, err)
+	}
+
+	// Artifacts with the summary's ID that aren't summaries are never replaced or deleted.
+	spec, err := names.ParseSpec(name)
+	if err != nil {
+		t.Fatalf("Setup: ParseSpec() returned error: %s", err)
+	}
+	other, err := models.NewArtifact(spec.Artifact(SpecSummaryArtifactID), &rpc.Artifact{MimeType: "text/plain"})
+	if err != nil {
+		t.Fatalf("Setup: NewArtifact() returned error: %s", err)
+	}
+	if err := server.storageClient.SaveArtifact(ctx, other); err != nil {
+		t.Fatalf("Setup: SaveArtifact() returned error: %s", err)
+	}
+	for _, mimeType := range []string{"application/x.openapi;version=3", "text/plain"} {
+		if _, err := server.UpdateApiSpec(ctx, &rpc.UpdateApiSpecRequest{
+			ApiSpec: &rpc.ApiSpec{
+				Name:     name,
+				MimeType: mimeType,
+				Contents: []byte("openapi: 3.0.0\ninfo:\n  title: Other\n  version: 1.0.0\npaths: {}\n"),
+			},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"mime_type", "contents"}},
+		}); err != nil {
+			t.Fatalf("UpdateApiSpec() returned error: %s", err)
+		}
+		got, err := server.GetArtifact(ctx, &rpc.GetArtifactRequest{Name: name + "/artifacts/" + SpecSummaryArtifactID})
+		if err != nil {
+			t.Fatalf("GetArtifact() after update to %q returned error: %s", mimeType, err)
+		}
+		if got.GetMimeType() != "text/plain" {
+			t.Errorf("GetArtifact() after update to %q returned MIME type %q, want %q", mimeType, got.GetMimeType(), "text/plain")
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/apigee/registry/server/registry/internal/storage"
+	"github.com/apigee/registry/server/registry/internal/validation"
+	"github.com/apigee/registry/server/registry/names"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SpecValidationArtifactID is the ID of a project-level artifact that enables
+// validation of uploaded spec contents. Projects without this artifact accept
+// spec contents without checking them.
+const SpecValidationArtifactID = "spec-validation"
+
+// validateSpecContents checks that spec contents can be parsed according to their
+// MIME type if validation is enabled for the spec's project.
+func (s *RegistryServer) validateSpecContents(ctx context.Context, db *storage.Client, name names.Spec, mimeType string, contents []byte) error {
+	if len(contents) == 0 || !validation.Supported(mimeType) {
+		return nil
+	}
+	if _, err := db.GetArtifact(ctx, name.Project().Artifact(SpecValidationArtifactID)); isNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Compressed contents can't uncompress to more than the project's limit on contents.
+	err := validation.ValidateSpec(mimeType, contents, s.quotas.limits(name.ProjectID).MaxBlobBytes)
+	var verr *validation.Error
+	if !errors.As(err, &verr) {
+		return err
+	}
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(verr.Problems))
+	for i, p := range verr.Problems {
+		violations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       "api_spec.contents",
+			Description: p.String(),
+		}
+	}
+	st := status.Newf(codes.InvalidArgument, "invalid contents for API spec %q with mime_type %q:\n%s", name, mimeType, verr)
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,206 @@
+// Copyright 2022 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/apigee/registry/rpc"
+	"github.com/apigee/registry/server/registry/test/seeder"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	validOpenAPI = `openapi: 3.0.0
+info:
+  title: Sample
+  version: 1.0.0
+paths: {}
+`
+	invalidOpenAPI = `openapi: 3.0.0
+info:
+  title: Sample
+  version: 1.0.0
+paths:
+  /items:
+    get: 7
+`
+)
+
+func TestSpecValidation(t *testing.T) {
+	tests := []struct {
+		desc     string
+		enabled  bool
+		mimeType string
+		contents string
+		want     codes.Code
+	}{
+		{
+			desc:     "disabled",
+			mimeType: "application/x.openapi;version=3",
+			contents: invalidOpenAPI,
+			want:     codes.OK,
+		},
+		{
+			desc:     "enabled with valid contents",
+			enabled:  true,
+			mimeType: "application/x.openapi;version=3",
+			contents: validOpenAPI,
+			want:     codes.OK,
+		},
+		{
+			desc:     "enabled with invalid contents",
+			enabled:  true,
+			mimeType: "application/x.openapi;version=3",
+			contents: invalidOpenAPI,
+			want:     codes.InvalidArgument,
+		},
+		{
+			desc:     "enabled with unsupported type",
+			enabled:  true,
+			mimeType: "text/plain",
+			contents: invalidOpenAPI,
+			want:     codes.OK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			server := defaultTestServer(t)
+			if err := seeder.SeedVersions(ctx, server, &rpc.ApiVersion{Name: "projects/my-project/locations/global/apis/a/versions/v1"}); err != nil {
+				t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+			}
+			if test.enabled {
+				if _, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+					Parent:     "projects/my-project/locations/global",
+					ArtifactId: SpecValidationArtifactID,
+					Artifact:   &rpc.Artifact{},
+				}); err != nil {
+					t.Fatalf("Setup: CreateArtifact() returned error: %s", err)
+				}
+			}
+
+			req := &rpc.CreateApiSpecRequest{
+				Parent:    "projects/my-project/locations/global/apis/a/versions/v1",
+				ApiSpecId: "s",
+				ApiSpec: &rpc.ApiSpec{
+					MimeType: test.mimeType,
+					Contents: []byte(test.contents),
+				},
+			}
+			_, err := server.CreateApiSpec(ctx, req)
+			if status.Code(err) != test.want {
+				t.Fatalf("CreateApiSpec(%+v) returned status code %q, want %q: %v", req, status.Code(err), test.want, err)
+			}
+			if err == nil {
+				return
+			}
+
+			var violations []*errdetails.BadRequest_FieldViolation
+			for _, d := range status.Convert(err).Details() {
+				if br, ok := d.(*errdetails.BadRequest); ok {
+					violations = append(violations, br.GetFieldViolations()...)
+				}
+			}
+			if len(violations) != 1 {
+				t.Fatalf("CreateApiSpec(%+v) returned %d field violations, want 1", req, len(violations))
+			}
+			if !strings.HasPrefix(violations[0].GetDescription(), "7:10: ") {
+				t.Errorf("CreateApiSpec(%+v) returned violation %q, want location 7:10", req, violations[0].GetDescription())
+			}
+		})
+	}
+}
+
+func TestSpecValidationOnUpdate(t *testing.T) {
+	ctx := context.Background()
+	server := defaultTestServer(t)
+	spec := &rpc.ApiSpec{
+		Name:     "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+		MimeType: "application/x.openapi;version=3",
+		Contents: []byte(validOpenAPI),
+	}
+	if err := seeder.SeedSpecs(ctx, server, spec); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	if _, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     "projects/my-project/locations/global",
+		ArtifactId: SpecValidationArtifactID,
+		Artifact:   &rpc.Artifact{},
+	}); err != nil {
+		t.Fatalf("Setup: CreateArtifact() returned error: %s", err)
+	}
+
+	req := &rpc.UpdateApiSpecRequest{
+		ApiSpec: &rpc.ApiSpec{
+			Name:     spec.GetName(),
+			Contents: []byte(invalidOpenAPI),
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"contents"}},
+	}
+	if _, err := server.UpdateApiSpec(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("UpdateApiSpec(%+v) returned status code %q, want %q: %v", req, status.Code(err), codes.InvalidArgument, err)
+	}
+
+	got, err := server.GetApiSpecContents(ctx, &rpc.GetApiSpecContentsRequest{Name: spec.GetName()})
+	if err != nil {
+		t.Fatalf("GetApiSpecContents() returned error: %s", err)
+	}
+	if string(got.GetData()) != validOpenAPI {
+		t.Errorf("GetApiSpecContents() returned %q, want rejected update to be discarded", got.GetData())
+	}
+}
+
+func TestSpecValidationOnMimeTypeUpdate(t *testing.T) {
+	ctx := context.Background()
+	server := defaultTestServer(t)
+	spec := &rpc.ApiSpec{
+		Name:     "projects/my-project/locations/global/apis/a/versions/v1/specs/s",
+		MimeType: "text/plain",
+		Contents: []byte(invalidOpenAPI),
+	}
+	if err := seeder.SeedSpecs(ctx, server, spec); err != nil {
+		t.Fatalf("Setup/Seeding: Failed to seed registry: %s", err)
+	}
+	if _, err := server.CreateArtifact(ctx, &rpc.CreateArtifactRequest{
+		Parent:     "projects/my-project/locations/global",
+		ArtifactId: SpecValidationArtifactID,
+		Artifact:   &rpc.Artifact{},
+	}); err != nil {
+		t.Fatalf("Setup: CreateArtifact() returned error: %s", err)
+	}
+
+	// Changing the type of invalid contents to a validated type is rejected.
+	req := &rpc.UpdateApiSpecRequest{
+		ApiSpec:    &rpc.ApiSpec{Name: spec.GetName(), MimeType: "application/x.openapi;version=3"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"mime_type"}},
+	}
+	if _, err := server.UpdateApiSpec(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("UpdateApiSpec(%+v) returned status code %q, want %q: %v", req, status.Code(err), codes.InvalidArgument, err)
+	}
+	got, err := server.GetApiSpec(ctx, &rpc.GetApiSpecRequest{Name: spec.GetName()})
+	if err != nil {
+		t.Fatalf("GetApiSpec() returned error: %s", err)
+	}
+	if got.GetMimeType() != spec.GetMimeType() {
+		t.Errorf("GetApiSpec() returned MIME type %q, want rejected update to be discarded", got.GetMimeType())
+	}
+}
@@ -17,6 +17,7 @@ package remote
 import (
 	"context"
 	"errors"
+	"io"
 	"log"
 	"strings"
 
@@ -43,6 +44,7 @@ type Proxy struct {
 
 	rpc.UnimplementedRegistryServer
 	rpc.UnimplementedAdminServer
+	longrunning.UnimplementedOperationsServer
 }
 
 func (p *Proxy) Open(ctx context.Context) error {
@@ -120,6 +122,135 @@ func (p *Proxy) MigrateDatabase(ctx context.Context, req *rpc.MigrateDatabaseReq
 	return p.adminClient.GrpcClient().MigrateDatabase(ctx, req)
 }
 
+func (p *Proxy) ExportProject(ctx context.Context, req *rpc.ExportProjectRequest) (*longrunning.Operation, error) {
+	if p.adminClient == nil {
+		return nil, ErrAdminServiceUnavailable
+	}
+	return p.adminClient.GrpcClient().ExportProject(ctx, req)
+}
+
+func (p *Proxy) ImportProject(ctx context.Context, req *rpc.ImportProjectRequest) (*longrunning.Operation, error) {
+	if p.adminClient == nil {
+		return nil, ErrAdminServiceUnavailable
+	}
+	return p.adminClient.GrpcClient().ImportProject(ctx, req)
+}
+
+func (p *Proxy) StreamArchive(req *rpc.StreamArchiveRequest, stream rpc.Admin_StreamArchiveServer) error {
+	if p.adminClient == nil {
+		return ErrAdminServiceUnavailable
+	}
+	ctx := stream.Context()
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, v := range md {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v[0])
+	}
+	client, err := p.adminClient.GrpcClient().StreamArchive(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		response, err := client.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *Proxy) UploadArchive(stream rpc.Admin_UploadArchiveServer) error {
+	if p.adminClient == nil {
+		return ErrAdminServiceUnavailable
+	}
+	client, err := p.adminClient.GrpcClient().UploadArchive(stream.Context())
+	if err != nil {
+		return err
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if err := client.Send(req); err != nil {
+			return err
+		}
+	}
+	response, err := client.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(response)
+}
+
+func (p *Proxy) BulkDelete(ctx context.Context, req *rpc.BulkDeleteRequest) (*longrunning.Operation, error) {
+	if p.adminClient == nil {
+		return nil, ErrAdminServiceUnavailable
+	}
+	return p.adminClient.GrpcClient().BulkDelete(ctx, req)
+}
+
+// Operations
+
+func (p *Proxy) operationsClient() (longrunning.OperationsClient, error) {
+	if p.adminClient == nil {
+		return nil, ErrAdminServiceUnavailable
+	}
+	return longrunning.NewOperationsClient(p.adminClient.Connection()), nil
+}
+
+func (p *Proxy) GetOperation(ctx context.Context, req *longrunning.GetOperationRequest) (*longrunning.Operation, error) {
+	c, err := p.operationsClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetOperation(ctx, req)
+}
+
+func (p *Proxy) ListOperations(ctx context.Context, req *longrunning.ListOperationsRequest) (*longrunning.ListOperationsResponse, error) {
+	c, err := p.operationsClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.ListOperations(ctx, req)
+}
+
+func (p *Proxy) DeleteOperation(ctx context.Context, req *longrunning.DeleteOperationRequest) (*emptypb.Empty, error) {
+	c, err := p.operationsClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.DeleteOperation(ctx, req)
+}
+
+func (p *Proxy) CancelOperation(ctx context.Context, req *longrunning.CancelOperationRequest) (*emptypb.Empty, error) {
+	c, err := p.operationsClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.CancelOperation(ctx, req)
+}
+
+func (p *Proxy) WaitOperation(ctx context.Context, req *longrunning.WaitOperationRequest) (*longrunning.Operation, error) {
+	c, err := p.operationsClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.WaitOperation(ctx, req)
+}
+
+func (p *Proxy) ListAuditEvents(ctx context.Context, req *rpc.ListAuditEventsRequest) (*rpc.ListAuditEventsResponse, error) {
+	if p.adminClient == nil {
+		return nil, ErrAdminServiceUnavailable
+	}
+	return p.adminClient.GrpcClient().ListAuditEvents(ctx, req)
+}
+
 // Projects
 
 func (p *Proxy) GetProject(ctx context.Context, req *rpc.GetProjectRequest) (*rpc.Project, error) {
@@ -161,6 +292,13 @@ func (p *Proxy) DeleteProject(ctx context.Context, req *rpc.DeleteProjectRequest
 	return p.adminClient.GrpcClient().DeleteProject(ctx, req)
 }
 
+func (p *Proxy) ListReferences(ctx context.Context, req *rpc.ListReferencesRequest) (*rpc.ListReferencesResponse, error) {
+	if p.adminClient == nil {
+		return nil, ErrAdminServiceUnavailable
+	}
+	return p.adminClient.GrpcClient().ListReferences(ctx, req)
+}
+
 // Apis
 
 func (p *Proxy) GetApi(ctx context.Context, req *rpc.GetApiRequest) (*rpc.Api, error) {
@@ -513,6 +651,89 @@ func (p *Proxy) CreateArtifact(ctx context.Context, req *rpc.CreateArtifactReque
 	return response, err
 }
 
+func (p *Proxy) StreamApiSpecContents(req *rpc.StreamApiSpecContentsRequest, stream rpc.Registry_StreamApiSpecContentsServer) error {
+	ctx := stream.Context()
+	req, _ = proto.Clone(req).(*rpc.StreamApiSpecContentsRequest)
+	req.Name = p.hostedResourceName(req.Name)
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, v := range md {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v[0])
+	}
+	client, err := p.registryClient.GrpcClient().StreamApiSpecContents(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		response, err := client.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *Proxy) UploadApiSpecContents(stream rpc.Registry_UploadApiSpecContentsServer) error {
+	client, err := p.registryClient.GrpcClient().UploadApiSpecContents(stream.Context())
+	if err != nil {
+		return err
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if req.ApiSpec != nil {
+			req.ApiSpec.Name = p.hostedResourceName(req.ApiSpec.Name)
+		}
+		if err := client.Send(req); err != nil {
+			return err
+		}
+	}
+	response, err := client.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if response.ApiSpec != nil {
+		response.ApiSpec.Name = p.testResourceName(response.ApiSpec.Name)
+	}
+	return stream.SendAndClose(response)
+}
+
+func (p *Proxy) UploadArtifactContents(stream rpc.Registry_UploadArtifactContentsServer) error {
+	client, err := p.registryClient.GrpcClient().UploadArtifactContents(stream.Context())
+	if err != nil {
+		return err
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if req.Artifact != nil {
+			req.Artifact.Name = p.hostedResourceName(req.Artifact.Name)
+		}
+		if err := client.Send(req); err != nil {
+			return err
+		}
+	}
+	response, err := client.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if response.Artifact != nil {
+		response.Artifact.Name = p.testResourceName(response.Artifact.Name)
+	}
+	return stream.SendAndClose(response)
+}
+
 func (p *Proxy) ReplaceArtifact(ctx context.Context, req *rpc.ReplaceArtifactRequest) (*rpc.Artifact, error) {
 	req, _ = proto.Clone(req).(*rpc.ReplaceArtifactRequest)
 	if req.Artifact != nil {
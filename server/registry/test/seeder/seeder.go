@@ -17,7 +17,6 @@ package seeder
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/apigee/registry/rpc"
 	"github.com/apigee/registry/server/registry/names"
@@ -174,8 +173,8 @@ func seedApi(ctx context.Context, s Registry, api *rpc.Api, history map[string]b
 		return err
 	}
 
-	if parent := strings.TrimSuffix(name.Parent(), "/locations/global"); !history[parent] {
-		if err := seedProject(ctx, s, &rpc.Project{Name: fmt.Sprintf("projects/%s", name.ProjectID)}, history); err != nil {
+	if parent := name.Project().String(); !history[parent] {
+		if err := seedProject(ctx, s, &rpc.Project{Name: parent}, history); err != nil {
 			return err
 		}
 	}
@@ -274,7 +273,12 @@ func seedArtifact(ctx context.Context, s Registry, a *rpc.Artifact, history map[
 		return err
 	}
 
-	if parent := strings.TrimSuffix(name.Parent(), "/locations/global"); !history[parent] {
+	parent := name.Parent()
+	if name.ApiID() == "" {
+		// Locations aren't stored, so project artifacts are seeded in their projects.
+		parent = names.Project{ProjectID: name.ProjectID()}.String()
+	}
+	if !history[parent] {
 		if name.SpecID() != "" {
 			err = seedSpec(ctx, s, &rpc.ApiSpec{Name: parent}, history)
 		} else if name.VersionID() != "" {